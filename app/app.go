@@ -10,7 +10,9 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memorydb"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/infrastructure/metrics"
 	"github.com/kaspanet/kaspad/infrastructure/os/execenv"
 	"github.com/kaspanet/kaspad/infrastructure/os/limits"
 	"github.com/kaspanet/kaspad/infrastructure/os/signal"
@@ -89,6 +91,11 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 	}
 	profiling.TrackHeap(app.cfg.AppDir, log)
 
+	// Enable the metrics server if requested.
+	if app.cfg.MetricsListen != "" {
+		metrics.Start(app.cfg.MetricsListen)
+	}
+
 	// Return now if an interrupt signal was triggered.
 	if signal.InterruptRequested(interrupt) {
 		return nil
@@ -122,6 +129,15 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 		return nil
 	}
 
+	if app.cfg.VerifyDB {
+		return app.verifyDB(databaseContext)
+	}
+
+	if app.cfg.ExportBlocks != "" || app.cfg.ImportBlocks != "" ||
+		app.cfg.ExportUTXOSnapshot != "" || app.cfg.ImportUTXOSnapshot != "" {
+		return app.exportOrImport(databaseContext, interrupt)
+	}
+
 	// Create componentManager and start it.
 	componentManager, err := NewComponentManager(app.cfg, databaseContext, interrupt)
 	if err != nil {
@@ -161,6 +177,32 @@ func (app *kaspadApp) main(startedChan chan<- struct{}) error {
 	return nil
 }
 
+// exportOrImport runs the --exportblocks, --importblocks, --exportutxosnapshot or --importutxosnapshot
+// mode, in which kaspad doesn't connect to peers and exits once it's done
+func (app *kaspadApp) exportOrImport(databaseContext database.Database, interrupt <-chan struct{}) error {
+	domain, err := newDomain(app.cfg, databaseContext)
+	if err != nil {
+		log.Errorf("Unable to create the domain: %+v", err)
+		return err
+	}
+
+	switch {
+	case app.cfg.ExportBlocks != "":
+		err = exportBlocks(app.cfg, domain.Consensus(), app.cfg.ExportBlocks, interrupt)
+	case app.cfg.ImportBlocks != "":
+		err = importBlocks(app.cfg, domain.Consensus(), app.cfg.ImportBlocks, interrupt)
+	case app.cfg.ExportUTXOSnapshot != "":
+		err = exportUTXOSnapshot(app.cfg, domain.Consensus(), app.cfg.ExportUTXOSnapshot, interrupt)
+	default:
+		err = importUTXOSnapshot(app.cfg, domain, app.cfg.ImportUTXOSnapshot, interrupt)
+	}
+	if err != nil {
+		log.Errorf("%+v", err)
+		return err
+	}
+	return nil
+}
+
 // dbPath returns the path to the block database given a database type.
 func databasePath(cfg *config.Config) string {
 	return filepath.Join(cfg.AppDir, defaultDataDirname)
@@ -172,8 +214,17 @@ func removeDatabase(cfg *config.Config) error {
 }
 
 func openDB(cfg *config.Config) (database.Database, error) {
+	if cfg.DbType == config.DBTypeMemory {
+		log.Warnf("Using an in-memory database. All data will be lost on shutdown")
+		return memorydb.NewMemoryDB(), nil
+	}
+
 	dbPath := databasePath(cfg)
 
+	if cfg.VerifyDB {
+		return openDBReadOnly(dbPath)
+	}
+
 	err := checkDatabaseVersion(dbPath)
 	if err != nil {
 		return nil, err
@@ -187,3 +238,20 @@ func openDB(cfg *config.Config) (database.Database, error) {
 
 	return db, nil
 }
+
+// openDBReadOnly opens an existing database without allowing any writes to it
+func openDBReadOnly(dbPath string) (database.Database, error) {
+	log.Infof("Loading database from '%s' in read-only mode", dbPath)
+	db, err := ldb.NewLevelDBReadOnly(dbPath, leveldbCacheSizeMiB)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDatabaseVersionReadOnly(dbPath)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -49,3 +49,8 @@ func RPCErrorf(format string, args ...interface{}) *RPCError {
 		Message: fmt.Sprintf(format, args...),
 	}
 }
+
+// RPCErrorCarrier is an RPC response message that may carry an RPC error
+type RPCErrorCarrier interface {
+	RPCError() *RPCError
+}
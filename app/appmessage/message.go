@@ -167,6 +167,12 @@ const (
 	CmdGetFeeEstimateResponseMessage
 	CmdSubmitTransactionReplacementRequestMessage
 	CmdSubmitTransactionReplacementResponseMessage
+	CmdGetTransactionRequestMessage
+	CmdGetTransactionResponseMessage
+	CmdGenerateBlocksRequestMessage
+	CmdGenerateBlocksResponseMessage
+	CmdGetPayoutTransactionRequestMessage
+	CmdGetPayoutTransactionResponseMessage
 )
 
 // ProtocolMessageCommandToString maps all MessageCommands to their string representation
@@ -263,6 +269,8 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdFinalityConflictResolvedNotificationMessage:                "FinalityConflictResolvedNotification",
 	CmdGetMempoolEntriesRequestMessage:                            "GetMempoolEntriesRequest",
 	CmdGetMempoolEntriesResponseMessage:                           "GetMempoolEntriesResponse",
+	CmdShutDownRequestMessage:                                     "ShutDownRequest",
+	CmdShutDownResponseMessage:                                    "ShutDownResponse",
 	CmdGetHeadersRequestMessage:                                   "GetHeadersRequest",
 	CmdGetHeadersResponseMessage:                                  "GetHeadersResponse",
 	CmdNotifyUTXOsChangedRequestMessage:                           "NotifyUTXOsChangedRequest",
@@ -308,6 +316,12 @@ var RPCMessageCommandToString = map[MessageCommand]string{
 	CmdGetFeeEstimateResponseMessage:                              "GetFeeEstimateResponse",
 	CmdSubmitTransactionReplacementRequestMessage:                 "SubmitTransactionReplacementRequest",
 	CmdSubmitTransactionReplacementResponseMessage:                "SubmitTransactionReplacementResponse",
+	CmdGetTransactionRequestMessage:                               "GetTransactionRequest",
+	CmdGetTransactionResponseMessage:                              "GetTransactionResponse",
+	CmdGenerateBlocksRequestMessage:                               "GenerateBlocksRequest",
+	CmdGenerateBlocksResponseMessage:                              "GenerateBlocksResponse",
+	CmdGetPayoutTransactionRequestMessage:                         "GetPayoutTransactionRequest",
+	CmdGetPayoutTransactionResponseMessage:                        "GetPayoutTransactionResponse",
 }
 
 // Message is an interface that describes a kaspa message. A type that
@@ -33,6 +33,11 @@ func (msg *AddPeerResponseMessage) Command() MessageCommand {
 	return CmdAddPeerResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *AddPeerResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewAddPeerResponseMessage returns a instance of the message
 func NewAddPeerResponseMessage() *AddPeerResponseMessage {
 	return &AddPeerResponseMessage{}
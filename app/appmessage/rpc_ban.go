@@ -33,6 +33,11 @@ func (msg *BanResponseMessage) Command() MessageCommand {
 	return CmdBanResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *BanResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewBanResponseMessage returns a instance of the message
 func NewBanResponseMessage() *BanResponseMessage {
 	return &BanResponseMessage{}
@@ -35,6 +35,11 @@ func (msg *EstimateNetworkHashesPerSecondResponseMessage) Command() MessageComma
 	return CmdEstimateNetworkHashesPerSecondResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *EstimateNetworkHashesPerSecondResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewEstimateNetworkHashesPerSecondResponseMessage returns a instance of the message
 func NewEstimateNetworkHashesPerSecondResponseMessage(networkHashesPerSecond uint64) *EstimateNetworkHashesPerSecondResponseMessage {
 	return &EstimateNetworkHashesPerSecondResponseMessage{
@@ -16,18 +16,22 @@ func NewGetFeeEstimateRequestMessage() *GetFeeEstimateRequestMessage {
 	return &GetFeeEstimateRequestMessage{}
 }
 
+// RPCFeeRateBucket is a fee rate recommendation, in sompi per gram,
+// paired with its estimated inclusion time
 type RPCFeeRateBucket struct {
 	Feerate          float64
 	EstimatedSeconds float64
 }
 
+// RPCFeeEstimate is a set of fee rate recommendations, ordered by
+// descending fee rate when chaining priority | normal | low
 type RPCFeeEstimate struct {
 	PriorityBucket RPCFeeRateBucket
 	NormalBuckets  []RPCFeeRateBucket
 	LowBuckets     []RPCFeeRateBucket
 }
 
-// GetCoinSupplyResponseMessage is an appmessage corresponding to
+// GetFeeEstimateResponseMessage is an appmessage corresponding to
 // its respective RPC message
 type GetFeeEstimateResponseMessage struct {
 	baseMessage
@@ -41,6 +45,11 @@ func (msg *GetFeeEstimateResponseMessage) Command() MessageCommand {
 	return CmdGetFeeEstimateResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetFeeEstimateResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetFeeEstimateResponseMessage returns a instance of the message
 func NewGetFeeEstimateResponseMessage() *GetFeeEstimateResponseMessage {
 	return &GetFeeEstimateResponseMessage{}
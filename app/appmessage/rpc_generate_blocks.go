@@ -0,0 +1,50 @@
+package appmessage
+
+// GenerateBlocksRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GenerateBlocksRequestMessage struct {
+	baseMessage
+	Count          uint32
+	PayAddress     string
+	TransactionIDs []string
+}
+
+// Command returns the protocol command string for the message
+func (msg *GenerateBlocksRequestMessage) Command() MessageCommand {
+	return CmdGenerateBlocksRequestMessage
+}
+
+// NewGenerateBlocksRequestMessage returns a instance of the message
+func NewGenerateBlocksRequestMessage(count uint32, payAddress string, transactionIDs []string) *GenerateBlocksRequestMessage {
+	return &GenerateBlocksRequestMessage{
+		Count:          count,
+		PayAddress:     payAddress,
+		TransactionIDs: transactionIDs,
+	}
+}
+
+// GenerateBlocksResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GenerateBlocksResponseMessage struct {
+	baseMessage
+	BlockHashes []string
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GenerateBlocksResponseMessage) Command() MessageCommand {
+	return CmdGenerateBlocksResponseMessage
+}
+
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GenerateBlocksResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
+// NewGenerateBlocksResponseMessage returns a instance of the message
+func NewGenerateBlocksResponseMessage(blockHashes []string) *GenerateBlocksResponseMessage {
+	return &GenerateBlocksResponseMessage{
+		BlockHashes: blockHashes,
+	}
+}
@@ -33,6 +33,11 @@ func (msg *GetBalanceByAddressResponseMessage) Command() MessageCommand {
 	return CmdGetBalanceByAddressResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBalanceByAddressResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBalanceByAddressResponse returns an instance of the message
 func NewGetBalanceByAddressResponse(Balance uint64) *GetBalanceByAddressResponseMessage {
 	return &GetBalanceByAddressResponseMessage{
@@ -39,6 +39,11 @@ func (msg *GetBalancesByAddressesResponseMessage) Command() MessageCommand {
 	return CmdGetBalancesByAddressesResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBalancesByAddressesResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBalancesByAddressesResponse returns an instance of the message
 func NewGetBalancesByAddressesResponse(entries []*BalancesByAddressesEntry) *GetBalancesByAddressesResponseMessage {
 	return &GetBalancesByAddressesResponseMessage{
@@ -35,6 +35,11 @@ func (msg *GetBlockResponseMessage) Command() MessageCommand {
 	return CmdGetBlockResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBlockResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBlockResponseMessage returns a instance of the message
 func NewGetBlockResponseMessage() *GetBlockResponseMessage {
 	return &GetBlockResponseMessage{}
@@ -33,6 +33,11 @@ func (msg *GetBlockCountResponseMessage) Command() MessageCommand {
 	return CmdGetBlockCountResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBlockCountResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBlockCountResponseMessage returns a instance of the message
 func NewGetBlockCountResponseMessage(syncInfo *externalapi.SyncInfo) *GetBlockCountResponseMessage {
 	return &GetBlockCountResponseMessage{
@@ -38,6 +38,11 @@ func (msg *GetBlockDAGInfoResponseMessage) Command() MessageCommand {
 	return CmdGetBlockDAGInfoResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBlockDAGInfoResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBlockDAGInfoResponseMessage returns a instance of the message
 func NewGetBlockDAGInfoResponseMessage() *GetBlockDAGInfoResponseMessage {
 	return &GetBlockDAGInfoResponseMessage{}
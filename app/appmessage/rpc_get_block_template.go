@@ -6,6 +6,14 @@ type GetBlockTemplateRequestMessage struct {
 	baseMessage
 	PayAddress string
 	ExtraData  string
+	Payouts    []*RPCBlockTemplatePayout
+}
+
+// RPCBlockTemplatePayout is a weighted address that the coinbase block rewards
+// paid into the pay address of a block template are distributed to
+type RPCBlockTemplatePayout struct {
+	Address string
+	Weight  uint32
 }
 
 // Command returns the protocol command string for the message
@@ -21,6 +29,17 @@ func NewGetBlockTemplateRequestMessage(payAddress, extraData string) *GetBlockTe
 	}
 }
 
+// NewGetBlockTemplateWithPayoutsRequestMessage returns a instance of the message
+func NewGetBlockTemplateWithPayoutsRequestMessage(payAddress string, payouts []*RPCBlockTemplatePayout,
+	extraData string) *GetBlockTemplateRequestMessage {
+
+	return &GetBlockTemplateRequestMessage{
+		PayAddress: payAddress,
+		ExtraData:  extraData,
+		Payouts:    payouts,
+	}
+}
+
 // GetBlockTemplateResponseMessage is an appmessage corresponding to
 // its respective RPC message
 type GetBlockTemplateResponseMessage struct {
@@ -36,6 +55,11 @@ func (msg *GetBlockTemplateResponseMessage) Command() MessageCommand {
 	return CmdGetBlockTemplateResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBlockTemplateResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBlockTemplateResponseMessage returns a instance of the message
 func NewGetBlockTemplateResponseMessage(block *RPCBlock, isSynced bool) *GetBlockTemplateResponseMessage {
 	return &GetBlockTemplateResponseMessage{
@@ -39,6 +39,11 @@ func (msg *GetBlocksResponseMessage) Command() MessageCommand {
 	return CmdGetBlocksResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetBlocksResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetBlocksResponseMessage returns a instance of the message
 func NewGetBlocksResponseMessage() *GetBlocksResponseMessage {
 	return &GetBlocksResponseMessage{}
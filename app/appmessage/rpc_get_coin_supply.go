@@ -31,6 +31,11 @@ func (msg *GetCoinSupplyResponseMessage) Command() MessageCommand {
 	return CmdGetCoinSupplyResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetCoinSupplyResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetCoinSupplyResponseMessage returns a instance of the message
 func NewGetCoinSupplyResponseMessage(maxSompi uint64, circulatingSompi uint64) *GetCoinSupplyResponseMessage {
 	return &GetCoinSupplyResponseMessage{
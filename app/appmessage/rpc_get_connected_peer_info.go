@@ -29,6 +29,11 @@ func (msg *GetConnectedPeerInfoResponseMessage) Command() MessageCommand {
 	return CmdGetConnectedPeerInfoResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetConnectedPeerInfoResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetConnectedPeerInfoResponseMessage returns a instance of the message
 func NewGetConnectedPeerInfoResponseMessage(infos []*GetConnectedPeerInfoMessage) *GetConnectedPeerInfoResponseMessage {
 	return &GetConnectedPeerInfoResponseMessage{
@@ -47,4 +52,6 @@ type GetConnectedPeerInfoMessage struct {
 	AdvertisedProtocolVersion uint32
 	TimeConnected             int64
 	IsIBDPeer                 bool
+	IsWhitelisted             bool
+	BanScore                  uint32
 }
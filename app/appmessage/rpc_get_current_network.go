@@ -30,6 +30,11 @@ func (msg *GetCurrentNetworkResponseMessage) Command() MessageCommand {
 	return CmdGetCurrentNetworkResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetCurrentNetworkResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetCurrentNetworkResponseMessage returns a instance of the message
 func NewGetCurrentNetworkResponseMessage(currentNetwork string) *GetCurrentNetworkResponseMessage {
 	return &GetCurrentNetworkResponseMessage{
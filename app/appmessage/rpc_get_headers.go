@@ -37,6 +37,11 @@ func (msg *GetHeadersResponseMessage) Command() MessageCommand {
 	return CmdGetHeadersResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetHeadersResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetHeadersResponseMessage returns a instance of the message
 func NewGetHeadersResponseMessage(headers []string) *GetHeadersResponseMessage {
 	return &GetHeadersResponseMessage{
@@ -34,6 +34,11 @@ func (msg *GetInfoResponseMessage) Command() MessageCommand {
 	return CmdGetInfoResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetInfoResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetInfoResponseMessage returns a instance of the message
 func NewGetInfoResponseMessage(p2pID string, mempoolSize uint64, serverVersion string, isUtxoIndexed bool, isSynced bool) *GetInfoResponseMessage {
 	return &GetInfoResponseMessage{
@@ -35,6 +35,11 @@ func (msg *GetMempoolEntriesResponseMessage) Command() MessageCommand {
 	return CmdGetMempoolEntriesResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetMempoolEntriesResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetMempoolEntriesResponseMessage returns a instance of the message
 func NewGetMempoolEntriesResponseMessage(entries []*MempoolEntry) *GetMempoolEntriesResponseMessage {
 	return &GetMempoolEntriesResponseMessage{
@@ -44,6 +44,11 @@ func (msg *GetMempoolEntriesByAddressesResponseMessage) Command() MessageCommand
 	return CmdGetMempoolEntriesByAddressesResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetMempoolEntriesByAddressesResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetMempoolEntriesByAddressesResponseMessage returns a instance of the message
 func NewGetMempoolEntriesByAddressesResponseMessage(entries []*MempoolEntryByAddress) *GetMempoolEntriesByAddressesResponseMessage {
 	return &GetMempoolEntriesByAddressesResponseMessage{
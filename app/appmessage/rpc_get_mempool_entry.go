@@ -44,6 +44,11 @@ func (msg *GetMempoolEntryResponseMessage) Command() MessageCommand {
 	return CmdGetMempoolEntryResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetMempoolEntryResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetMempoolEntryResponseMessage returns a instance of the message
 func NewGetMempoolEntryResponseMessage(fee uint64, transaction *RPCTransaction, isOrphan bool) *GetMempoolEntryResponseMessage {
 	return &GetMempoolEntryResponseMessage{
@@ -0,0 +1,52 @@
+package appmessage
+
+// GetPayoutTransactionRequestMessage is an appmessage corresponding to
+// its respective RPC message
+type GetPayoutTransactionRequestMessage struct {
+	baseMessage
+	PayAddress string
+	FeeRate    float64
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetPayoutTransactionRequestMessage) Command() MessageCommand {
+	return CmdGetPayoutTransactionRequestMessage
+}
+
+// NewGetPayoutTransactionRequestMessage returns a instance of the message
+func NewGetPayoutTransactionRequestMessage(payAddress string, feeRate float64) *GetPayoutTransactionRequestMessage {
+	return &GetPayoutTransactionRequestMessage{
+		PayAddress: payAddress,
+		FeeRate:    feeRate,
+	}
+}
+
+// GetPayoutTransactionResponseMessage is an appmessage corresponding to
+// its respective RPC message
+type GetPayoutTransactionResponseMessage struct {
+	baseMessage
+	Transaction *RPCTransaction
+	UTXOEntries []*RPCUTXOEntry
+
+	Error *RPCError
+}
+
+// Command returns the protocol command string for the message
+func (msg *GetPayoutTransactionResponseMessage) Command() MessageCommand {
+	return CmdGetPayoutTransactionResponseMessage
+}
+
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetPayoutTransactionResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
+// NewGetPayoutTransactionResponseMessage returns a instance of the message
+func NewGetPayoutTransactionResponseMessage(transaction *RPCTransaction,
+	utxoEntries []*RPCUTXOEntry) *GetPayoutTransactionResponseMessage {
+
+	return &GetPayoutTransactionResponseMessage{
+		Transaction: transaction,
+		UTXOEntries: utxoEntries,
+	}
+}
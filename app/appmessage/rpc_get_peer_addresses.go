@@ -31,6 +31,11 @@ func (msg *GetPeerAddressesResponseMessage) Command() MessageCommand {
 	return CmdGetPeerAddressesResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetPeerAddressesResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetPeerAddressesResponseMessage returns a instance of the message
 func NewGetPeerAddressesResponseMessage(addresses []*GetPeerAddressesKnownAddressMessage, bannedAddresses []*GetPeerAddressesKnownAddressMessage) *GetPeerAddressesResponseMessage {
 	return &GetPeerAddressesResponseMessage{
@@ -30,6 +30,11 @@ func (msg *GetSelectedTipHashResponseMessage) Command() MessageCommand {
 	return CmdGetSelectedTipHashResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetSelectedTipHashResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetSelectedTipHashResponseMessage returns a instance of the message
 func NewGetSelectedTipHashResponseMessage(selectedTipHash string) *GetSelectedTipHashResponseMessage {
 	return &GetSelectedTipHashResponseMessage{
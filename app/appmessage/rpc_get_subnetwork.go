@@ -33,6 +33,11 @@ func (msg *GetSubnetworkResponseMessage) Command() MessageCommand {
 	return CmdGetSubnetworkResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetSubnetworkResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetSubnetworkResponseMessage returns a instance of the message
 func NewGetSubnetworkResponseMessage(gasLimit uint64) *GetSubnetworkResponseMessage {
 	return &GetSubnetworkResponseMessage{
@@ -33,6 +33,11 @@ func (msg *GetUTXOsByAddressesResponseMessage) Command() MessageCommand {
 	return CmdGetUTXOsByAddressesResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetUTXOsByAddressesResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetUTXOsByAddressesResponseMessage returns a instance of the message
 func NewGetUTXOsByAddressesResponseMessage(entries []*UTXOsByAddressesEntry) *GetUTXOsByAddressesResponseMessage {
 	return &GetUTXOsByAddressesResponseMessage{
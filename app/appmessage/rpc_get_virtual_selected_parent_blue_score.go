@@ -30,6 +30,11 @@ func (msg *GetVirtualSelectedParentBlueScoreResponseMessage) Command() MessageCo
 	return CmdGetVirtualSelectedParentBlueScoreResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetVirtualSelectedParentBlueScoreResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetVirtualSelectedParentBlueScoreResponseMessage returns a instance of the message
 func NewGetVirtualSelectedParentBlueScoreResponseMessage(blueScore uint64) *GetVirtualSelectedParentBlueScoreResponseMessage {
 	return &GetVirtualSelectedParentBlueScoreResponseMessage{
@@ -46,6 +46,11 @@ func (msg *GetVirtualSelectedParentChainFromBlockResponseMessage) Command() Mess
 	return CmdGetVirtualSelectedParentChainFromBlockResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *GetVirtualSelectedParentChainFromBlockResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewGetVirtualSelectedParentChainFromBlockResponseMessage returns a instance of the message
 func NewGetVirtualSelectedParentChainFromBlockResponseMessage(removedChainBlockHashes,
 	addedChainBlockHashes []string, acceptedTransactionIDs []*AcceptedTransactionIDs) *GetVirtualSelectedParentChainFromBlockResponseMessage {
@@ -28,6 +28,11 @@ func (msg *NotifyBlockAddedResponseMessage) Command() MessageCommand {
 	return CmdNotifyBlockAddedResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyBlockAddedResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyBlockAddedResponseMessage returns a instance of the message
 func NewNotifyBlockAddedResponseMessage() *NotifyBlockAddedResponseMessage {
 	return &NotifyBlockAddedResponseMessage{}
@@ -28,6 +28,11 @@ func (msg *NotifyFinalityConflictsResponseMessage) Command() MessageCommand {
 	return CmdNotifyFinalityConflictsResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyFinalityConflictsResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyFinalityConflictsResponseMessage returns a instance of the message
 func NewNotifyFinalityConflictsResponseMessage() *NotifyFinalityConflictsResponseMessage {
 	return &NotifyFinalityConflictsResponseMessage{}
@@ -28,6 +28,11 @@ func (msg *NotifyNewBlockTemplateResponseMessage) Command() MessageCommand {
 	return CmdNotifyNewBlockTemplateResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyNewBlockTemplateResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyNewBlockTemplateResponseMessage returns an instance of the message
 func NewNotifyNewBlockTemplateResponseMessage() *NotifyNewBlockTemplateResponseMessage {
 	return &NotifyNewBlockTemplateResponseMessage{}
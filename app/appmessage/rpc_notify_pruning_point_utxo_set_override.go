@@ -28,6 +28,11 @@ func (msg *NotifyPruningPointUTXOSetOverrideResponseMessage) Command() MessageCo
 	return CmdNotifyPruningPointUTXOSetOverrideResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyPruningPointUTXOSetOverrideResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyPruningPointUTXOSetOverrideResponseMessage returns a instance of the message
 func NewNotifyPruningPointUTXOSetOverrideResponseMessage() *NotifyPruningPointUTXOSetOverrideResponseMessage {
 	return &NotifyPruningPointUTXOSetOverrideResponseMessage{}
@@ -77,6 +82,11 @@ func (msg *StopNotifyingPruningPointUTXOSetOverrideResponseMessage) Command() Me
 	return CmdNotifyPruningPointUTXOSetOverrideResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *StopNotifyingPruningPointUTXOSetOverrideResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewStopNotifyingPruningPointUTXOSetOverrideResponseMessage returns a instance of the message
 func NewStopNotifyingPruningPointUTXOSetOverrideResponseMessage() *StopNotifyingPruningPointUTXOSetOverrideResponseMessage {
 	return &StopNotifyingPruningPointUTXOSetOverrideResponseMessage{}
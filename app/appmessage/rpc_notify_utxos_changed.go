@@ -31,6 +31,11 @@ func (msg *NotifyUTXOsChangedResponseMessage) Command() MessageCommand {
 	return CmdNotifyUTXOsChangedResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyUTXOsChangedResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyUTXOsChangedResponseMessage returns a instance of the message
 func NewNotifyUTXOsChangedResponseMessage() *NotifyUTXOsChangedResponseMessage {
 	return &NotifyUTXOsChangedResponseMessage{}
@@ -28,6 +28,11 @@ func (msg *NotifyVirtualDaaScoreChangedResponseMessage) Command() MessageCommand
 	return CmdNotifyVirtualDaaScoreChangedResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyVirtualDaaScoreChangedResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyVirtualDaaScoreChangedResponseMessage returns a instance of the message
 func NewNotifyVirtualDaaScoreChangedResponseMessage() *NotifyVirtualDaaScoreChangedResponseMessage {
 	return &NotifyVirtualDaaScoreChangedResponseMessage{}
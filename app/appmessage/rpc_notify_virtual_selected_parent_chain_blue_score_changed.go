@@ -28,6 +28,11 @@ func (msg *NotifyVirtualSelectedParentBlueScoreChangedResponseMessage) Command()
 	return CmdNotifyVirtualSelectedParentBlueScoreChangedResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyVirtualSelectedParentBlueScoreChangedResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyVirtualSelectedParentBlueScoreChangedResponseMessage returns a instance of the message
 func NewNotifyVirtualSelectedParentBlueScoreChangedResponseMessage() *NotifyVirtualSelectedParentBlueScoreChangedResponseMessage {
 	return &NotifyVirtualSelectedParentBlueScoreChangedResponseMessage{}
@@ -33,6 +33,11 @@ func (msg *NotifyVirtualSelectedParentChainChangedResponseMessage) Command() Mes
 	return CmdNotifyVirtualSelectedParentChainChangedResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *NotifyVirtualSelectedParentChainChangedResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewNotifyVirtualSelectedParentChainChangedResponseMessage returns a instance of the message
 func NewNotifyVirtualSelectedParentChainChangedResponseMessage() *NotifyVirtualSelectedParentChainChangedResponseMessage {
 	return &NotifyVirtualSelectedParentChainChangedResponseMessage{}
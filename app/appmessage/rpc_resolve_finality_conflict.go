@@ -31,6 +31,11 @@ func (msg *ResolveFinalityConflictResponseMessage) Command() MessageCommand {
 	return CmdResolveFinalityConflictResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *ResolveFinalityConflictResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewResolveFinalityConflictResponseMessage returns a instance of the message
 func NewResolveFinalityConflictResponseMessage() *ResolveFinalityConflictResponseMessage {
 	return &ResolveFinalityConflictResponseMessage{}
@@ -28,6 +28,11 @@ func (msg *ShutDownResponseMessage) Command() MessageCommand {
 	return CmdShutDownResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *ShutDownResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewShutDownResponseMessage returns a instance of the message
 func NewShutDownResponseMessage() *ShutDownResponseMessage {
 	return &ShutDownResponseMessage{}
@@ -31,6 +31,11 @@ func (msg *StopNotifyingUTXOsChangedResponseMessage) Command() MessageCommand {
 	return CmdStopNotifyingUTXOsChangedResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *StopNotifyingUTXOsChangedResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewStopNotifyingUTXOsChangedResponseMessage returns a instance of the message
 func NewStopNotifyingUTXOsChangedResponseMessage() *StopNotifyingUTXOsChangedResponseMessage {
 	return &StopNotifyingUTXOsChangedResponseMessage{}
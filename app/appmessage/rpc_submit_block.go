@@ -55,6 +55,11 @@ func (msg *SubmitBlockResponseMessage) Command() MessageCommand {
 	return CmdSubmitBlockResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *SubmitBlockResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewSubmitBlockResponseMessage returns an instance of the message
 func NewSubmitBlockResponseMessage() *SubmitBlockResponseMessage {
 	return &SubmitBlockResponseMessage{}
@@ -35,6 +35,11 @@ func (msg *SubmitTransactionResponseMessage) Command() MessageCommand {
 	return CmdSubmitTransactionResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *SubmitTransactionResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewSubmitTransactionResponseMessage returns a instance of the message
 func NewSubmitTransactionResponseMessage(transactionID string) *SubmitTransactionResponseMessage {
 	return &SubmitTransactionResponseMessage{
@@ -34,6 +34,11 @@ func (msg *SubmitTransactionReplacementResponseMessage) Command() MessageCommand
 	return CmdSubmitTransactionReplacementResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *SubmitTransactionReplacementResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewSubmitTransactionReplacementResponseMessage returns a instance of the message
 func NewSubmitTransactionReplacementResponseMessage(transactionID string) *SubmitTransactionReplacementResponseMessage {
 	return &SubmitTransactionReplacementResponseMessage{
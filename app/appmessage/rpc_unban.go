@@ -33,6 +33,11 @@ func (msg *UnbanResponseMessage) Command() MessageCommand {
 	return CmdUnbanResponseMessage
 }
 
+// RPCError returns the RPC error carried by the message, if any
+func (msg *UnbanResponseMessage) RPCError() *RPCError {
+	return msg.Error
+}
+
 // NewUnbanResponseMessage returns a instance of the message
 func NewUnbanResponseMessage() *UnbanResponseMessage {
 	return &UnbanResponseMessage{}
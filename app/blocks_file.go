@@ -0,0 +1,191 @@
+package app
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/kaspanet/kaspad/domain/consensus/database/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// A blocks file starts with a header of the magic, the format version, the genesis hash of the
+// network the blocks belong to and the number of blocks in the file. The blocks follow in
+// topological order, each one serialized the same way blocks are stored in the database and
+// prefixed with its length. All integers are little-endian.
+const (
+	blocksFileMagic   = "KASBLKS\x00"
+	blocksFileVersion = 1
+
+	blocksFileHeaderLength     = len(blocksFileMagic) + 4 + externalapi.DomainHashSize + 8
+	blocksFileBlockCountOffset = blocksFileHeaderLength - 8
+)
+
+// maxFileRecordSize is the maximum size of a single record in a blocks file or a UTXO snapshot file
+const maxFileRecordSize = 32 * 1024 * 1024
+
+// writeFileRecord writes the given record prefixed with its length
+func writeFileRecord(writer io.Writer, record []byte) error {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(record)))
+	_, err := writer.Write(length[:])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = writer.Write(record)
+	return errors.WithStack(err)
+}
+
+// readFileRecord reads a record written by writeFileRecord. It returns io.EOF if the reader
+// ends right before the record.
+func readFileRecord(reader io.Reader) ([]byte, error) {
+	var length [4]byte
+	_, err := io.ReadFull(reader, length[:])
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, errors.WithStack(err)
+	}
+	recordLength := binary.LittleEndian.Uint32(length[:])
+	if recordLength > maxFileRecordSize {
+		return nil, errors.Errorf("record of %d bytes is larger than the maximum of %d",
+			recordLength, maxFileRecordSize)
+	}
+	record := make([]byte, recordLength)
+	_, err = io.ReadFull(reader, record)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading a record")
+	}
+	return record, nil
+}
+
+// blocksFileWriter writes blocks into a new blocks file
+type blocksFileWriter struct {
+	file       *os.File
+	writer     *bufio.Writer
+	blockCount uint64
+}
+
+// createBlocksFile creates a new blocks file for the network with the given genesis hash.
+// It fails if the file already exists, so that existing files aren't overwritten.
+func createBlocksFile(path string, genesisHash *externalapi.DomainHash) (*blocksFileWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	header := make([]byte, 0, blocksFileHeaderLength)
+	header = append(header, blocksFileMagic...)
+	header = binary.LittleEndian.AppendUint32(header, blocksFileVersion)
+	header = append(header, genesisHash.ByteSlice()...)
+	// The block count is filled in when the file is closed
+	header = binary.LittleEndian.AppendUint64(header, 0)
+
+	writer := bufio.NewWriter(file)
+	_, err = writer.Write(header)
+	if err != nil {
+		file.Close()
+		return nil, errors.WithStack(err)
+	}
+	return &blocksFileWriter{file: file, writer: writer}, nil
+}
+
+func (w *blocksFileWriter) writeBlock(block *externalapi.DomainBlock) error {
+	blockBytes, err := proto.Marshal(serialization.DomainBlockToDbBlock(block))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = writeFileRecord(w.writer, blockBytes)
+	if err != nil {
+		return err
+	}
+	w.blockCount++
+	return nil
+}
+
+// close writes the block count into the header and closes the file
+func (w *blocksFileWriter) close() error {
+	err := w.writer.Flush()
+	if err != nil {
+		w.file.Close()
+		return errors.WithStack(err)
+	}
+	var blockCount [8]byte
+	binary.LittleEndian.PutUint64(blockCount[:], w.blockCount)
+	_, err = w.file.WriteAt(blockCount[:], int64(blocksFileBlockCountOffset))
+	if err != nil {
+		w.file.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(w.file.Close())
+}
+
+// blocksFileReader reads the blocks of a blocks file
+type blocksFileReader struct {
+	file       *os.File
+	reader     *bufio.Reader
+	blockCount uint64
+}
+
+// openBlocksFile opens a blocks file, and checks that it belongs to the network with the given genesis hash
+func openBlocksFile(path string, expectedGenesisHash *externalapi.DomainHash) (*blocksFileReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	reader := bufio.NewReader(file)
+
+	header := make([]byte, blocksFileHeaderLength)
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "error reading the header of %s", path)
+	}
+	if string(header[:len(blocksFileMagic)]) != blocksFileMagic {
+		file.Close()
+		return nil, errors.Errorf("%s is not a blocks file", path)
+	}
+	header = header[len(blocksFileMagic):]
+	version := binary.LittleEndian.Uint32(header[:4])
+	if version != blocksFileVersion {
+		file.Close()
+		return nil, errors.Errorf("%s has unsupported version %d", path, version)
+	}
+	header = header[4:]
+	genesisHash, err := externalapi.NewDomainHashFromByteSlice(header[:externalapi.DomainHashSize])
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !genesisHash.Equal(expectedGenesisHash) {
+		file.Close()
+		return nil, errors.Errorf("%s holds blocks of another network: its genesis is %s while ours is %s",
+			path, genesisHash, expectedGenesisHash)
+	}
+	blockCount := binary.LittleEndian.Uint64(header[externalapi.DomainHashSize:])
+
+	return &blocksFileReader{file: file, reader: reader, blockCount: blockCount}, nil
+}
+
+// readBlock returns the next block in the file, or io.EOF if there are no more blocks
+func (r *blocksFileReader) readBlock() (*externalapi.DomainBlock, error) {
+	blockBytes, err := readFileRecord(r.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	dbBlock := &serialization.DbBlock{}
+	err = proto.Unmarshal(blockBytes, dbBlock)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deserializing a block")
+	}
+	return serialization.DbBlockToDomainBlock(dbBlock)
+}
+
+func (r *blocksFileReader) close() error {
+	return errors.WithStack(r.file.Close())
+}
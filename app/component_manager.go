@@ -12,11 +12,13 @@ import (
 	"github.com/kaspanet/kaspad/app/rpc"
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/txindex"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
+	"github.com/kaspanet/kaspad/infrastructure/network/natmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/id"
 	"github.com/kaspanet/kaspad/util/panics"
@@ -30,6 +32,7 @@ type ComponentManager struct {
 	rpcManager        *rpc.Manager
 	connectionManager *connmanager.ConnectionManager
 	netAdapter        *netadapter.NetAdapter
+	natManager        *natmanager.Manager
 
 	started, shutdown int32
 }
@@ -49,6 +52,10 @@ func (a *ComponentManager) Start() {
 	}
 
 	a.connectionManager.Start()
+
+	if a.natManager != nil {
+		a.natManager.Start()
+	}
 }
 
 // Stop gracefully shuts down all the kaspad services.
@@ -61,6 +68,10 @@ func (a *ComponentManager) Stop() {
 
 	log.Warnf("Kaspad shutting down")
 
+	if a.natManager != nil {
+		a.natManager.Stop()
+	}
+
 	a.connectionManager.Stop()
 
 	err := a.netAdapter.Stop()
@@ -79,16 +90,7 @@ func (a *ComponentManager) Stop() {
 func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database, interrupt chan<- struct{}) (
 	*ComponentManager, error) {
 
-	consensusConfig := consensus.Config{
-		Params:                          *cfg.ActiveNetParams,
-		IsArchival:                      cfg.IsArchivalNode,
-		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
-	}
-	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
-	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
-	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
-
-	domain, err := domain.New(&consensusConfig, mempoolConfig, db)
+	domain, err := newDomain(cfg, db)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +105,23 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		return nil, err
 	}
 
+	var natManager *natmanager.Manager
+	if cfg.Upnp {
+		switch {
+		case cfg.DisableListen:
+			log.Warnf("Not mapping the P2P port with UPnP because listening is disabled")
+		case len(cfg.ExternalIPs) > 0:
+			log.Warnf("Not mapping the P2P port with UPnP because --externalip is set")
+		case cfg.Proxy != "":
+			log.Warnf("Not mapping the P2P port with UPnP because --proxy is set")
+		default:
+			natManager, err = natmanager.New(cfg, addressManager)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	var utxoIndex *utxoindex.UTXOIndex
 	if cfg.UTXOIndex {
 		utxoIndex, err = utxoindex.New(domain, db)
@@ -113,6 +132,16 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		log.Infof("UTXO index started")
 	}
 
+	var txIndex *txindex.TXIndex
+	if cfg.TxIndex {
+		txIndex, err = txindex.New(domain, db)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("TX index started")
+	}
+
 	connectionManager, err := connmanager.New(cfg, netAdapter, addressManager)
 	if err != nil {
 		return nil, err
@@ -121,7 +150,14 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 	if err != nil {
 		return nil, err
 	}
-	rpcManager := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, domain.ConsensusEventsChannel(), interrupt)
+	rpcManager, err := setupRPC(cfg, domain, netAdapter, protocolManager, connectionManager, addressManager, utxoIndex, txIndex, db, domain.ConsensusEventsChannel(), interrupt)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MetricsListen != "" {
+		registerMetrics(domain, protocolManager, connectionManager, db)
+	}
 
 	return &ComponentManager{
 		cfg:               cfg,
@@ -130,10 +166,29 @@ func NewComponentManager(cfg *config.Config, db infrastructuredatabase.Database,
 		connectionManager: connectionManager,
 		netAdapter:        netAdapter,
 		addressManager:    addressManager,
+		natManager:        natManager,
 	}, nil
 
 }
 
+// newDomain returns the domain of the node, according to the given config
+func newDomain(cfg *config.Config, db infrastructuredatabase.Database) (domain.Domain, error) {
+	consensusConfig := newConsensusConfig(cfg)
+	mempoolConfig := mempool.DefaultConfig(&consensusConfig.Params)
+	mempoolConfig.MaximumOrphanTransactionCount = cfg.MaxOrphanTxs
+	mempoolConfig.MinimumRelayTransactionFee = cfg.MinRelayTxFee
+
+	return domain.New(consensusConfig, mempoolConfig, db)
+}
+
+func newConsensusConfig(cfg *config.Config) *consensus.Config {
+	return &consensus.Config{
+		Params:                          *cfg.ActiveNetParams,
+		IsArchival:                      cfg.IsArchivalNode,
+		EnableSanityCheckPruningUTXOSet: cfg.EnableSanityCheckPruningUTXOSet,
+	}
+}
+
 func setupRPC(
 	cfg *config.Config,
 	domain domain.Domain,
@@ -142,11 +197,13 @@ func setupRPC(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	txIndex *txindex.TXIndex,
+	db infrastructuredatabase.Database,
 	consensusEventsChan chan externalapi.ConsensusEvent,
 	shutDownChan chan<- struct{},
-) *rpc.Manager {
+) (*rpc.Manager, error) {
 
-	rpcManager := rpc.NewManager(
+	rpcManager, err := rpc.NewManager(
 		cfg,
 		domain,
 		netAdapter,
@@ -154,13 +211,18 @@ func setupRPC(
 		connectionManager,
 		addressManager,
 		utxoIndex,
+		txIndex,
+		db,
 		consensusEventsChan,
 		shutDownChan,
 	)
+	if err != nil {
+		return nil, err
+	}
 	protocolManager.SetOnNewBlockTemplateHandler(rpcManager.NotifyNewBlockTemplate)
 	protocolManager.SetOnPruningPointUTXOSetOverrideHandler(rpcManager.NotifyPruningPointUTXOSetOverride)
 
-	return rpcManager
+	return rpcManager, nil
 }
 
 // P2PNodeID returns the network ID associated with this ComponentManager
@@ -21,6 +21,26 @@ func checkDatabaseVersion(dbPath string) (err error) {
 		return err
 	}
 
+	return verifyDatabaseVersion(versionBytes)
+}
+
+// checkDatabaseVersionReadOnly checks the version of an existing database without creating a
+// version file, so a missing version file is an error
+func checkDatabaseVersionReadOnly(dbPath string) error {
+	versionFileName := versionFilePath(dbPath)
+
+	versionBytes, err := os.ReadFile(versionFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("The database in %s has no version file", dbPath)
+		}
+		return err
+	}
+
+	return verifyDatabaseVersion(versionBytes)
+}
+
+func verifyDatabaseVersion(versionBytes []byte) error {
 	databaseVersion, err := strconv.Atoi(string(versionBytes))
 	if err != nil {
 		return err
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/os/signal"
+	"github.com/pkg/errors"
+)
+
+// exportBlocksPageSize is the maximum number of block hashes fetched at once while exporting
+const exportBlocksPageSize = 10_000
+
+// exportBlocks writes the blocks of the node into a new blocks file, in topological order. An
+// archival node exports all the blocks from genesis, which a new node can import. Other nodes
+// don't have the blocks below their pruning point, so they export the blocks from their pruning
+// point, which only a node that already has that pruning point can import.
+func exportBlocks(cfg *config.Config, consensus externalapi.Consensus, path string, interrupt <-chan struct{}) (err error) {
+	lowHash := cfg.ActiveNetParams.GenesisHash
+	if !cfg.IsArchivalNode {
+		lowHash, err = consensus.PruningPoint()
+		if err != nil {
+			return err
+		}
+		if !lowHash.Equal(cfg.ActiveNetParams.GenesisHash) {
+			log.Warnf("This node isn't archival, so it exports the blocks from its pruning point %s. "+
+				"Only a node that already has this pruning point can import them", lowHash)
+		}
+	}
+	highHash, err := consensus.GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+	lowHeader, err := consensus.GetBlockHeader(lowHash)
+	if err != nil {
+		return err
+	}
+	highHeader, err := consensus.GetBlockHeader(highHash)
+	if err != nil {
+		return err
+	}
+
+	writer, err := createBlocksFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := writer.close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	log.Infof("Exporting the blocks from %s to %s into %s", lowHash, highHash, path)
+	progressReporter := newBlocksFileProgressReporter("Exported", lowHeader.DAAScore(), highHeader.DAAScore())
+	exportBlock := func(blockHash *externalapi.DomainHash) error {
+		block, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Errorf("block %s has no body. The blocks of this node can't be exported "+
+				"from %s", blockHash, lowHash)
+		}
+		err = writer.writeBlock(block)
+		if err != nil {
+			return err
+		}
+		progressReporter.report(writer.blockCount, block.Header.DAAScore())
+		return nil
+	}
+
+	err = exportBlock(lowHash)
+	if err != nil {
+		return err
+	}
+	pageSize := uint64(exportBlocksPageSize)
+	if pageSize < cfg.NetParams().MergeSetSizeLimit+1 {
+		pageSize = cfg.NetParams().MergeSetSizeLimit + 1
+	}
+	for currentLowHash := lowHash; !currentLowHash.Equal(highHash); {
+		if signal.InterruptRequested(interrupt) {
+			return errors.New("the export was interrupted")
+		}
+		blockHashes, currentHighHash, err := consensus.GetHashesBetween(currentLowHash, highHash, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, blockHash := range blockHashes {
+			err := exportBlock(blockHash)
+			if err != nil {
+				return err
+			}
+		}
+		currentLowHash = currentHighHash
+	}
+
+	// The blocks that aren't in the past of the virtual selected parent are its anticone.
+	// They're sorted by blue work, which is a topological order.
+	anticone, err := consensus.Anticone(highHash)
+	if err != nil {
+		return err
+	}
+	anticoneBlueWork := make(map[externalapi.DomainHash]*externalapi.BlockInfo, len(anticone))
+	anticoneWithBodies := make([]*externalapi.DomainHash, 0, len(anticone))
+	for _, blockHash := range anticone {
+		blockInfo, err := consensus.GetBlockInfo(blockHash)
+		if err != nil {
+			return err
+		}
+		if !blockInfo.HasBody() {
+			continue
+		}
+		anticoneBlueWork[*blockHash] = blockInfo
+		anticoneWithBodies = append(anticoneWithBodies, blockHash)
+	}
+	sort.Slice(anticoneWithBodies, func(i, j int) bool {
+		return anticoneBlueWork[*anticoneWithBodies[i]].BlueWork.Cmp(anticoneBlueWork[*anticoneWithBodies[j]].BlueWork) < 0
+	})
+	for _, blockHash := range anticoneWithBodies {
+		err := exportBlock(blockHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Exported %d blocks into %s", writer.blockCount, path)
+	return nil
+}
+
+// importBlocks validates and inserts the blocks of the given blocks file. Blocks the node already
+// has are skipped, so an interrupted import can be resumed by running it again. The virtual is
+// resolved once all the blocks are in, including when all of them were inserted by a previous,
+// interrupted, import.
+func importBlocks(cfg *config.Config, consensus externalapi.Consensus, path string, interrupt <-chan struct{}) error {
+	reader, err := openBlocksFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		return err
+	}
+	defer reader.close()
+
+	log.Infof("Importing %d blocks from %s", reader.blockCount, path)
+	progressReporter := newBlocksFileProgressReporter("Imported", 0, reader.blockCount)
+	processed, inserted := uint64(0), uint64(0)
+	for {
+		if signal.InterruptRequested(interrupt) {
+			return errors.Errorf("the import was interrupted after %d blocks. Run it again to resume it", processed)
+		}
+
+		block, err := reader.readBlock()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error reading block #%d of %s", processed+1, path)
+		}
+		processed++
+
+		blockHash := consensushashing.BlockHash(block)
+		blockInfo, err := consensus.GetBlockInfo(blockHash)
+		if err != nil {
+			return err
+		}
+		if !blockInfo.HasBody() {
+			// The virtual is resolved once all the blocks are in, the same way as in IBD
+			err = consensus.ValidateAndInsertBlock(block, false)
+			if err != nil {
+				if errors.As(err, &ruleerrors.ErrMissingParents{}) {
+					return errors.Wrapf(err, "block %s can't be imported because this node doesn't have "+
+						"its parents. Blocks exported from the pruning point of a node can only be imported "+
+						"by a node that has that pruning point", blockHash)
+				}
+				if !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
+					return errors.Wrapf(err, "error importing block %s", blockHash)
+				}
+			} else {
+				inserted++
+			}
+		}
+		progressReporter.report(processed, processed)
+	}
+
+	log.Infof("Imported %d blocks, and skipped %d blocks that were already known", inserted, processed-inserted)
+
+	// ResolveVirtual returns right away if the virtual is already resolved
+	err = consensus.ResolveVirtual(func(virtualDAAScoreStart uint64, virtualDAAScore uint64) {
+		log.Infof("Resolving virtual. Current DAA score: %d", virtualDAAScore)
+	})
+	if err != nil {
+		return err
+	}
+	log.Infof("Resolved virtual")
+	return nil
+}
+
+// blocksFileProgressReporter logs the progress of an export or import whenever it advances by a percent
+type blocksFileProgressReporter struct {
+	action              string
+	low, high           uint64
+	lastReportedPercent int
+}
+
+func newBlocksFileProgressReporter(action string, low, high uint64) *blocksFileProgressReporter {
+	if high <= low {
+		// Avoid a zero or negative diff
+		high = low + 1
+	}
+	return &blocksFileProgressReporter{action: action, low: low, high: high}
+}
+
+func (r *blocksFileProgressReporter) report(blockCount uint64, current uint64) {
+	if current < r.low {
+		current = r.low
+	}
+	if current > r.high {
+		current = r.high
+	}
+	percent := int(float64(current-r.low) / float64(r.high-r.low) * 100)
+	if percent > r.lastReportedPercent {
+		log.Infof("%s %d blocks (%d%%)", r.action, blockCount, percent)
+		r.lastReportedPercent = percent
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memorydb"
+	"github.com/pkg/errors"
+)
+
+func TestExportImportBlocks(t *testing.T) {
+	cfg := config.DefaultConfig()
+	params := dagconfig.SimnetParams
+	params.SkipProofOfWork = true
+	cfg.ActiveNetParams = &params
+	cfg.IsArchivalNode = true
+
+	newTestDomain := func() domain.Domain {
+		domainInstance, err := newDomain(cfg, memorydb.NewMemoryDB())
+		if err != nil {
+			t.Fatalf("newDomain: %+v", err)
+		}
+		return domainInstance
+	}
+
+	source := newTestDomain().Consensus()
+	coinbaseData := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: &externalapi.ScriptPublicKey{},
+		ExtraData:       []byte{},
+	}
+	for i := 0; i < 30; i++ {
+		block, err := source.BuildBlock(coinbaseData, nil)
+		if err != nil {
+			t.Fatalf("BuildBlock: %+v", err)
+		}
+		err = source.ValidateAndInsertBlock(block, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+		if i%3 == 0 {
+			// Add a sibling so that the DAG isn't a chain
+			sibling := block.Clone()
+			mutableHeader := sibling.Header.ToMutable()
+			mutableHeader.SetNonce(mutableHeader.Nonce() + 1)
+			sibling.Header = mutableHeader.ToImmutable()
+			err = source.ValidateAndInsertBlock(sibling, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "blocks")
+	err := exportBlocks(cfg, source, path, nil)
+	if err != nil {
+		t.Fatalf("exportBlocks: %+v", err)
+	}
+	err = exportBlocks(cfg, source, path, nil)
+	if !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected exporting into an existing file to fail, got %+v", err)
+	}
+
+	reader, err := openBlocksFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		t.Fatalf("openBlocksFile: %+v", err)
+	}
+	// The genesis, the 30 blocks and their 10 siblings
+	const expectedBlockCount = 41
+	if reader.blockCount != expectedBlockCount {
+		t.Fatalf("expected %d blocks in the file, got %d", expectedBlockCount, reader.blockCount)
+	}
+	reader.close()
+
+	_, err = openBlocksFile(path, dagconfig.MainnetParams.GenesisHash)
+	if err == nil {
+		t.Fatalf("expected opening the blocks file of another network to fail")
+	}
+
+	destination := newTestDomain().Consensus()
+	interrupt := make(chan struct{})
+	close(interrupt)
+	err = importBlocks(cfg, destination, path, interrupt)
+	if err == nil {
+		t.Fatalf("expected an interrupted import to fail")
+	}
+
+	// Insert all the blocks without resolving the virtual, as an import that was
+	// interrupted right before resolving it would, so that the imports below insert
+	// nothing but still have to resolve the virtual
+	reader, err = openBlocksFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		t.Fatalf("openBlocksFile: %+v", err)
+	}
+	for {
+		block, err := reader.readBlock()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("readBlock: %+v", err)
+		}
+		if consensushashing.BlockHash(block).Equal(cfg.ActiveNetParams.GenesisHash) {
+			continue
+		}
+		err = destination.ValidateAndInsertBlock(block, false)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+	}
+	reader.close()
+
+	// Import twice to check that known blocks are skipped
+	for i := 0; i < 2; i++ {
+		err = importBlocks(cfg, destination, path, nil)
+		if err != nil {
+			t.Fatalf("importBlocks: %+v", err)
+		}
+	}
+
+	expectedVirtualSelectedParent, err := source.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	virtualSelectedParent, err := destination.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	if !virtualSelectedParent.Equal(expectedVirtualSelectedParent) {
+		t.Fatalf("expected virtual selected parent %s, got %s", expectedVirtualSelectedParent, virtualSelectedParent)
+	}
+	expectedTips, err := source.Tips()
+	if err != nil {
+		t.Fatalf("Tips: %+v", err)
+	}
+	tips, err := destination.Tips()
+	if err != nil {
+		t.Fatalf("Tips: %+v", err)
+	}
+	if !externalapi.HashesEqual(tips, expectedTips) {
+		t.Fatalf("expected tips %s, got %s", expectedTips, tips)
+	}
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"math"
+
+	"github.com/kaspanet/kaspad/app/protocol"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/processes/blockprocessor/blocklogger"
+	infrastructuredatabase "github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/metrics"
+	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
+)
+
+// registerMetrics registers the metrics which are sampled from the
+// different kaspad components whenever metrics are collected
+func registerMetrics(domain domain.Domain, protocolManager *protocol.Manager,
+	connectionManager *connmanager.ConnectionManager, db infrastructuredatabase.Database) {
+
+	blocklogger.SetBlockProcessedHook(metrics.AddProcessedBlock)
+
+	metrics.RegisterGaugeFunc("consensus", "virtual_daa_score", "DAA score of the virtual block", func() float64 {
+		virtualDAAScore, err := domain.Consensus().GetVirtualDAAScore()
+		if err != nil {
+			log.Warnf("Could not get the virtual DAA score for metrics: %s", err)
+			return math.NaN()
+		}
+		return float64(virtualDAAScore)
+	})
+
+	metrics.RegisterGaugeFunc("mempool", "transactions", "Number of transactions in the mempool", func() float64 {
+		return float64(domain.MiningManager().TransactionCount(true, false))
+	})
+	metrics.RegisterGaugeFunc("mempool", "mass", "Total mass of the transactions in the mempool", func() float64 {
+		return float64(domain.MiningManager().TransactionMass(true, false))
+	})
+	metrics.RegisterGaugeFunc("mempool", "orphan_transactions", "Number of transactions in the orphan pool", func() float64 {
+		return float64(domain.MiningManager().TransactionCount(false, true))
+	})
+	metrics.RegisterGaugeFunc("mempool", "orphan_mass", "Total mass of the transactions in the orphan pool", func() float64 {
+		return float64(domain.MiningManager().TransactionMass(false, true))
+	})
+
+	metrics.RegisterGaugeFunc("p2p", "outbound_peers", "Number of connected outbound peers", func() float64 {
+		outboundPeers := 0
+		for _, peer := range protocolManager.Peers() {
+			if peer.IsOutbound() {
+				outboundPeers++
+			}
+		}
+		return float64(outboundPeers)
+	})
+	metrics.RegisterGaugeFunc("p2p", "inbound_peers", "Number of connected inbound peers", func() float64 {
+		inboundPeers := 0
+		for _, peer := range protocolManager.Peers() {
+			if !peer.IsOutbound() {
+				inboundPeers++
+			}
+		}
+		return float64(inboundPeers)
+	})
+	metrics.RegisterGaugeFunc("p2p", "connections", "Number of open P2P connections", func() float64 {
+		return float64(connectionManager.ConnectionCount())
+	})
+
+	if levelDB, ok := db.(*ldb.LevelDB); ok {
+		metrics.RegisterLevelDBCollector(levelDB.Stats)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/metrics"
 	"github.com/pkg/errors"
 
 	"github.com/kaspanet/kaspad/app/appmessage"
@@ -104,7 +105,7 @@ func (f *FlowContext) SharedRequestedBlocks() *SharedRequestedBlocks {
 // AddBlock adds the given block to the DAG and propagates it.
 func (f *FlowContext) AddBlock(block *externalapi.DomainBlock) error {
 	if len(block.Transactions) == 0 {
-		return protocolerrors.Errorf(false, "cannot add header only block")
+		return protocolerrors.Errorf(protocolerrors.BanScoreNone, "cannot add header only block")
 	}
 
 	err := f.Domain().Consensus().ValidateAndInsertBlock(block, true)
@@ -144,6 +145,7 @@ func (f *FlowContext) TrySetIBDRunning(ibdPeer *peerpkg.Peer) bool {
 	}
 	f.ibdPeer = ibdPeer
 	log.Infof("IBD started with peer %s", ibdPeer)
+	metrics.SetIBDRunning(true)
 
 	return true
 }
@@ -158,6 +160,7 @@ func (f *FlowContext) UnsetIBDRunning() {
 	}
 
 	f.ibdPeer = nil
+	metrics.SetIBDRunning(false)
 }
 
 // IBDPeer returns the current IBD peer or null if the node is not
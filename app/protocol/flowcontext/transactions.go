@@ -6,6 +6,7 @@ import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
 )
 
 // TransactionIDPropagationInterval is the interval between transaction IDs propagations
@@ -22,6 +23,25 @@ func (f *FlowContext) AddTransaction(tx *externalapi.DomainTransaction, allowOrp
 	return f.EnqueueTransactionIDsForPropagation(acceptedTransactionIDs)
 }
 
+// AddTransactionWithReplacement adds transaction to the mempool, replacing the mempool
+// transaction it double-spends, and propagates it. The replaced transaction is returned.
+func (f *FlowContext) AddTransactionWithReplacement(tx *externalapi.DomainTransaction) (
+	replacedTransaction *externalapi.DomainTransaction, err error) {
+
+	acceptedTransactions, replacedTransaction, err := f.Domain().MiningManager().ValidateAndInsertTransactionWithReplacement(
+		tx, true, false, miningmanagermodel.RBFPolicyMandatory)
+	if err != nil {
+		return nil, err
+	}
+
+	acceptedTransactionIDs := consensushashing.TransactionIDs(acceptedTransactions)
+	err = f.EnqueueTransactionIDsForPropagation(acceptedTransactionIDs)
+	if err != nil {
+		return nil, err
+	}
+	return replacedTransaction, nil
+}
+
 func (f *FlowContext) shouldRebroadcastTransactions() bool {
 	const rebroadcastInterval = 30 * time.Second
 	return time.Since(f.lastRebroadcastTime) > rebroadcastInterval
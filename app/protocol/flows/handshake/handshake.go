@@ -83,7 +83,7 @@ func HandleHandshake(context HandleHandshakeContext, netConnection *netadapter.N
 	err := context.AddToPeers(peer)
 	if err != nil {
 		if errors.Is(err, common.ErrPeerWithSameIDExists) {
-			return nil, protocolerrors.Wrap(false, err, "peer already exists")
+			return nil, protocolerrors.Wrap(protocolerrors.BanScoreNone, err, "peer already exists")
 		}
 		return nil, err
 	}
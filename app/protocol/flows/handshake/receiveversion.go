@@ -59,16 +59,16 @@ func (flow *receiveVersionFlow) start() (*appmessage.NetAddress, error) {
 
 	msgVersion, ok := message.(*appmessage.MsgVersion)
 	if !ok {
-		return nil, protocolerrors.New(true, "a version message must precede all others")
+		return nil, protocolerrors.New(protocolerrors.BanScoreMajor, "a version message must precede all others")
 	}
 
 	if !allowSelfConnections && flow.NetAdapter().ID().IsEqual(msgVersion.ID) {
-		return nil, protocolerrors.New(false, "connected to self")
+		return nil, protocolerrors.New(protocolerrors.BanScoreNone, "connected to self")
 	}
 
 	// Disconnect and ban peers from a different network
 	if msgVersion.Network != flow.Config().ActiveNetParams.Name {
-		return nil, protocolerrors.Errorf(true, "wrong network")
+		return nil, protocolerrors.Errorf(protocolerrors.BanScoreSevere, "wrong network")
 	}
 
 	// Notify and disconnect clients that have a protocol version that is
@@ -78,13 +78,13 @@ func (flow *receiveVersionFlow) start() (*appmessage.NetAddress, error) {
 	// appmessage.RejectVersion, this should send a reject packet before
 	// disconnecting.
 	if msgVersion.ProtocolVersion < minAcceptableProtocolVersion {
-		return nil, protocolerrors.Errorf(false, "protocol version must be %d or greater",
+		return nil, protocolerrors.Errorf(protocolerrors.BanScoreNone, "protocol version must be %d or greater",
 			minAcceptableProtocolVersion)
 	}
 
 	// Disconnect from partial nodes in networks that don't allow them
 	if !flow.Config().ActiveNetParams.EnableNonNativeSubnetworks && msgVersion.SubnetworkID != nil {
-		return nil, protocolerrors.New(true, "partial nodes are not allowed")
+		return nil, protocolerrors.New(protocolerrors.BanScoreSevere, "partial nodes are not allowed")
 	}
 
 	// Disconnect if:
@@ -97,7 +97,7 @@ func (flow *receiveVersionFlow) start() (*appmessage.NetAddress, error) {
 	if (isLocalNodeFull && !isRemoteNodeFull && isOutbound) ||
 		(!isLocalNodeFull && !isRemoteNodeFull && !msgVersion.SubnetworkID.Equal(localSubnetworkID)) {
 
-		return nil, protocolerrors.New(false, "incompatible subnetworks")
+		return nil, protocolerrors.New(protocolerrors.BanScoreNone, "incompatible subnetworks")
 	}
 
 	if flow.Config().ProtocolVersion > maxAcceptableProtocolVersion {
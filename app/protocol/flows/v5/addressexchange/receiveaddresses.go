@@ -32,7 +32,7 @@ func ReceiveAddresses(context ReceiveAddressesContext, incomingRoute *router.Rou
 
 	msgAddresses := message.(*appmessage.MsgAddresses)
 	if len(msgAddresses.AddressList) > addressmanager.GetAddressesMax {
-		return protocolerrors.Errorf(true, "address count exceeded %d", addressmanager.GetAddressesMax)
+		return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "address count exceeded %d", addressmanager.GetAddressesMax)
 	}
 
 	return context.AddressManager().AddAddresses(msgAddresses.AddressList...)
@@ -26,7 +26,7 @@ func (flow *handleRelayInvsFlow) receiveBlockLocator() (blockLocatorHashes []*ex
 			return message.BlockLocatorHashes, nil
 		default:
 			return nil,
-				protocolerrors.Errorf(true, "received unexpected message type. "+
+				protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 					"expected: %s, got: %s", appmessage.CmdBlockLocator, message.Command())
 		}
 	}
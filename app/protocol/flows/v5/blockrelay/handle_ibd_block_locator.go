@@ -34,7 +34,7 @@ func HandleIBDBlockLocator(context HandleIBDBlockLocatorContext, incomingRoute *
 			return err
 		}
 		if !blockInfo.HasHeader() {
-			return protocolerrors.Errorf(true, "received IBDBlockLocator "+
+			return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "received IBDBlockLocator "+
 				"with an unknown targetHash %s", targetHash)
 		}
 
@@ -33,7 +33,7 @@ func HandleIBDBlockRequests(context HandleIBDBlockRequestsContext, incomingRoute
 			}
 
 			if !found {
-				return protocolerrors.Errorf(false, "IBD block %s not found", hash)
+				return protocolerrors.Errorf(protocolerrors.BanScoreNone, "IBD block %s not found", hash)
 			}
 
 			// TODO (Partial nodes): Convert block to partial block if needed
@@ -34,7 +34,7 @@ func HandleRelayBlockRequests(context RelayBlockRequestsContext, incomingRoute *
 			}
 
 			if !found {
-				return protocolerrors.Errorf(false, "Relay block %s not found", hash)
+				return protocolerrors.Errorf(protocolerrors.BanScoreNone, "Relay block %s not found", hash)
 			}
 
 			// TODO (Partial nodes): Convert block to partial block if needed
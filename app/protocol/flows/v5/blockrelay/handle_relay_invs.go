@@ -85,7 +85,7 @@ func (flow *handleRelayInvsFlow) start() error {
 		}
 		if blockInfo.Exists && blockInfo.BlockStatus != externalapi.StatusHeaderOnly {
 			if blockInfo.BlockStatus == externalapi.StatusInvalid {
-				return protocolerrors.Errorf(true, "sent inv of an invalid block %s",
+				return protocolerrors.Errorf(protocolerrors.BanScoreSevere, "sent inv of an invalid block %s",
 					inv.Hash)
 			}
 			log.Debugf("Block %s already exists. continuing...", inv.Hash)
@@ -217,7 +217,7 @@ func (flow *handleRelayInvsFlow) start() error {
 			}
 
 			if !found {
-				return protocolerrors.Errorf(false, "Virtual parent %s not found", parent)
+				return protocolerrors.Errorf(protocolerrors.BanScoreNone, "Virtual parent %s not found", parent)
 			}
 			blockHash := consensushashing.BlockHash(block)
 			log.Debugf("Relaying block %s", blockHash)
@@ -245,7 +245,7 @@ func (flow *handleRelayInvsFlow) start() error {
 
 func (flow *handleRelayInvsFlow) banIfBlockIsHeaderOnly(block *externalapi.DomainBlock) error {
 	if len(block.Transactions) == 0 {
-		return protocolerrors.Errorf(true, "sent header of %s block where expected block with body",
+		return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "sent header of %s block where expected block with body",
 			consensushashing.BlockHash(block))
 	}
 
@@ -266,7 +266,7 @@ func (flow *handleRelayInvsFlow) readInv() (invRelayBlock, error) {
 
 	msgInv, ok := msg.(*appmessage.MsgInvRelayBlock)
 	if !ok {
-		return invRelayBlock{}, protocolerrors.Errorf(true, "unexpected %s message in the block relay handleRelayInvsFlow while "+
+		return invRelayBlock{}, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "unexpected %s message in the block relay handleRelayInvsFlow while "+
 			"expecting an inv message", msg.Command())
 	}
 	return invRelayBlock{Hash: msgInv.Hash, IsOrphanRoot: false}, nil
@@ -296,7 +296,7 @@ func (flow *handleRelayInvsFlow) requestBlock(requestHash *externalapi.DomainHas
 	block := appmessage.MsgBlockToDomainBlock(msgBlock)
 	blockHash := consensushashing.BlockHash(block)
 	if !blockHash.Equal(requestHash) {
-		return nil, false, protocolerrors.Errorf(true, "got unrequested block %s", blockHash)
+		return nil, false, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "got unrequested block %s", blockHash)
 	}
 
 	return block, false, nil
@@ -339,7 +339,7 @@ func (flow *handleRelayInvsFlow) processBlock(block *externalapi.DomainBlock) ([
 		if !errors.Is(err, ruleerrors.ErrDuplicateBlock) {
 			log.Warnf("Rejected block %s from %s: %s", blockHash, flow.peer, err)
 		}
-		return nil, protocolerrors.Wrapf(true, err, "got invalid block %s from relay", blockHash)
+		return nil, protocolerrors.Wrapf(protocolerrors.BanScoreSevere, err, "got invalid block %s from relay", blockHash)
 	}
 	return nil, nil
 }
@@ -51,7 +51,7 @@ func (flow *handleRequestAnticoneFlow) start() error {
 		blockHashes, err := flow.Domain().Consensus().GetAnticone(blockHash, contextHash,
 			flow.Config().ActiveNetParams.MergeSetSizeLimit*2)
 		if err != nil {
-			return protocolerrors.Wrap(true, err, "Failed querying anticone")
+			return protocolerrors.Wrap(protocolerrors.BanScoreMinor, err, "Failed querying anticone")
 		}
 		log.Debugf("Got %d header hashes in past(%s) cap anticone(%s)", len(blockHashes), contextHash, blockHash)
 
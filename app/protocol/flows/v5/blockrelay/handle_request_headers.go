@@ -53,7 +53,7 @@ func (flow *handleRequestHeadersFlow) start() error {
 			return err
 		}
 		if !lowHashInfo.HasHeader() {
-			return protocolerrors.Errorf(true, "Block %s does not exist", lowHash)
+			return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "Block %s does not exist", lowHash)
 		}
 
 		highHashInfo, err := consensus.GetBlockInfo(highHash)
@@ -61,7 +61,7 @@ func (flow *handleRequestHeadersFlow) start() error {
 			return err
 		}
 		if !highHashInfo.HasHeader() {
-			return protocolerrors.Errorf(true, "Block %s does not exist", highHash)
+			return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "Block %s does not exist", highHash)
 		}
 
 		isLowSelectedAncestorOfHigh, err := consensus.IsInSelectedParentChainOf(lowHash, highHash)
@@ -69,7 +69,7 @@ func (flow *handleRequestHeadersFlow) start() error {
 			return err
 		}
 		if !isLowSelectedAncestorOfHigh {
-			return protocolerrors.Errorf(true, "Expected %s to be on the selected chain of %s",
+			return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "Expected %s to be on the selected chain of %s",
 				lowHash, highHash)
 		}
 
@@ -106,7 +106,7 @@ func (flow *handleRequestHeadersFlow) start() error {
 				return err
 			}
 			if _, ok := message.(*appmessage.MsgRequestNextHeaders); !ok {
-				return protocolerrors.Errorf(true, "received unexpected message type. "+
+				return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 					"expected: %s, got: %s", appmessage.CmdRequestNextHeaders, message.Command())
 			}
 
@@ -165,7 +165,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 		return nil, nil, err
 	}
 	if len(locatorHashes) == 0 {
-		return nil, nil, protocolerrors.Errorf(true, "Expecting initial syncer chain block locator "+
+		return nil, nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "Expecting initial syncer chain block locator "+
 			"to contain at least one element")
 	}
 	log.Debugf("IBD chain negotiation with peer %s started and received %d hashes (%s, %s)", flow.peer,
@@ -189,7 +189,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 			}
 			if info.Exists {
 				if info.BlockStatus == externalapi.StatusInvalid {
-					return nil, nil, protocolerrors.Errorf(true, "Sent invalid chain block %s", syncerChainHash)
+					return nil, nil, protocolerrors.Errorf(protocolerrors.BanScoreSevere, "Sent invalid chain block %s", syncerChainHash)
 				}
 
 				isPruningPointOnSyncerChain, err := flow.Domain().Consensus().IsInSelectedParentChainOf(pruningPoint, syncerChainHash)
@@ -235,7 +235,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 		if len(locatorHashes) > 0 {
 			if !locatorHashes[0].Equal(lowestUnknownSyncerChainHash) ||
 				!locatorHashes[len(locatorHashes)-1].Equal(currentHighestKnownSyncerChainHash) {
-				return nil, nil, protocolerrors.Errorf(true, "Expecting the high and low "+
+				return nil, nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "Expecting the high and low "+
 					"hashes to match the locator bounds")
 			}
 
@@ -252,7 +252,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 			if chainNegotiationZoomCounts > initialLocatorLen*2 {
 				// Since the zoom-in always queries two consecutive entries in the previous locator, it is
 				// expected to decrease in size at least every two iterations
-				return nil, nil, protocolerrors.Errorf(true,
+				return nil, nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor,
 					"IBD chain negotiation: Number of zoom-in steps %d exceeded the upper bound of 2*%d",
 					chainNegotiationZoomCounts, initialLocatorLen)
 			}
@@ -261,7 +261,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 			chainNegotiationZoomCounts = 0
 			chainNegotiationRestartCounter++
 			if chainNegotiationRestartCounter > 32 {
-				return nil, nil, protocolerrors.Errorf(false,
+				return nil, nil, protocolerrors.Errorf(protocolerrors.BanScoreNone,
 					"IBD chain negotiation with syncer %s exceeded restart limit %d", flow.peer, chainNegotiationRestartCounter)
 			}
 			log.Warnf("IBD chain negotiation with syncer %s restarted %d times", flow.peer, chainNegotiationRestartCounter)
@@ -273,7 +273,7 @@ func (flow *handleIBDFlow) negotiateMissingSyncerChainSegment() (*externalapi.Do
 				return nil, nil, err
 			}
 			if len(locatorHashes) == 0 {
-				return nil, nil, protocolerrors.Errorf(true, "Expecting initial syncer chain block locator "+
+				return nil, nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "Expecting initial syncer chain block locator "+
 					"to contain at least one element")
 			}
 			log.Infof("IBD chain negotiation with peer %s restarted (%d) and received %d hashes (%s, %s)", flow.peer,
@@ -327,14 +327,14 @@ func (flow *handleIBDFlow) getSyncerChainBlockLocator(
 	switch message := message.(type) {
 	case *appmessage.MsgIBDChainBlockLocator:
 		if len(message.BlockLocatorHashes) > 64 {
-			return nil, protocolerrors.Errorf(true,
+			return nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor,
 				"Got block locator of size %d>64 while expecting locator to have size "+
 					"which is logarithmic in DAG size (which should never exceed 2^64)",
 				len(message.BlockLocatorHashes))
 		}
 		return message.BlockLocatorHashes, nil
 	default:
-		return nil, protocolerrors.Errorf(true, "received unexpected message type. "+
+		return nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 			"expected: %s, got: %s", appmessage.CmdIBDChainBlockLocator, message.Command())
 	}
 }
@@ -379,7 +379,7 @@ func (flow *handleIBDFlow) syncPruningPointFutureHeaders(consensus externalapi.C
 			}
 			if len(blockHeadersMessage.BlockHeaders) == 0 {
 				// The syncer should have sent a done message if the search completed, and not an empty list
-				errChan <- protocolerrors.Errorf(true, "Received an empty headers message from peer %s", flow.peer)
+				errChan <- protocolerrors.Errorf(protocolerrors.BanScoreMinor, "Received an empty headers message from peer %s", flow.peer)
 				return
 			}
 
@@ -433,7 +433,7 @@ func (flow *handleIBDFlow) syncMissingRelayPast(consensus externalapi.Consensus,
 			return err
 		}
 		if anticoneDone {
-			return protocolerrors.Errorf(true,
+			return protocolerrors.Errorf(protocolerrors.BanScoreMajor,
 				"Expected one anticone header chunk for past(%s) cap anticone(%s) but got zero",
 				relayBlockHash, syncerHeaderSelectedTipHash)
 		}
@@ -442,7 +442,7 @@ func (flow *handleIBDFlow) syncMissingRelayPast(consensus externalapi.Consensus,
 			return err
 		}
 		if !anticoneDone {
-			return protocolerrors.Errorf(true,
+			return protocolerrors.Errorf(protocolerrors.BanScoreMajor,
 				"Expected only one anticone header chunk for past(%s) cap anticone(%s)",
 				relayBlockHash, syncerHeaderSelectedTipHash)
 		}
@@ -460,7 +460,7 @@ func (flow *handleIBDFlow) syncMissingRelayPast(consensus externalapi.Consensus,
 		return err
 	}
 	if !relayBlockInfo.Exists {
-		return protocolerrors.Errorf(true, "did not receive "+
+		return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "did not receive "+
 			"relayBlockHash block %s from peer %s during block download", relayBlockHash, flow.peer)
 	}
 	return nil
@@ -492,7 +492,7 @@ func (flow *handleIBDFlow) receiveHeaders() (msgIBDBlock *appmessage.BlockHeader
 		return nil, true, nil
 	default:
 		return nil, false,
-			protocolerrors.Errorf(true, "received unexpected message type. "+
+			protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 				"expected: %s or %s, got: %s",
 				appmessage.CmdBlockHeaders,
 				appmessage.CmdDoneHeaders,
@@ -526,7 +526,7 @@ func (flow *handleIBDFlow) processHeader(consensus externalapi.Consensus, msgBlo
 			log.Debugf("Skipping block header %s as it is a duplicate", blockHash)
 		} else {
 			log.Infof("Rejected block header %s from %s during IBD: %s", blockHash, flow.peer, err)
-			return protocolerrors.Wrapf(true, err, "got invalid block header %s during IBD", blockHash)
+			return protocolerrors.Wrapf(protocolerrors.BanScoreSevere, err, "got invalid block header %s during IBD", blockHash)
 		}
 	}
 
@@ -555,13 +555,13 @@ func (flow *handleIBDFlow) validatePruningPointFutureHeaderTimestamps() error {
 	currentSelectedTipTimestamp := currentSelectedTipHeader.TimeInMilliseconds()
 
 	if headerSelectedTipTimestamp < currentSelectedTipTimestamp {
-		return protocolerrors.Errorf(false, "the timestamp of the candidate selected "+
+		return protocolerrors.Errorf(protocolerrors.BanScoreNone, "the timestamp of the candidate selected "+
 			"tip is smaller than the current selected tip")
 	}
 
 	minTimestampDifferenceInMilliseconds := (10 * time.Minute).Milliseconds()
 	if headerSelectedTipTimestamp-currentSelectedTipTimestamp < minTimestampDifferenceInMilliseconds {
-		return protocolerrors.Errorf(false, "difference between the timestamps of "+
+		return protocolerrors.Errorf(protocolerrors.BanScoreNone, "difference between the timestamps of "+
 			"the current pruning point and the candidate pruning point is too small. Aborting IBD...")
 	}
 	return nil
@@ -614,7 +614,7 @@ func (flow *handleIBDFlow) receiveAndInsertPruningPointUTXOSet(
 			return false, nil
 
 		default:
-			return false, protocolerrors.Errorf(true, "received unexpected message type. "+
+			return false, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 				"expected: %s or %s or %s, got: %s", appmessage.CmdPruningPointUTXOSetChunk,
 				appmessage.CmdDonePruningPointUTXOSetChunks, appmessage.CmdUnexpectedPruningPoint, message.Command(),
 			)
@@ -673,14 +673,14 @@ func (flow *handleIBDFlow) syncMissingBlockBodies(highHash *externalapi.DomainHa
 
 			msgIBDBlock, ok := message.(*appmessage.MsgIBDBlock)
 			if !ok {
-				return protocolerrors.Errorf(true, "received unexpected message type. "+
+				return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 					"expected: %s, got: %s", appmessage.CmdIBDBlock, message.Command())
 			}
 
 			block := appmessage.MsgBlockToDomainBlock(msgIBDBlock.MsgBlock)
 			blockHash := consensushashing.BlockHash(block)
 			if !expectedHash.Equal(blockHash) {
-				return protocolerrors.Errorf(true, "expected block %s but got %s", expectedHash, blockHash)
+				return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "expected block %s but got %s", expectedHash, blockHash)
 			}
 
 			err = flow.banIfBlockIsHeaderOnly(block)
@@ -720,7 +720,7 @@ func (flow *handleIBDFlow) syncMissingBlockBodies(highHash *externalapi.DomainHa
 
 func (flow *handleIBDFlow) banIfBlockIsHeaderOnly(block *externalapi.DomainBlock) error {
 	if len(block.Transactions) == 0 {
-		return protocolerrors.Errorf(true, "sent header of %s block where expected block with body",
+		return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "sent header of %s block where expected block with body",
 			consensushashing.BlockHash(block))
 	}
 
@@ -1,5 +1,7 @@
 package blockrelay
 
+import "github.com/kaspanet/kaspad/infrastructure/metrics"
+
 type ibdProgressReporter struct {
 	lowDAAScore                 uint64
 	highDAAScore                uint64
@@ -41,5 +43,6 @@ func (ipr *ibdProgressReporter) reportProgress(processedDelta int, highestProces
 	if progressPercent > ipr.lastReportedProgressPercent {
 		log.Infof("IBD: Processed %d %s (%d%%)", ipr.processed, ipr.objectName, progressPercent)
 		ipr.lastReportedProgressPercent = progressPercent
+		metrics.SetIBDProgress(ipr.objectName, progressPercent)
 	}
 }
@@ -126,14 +126,14 @@ func (flow *handleIBDFlow) syncAndValidatePruningPointProof() (*externalapi.Doma
 	}
 	pruningPointProofMessage, ok := message.(*appmessage.MsgPruningPointProof)
 	if !ok {
-		return nil, protocolerrors.Errorf(true, "received unexpected message type. "+
+		return nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 			"expected: %s, got: %s", appmessage.CmdPruningPointProof, message.Command())
 	}
 	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(pruningPointProofMessage)
 	err = flow.Domain().Consensus().ValidatePruningPointProof(pruningPointProof)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
-			return nil, protocolerrors.Wrapf(true, err, "pruning point proof validation failed")
+			return nil, protocolerrors.Wrapf(protocolerrors.BanScoreSevere, err, "pruning point proof validation failed")
 		}
 		return nil, err
 	}
@@ -163,7 +163,7 @@ func (flow *handleIBDFlow) downloadHeadersAndPruningUTXOSet(
 	// TODO: Remove this condition once there's more proper way to check finality violation
 	// in the headers proof.
 	if proofPruningPoint.Equal(flow.Config().NetParams().GenesisHash) {
-		return protocolerrors.Errorf(true, "the genesis pruning point violates finality")
+		return protocolerrors.Errorf(protocolerrors.BanScoreSevere, "the genesis pruning point violates finality")
 	}
 
 	err = flow.syncPruningPointFutureHeaders(flow.Domain().StagingConsensus(),
@@ -180,7 +180,7 @@ func (flow *handleIBDFlow) downloadHeadersAndPruningUTXOSet(
 	}
 
 	if !relayBlockInfo.Exists {
-		return protocolerrors.Errorf(true, "the triggering IBD block was not sent")
+		return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "the triggering IBD block was not sent")
 	}
 
 	err = flow.validatePruningPointFutureHeaderTimestamps()
@@ -220,7 +220,7 @@ func (flow *handleIBDFlow) syncPruningPointsAndPruningPointAnticone(proofPruning
 
 	msgTrustedData, ok := message.(*appmessage.MsgTrustedData)
 	if !ok {
-		return protocolerrors.Errorf(true, "received unexpected message type. "+
+		return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 			"expected: %s, got: %s", appmessage.CmdTrustedData, message.Command())
 	}
 
@@ -230,11 +230,11 @@ func (flow *handleIBDFlow) syncPruningPointsAndPruningPointAnticone(proofPruning
 	}
 
 	if done {
-		return protocolerrors.Errorf(true, "got `done` message before receiving the pruning point")
+		return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "got `done` message before receiving the pruning point")
 	}
 
 	if !pruningPointWithMetaData.Block.Header.BlockHash().Equal(proofPruningPoint) {
-		return protocolerrors.Errorf(true, "first block with trusted data is not the pruning point")
+		return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "first block with trusted data is not the pruning point")
 	}
 
 	err = flow.processBlockWithTrustedData(flow.Domain().StagingConsensus(), pruningPointWithMetaData, msgTrustedData)
@@ -276,24 +276,14 @@ func (flow *handleIBDFlow) syncPruningPointsAndPruningPointAnticone(proofPruning
 func (flow *handleIBDFlow) processBlockWithTrustedData(
 	consensus externalapi.Consensus, block *appmessage.MsgBlockWithTrustedDataV4, data *appmessage.MsgTrustedData) error {
 
-	blockWithTrustedData := &externalapi.BlockWithTrustedData{
-		Block:        appmessage.MsgBlockToDomainBlock(block.Block),
-		DAAWindow:    make([]*externalapi.TrustedDataDataDAAHeader, 0, len(block.DAAWindowIndices)),
-		GHOSTDAGData: make([]*externalapi.BlockGHOSTDAGDataHashPair, 0, len(block.GHOSTDAGDataIndices)),
-	}
-
-	for _, index := range block.DAAWindowIndices {
-		blockWithTrustedData.DAAWindow = append(blockWithTrustedData.DAAWindow, appmessage.TrustedDataDataDAABlockV4ToTrustedDataDataDAAHeader(data.DAAWindow[index]))
-	}
-
-	for _, index := range block.GHOSTDAGDataIndices {
-		blockWithTrustedData.GHOSTDAGData = append(blockWithTrustedData.GHOSTDAGData, appmessage.GHOSTDAGHashPairToDomainGHOSTDAGHashPair(data.GHOSTDAGData[index]))
+	blockWithTrustedData, err := BlockWithTrustedDataMessageToDomain(block, data)
+	if err != nil {
+		return protocolerrors.Wrapf(protocolerrors.BanScoreSevere, err, "received an invalid block with trusted data")
 	}
-
-	err := consensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
+	err = consensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
 	if err != nil {
 		if errors.As(err, &ruleerrors.RuleError{}) {
-			return protocolerrors.Wrapf(true, err, "failed validating block with trusted data")
+			return protocolerrors.Wrapf(protocolerrors.BanScoreSevere, err, "failed validating block with trusted data")
 		}
 		return err
 	}
@@ -313,7 +303,7 @@ func (flow *handleIBDFlow) receiveBlockWithTrustedData() (*appmessage.MsgBlockWi
 		return nil, true, nil
 	default:
 		return nil, false,
-			protocolerrors.Errorf(true, "received unexpected message type. "+
+			protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 				"expected: %s or %s, got: %s",
 				(&appmessage.MsgBlockWithTrustedData{}).Command(),
 				(&appmessage.MsgDoneBlocksWithTrustedData{}).Command(),
@@ -330,7 +320,7 @@ func (flow *handleIBDFlow) receivePruningPoints() (*appmessage.MsgPruningPoints,
 	msgPruningPoints, ok := message.(*appmessage.MsgPruningPoints)
 	if !ok {
 		return nil,
-			protocolerrors.Errorf(true, "received unexpected message type. "+
+			protocolerrors.Errorf(protocolerrors.BanScoreMajor, "received unexpected message type. "+
 				"expected: %s, got: %s", appmessage.CmdPruningPoints, message.Command())
 	}
 
@@ -344,7 +334,7 @@ func (flow *handleIBDFlow) validateAndInsertPruningPoints(proofPruningPoint *ext
 	}
 
 	if currentPruningPoint.Equal(proofPruningPoint) {
-		return protocolerrors.Errorf(true, "the proposed pruning point is the same as the current pruning point")
+		return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "the proposed pruning point is the same as the current pruning point")
 	}
 
 	pruningPoints, err := flow.receivePruningPoints()
@@ -364,12 +354,12 @@ func (flow *handleIBDFlow) validateAndInsertPruningPoints(proofPruningPoint *ext
 
 	if arePruningPointsViolatingFinality {
 		// TODO: Find a better way to deal with finality conflicts.
-		return protocolerrors.Errorf(false, "pruning points are violating finality")
+		return protocolerrors.Errorf(protocolerrors.BanScoreNone, "pruning points are violating finality")
 	}
 
 	lastPruningPoint := consensushashing.HeaderHash(headers[len(headers)-1])
 	if !lastPruningPoint.Equal(proofPruningPoint) {
-		return protocolerrors.Errorf(true, "the proof pruning point is not equal to the last pruning "+
+		return protocolerrors.Errorf(protocolerrors.BanScoreMinor, "the proof pruning point is not equal to the last pruning "+
 			"point in the list")
 	}
 
@@ -391,7 +381,7 @@ func (flow *handleIBDFlow) syncPruningPointUTXOSet(consensus externalapi.Consens
 	}
 
 	if !isValid {
-		return false, protocolerrors.Errorf(true, "invalid pruning point %s", pruningPoint)
+		return false, protocolerrors.Errorf(protocolerrors.BanScoreSevere, "invalid pruning point %s", pruningPoint)
 	}
 
 	log.Info("Fetching the pruning point UTXO set")
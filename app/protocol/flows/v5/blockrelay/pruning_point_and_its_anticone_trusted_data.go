@@ -0,0 +1,134 @@
+package blockrelay
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// PruningPointAndItsAnticoneTrustedData is the trusted data a syncee needs in order to insert the
+// pruning point and its anticone without their past
+type PruningPointAndItsAnticoneTrustedData struct {
+	PointAndItsAnticone []*externalapi.DomainHash
+	TrustedData         *appmessage.MsgTrustedData
+
+	daaWindowIndices    map[externalapi.DomainHash][]uint64
+	ghostdagDataIndices map[externalapi.DomainHash][]uint64
+}
+
+// BuildPruningPointAndItsAnticoneTrustedData collects the DAA windows and GHOSTDAG data of the pruning
+// point and its anticone. Each DAA window header and GHOSTDAG data appears once in the trusted data,
+// and the blocks refer to them by their indices.
+func BuildPruningPointAndItsAnticoneTrustedData(consensus externalapi.Consensus, params *dagconfig.Params) (
+	*PruningPointAndItsAnticoneTrustedData, error) {
+
+	pointAndItsAnticone, err := consensus.PruningPointAndItsAnticone()
+	if err != nil {
+		return nil, err
+	}
+
+	windowSize := params.DifficultyAdjustmentWindowSize
+	daaWindowBlocks := make([]*externalapi.TrustedDataDataDAAHeader, 0, windowSize)
+	daaWindowHashesToIndex := make(map[externalapi.DomainHash]int, windowSize)
+	trustedDataDAABlockIndexes := make(map[externalapi.DomainHash][]uint64)
+
+	ghostdagData := make([]*externalapi.BlockGHOSTDAGDataHashPair, 0)
+	ghostdagDataHashToIndex := make(map[externalapi.DomainHash]int)
+	trustedDataGHOSTDAGDataIndexes := make(map[externalapi.DomainHash][]uint64)
+	for _, blockHash := range pointAndItsAnticone {
+		blockDAAWindowHashes, err := consensus.BlockDAAWindowHashes(blockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		trustedDataDAABlockIndexes[*blockHash] = make([]uint64, 0, windowSize)
+		for i, daaBlockHash := range blockDAAWindowHashes {
+			index, exists := daaWindowHashesToIndex[*daaBlockHash]
+			if !exists {
+				trustedDataDataDAAHeader, err := consensus.TrustedDataDataDAAHeader(blockHash, daaBlockHash, uint64(i))
+				if err != nil {
+					return nil, err
+				}
+				daaWindowBlocks = append(daaWindowBlocks, trustedDataDataDAAHeader)
+				index = len(daaWindowBlocks) - 1
+				daaWindowHashesToIndex[*daaBlockHash] = index
+			}
+
+			trustedDataDAABlockIndexes[*blockHash] = append(trustedDataDAABlockIndexes[*blockHash], uint64(index))
+		}
+
+		ghostdagDataBlockHashes, err := consensus.TrustedBlockAssociatedGHOSTDAGDataBlockHashes(blockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		trustedDataGHOSTDAGDataIndexes[*blockHash] = make([]uint64, 0, params.K)
+		for _, ghostdagDataBlockHash := range ghostdagDataBlockHashes {
+			index, exists := ghostdagDataHashToIndex[*ghostdagDataBlockHash]
+			if !exists {
+				data, err := consensus.TrustedGHOSTDAGData(ghostdagDataBlockHash)
+				if err != nil {
+					return nil, err
+				}
+				ghostdagData = append(ghostdagData, &externalapi.BlockGHOSTDAGDataHashPair{
+					Hash:         ghostdagDataBlockHash,
+					GHOSTDAGData: data,
+				})
+				index = len(ghostdagData) - 1
+				ghostdagDataHashToIndex[*ghostdagDataBlockHash] = index
+			}
+
+			trustedDataGHOSTDAGDataIndexes[*blockHash] = append(trustedDataGHOSTDAGDataIndexes[*blockHash], uint64(index))
+		}
+	}
+
+	return &PruningPointAndItsAnticoneTrustedData{
+		PointAndItsAnticone: pointAndItsAnticone,
+		TrustedData:         appmessage.DomainTrustedDataToTrustedData(daaWindowBlocks, ghostdagData),
+		daaWindowIndices:    trustedDataDAABlockIndexes,
+		ghostdagDataIndices: trustedDataGHOSTDAGDataIndexes,
+	}, nil
+}
+
+// BlockWithTrustedDataMessage returns the message of the given block of the pruning point and its
+// anticone, which refers to its DAA window and GHOSTDAG data in the trusted data
+func (d *PruningPointAndItsAnticoneTrustedData) BlockWithTrustedDataMessage(
+	block *externalapi.DomainBlock) *appmessage.MsgBlockWithTrustedDataV4 {
+
+	blockHash := consensushashing.BlockHash(block)
+	return appmessage.DomainBlockWithTrustedDataToBlockWithTrustedDataV4(
+		block, d.daaWindowIndices[*blockHash], d.ghostdagDataIndices[*blockHash])
+}
+
+// BlockWithTrustedDataMessageToDomain converts a block with trusted data message back into the
+// domain block with trusted data that it refers to in the given trusted data. It returns an error
+// if the block refers to data that isn't in the trusted data
+func BlockWithTrustedDataMessageToDomain(block *appmessage.MsgBlockWithTrustedDataV4,
+	data *appmessage.MsgTrustedData) (*externalapi.BlockWithTrustedData, error) {
+
+	blockWithTrustedData := &externalapi.BlockWithTrustedData{
+		Block:        appmessage.MsgBlockToDomainBlock(block.Block),
+		DAAWindow:    make([]*externalapi.TrustedDataDataDAAHeader, 0, len(block.DAAWindowIndices)),
+		GHOSTDAGData: make([]*externalapi.BlockGHOSTDAGDataHashPair, 0, len(block.GHOSTDAGDataIndices)),
+	}
+
+	for _, index := range block.DAAWindowIndices {
+		if index >= uint64(len(data.DAAWindow)) {
+			return nil, errors.Errorf("DAA window index %d is out of the range of the %d blocks in the trusted data",
+				index, len(data.DAAWindow))
+		}
+		blockWithTrustedData.DAAWindow = append(blockWithTrustedData.DAAWindow, appmessage.TrustedDataDataDAABlockV4ToTrustedDataDataDAAHeader(data.DAAWindow[index]))
+	}
+
+	for _, index := range block.GHOSTDAGDataIndices {
+		if index >= uint64(len(data.GHOSTDAGData)) {
+			return nil, errors.Errorf("GHOSTDAG data index %d is out of the range of the %d entries in the trusted data",
+				index, len(data.GHOSTDAGData))
+		}
+		blockWithTrustedData.GHOSTDAGData = append(blockWithTrustedData.GHOSTDAGData, appmessage.GHOSTDAGHashPairToDomainGHOSTDAGHashPair(data.GHOSTDAGData[index]))
+	}
+
+	return blockWithTrustedData, nil
+}
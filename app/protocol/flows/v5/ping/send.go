@@ -70,7 +70,7 @@ func (flow *sendPingsFlow) start() error {
 		}
 		pongMessage := message.(*appmessage.MsgPong)
 		if pongMessage.Nonce != pingMessage.Nonce {
-			return protocolerrors.New(true, "nonce mismatch between ping and pong")
+			return protocolerrors.New(protocolerrors.BanScoreMinor, "nonce mismatch between ping and pong")
 		}
 		flow.peer.SetPingIdle()
 	}
@@ -33,5 +33,5 @@ func (flow *handleRejectsFlow) start() error {
 	}
 	rejectMessage := message.(*appmessage.MsgReject)
 
-	return protocolerrors.Errorf(false, "got reject message: `%s`", rejectMessage.Reason)
+	return protocolerrors.Errorf(protocolerrors.BanScoreNone, "got reject message: `%s`", rejectMessage.Reason)
 }
@@ -8,14 +8,14 @@ import (
 	"github.com/pkg/errors"
 )
 
-func checkFlowError(t *testing.T, err error, isProtocolError bool, shouldBan bool, contains string) {
+func checkFlowError(t *testing.T, err error, isProtocolError bool, banScore uint32, contains string) {
 	pErr := protocolerrors.ProtocolError{}
 	if errors.As(err, &pErr) != isProtocolError {
 		t.Fatalf("Unexepcted error %+v", err)
 	}
 
-	if pErr.ShouldBan != shouldBan {
-		t.Fatalf("Exepcted shouldBan %t but got %t", shouldBan, pErr.ShouldBan)
+	if pErr.BanScore != banScore {
+		t.Fatalf("Exepcted ban score %d but got %d", banScore, pErr.BanScore)
 	}
 
 	if !strings.Contains(err.Error(), contains) {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kaspanet/kaspad/app/appmessage"
 	peerpkg "github.com/kaspanet/kaspad/app/protocol/peer"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
 	"github.com/kaspanet/kaspad/domain/consensus"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
@@ -43,7 +44,7 @@ func TestReceiveAddressesErrors(t *testing.T) {
 
 		select {
 		case err := <-errChan:
-			checkFlowError(t, err, true, true, "address count exceeded")
+			checkFlowError(t, err, true, protocolerrors.BanScoreMajor, "address count exceeded")
 		case <-time.After(time.Second):
 			t.Fatalf("timed out after %s", time.Second)
 		}
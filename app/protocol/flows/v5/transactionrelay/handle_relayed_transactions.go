@@ -123,7 +123,7 @@ func (flow *handleRelayedTransactionsFlow) readInv() (*appmessage.MsgInvTransact
 
 	inv, ok := msg.(*appmessage.MsgInvTransaction)
 	if !ok {
-		return nil, protocolerrors.Errorf(true, "unexpected %s message in the block relay flow while "+
+		return nil, protocolerrors.Errorf(protocolerrors.BanScoreMajor, "unexpected %s message in the block relay flow while "+
 			"expecting an inv message", msg.Command())
 	}
 	return inv, nil
@@ -170,7 +170,7 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 		}
 		if msgTxNotFound != nil {
 			if !msgTxNotFound.ID.Equal(expectedID) {
-				return protocolerrors.Errorf(true, "expected transaction %s, but got %s",
+				return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "expected transaction %s, but got %s",
 					expectedID, msgTxNotFound.ID)
 			}
 
@@ -179,7 +179,7 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 		tx := appmessage.MsgTxToDomainTransaction(msgTx)
 		txID := consensushashing.TransactionID(tx)
 		if !txID.Equal(expectedID) {
-			return protocolerrors.Errorf(true, "expected transaction %s, but got %s",
+			return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "expected transaction %s, but got %s",
 				expectedID, txID)
 		}
 
@@ -202,7 +202,7 @@ func (flow *handleRelayedTransactionsFlow) receiveTransactions(requestedTransact
 				continue
 			}
 
-			return protocolerrors.Errorf(true, "rejected transaction %s: %s", txID, ruleErr)
+			return protocolerrors.Errorf(protocolerrors.BanScoreMajor, "rejected transaction %s: %s", txID, ruleErr)
 		}
 		err = flow.broadcastAcceptedTransactions(consensushashing.TransactionIDs(acceptedTransactions))
 		if err != nil {
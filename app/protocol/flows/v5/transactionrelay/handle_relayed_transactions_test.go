@@ -135,8 +135,8 @@ func TestHandleRelayedTransactionsNotFound(t *testing.T) {
 		if protocolErr := (protocolerrors.ProtocolError{}); err == nil || !errors.As(err, &protocolErr) {
 			t.Fatalf("Expected to protocol error")
 		} else {
-			if !protocolErr.ShouldBan {
-				t.Fatalf("Exepcted shouldBan true, but got false.")
+			if protocolErr.BanScore != protocolerrors.BanScoreMajor {
+				t.Fatalf("Exepcted ban score %d, but got %d.", protocolerrors.BanScoreMajor, protocolErr.BanScore)
 			}
 			if !strings.Contains(err.Error(), "unexpected Addresses [code 3] message in the block relay flow while expecting an inv message") {
 				t.Fatalf("Unexpected error: expected: an error due to existence of an Addresses message "+
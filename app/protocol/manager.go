@@ -68,6 +68,14 @@ func (m *Manager) AddTransaction(tx *externalapi.DomainTransaction, allowOrphan
 	return m.context.AddTransaction(tx, allowOrphan)
 }
 
+// AddTransactionWithReplacement adds transaction to the mempool, replacing the mempool
+// transaction it double-spends, and propagates it.
+func (m *Manager) AddTransactionWithReplacement(tx *externalapi.DomainTransaction) (
+	replacedTransaction *externalapi.DomainTransaction, err error) {
+
+	return m.context.AddTransactionWithReplacement(tx)
+}
+
 // AddBlock adds the given block to the DAG and propagates it.
 func (m *Manager) AddBlock(block *externalapi.DomainBlock) error {
 	return m.context.AddBlock(block)
@@ -49,7 +49,7 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 
 		netConnection.SetOnInvalidMessageHandler(func(err error) {
 			if atomic.AddUint32(&isStopping, 1) == 1 {
-				errChan <- protocolerrors.Wrap(true, err, "received bad message")
+				errChan <- protocolerrors.Wrap(protocolerrors.BanScoreMajor, err, "received bad message")
 			}
 		})
 
@@ -106,18 +106,8 @@ func (m *Manager) routerInitializer(router *routerpkg.Router, netConnection *net
 
 func (m *Manager) handleError(err error, netConnection *netadapter.NetConnection, outgoingRoute *routerpkg.Route) {
 	if protocolErr := (protocolerrors.ProtocolError{}); errors.As(err, &protocolErr) {
-		if m.context.Config().EnableBanning && protocolErr.ShouldBan {
-			log.Warnf("Banning %s (reason: %s)", netConnection, protocolErr.Cause)
-
-			err := m.context.ConnectionManager().Ban(netConnection)
-			if err != nil && !errors.Is(err, connmanager.ErrCannotBanPermanent) {
-				panic(err)
-			}
-
-			err = outgoingRoute.Enqueue(appmessage.NewMsgReject(protocolErr.Error()))
-			if err != nil && !errors.Is(err, routerpkg.ErrRouteClosed) {
-				panic(err)
-			}
+		if protocolErr.BanScore > 0 {
+			m.addBanScore(netConnection, protocolErr, outgoingRoute)
 		}
 		log.Infof("Disconnecting from %s (reason: %s)", netConnection, protocolErr.Cause)
 		netConnection.Disconnect()
@@ -134,6 +124,41 @@ func (m *Manager) handleError(err error, netConnection *netadapter.NetConnection
 	panic(err)
 }
 
+// addBanScore adds the ban score of the given protocol error to the peer's ban score,
+// and bans the peer if its ban score reached the ban threshold
+func (m *Manager) addBanScore(netConnection *netadapter.NetConnection, protocolErr protocolerrors.ProtocolError,
+	outgoingRoute *routerpkg.Route) {
+
+	if m.context.ConnectionManager().IsWhitelisted(netConnection) {
+		log.Warnf("Not increasing the ban score of whitelisted peer %s (reason: %s)", netConnection, protocolErr.Cause)
+		return
+	}
+
+	banScore := m.context.ConnectionManager().AddBanScore(netConnection, protocolErr.BanScore)
+	log.Warnf("Ban score of %s increased by %d to %d (reason: %s)",
+		netConnection, protocolErr.BanScore, banScore, protocolErr.Cause)
+
+	if m.context.Config().EnableBanning && banScore >= m.context.Config().BanThreshold {
+		m.ban(netConnection, protocolErr, outgoingRoute)
+	}
+}
+
+func (m *Manager) ban(netConnection *netadapter.NetConnection, protocolErr protocolerrors.ProtocolError,
+	outgoingRoute *routerpkg.Route) {
+
+	log.Warnf("Banning %s (reason: %s)", netConnection, protocolErr.Cause)
+
+	err := m.context.ConnectionManager().Ban(netConnection)
+	if err != nil && !errors.Is(err, connmanager.ErrCannotBanPermanent) {
+		panic(err)
+	}
+
+	err = outgoingRoute.Enqueue(appmessage.NewMsgReject(protocolErr.Error()))
+	if err != nil && !errors.Is(err, routerpkg.ErrRouteClosed) {
+		panic(err)
+	}
+}
+
 // RegisterFlow registers a flow to the given router.
 func (m *Manager) RegisterFlow(name string, router *routerpkg.Router, messageTypes []appmessage.MessageCommand, isStopping *uint32,
 	errChan chan error, initializeFunc common.FlowInitializeFunc) *common.Flow {
@@ -5,11 +5,31 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Ban scores that protocol errors add to the misbehaving peer's ban score.
+// A peer is banned once its accumulated ban score reaches the configured
+// ban threshold.
+const (
+	// BanScoreNone is for errors that don't indicate misbehavior
+	BanScoreNone uint32 = 0
+
+	// BanScoreMinor is for errors that an honest peer might cause, e.g. due
+	// to a race between its DAG and ours
+	BanScoreMinor uint32 = 10
+
+	// BanScoreMajor is for violations of the protocol flow, such as
+	// unexpected or unrequested messages
+	BanScoreMajor uint32 = 50
+
+	// BanScoreSevere is for sending invalid consensus data, such as an
+	// invalid block or an invalid pruning point proof
+	BanScoreSevere uint32 = 100
+)
+
 // ProtocolError is an error that signifies a violation
 // of the peer-to-peer protocol
 type ProtocolError struct {
-	ShouldBan bool
-	Cause     error
+	BanScore uint32
+	Cause    error
 }
 
 func (e ProtocolError) Error() string {
@@ -23,35 +43,35 @@ func (e ProtocolError) Unwrap() error {
 
 // Errorf formats according to a format specifier and returns the string
 // as a ProtocolError.
-func Errorf(shouldBan bool, format string, args ...interface{}) error {
+func Errorf(banScore uint32, format string, args ...interface{}) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.Errorf(format, args...),
+		BanScore: banScore,
+		Cause:    errors.Errorf(format, args...),
 	}
 }
 
 // New returns a ProtocolError with the supplied message.
 // New also records the stack trace at the point it was called.
-func New(shouldBan bool, message string) error {
+func New(banScore uint32, message string) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.New(message),
+		BanScore: banScore,
+		Cause:    errors.New(message),
 	}
 }
 
 // Wrap wraps the given error and returns it as a ProtocolError.
-func Wrap(shouldBan bool, err error, message string) error {
+func Wrap(banScore uint32, err error, message string) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.Wrap(err, message),
+		BanScore: banScore,
+		Cause:    errors.Wrap(err, message),
 	}
 }
 
 // Wrapf wraps the given error with the given format and returns it as a ProtocolError.
-func Wrapf(shouldBan bool, err error, format string, args ...interface{}) error {
+func Wrapf(banScore uint32, err error, format string, args ...interface{}) error {
 	return ProtocolError{
-		ShouldBan: shouldBan,
-		Cause:     errors.Wrapf(err, format, args...),
+		BanScore: banScore,
+		Cause:    errors.Wrapf(err, format, args...),
 	}
 }
 
@@ -63,5 +83,5 @@ func ConvertToBanningProtocolErrorIfRuleError(err error, format string, args ...
 		return err
 	}
 
-	return Wrapf(true, err, format, args...)
+	return Wrapf(BanScoreSevere, err, format, args...)
 }
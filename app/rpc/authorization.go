@@ -0,0 +1,373 @@
+package rpc
+
+import (
+	"net"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/pkg/errors"
+)
+
+// Built-in RPC roles. The admin role allows every command
+const (
+	roleReadOnly     = "read-only"
+	roleTransactions = "transactions"
+	roleMining       = "mining"
+	roleAdmin        = "admin"
+)
+
+var builtInRoles = map[string][]appmessage.MessageCommand{
+	roleReadOnly: {
+		appmessage.CmdGetCurrentNetworkRequestMessage,
+		appmessage.CmdNotifyBlockAddedRequestMessage,
+		appmessage.CmdGetPeerAddressesRequestMessage,
+		appmessage.CmdGetSelectedTipHashRequestMessage,
+		appmessage.CmdGetMempoolEntryRequestMessage,
+		appmessage.CmdGetConnectedPeerInfoRequestMessage,
+		appmessage.CmdNotifyVirtualSelectedParentChainChangedRequestMessage,
+		appmessage.CmdGetBlockRequestMessage,
+		appmessage.CmdGetSubnetworkRequestMessage,
+		appmessage.CmdGetVirtualSelectedParentChainFromBlockRequestMessage,
+		appmessage.CmdGetBlocksRequestMessage,
+		appmessage.CmdGetBlockCountRequestMessage,
+		appmessage.CmdGetBalanceByAddressRequestMessage,
+		appmessage.CmdGetBlockDAGInfoRequestMessage,
+		appmessage.CmdNotifyFinalityConflictsRequestMessage,
+		appmessage.CmdGetMempoolEntriesRequestMessage,
+		appmessage.CmdGetHeadersRequestMessage,
+		appmessage.CmdNotifyUTXOsChangedRequestMessage,
+		appmessage.CmdStopNotifyingUTXOsChangedRequestMessage,
+		appmessage.CmdGetUTXOsByAddressesRequestMessage,
+		appmessage.CmdGetBalancesByAddressesRequestMessage,
+		appmessage.CmdGetVirtualSelectedParentBlueScoreRequestMessage,
+		appmessage.CmdNotifyVirtualSelectedParentBlueScoreChangedRequestMessage,
+		appmessage.CmdGetInfoRequestMessage,
+		appmessage.CmdNotifyPruningPointUTXOSetOverrideRequestMessage,
+		appmessage.CmdStopNotifyingPruningPointUTXOSetOverrideRequestMessage,
+		appmessage.CmdEstimateNetworkHashesPerSecondRequestMessage,
+		appmessage.CmdNotifyVirtualDaaScoreChangedRequestMessage,
+		appmessage.CmdGetCoinSupplyRequestMessage,
+		appmessage.CmdGetMempoolEntriesByAddressesRequestMessage,
+		appmessage.CmdGetFeeEstimateRequestMessage,
+		appmessage.CmdGetTransactionRequestMessage,
+	},
+	roleTransactions: {
+		appmessage.CmdSubmitTransactionRequestMessage,
+		appmessage.CmdSubmitTransactionReplacementRequestMessage,
+	},
+	roleMining: {
+		appmessage.CmdGetBlockTemplateRequestMessage,
+		appmessage.CmdSubmitBlockRequestMessage,
+		appmessage.CmdNotifyNewBlockTemplateRequestMessage,
+		appmessage.CmdGetPayoutTransactionRequestMessage,
+	},
+}
+
+// alwaysAllowedCommands are allowed to all clients regardless of their roles.
+// RPC clients call GetInfo as soon as they connect to check for version mismatches
+var alwaysAllowedCommands = []appmessage.MessageCommand{
+	appmessage.CmdGetInfoRequestMessage,
+}
+
+// saferpcRoles are the roles granted to all clients when kaspad is run with --saferpc
+var saferpcRoles = []string{roleReadOnly, roleTransactions, roleMining}
+
+var errorResponseConstructors = map[appmessage.MessageCommand]func(err *appmessage.RPCError) appmessage.Message{
+	appmessage.CmdGetCurrentNetworkRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetCurrentNetworkResponseMessage{Error: err}
+	},
+	appmessage.CmdSubmitBlockRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.SubmitBlockResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBlockTemplateRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBlockTemplateResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyBlockAddedRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyBlockAddedResponseMessage{Error: err}
+	},
+	appmessage.CmdGetPeerAddressesRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetPeerAddressesResponseMessage{Error: err}
+	},
+	appmessage.CmdGetSelectedTipHashRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetSelectedTipHashResponseMessage{Error: err}
+	},
+	appmessage.CmdGetMempoolEntryRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetMempoolEntryResponseMessage{Error: err}
+	},
+	appmessage.CmdGetConnectedPeerInfoRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetConnectedPeerInfoResponseMessage{Error: err}
+	},
+	appmessage.CmdAddPeerRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.AddPeerResponseMessage{Error: err}
+	},
+	appmessage.CmdSubmitTransactionRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.SubmitTransactionResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyVirtualSelectedParentChainChangedRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyVirtualSelectedParentChainChangedResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBlockRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBlockResponseMessage{Error: err}
+	},
+	appmessage.CmdGetSubnetworkRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetSubnetworkResponseMessage{Error: err}
+	},
+	appmessage.CmdGetVirtualSelectedParentChainFromBlockRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetVirtualSelectedParentChainFromBlockResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBlocksRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBlocksResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBlockCountRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBlockCountResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBalanceByAddressRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBalanceByAddressResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBlockDAGInfoRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBlockDAGInfoResponseMessage{Error: err}
+	},
+	appmessage.CmdResolveFinalityConflictRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.ResolveFinalityConflictResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyFinalityConflictsRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyFinalityConflictsResponseMessage{Error: err}
+	},
+	appmessage.CmdGetMempoolEntriesRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetMempoolEntriesResponseMessage{Error: err}
+	},
+	appmessage.CmdShutDownRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.ShutDownResponseMessage{Error: err}
+	},
+	appmessage.CmdGetHeadersRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetHeadersResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyUTXOsChangedRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyUTXOsChangedResponseMessage{Error: err}
+	},
+	appmessage.CmdStopNotifyingUTXOsChangedRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.StopNotifyingUTXOsChangedResponseMessage{Error: err}
+	},
+	appmessage.CmdGetUTXOsByAddressesRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetUTXOsByAddressesResponseMessage{Error: err}
+	},
+	appmessage.CmdGetBalancesByAddressesRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetBalancesByAddressesResponseMessage{Error: err}
+	},
+	appmessage.CmdGetVirtualSelectedParentBlueScoreRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetVirtualSelectedParentBlueScoreResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyVirtualSelectedParentBlueScoreChangedRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyVirtualSelectedParentBlueScoreChangedResponseMessage{Error: err}
+	},
+	appmessage.CmdBanRequestMessage:   func(err *appmessage.RPCError) appmessage.Message { return &appmessage.BanResponseMessage{Error: err} },
+	appmessage.CmdUnbanRequestMessage: func(err *appmessage.RPCError) appmessage.Message { return &appmessage.UnbanResponseMessage{Error: err} },
+	appmessage.CmdGetInfoRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetInfoResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyPruningPointUTXOSetOverrideRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyPruningPointUTXOSetOverrideResponseMessage{Error: err}
+	},
+	appmessage.CmdStopNotifyingPruningPointUTXOSetOverrideRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.StopNotifyingPruningPointUTXOSetOverrideResponseMessage{Error: err}
+	},
+	appmessage.CmdEstimateNetworkHashesPerSecondRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.EstimateNetworkHashesPerSecondResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyVirtualDaaScoreChangedRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyVirtualDaaScoreChangedResponseMessage{Error: err}
+	},
+	appmessage.CmdNotifyNewBlockTemplateRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.NotifyNewBlockTemplateResponseMessage{Error: err}
+	},
+	appmessage.CmdGetCoinSupplyRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetCoinSupplyResponseMessage{Error: err}
+	},
+	appmessage.CmdGetMempoolEntriesByAddressesRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetMempoolEntriesByAddressesResponseMessage{Error: err}
+	},
+	appmessage.CmdGetFeeEstimateRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetFeeEstimateResponseMessage{Error: err}
+	},
+	appmessage.CmdSubmitTransactionReplacementRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.SubmitTransactionReplacementResponseMessage{Error: err}
+	},
+	appmessage.CmdGetTransactionRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetTransactionResponseMessage{Error: err}
+	},
+	appmessage.CmdGenerateBlocksRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GenerateBlocksResponseMessage{Error: err}
+	},
+	appmessage.CmdGetPayoutTransactionRequestMessage: func(err *appmessage.RPCError) appmessage.Message {
+		return &appmessage.GetPayoutTransactionResponseMessage{Error: err}
+	},
+}
+
+type commandSet map[appmessage.MessageCommand]struct{}
+
+func (s commandSet) add(commands ...appmessage.MessageCommand) {
+	for _, command := range commands {
+		s[command] = struct{}{}
+	}
+}
+
+func (s commandSet) contains(command appmessage.MessageCommand) bool {
+	_, ok := s[command]
+	return ok
+}
+
+// authorizer resolves the commands each RPC client is allowed to call
+type authorizer struct {
+	roles            map[string]commandSet
+	identityCommands map[string]commandSet
+	listenerCommands map[string]commandSet
+	defaultCommands  commandSet
+}
+
+func newAuthorizer(cfg *config.Config) (*authorizer, error) {
+	a := &authorizer{
+		roles:            make(map[string]commandSet),
+		identityCommands: make(map[string]commandSet),
+		listenerCommands: make(map[string]commandSet),
+	}
+
+	for role, commands := range builtInRoles {
+		a.roles[role] = make(commandSet)
+		a.roles[role].add(commands...)
+	}
+	a.roles[roleAdmin] = make(commandSet)
+	for command := range handlers {
+		a.roles[roleAdmin].add(command)
+	}
+	a.identityCommands[config.RPCOperatorIdentity] = a.roles[roleAdmin]
+
+	if cfg.RPCRoles == nil {
+		if cfg.SafeRPC {
+			a.defaultCommands = a.mustCommandsForRoles(saferpcRoles)
+		} else {
+			a.defaultCommands = a.roles[roleAdmin]
+		}
+		return a, nil
+	}
+
+	for role, commandNames := range cfg.RPCRoles.Roles {
+		if _, ok := a.roles[role]; ok {
+			return nil, errors.Errorf("role %s is already defined", role)
+		}
+		commands := make(commandSet, len(commandNames))
+		commands.add(alwaysAllowedCommands...)
+		for _, commandName := range commandNames {
+			command, ok := commandByName(commandName)
+			if !ok {
+				return nil, errors.Errorf("role %s has unknown command %s", role, commandName)
+			}
+			commands.add(command)
+		}
+		a.roles[role] = commands
+	}
+
+	var err error
+	for _, credential := range cfg.RPCRoles.Credentials {
+		a.identityCommands[credential.Name], err = a.commandsForRoles(credential.Roles)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid roles for RPC credential %s", credential.Name)
+		}
+	}
+	for listener, roles := range cfg.RPCRoles.Listeners {
+		a.listenerCommands[listener], err = a.commandsForRoles(roles)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid roles for RPC listener %s", listener)
+		}
+	}
+	a.defaultCommands, err = a.commandsForRoles(cfg.RPCRoles.DefaultRoles)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid default RPC roles")
+	}
+
+	return a, nil
+}
+
+func (a *authorizer) commandsForRoles(roles []string) (commandSet, error) {
+	commands := make(commandSet)
+	commands.add(alwaysAllowedCommands...)
+	for _, role := range roles {
+		roleCommands, ok := a.roles[role]
+		if !ok {
+			return nil, errors.Errorf("unknown role %s", role)
+		}
+		for command := range roleCommands {
+			commands.add(command)
+		}
+	}
+	return commands, nil
+}
+
+func (a *authorizer) mustCommandsForRoles(roles []string) commandSet {
+	commands, err := a.commandsForRoles(roles)
+	if err != nil {
+		panic(err)
+	}
+	return commands
+}
+
+// allowedCommands returns the commands a client with the given identity, connected through
+// the given local address, is allowed to call. Authenticated clients are allowed the commands
+// of their credential's roles. Other clients are allowed the commands of the roles of the
+// listener they connected through, if any.
+func (a *authorizer) allowedCommands(identity string, localAddress string) commandSet {
+	if identity != "" {
+		commands, ok := a.identityCommands[identity]
+		if !ok {
+			// Should never happen, since all identities are defined by the roles config
+			return a.mustCommandsForRoles(nil)
+		}
+		return commands
+	}
+
+	if localAddress != "" {
+		commands, ok := a.listenerCommands[localAddress]
+		if ok {
+			return commands
+		}
+		commands, ok = a.wildcardListenerCommands(localAddress)
+		if ok {
+			return commands
+		}
+	}
+
+	return a.defaultCommands
+}
+
+// wildcardListenerCommands returns the commands of a listener on all interfaces
+// (e.g. 0.0.0.0:16110) which accepted a connection on the given local address
+func (a *authorizer) wildcardListenerCommands(localAddress string) (commandSet, bool) {
+	_, localPort, err := net.SplitHostPort(localAddress)
+	if err != nil {
+		return nil, false
+	}
+	for listener, commands := range a.listenerCommands {
+		host, port, err := net.SplitHostPort(listener)
+		if err != nil || port != localPort {
+			continue
+		}
+		if host == "" || net.ParseIP(host).IsUnspecified() {
+			return commands, true
+		}
+	}
+	return nil, false
+}
+
+// commandByName returns the request command with the given name, e.g. "GetBlock"
+func commandByName(name string) (appmessage.MessageCommand, bool) {
+	for command := range handlers {
+		if appmessage.RPCMessageCommandToString[command] == name+"Request" {
+			return command, true
+		}
+	}
+	return 0, false
+}
+
+func commandName(command appmessage.MessageCommand) string {
+	return strings.TrimSuffix(appmessage.RPCMessageCommandToString[command], "Request")
+}
@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+func TestErrorResponseConstructors(t *testing.T) {
+	for command := range handlers {
+		errorResponseConstructor, ok := errorResponseConstructors[command]
+		if !ok {
+			t.Fatalf("command %s has no error response constructor", command)
+		}
+		response := errorResponseConstructor(appmessage.RPCErrorf("test"))
+		responseName := appmessage.RPCMessageCommandToString[response.Command()]
+		if !strings.HasSuffix(responseName, "Response") {
+			t.Fatalf("the error response of command %s has unexpected command %s", command, response.Command())
+		}
+	}
+}
+
+func TestBuiltInRoles(t *testing.T) {
+	for role, commands := range builtInRoles {
+		for _, command := range commands {
+			if _, ok := handlers[command]; !ok {
+				t.Fatalf("role %s has command %s which has no handler", role, command)
+			}
+		}
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	cfg := &config.Config{
+		Flags: &config.Flags{},
+		RPCRoles: &config.RPCRolesConfig{
+			Roles: map[string][]string{
+				"info": {"GetInfo"},
+			},
+			Credentials: []*config.RPCRoleCredential{
+				{Name: "miner", User: "miner", Password: "secret", Roles: []string{roleMining}},
+			},
+			Listeners: map[string][]string{
+				"127.0.0.1:16110": {roleAdmin},
+				"0.0.0.0:16210":   {"info"},
+			},
+			DefaultRoles: []string{roleReadOnly},
+		},
+	}
+	authorizer, err := newAuthorizer(cfg)
+	if err != nil {
+		t.Fatalf("newAuthorizer: %s", err)
+	}
+
+	tests := []struct {
+		name         string
+		identity     string
+		localAddress string
+		allowed      []appmessage.MessageCommand
+		denied       []appmessage.MessageCommand
+	}{
+		{
+			name:     "operator",
+			identity: config.RPCOperatorIdentity,
+			allowed:  []appmessage.MessageCommand{appmessage.CmdShutDownRequestMessage, appmessage.CmdGetInfoRequestMessage},
+		},
+		{
+			name:         "credential overrides listener",
+			identity:     "miner",
+			localAddress: "127.0.0.1:16110",
+			allowed:      []appmessage.MessageCommand{appmessage.CmdSubmitBlockRequestMessage},
+			denied:       []appmessage.MessageCommand{appmessage.CmdShutDownRequestMessage, appmessage.CmdGetBlockRequestMessage},
+		},
+		{
+			name:         "listener",
+			localAddress: "127.0.0.1:16110",
+			allowed:      []appmessage.MessageCommand{appmessage.CmdShutDownRequestMessage, appmessage.CmdSubmitBlockRequestMessage},
+		},
+		{
+			name:         "wildcard listener",
+			localAddress: "10.0.0.1:16210",
+			allowed:      []appmessage.MessageCommand{appmessage.CmdGetInfoRequestMessage},
+			denied:       []appmessage.MessageCommand{appmessage.CmdGetBlockRequestMessage},
+		},
+		{
+			name:         "default",
+			localAddress: "10.0.0.1:16110",
+			allowed:      []appmessage.MessageCommand{appmessage.CmdGetInfoRequestMessage, appmessage.CmdGetBlockRequestMessage},
+			denied:       []appmessage.MessageCommand{appmessage.CmdSubmitBlockRequestMessage, appmessage.CmdBanRequestMessage},
+		},
+	}
+
+	for _, test := range tests {
+		allowedCommands := authorizer.allowedCommands(test.identity, test.localAddress)
+		for _, command := range test.allowed {
+			if !allowedCommands.contains(command) {
+				t.Errorf("%s: expected command %s to be allowed", test.name, command)
+			}
+		}
+		for _, command := range test.denied {
+			if allowedCommands.contains(command) {
+				t.Errorf("%s: expected command %s to be denied", test.name, command)
+			}
+		}
+	}
+}
+
+func TestAuthorizerSafeRPC(t *testing.T) {
+	authorizer, err := newAuthorizer(&config.Config{Flags: &config.Flags{SafeRPC: true}})
+	if err != nil {
+		t.Fatalf("newAuthorizer: %s", err)
+	}
+	allowedCommands := authorizer.allowedCommands("", "127.0.0.1:16110")
+	if !allowedCommands.contains(appmessage.CmdSubmitBlockRequestMessage) {
+		t.Fatalf("expected SubmitBlock to be allowed in safe RPC mode")
+	}
+	for _, command := range []appmessage.MessageCommand{appmessage.CmdBanRequestMessage, appmessage.CmdUnbanRequestMessage,
+		appmessage.CmdAddPeerRequestMessage, appmessage.CmdShutDownRequestMessage,
+		appmessage.CmdResolveFinalityConflictRequestMessage} {
+
+		if allowedCommands.contains(command) {
+			t.Fatalf("expected command %s to be denied in safe RPC mode", command)
+		}
+	}
+}
+
+func TestAuthorizerUnknownRole(t *testing.T) {
+	cfg := &config.Config{
+		Flags: &config.Flags{},
+		RPCRoles: &config.RPCRolesConfig{
+			DefaultRoles: []string{"no-such-role"},
+		},
+	}
+	_, err := newAuthorizer(cfg)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown role")
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
 	"github.com/kaspanet/kaspad/domain"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/txindex"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
@@ -17,7 +19,8 @@ import (
 
 // Manager is an RPC manager
 type Manager struct {
-	context *rpccontext.Context
+	context    *rpccontext.Context
+	authorizer *authorizer
 }
 
 // NewManager creates a new RPC Manager
@@ -29,26 +32,40 @@ func NewManager(
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
+	txIndex *txindex.TXIndex,
+	database database.Database,
 	consensusEventsChan chan externalapi.ConsensusEvent,
-	shutDownChan chan<- struct{}) *Manager {
+	shutDownChan chan<- struct{}) (*Manager, error) {
 
+	authorizer, err := newAuthorizer(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading the RPC roles")
+	}
+
+	context, err := rpccontext.NewContext(
+		cfg,
+		domain,
+		netAdapter,
+		protocolManager,
+		connectionManager,
+		addressManager,
+		utxoIndex,
+		txIndex,
+		database,
+		shutDownChan,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading the payout ledger")
+	}
 	manager := Manager{
-		context: rpccontext.NewContext(
-			cfg,
-			domain,
-			netAdapter,
-			protocolManager,
-			connectionManager,
-			addressManager,
-			utxoIndex,
-			shutDownChan,
-		),
+		authorizer: authorizer,
+		context:    context,
 	}
 	netAdapter.SetRPCRouterInitializer(manager.routerInitializer)
 
 	manager.initConsensusEventsHandler(consensusEventsChan)
 
-	return &manager
+	return &manager, nil
 }
 
 func (m *Manager) initConsensusEventsHandler(consensusEventsChan chan externalapi.ConsensusEvent) {
@@ -113,7 +130,19 @@ func (m *Manager) notifyVirtualChange(virtualChangeSet *externalapi.VirtualChang
 		}
 	}
 
-	err := m.notifyVirtualSelectedParentBlueScoreChanged(virtualChangeSet.VirtualSelectedParentBlueScore)
+	if m.context.Config.TxIndex {
+		err := m.context.TXIndex.Update(virtualChangeSet)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := m.context.PayoutLedger.Update(m.context.Domain.Consensus(), virtualChangeSet)
+	if err != nil {
+		return err
+	}
+
+	err = m.notifyVirtualSelectedParentBlueScoreChanged(virtualChangeSet.VirtualSelectedParentBlueScore)
 	if err != nil {
 		return err
 	}
@@ -158,6 +187,13 @@ func (m *Manager) NotifyPruningPointUTXOSetOverride() error {
 		}
 	}
 
+	if m.context.Config.TxIndex {
+		err := m.context.TXIndex.Reset()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1,9 +1,12 @@
 package rpc
 
 import (
+	"time"
+
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
 	"github.com/kaspanet/kaspad/app/rpc/rpchandlers"
+	"github.com/kaspanet/kaspad/infrastructure/metrics"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/pkg/errors"
@@ -51,6 +54,11 @@ var handlers = map[appmessage.MessageCommand]handler{
 	appmessage.CmdNotifyNewBlockTemplateRequestMessage:                      rpchandlers.HandleNotifyNewBlockTemplate,
 	appmessage.CmdGetCoinSupplyRequestMessage:                               rpchandlers.HandleGetCoinSupply,
 	appmessage.CmdGetMempoolEntriesByAddressesRequestMessage:                rpchandlers.HandleGetMempoolEntriesByAddresses,
+	appmessage.CmdGetFeeEstimateRequestMessage:                              rpchandlers.HandleGetFeeEstimate,
+	appmessage.CmdSubmitTransactionReplacementRequestMessage:                rpchandlers.HandleSubmitTransactionReplacement,
+	appmessage.CmdGetTransactionRequestMessage:                              rpchandlers.HandleGetTransaction,
+	appmessage.CmdGenerateBlocksRequestMessage:                              rpchandlers.HandleGenerateBlocks,
+	appmessage.CmdGetPayoutTransactionRequestMessage:                        rpchandlers.HandleGetPayoutTransaction,
 }
 
 func (m *Manager) routerInitializer(router *router.Router, netConnection *netadapter.NetConnection) {
@@ -63,16 +71,21 @@ func (m *Manager) routerInitializer(router *router.Router, netConnection *netada
 		panic(err)
 	}
 	m.context.NotificationManager.AddListener(router)
+	m.context.AddClient(router, netConnection.Identity())
+	allowedCommands := m.authorizer.allowedCommands(netConnection.Identity(), netConnection.LocalAddress())
 
 	spawn("routerInitializer-handleIncomingMessages", func() {
 		defer m.context.NotificationManager.RemoveListener(router)
+		defer m.context.RemoveClient(router)
 
-		err := m.handleIncomingMessages(router, incomingRoute)
+		err := m.handleIncomingMessages(router, incomingRoute, allowedCommands)
 		m.handleError(err, netConnection)
 	})
 }
 
-func (m *Manager) handleIncomingMessages(router *router.Router, incomingRoute *router.Route) error {
+func (m *Manager) handleIncomingMessages(router *router.Router, incomingRoute *router.Route,
+	allowedCommands commandSet) error {
+
 	outgoingRoute := router.OutgoingRoute()
 	for {
 		request, err := incomingRoute.Dequeue()
@@ -83,7 +96,17 @@ func (m *Manager) handleIncomingMessages(router *router.Router, incomingRoute *r
 		if !ok {
 			return err
 		}
-		response, err := handler(m.context, router, request)
+		if !allowedCommands.contains(request.Command()) {
+			log.Debugf("Denied %s RPC command to a client that isn't allowed to call it", commandName(request.Command()))
+			errorResponse := errorResponseConstructors[request.Command()](
+				appmessage.RPCErrorf("The %s RPC command is not allowed for this client", commandName(request.Command())))
+			err = outgoingRoute.Enqueue(errorResponse)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		response, err := m.handleRequest(handler, router, request)
 		if err != nil {
 			return err
 		}
@@ -94,6 +117,26 @@ func (m *Manager) handleIncomingMessages(router *router.Router, incomingRoute *r
 	}
 }
 
+// handleRequest handles the given request and records it in the metrics,
+// whether it succeeded or not
+func (m *Manager) handleRequest(handler handler, router *router.Router, request appmessage.Message) (
+	response appmessage.Message, err error) {
+
+	start := time.Now()
+	defer func() {
+		isError := err != nil || responseHasRPCError(response)
+		metrics.ObserveRPCRequest(request.Command().String(), time.Since(start), isError)
+	}()
+
+	return handler(m.context, router, request)
+}
+
+// responseHasRPCError returns whether the given response message carries an RPC error
+func responseHasRPCError(response appmessage.Message) bool {
+	errorCarrier, ok := response.(appmessage.RPCErrorCarrier)
+	return ok && errorCarrier.RPCError() != nil
+}
+
 func (m *Manager) handleError(err error, netConnection *netadapter.NetConnection) {
 	if errors.Is(err, router.ErrTimeout) {
 		log.Warnf("Got timeout from %s. Disconnecting...", netConnection)
@@ -0,0 +1,23 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+func TestResponseHasRPCError(t *testing.T) {
+	for command, errorResponseConstructor := range errorResponseConstructors {
+		response := errorResponseConstructor(appmessage.RPCErrorf("test"))
+		if !responseHasRPCError(response) {
+			t.Fatalf("the error response of command %s is not detected as an error", command)
+		}
+	}
+
+	if responseHasRPCError(&appmessage.GetInfoResponseMessage{}) {
+		t.Fatalf("a successful response is detected as an error")
+	}
+	if responseHasRPCError(nil) {
+		t.Fatalf("a missing response is detected as an error")
+	}
+}
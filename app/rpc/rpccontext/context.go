@@ -1,13 +1,18 @@
 package rpccontext
 
 import (
+	"sync"
+
 	"github.com/kaspanet/kaspad/app/protocol"
 	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/txindex"
 	"github.com/kaspanet/kaspad/domain/utxoindex"
 	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
 	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/connmanager"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 )
 
 // Context represents the RPC context
@@ -19,9 +24,14 @@ type Context struct {
 	ConnectionManager *connmanager.ConnectionManager
 	AddressManager    *addressmanager.AddressManager
 	UTXOIndex         *utxoindex.UTXOIndex
+	TXIndex           *txindex.TXIndex
 	ShutDownChan      chan<- struct{}
 
 	NotificationManager *NotificationManager
+	PayoutLedger        *PayoutLedger
+
+	clientIdentitiesLock sync.RWMutex
+	clientIdentities     map[*router.Router]string
 }
 
 // NewContext creates a new RPC context
@@ -32,7 +42,9 @@ func NewContext(cfg *config.Config,
 	connectionManager *connmanager.ConnectionManager,
 	addressManager *addressmanager.AddressManager,
 	utxoIndex *utxoindex.UTXOIndex,
-	shutDownChan chan<- struct{}) *Context {
+	txIndex *txindex.TXIndex,
+	database database.Database,
+	shutDownChan chan<- struct{}) (*Context, error) {
 
 	context := &Context{
 		Config:            cfg,
@@ -42,9 +54,47 @@ func NewContext(cfg *config.Config,
 		ConnectionManager: connectionManager,
 		AddressManager:    addressManager,
 		UTXOIndex:         utxoIndex,
+		TXIndex:           txIndex,
 		ShutDownChan:      shutDownChan,
+		clientIdentities:  make(map[*router.Router]string),
 	}
 	context.NotificationManager = NewNotificationManager(cfg.ActiveNetParams)
+	payoutLedger, err := NewPayoutLedger(database, cfg.ActiveNetParams.CoinbasePayloadScriptPublicKeyMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	context.PayoutLedger = payoutLedger
 
-	return context
+	return context, nil
+}
+
+// AddClient records the RPC identity of the client of the given router.
+// Clients that didn't authenticate have an empty identity
+func (ctx *Context) AddClient(router *router.Router, identity string) {
+	ctx.clientIdentitiesLock.Lock()
+	defer ctx.clientIdentitiesLock.Unlock()
+
+	ctx.clientIdentities[router] = identity
+}
+
+// RemoveClient forgets the client of the given router once it disconnects,
+// along with the payouts it registered without an RPC identity
+func (ctx *Context) RemoveClient(router *router.Router) {
+	ctx.clientIdentitiesLock.Lock()
+	delete(ctx.clientIdentities, router)
+	ctx.clientIdentitiesLock.Unlock()
+
+	ctx.PayoutLedger.RemoveRouter(router)
+}
+
+// PayoutsOwner returns the owner of the payouts that the client of the given router registers
+func (ctx *Context) PayoutsOwner(router *router.Router) PayoutsOwner {
+	ctx.clientIdentitiesLock.RLock()
+	defer ctx.clientIdentitiesLock.RUnlock()
+
+	identity := ctx.clientIdentities[router]
+	if identity != "" {
+		return PayoutsOwner{Identity: identity}
+	}
+	return PayoutsOwner{Router: router}
 }
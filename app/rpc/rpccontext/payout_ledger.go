@@ -0,0 +1,380 @@
+package rpccontext
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/processes/coinbasemanager"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+)
+
+// payoutsHashMarker precedes the payouts hash at the end of the coinbase extra data of block templates
+// that have payouts
+var payoutsHashMarker = []byte("/payouts:")
+
+// ErrPayoutsRegisteredByAnotherClient is returned when a client registers payouts for a pay
+// script that another client already registered other payouts for
+var ErrPayoutsRegisteredByAnotherClient = errors.New("other payouts are registered for this pay address by another client")
+
+// PayoutLedger keeps track of the coinbase rewards of blocks that were built from block templates
+// with weighted payouts, so that the rewards can be distributed to the payouts by a follow-up
+// payout transaction.
+//
+// Consensus pays the whole reward of a block to the single script in its coinbase payload, so
+// the reward can't be split within the block itself. Instead, the coinbase extra data of a block
+// template with payouts commits to the hash of its pay script and payouts. Once a chain block pays
+// the reward of such a block, the reward is credited to the payouts the block committed to, as long
+// as they were registered for the script the reward is paid to. The payouts of a reward are therefore
+// the ones of the template the block was built from, and registering other payouts later doesn't
+// change them.
+//
+// The payouts of a pay script can only be replaced or unregistered by the client that registered
+// them: the RPC identity it authenticated with, or, for a client without one, its connection until
+// it disconnects.
+//
+// The ledger is kept in the database. The credited rewards themselves are looked up in the UTXO
+// index, so a credit lives exactly as long as its UTXO lives in the virtual UTXO set.
+type PayoutLedger struct {
+	lock                                    sync.RWMutex
+	store                                   *payoutLedgerStore
+	coinbasePayloadScriptPublicKeyMaxLength uint8
+
+	payoutsByHash map[externalapi.DomainHash]*Payouts
+	registrations map[string]*payoutsRegistration
+}
+
+// Payouts are the weighted scripts the rewards paid into a pay script are distributed to
+type Payouts struct {
+	PayScriptPublicKey *externalapi.ScriptPublicKey
+	Payouts            []*Payout
+}
+
+// Payout is a weighted script that the rewards of a pay address are distributed to
+type Payout struct {
+	ScriptPublicKey *externalapi.ScriptPublicKey
+	Weight          uint32
+}
+
+// PayoutCredit is a coinbase UTXO of a pay address along with the payouts its amount
+// is distributed to
+type PayoutCredit struct {
+	Outpoint  *externalapi.DomainOutpoint
+	UTXOEntry externalapi.UTXOEntry
+	Payouts   []*Payout
+}
+
+// PayoutsOwner is the client that registered the payouts of a pay script. Clients that
+// authenticated with an RPC identity are identified by it, and other clients by their router
+type PayoutsOwner struct {
+	Identity string
+	Router   *router.Router
+}
+
+type payoutsRegistration struct {
+	owner       PayoutsOwner
+	payoutsHash externalapi.DomainHash
+}
+
+// NewPayoutLedger creates a PayoutLedger that's kept in the given database
+func NewPayoutLedger(database database.Database, coinbasePayloadScriptPublicKeyMaxLength uint8) (*PayoutLedger, error) {
+	store := newPayoutLedgerStore(database)
+	payoutsByHash, err := store.payoutsByHash()
+	if err != nil {
+		return nil, err
+	}
+	identityRegistrations, err := store.identityRegistrations()
+	if err != nil {
+		return nil, err
+	}
+
+	registrations := make(map[string]*payoutsRegistration, len(identityRegistrations))
+	for _, registration := range identityRegistrations {
+		payouts, ok := payoutsByHash[registration.payoutsHash]
+		if !ok {
+			return nil, errors.Errorf("the payouts %s of identity %s are missing from the payout ledger",
+				registration.payoutsHash, registration.owner.Identity)
+		}
+		registrations[payouts.PayScriptPublicKey.String()] = registration
+	}
+
+	return &PayoutLedger{
+		store:                                   store,
+		coinbasePayloadScriptPublicKeyMaxLength: coinbasePayloadScriptPublicKeyMaxLength,
+		payoutsByHash:                           payoutsByHash,
+		registrations:                           registrations,
+	}, nil
+}
+
+// PayoutsHash returns the hash that the coinbase of a block template with the given payouts commits to
+func PayoutsHash(payouts *Payouts) *externalapi.DomainHash {
+	hash := blake2b.Sum256(serializePayouts(payouts))
+	return externalapi.NewDomainHashFromByteArray(&hash)
+}
+
+// PayoutsExtraData returns the given coinbase extra data with a commitment to the given payouts hash
+func PayoutsExtraData(extraData []byte, payoutsHash *externalapi.DomainHash) []byte {
+	payoutsExtraData := make([]byte, 0, len(extraData)+len(payoutsHashMarker)+externalapi.DomainHashSize)
+	payoutsExtraData = append(payoutsExtraData, extraData...)
+	payoutsExtraData = append(payoutsExtraData, payoutsHashMarker...)
+	return append(payoutsExtraData, payoutsHash.ByteSlice()...)
+}
+
+// payoutsHashFromExtraData returns the payouts hash the given coinbase extra data commits to, if any
+func payoutsHashFromExtraData(extraData []byte) (*externalapi.DomainHash, bool) {
+	hashStart := len(extraData) - externalapi.DomainHashSize
+	markerStart := hashStart - len(payoutsHashMarker)
+	if markerStart < 0 || !bytes.Equal(extraData[markerStart:hashStart], payoutsHashMarker) {
+		return nil, false
+	}
+	payoutsHash, err := externalapi.NewDomainHashFromByteSlice(extraData[hashStart:])
+	if err != nil {
+		return nil, false
+	}
+	return payoutsHash, true
+}
+
+// RegisterPayouts registers the given payouts for their pay script on behalf of the given owner,
+// and returns their hash. It fails if other payouts are registered for the pay script by another owner
+func (pl *PayoutLedger) RegisterPayouts(owner PayoutsOwner, payouts *Payouts) (*externalapi.DomainHash, error) {
+	pl.lock.Lock()
+	defer pl.lock.Unlock()
+
+	payoutsHash := PayoutsHash(payouts)
+	payScriptPublicKeyString := payouts.PayScriptPublicKey.String()
+	registration, ok := pl.registrations[payScriptPublicKeyString]
+	if ok && registration.owner != owner {
+		if registration.payoutsHash.Equal(payoutsHash) {
+			return payoutsHash, nil
+		}
+		return nil, ErrPayoutsRegisteredByAnotherClient
+	}
+
+	registration = &payoutsRegistration{owner: owner, payoutsHash: *payoutsHash}
+	err := pl.store.putRegistration(payoutsHash, payouts, registration)
+	if err != nil {
+		return nil, err
+	}
+	pl.payoutsByHash[*payoutsHash] = payouts
+	pl.registrations[payScriptPublicKeyString] = registration
+	return payoutsHash, nil
+}
+
+// UnregisterPayouts unregisters the payouts of the given pay script if they were registered by the given owner.
+// Rewards of blocks that were built from templates with these payouts remain credited to them
+func (pl *PayoutLedger) UnregisterPayouts(owner PayoutsOwner, payScriptPublicKey *externalapi.ScriptPublicKey) error {
+	pl.lock.Lock()
+	defer pl.lock.Unlock()
+
+	payScriptPublicKeyString := payScriptPublicKey.String()
+	registration, ok := pl.registrations[payScriptPublicKeyString]
+	if !ok || registration.owner != owner {
+		return nil
+	}
+
+	if owner.Identity != "" {
+		err := pl.store.deleteIdentityRegistration(payScriptPublicKey)
+		if err != nil {
+			return err
+		}
+	}
+	delete(pl.registrations, payScriptPublicKeyString)
+	return nil
+}
+
+// RemoveRouter unregisters the payouts that were registered by the client of the given router
+// without an RPC identity. It's called when the client disconnects
+func (pl *PayoutLedger) RemoveRouter(router *router.Router) {
+	pl.lock.Lock()
+	defer pl.lock.Unlock()
+
+	for payScriptPublicKeyString, registration := range pl.registrations {
+		if registration.owner == (PayoutsOwner{Router: router}) {
+			delete(pl.registrations, payScriptPublicKeyString)
+		}
+	}
+}
+
+// Update credits the rewards paid by the coinbases of the chain blocks added by the given virtual change
+// set to the payouts their blocks committed to, and forgets the credits of the UTXOs it removes
+func (pl *PayoutLedger) Update(consensus externalapi.Consensus, virtualChangeSet *externalapi.VirtualChangeSet) error {
+	pl.lock.RLock()
+	defer pl.lock.RUnlock()
+
+	if len(pl.payoutsByHash) == 0 {
+		return nil
+	}
+
+	var removedOutpoints []*externalapi.DomainOutpoint
+	if virtualChangeSet.VirtualUTXODiff != nil {
+		toRemoveIterator := virtualChangeSet.VirtualUTXODiff.ToRemove().Iterator()
+		defer toRemoveIterator.Close()
+		for ok := toRemoveIterator.First(); ok; ok = toRemoveIterator.Next() {
+			outpoint, utxoEntry, err := toRemoveIterator.Get()
+			if err != nil {
+				return err
+			}
+			if utxoEntry.IsCoinbase() {
+				removedOutpoints = append(removedOutpoints, outpoint)
+			}
+		}
+	}
+
+	credits := make(map[externalapi.DomainOutpoint]*externalapi.DomainHash)
+	if virtualChangeSet.VirtualSelectedParentChainChanges != nil {
+		for _, chainBlockHash := range virtualChangeSet.VirtualSelectedParentChainChanges.Added {
+			err := pl.addChainBlockCredits(consensus, chainBlockHash, credits)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(removedOutpoints) == 0 && len(credits) == 0 {
+		return nil
+	}
+	return pl.store.updateCredits(removedOutpoints, credits)
+}
+
+// addChainBlockCredits adds the outputs of the coinbase of the given chain block that pay rewards
+// of blocks with payouts to credits, along with the hashes of their payouts
+func (pl *PayoutLedger) addChainBlockCredits(consensus externalapi.Consensus, chainBlockHash *externalapi.DomainHash,
+	credits map[externalapi.DomainOutpoint]*externalapi.DomainHash) error {
+
+	block, found, err := consensus.GetBlock(chainBlockHash)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	blockInfo, err := consensus.GetBlockInfo(chainBlockHash)
+	if err != nil {
+		return err
+	}
+	acceptanceData, err := consensus.GetBlockAcceptanceData(chainBlockHash)
+	if database.IsNotFoundError(err) {
+		// Blocks that were received with trusted data have no acceptance data
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	acceptanceDataByHash := make(map[externalapi.DomainHash]*externalapi.BlockAcceptanceData, len(acceptanceData))
+	for _, blockAcceptanceData := range acceptanceData {
+		acceptanceDataByHash[*blockAcceptanceData.BlockHash] = blockAcceptanceData
+	}
+
+	coinbaseTransaction := block.Transactions[transactionhelper.CoinbaseTransactionIndex]
+	coinbaseTransactionID := consensushashing.TransactionID(coinbaseTransaction)
+	credit := func(outputIndex int, coinbaseData *externalapi.DomainCoinbaseData) {
+		payoutsHash, ok := payoutsHashFromExtraData(coinbaseData.ExtraData)
+		if !ok {
+			return
+		}
+		// Anyone can commit to any payouts hash, so the reward is credited only if the payouts
+		// are registered for the script it's paid to
+		output := coinbaseTransaction.Outputs[outputIndex]
+		payouts, ok := pl.payoutsByHash[*payoutsHash]
+		if !ok || !payouts.PayScriptPublicKey.Equal(output.ScriptPublicKey) {
+			return
+		}
+		credits[externalapi.DomainOutpoint{TransactionID: *coinbaseTransactionID, Index: uint32(outputIndex)}] = payoutsHash
+	}
+
+	// The coinbase has an output for every rewarded blue in the merge set, in merge set order,
+	// followed by an output that pays the rewards of the reds to the chain block itself.
+	// A blue that isn't rewarded has no output.
+	outputIndex := 0
+	for _, blueHash := range blockInfo.MergeSetBlues {
+		if outputIndex >= len(coinbaseTransaction.Outputs) {
+			break
+		}
+		blueAcceptanceData, ok := acceptanceDataByHash[*blueHash]
+		if !ok {
+			return errors.Errorf("missing the acceptance data of block %s in the merge set of %s", blueHash, chainBlockHash)
+		}
+		blueCoinbaseTransaction := blueAcceptanceData.TransactionAcceptanceData[transactionhelper.CoinbaseTransactionIndex].Transaction
+		_, coinbaseData, subsidy, err := coinbasemanager.ExtractCoinbaseDataBlueScoreAndSubsidy(
+			blueCoinbaseTransaction, pl.coinbasePayloadScriptPublicKeyMaxLength)
+		if err != nil {
+			return err
+		}
+		reward := subsidy
+		for _, transactionAcceptanceData := range blueAcceptanceData.TransactionAcceptanceData {
+			if transactionAcceptanceData.IsAccepted {
+				reward += transactionAcceptanceData.Fee
+			}
+		}
+
+		output := coinbaseTransaction.Outputs[outputIndex]
+		if output.Value != reward || !output.ScriptPublicKey.Equal(coinbaseData.ScriptPublicKey) {
+			continue
+		}
+		credit(outputIndex, coinbaseData)
+		outputIndex++
+	}
+
+	if outputIndex < len(coinbaseTransaction.Outputs) {
+		_, coinbaseData, _, err := coinbasemanager.ExtractCoinbaseDataBlueScoreAndSubsidy(
+			coinbaseTransaction, pl.coinbasePayloadScriptPublicKeyMaxLength)
+		if err != nil {
+			return err
+		}
+		credit(outputIndex, coinbaseData)
+	}
+	return nil
+}
+
+// MatureCredits returns up to maxCredits credits among the given UTXOs of a pay script that can
+// already be spent at the given virtual DAA score, oldest first
+func (pl *PayoutLedger) MatureCredits(payScriptUTXOs map[externalapi.DomainOutpoint]externalapi.UTXOEntry,
+	virtualDAAScore uint64, coinbaseMaturity uint64, maxCredits int) ([]*PayoutCredit, error) {
+
+	pl.lock.RLock()
+	defer pl.lock.RUnlock()
+
+	var matureCredits []*PayoutCredit
+	for outpoint, utxoEntry := range payScriptUTXOs {
+		if !utxoEntry.IsCoinbase() || utxoEntry.BlockDAAScore()+coinbaseMaturity > virtualDAAScore {
+			continue
+		}
+		outpoint := outpoint
+		payoutsHash, found, err := pl.store.credit(&outpoint)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		payouts, ok := pl.payoutsByHash[*payoutsHash]
+		if !ok || !payouts.PayScriptPublicKey.Equal(utxoEntry.ScriptPublicKey()) {
+			continue
+		}
+		matureCredits = append(matureCredits, &PayoutCredit{
+			Outpoint:  &outpoint,
+			UTXOEntry: utxoEntry,
+			Payouts:   payouts.Payouts,
+		})
+	}
+
+	sort.Slice(matureCredits, func(i, j int) bool {
+		if matureCredits[i].UTXOEntry.BlockDAAScore() != matureCredits[j].UTXOEntry.BlockDAAScore() {
+			return matureCredits[i].UTXOEntry.BlockDAAScore() < matureCredits[j].UTXOEntry.BlockDAAScore()
+		}
+		if !matureCredits[i].Outpoint.TransactionID.Equal(&matureCredits[j].Outpoint.TransactionID) {
+			return matureCredits[i].Outpoint.TransactionID.Less(&matureCredits[j].Outpoint.TransactionID)
+		}
+		return matureCredits[i].Outpoint.Index < matureCredits[j].Outpoint.Index
+	})
+	if len(matureCredits) > maxCredits {
+		matureCredits = matureCredits[:maxCredits]
+	}
+	return matureCredits, nil
+}
@@ -0,0 +1,241 @@
+package rpccontext
+
+import (
+	"encoding/binary"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+var payoutLedgerPayoutsBucket = database.MakeBucket([]byte("payout-ledger-payouts"))
+var payoutLedgerRegistrationsBucket = database.MakeBucket([]byte("payout-ledger-registrations"))
+var payoutLedgerCreditsBucket = database.MakeBucket([]byte("payout-ledger-credits"))
+
+// payoutLedgerStore keeps the payouts that block templates committed to, the payouts registered
+// by RPC identities and the credited coinbase outpoints in the database
+type payoutLedgerStore struct {
+	database database.Database
+}
+
+func newPayoutLedgerStore(database database.Database) *payoutLedgerStore {
+	return &payoutLedgerStore{database: database}
+}
+
+func (pls *payoutLedgerStore) payoutsByHash() (map[externalapi.DomainHash]*Payouts, error) {
+	cursor, err := pls.database.Cursor(payoutLedgerPayoutsBucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	payoutsByHash := make(map[externalapi.DomainHash]*Payouts)
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return nil, err
+		}
+		payoutsHash, err := externalapi.NewDomainHashFromByteSlice(key.Suffix())
+		if err != nil {
+			return nil, err
+		}
+		serializedPayouts, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		payouts, err := deserializePayouts(serializedPayouts)
+		if err != nil {
+			return nil, err
+		}
+		payoutsByHash[*payoutsHash] = payouts
+	}
+	return payoutsByHash, nil
+}
+
+func (pls *payoutLedgerStore) identityRegistrations() ([]*payoutsRegistration, error) {
+	cursor, err := pls.database.Cursor(payoutLedgerRegistrationsBucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	var registrations []*payoutsRegistration
+	for cursor.Next() {
+		serializedRegistration, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		registration, err := deserializeIdentityRegistration(serializedRegistration)
+		if err != nil {
+			return nil, err
+		}
+		registrations = append(registrations, registration)
+	}
+	return registrations, nil
+}
+
+// putRegistration stores the given payouts, and the given registration if it's owned by an RPC identity
+func (pls *payoutLedgerStore) putRegistration(payoutsHash *externalapi.DomainHash, payouts *Payouts,
+	registration *payoutsRegistration) error {
+
+	dbTransaction, err := pls.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.RollbackUnlessClosed()
+
+	err = dbTransaction.Put(payoutLedgerPayoutsBucket.Key(payoutsHash.ByteSlice()), serializePayouts(payouts))
+	if err != nil {
+		return err
+	}
+
+	registrationKey := registrationKey(payouts.PayScriptPublicKey)
+	if registration.owner.Identity != "" {
+		err = dbTransaction.Put(registrationKey, serializeIdentityRegistration(registration))
+	} else {
+		// Registrations of clients without an RPC identity don't outlive their connection
+		err = dbTransaction.Delete(registrationKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	return dbTransaction.Commit()
+}
+
+func (pls *payoutLedgerStore) deleteIdentityRegistration(payScriptPublicKey *externalapi.ScriptPublicKey) error {
+	return pls.database.Delete(registrationKey(payScriptPublicKey))
+}
+
+func (pls *payoutLedgerStore) updateCredits(removedOutpoints []*externalapi.DomainOutpoint,
+	credits map[externalapi.DomainOutpoint]*externalapi.DomainHash) error {
+
+	dbTransaction, err := pls.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.RollbackUnlessClosed()
+
+	for _, outpoint := range removedOutpoints {
+		err := dbTransaction.Delete(creditKey(outpoint))
+		if err != nil {
+			return err
+		}
+	}
+	for outpoint, payoutsHash := range credits {
+		outpoint := outpoint
+		err := dbTransaction.Put(creditKey(&outpoint), payoutsHash.ByteSlice())
+		if err != nil {
+			return err
+		}
+	}
+
+	return dbTransaction.Commit()
+}
+
+func (pls *payoutLedgerStore) credit(outpoint *externalapi.DomainOutpoint) (*externalapi.DomainHash, bool, error) {
+	serializedPayoutsHash, err := pls.database.Get(creditKey(outpoint))
+	if database.IsNotFoundError(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	payoutsHash, err := externalapi.NewDomainHashFromByteSlice(serializedPayoutsHash)
+	if err != nil {
+		return nil, false, err
+	}
+	return payoutsHash, true, nil
+}
+
+func registrationKey(payScriptPublicKey *externalapi.ScriptPublicKey) *database.Key {
+	return payoutLedgerRegistrationsBucket.Key(serializeScriptPublicKey(nil, payScriptPublicKey))
+}
+
+func creditKey(outpoint *externalapi.DomainOutpoint) *database.Key {
+	serializedOutpoint := make([]byte, externalapi.DomainHashSize+4) // uint32
+	copy(serializedOutpoint, outpoint.TransactionID.ByteSlice())
+	binary.LittleEndian.PutUint32(serializedOutpoint[externalapi.DomainHashSize:], outpoint.Index)
+	return payoutLedgerCreditsBucket.Key(serializedOutpoint)
+}
+
+func serializeScriptPublicKey(serialized []byte, scriptPublicKey *externalapi.ScriptPublicKey) []byte {
+	serialized = binary.LittleEndian.AppendUint16(serialized, scriptPublicKey.Version)
+	serialized = binary.LittleEndian.AppendUint32(serialized, uint32(len(scriptPublicKey.Script)))
+	return append(serialized, scriptPublicKey.Script...)
+}
+
+func deserializeScriptPublicKey(serialized []byte) (*externalapi.ScriptPublicKey, []byte, error) {
+	if len(serialized) < 6 { // uint16 + uint32
+		return nil, nil, errors.New("the serialized script public key is too short")
+	}
+	version := binary.LittleEndian.Uint16(serialized[:2])
+	scriptLength := binary.LittleEndian.Uint32(serialized[2:6])
+	serialized = serialized[6:]
+	if uint64(len(serialized)) < uint64(scriptLength) {
+		return nil, nil, errors.New("the serialized script public key is too short")
+	}
+	script := make([]byte, scriptLength)
+	copy(script, serialized)
+	return &externalapi.ScriptPublicKey{Script: script, Version: version}, serialized[scriptLength:], nil
+}
+
+func serializePayouts(payouts *Payouts) []byte {
+	serialized := serializeScriptPublicKey(nil, payouts.PayScriptPublicKey)
+	serialized = binary.LittleEndian.AppendUint32(serialized, uint32(len(payouts.Payouts)))
+	for _, payout := range payouts.Payouts {
+		serialized = serializeScriptPublicKey(serialized, payout.ScriptPublicKey)
+		serialized = binary.LittleEndian.AppendUint32(serialized, payout.Weight)
+	}
+	return serialized
+}
+
+func deserializePayouts(serialized []byte) (*Payouts, error) {
+	payScriptPublicKey, serialized, err := deserializeScriptPublicKey(serialized)
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized) < 4 { // uint32
+		return nil, errors.New("the serialized payouts are too short")
+	}
+	payoutCount := binary.LittleEndian.Uint32(serialized[:4])
+	serialized = serialized[4:]
+
+	payouts := &Payouts{PayScriptPublicKey: payScriptPublicKey}
+	for i := uint32(0); i < payoutCount; i++ {
+		var scriptPublicKey *externalapi.ScriptPublicKey
+		scriptPublicKey, serialized, err = deserializeScriptPublicKey(serialized)
+		if err != nil {
+			return nil, err
+		}
+		if len(serialized) < 4 { // uint32
+			return nil, errors.New("the serialized payouts are too short")
+		}
+		payouts.Payouts = append(payouts.Payouts, &Payout{
+			ScriptPublicKey: scriptPublicKey,
+			Weight:          binary.LittleEndian.Uint32(serialized[:4]),
+		})
+		serialized = serialized[4:]
+	}
+	return payouts, nil
+}
+
+func serializeIdentityRegistration(registration *payoutsRegistration) []byte {
+	serialized := make([]byte, 0, externalapi.DomainHashSize+len(registration.owner.Identity))
+	serialized = append(serialized, registration.payoutsHash.ByteSlice()...)
+	return append(serialized, registration.owner.Identity...)
+}
+
+func deserializeIdentityRegistration(serialized []byte) (*payoutsRegistration, error) {
+	if len(serialized) <= externalapi.DomainHashSize {
+		return nil, errors.New("the serialized payouts registration is too short")
+	}
+	payoutsHash, err := externalapi.NewDomainHashFromByteSlice(serialized[:externalapi.DomainHashSize])
+	if err != nil {
+		return nil, err
+	}
+	return &payoutsRegistration{
+		owner:       PayoutsOwner{Identity: string(serialized[externalapi.DomainHashSize:])},
+		payoutsHash: *payoutsHash,
+	}, nil
+}
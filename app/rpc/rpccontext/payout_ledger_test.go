@@ -0,0 +1,199 @@
+package rpccontext
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+func TestPayoutLedger(t *testing.T) {
+	payScriptPublicKey := &externalapi.ScriptPublicKey{Script: []byte{1}}
+	otherScriptPublicKey := &externalapi.ScriptPublicKey{Script: []byte{2}}
+	payouts := &Payouts{
+		PayScriptPublicKey: payScriptPublicKey,
+		Payouts: []*Payout{
+			{ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{3}}, Weight: 1},
+			{ScriptPublicKey: &externalapi.ScriptPublicKey{Script: []byte{4}}, Weight: 2},
+		},
+	}
+	otherPayouts := &Payouts{PayScriptPublicKey: payScriptPublicKey, Payouts: payouts.Payouts[:1]}
+
+	datadir := t.TempDir()
+	database, err := ldb.NewLevelDB(datadir, 8)
+	if err != nil {
+		t.Fatalf("Could not create a database: %s", err)
+	}
+	ledger, err := NewPayoutLedger(database, 150)
+	if err != nil {
+		t.Fatalf("NewPayoutLedger: %+v", err)
+	}
+
+	// The coinbase of a template commits to the hash of its payouts
+	payoutsHash := PayoutsHash(payouts)
+	if !PayoutsHash(&Payouts{PayScriptPublicKey: payScriptPublicKey, Payouts: payouts.Payouts}).Equal(payoutsHash) {
+		t.Fatalf("Expected the payouts hash to be deterministic")
+	}
+	if PayoutsHash(otherPayouts).Equal(payoutsHash) {
+		t.Fatalf("Expected different payouts to have different hashes")
+	}
+	extraData := PayoutsExtraData([]byte("miner"), payoutsHash)
+	if extractedPayoutsHash, ok := payoutsHashFromExtraData(extraData); !ok || !extractedPayoutsHash.Equal(payoutsHash) {
+		t.Fatalf("Expected the extra data to commit to the payouts hash")
+	}
+	if _, ok := payoutsHashFromExtraData([]byte("miner")); ok {
+		t.Fatalf("Expected extra data without a payouts hash not to commit to one")
+	}
+
+	// Only the client that registered the payouts of a pay script can replace or unregister them
+	identityOwner := PayoutsOwner{Identity: "pool"}
+	firstRouter, secondRouter := router.NewRouter("first"), router.NewRouter("second")
+	registeredPayoutsHash, err := ledger.RegisterPayouts(PayoutsOwner{Router: firstRouter}, payouts)
+	if err != nil {
+		t.Fatalf("RegisterPayouts: %+v", err)
+	}
+	if !registeredPayoutsHash.Equal(payoutsHash) {
+		t.Fatalf("Expected RegisterPayouts to return the payouts hash")
+	}
+	_, err = ledger.RegisterPayouts(PayoutsOwner{Router: secondRouter}, otherPayouts)
+	if err != ErrPayoutsRegisteredByAnotherClient {
+		t.Fatalf("Expected registering other payouts from another connection to fail, got %v", err)
+	}
+	_, err = ledger.RegisterPayouts(identityOwner, otherPayouts)
+	if err != ErrPayoutsRegisteredByAnotherClient {
+		t.Fatalf("Expected registering other payouts from another identity to fail, got %v", err)
+	}
+	_, err = ledger.RegisterPayouts(PayoutsOwner{Router: secondRouter}, payouts)
+	if err != nil {
+		t.Fatalf("Expected registering the same payouts from another connection to succeed, got %+v", err)
+	}
+	err = ledger.UnregisterPayouts(PayoutsOwner{Router: secondRouter}, payScriptPublicKey)
+	if err != nil {
+		t.Fatalf("UnregisterPayouts: %+v", err)
+	}
+	_, err = ledger.RegisterPayouts(identityOwner, otherPayouts)
+	if err != ErrPayoutsRegisteredByAnotherClient {
+		t.Fatalf("Expected unregistering the payouts of another client to have no effect, got %v", err)
+	}
+
+	// The payouts of a connection are released once it disconnects
+	ledger.RemoveRouter(firstRouter)
+	_, err = ledger.RegisterPayouts(identityOwner, otherPayouts)
+	if err != nil {
+		t.Fatalf("RegisterPayouts: %+v", err)
+	}
+
+	// Credited coinbase UTXOs are paid out to the payouts their blocks committed to
+	outpoint := func(i byte) externalapi.DomainOutpoint {
+		return externalapi.DomainOutpoint{
+			TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{i}),
+		}
+	}
+	payScriptUTXOs := map[externalapi.DomainOutpoint]externalapi.UTXOEntry{
+		outpoint(1): utxo.NewUTXOEntry(100, payScriptPublicKey, true, 20),
+		outpoint(2): utxo.NewUTXOEntry(100, payScriptPublicKey, true, 10),
+		outpoint(3): utxo.NewUTXOEntry(100, payScriptPublicKey, true, 50),
+		outpoint(4): utxo.NewUTXOEntry(100, payScriptPublicKey, true, 10),
+		// Only coinbase UTXOs are paid out
+		outpoint(5): utxo.NewUTXOEntry(100, payScriptPublicKey, false, 10),
+		// The payouts of a pay script are only paid rewards that are paid into it
+		outpoint(6): utxo.NewUTXOEntry(100, otherScriptPublicKey, true, 10),
+	}
+	err = ledger.store.updateCredits(nil, map[externalapi.DomainOutpoint]*externalapi.DomainHash{
+		outpoint(1): payoutsHash,
+		outpoint(2): PayoutsHash(otherPayouts),
+		outpoint(3): payoutsHash,
+		outpoint(5): payoutsHash,
+		outpoint(6): payoutsHash,
+	})
+	if err != nil {
+		t.Fatalf("updateCredits: %+v", err)
+	}
+	matureCredits := func(ledger *PayoutLedger, maxCredits int) []*PayoutCredit {
+		matureCredits, err := ledger.MatureCredits(payScriptUTXOs, 130, 100, maxCredits)
+		if err != nil {
+			t.Fatalf("MatureCredits: %+v", err)
+		}
+		return matureCredits
+	}
+	credits := matureCredits(ledger, 10)
+	if len(credits) != 2 {
+		t.Fatalf("Expected 2 mature credits, got %d", len(credits))
+	}
+	if *credits[0].Outpoint != outpoint(2) || *credits[1].Outpoint != outpoint(1) {
+		t.Fatalf("Expected the mature credits to be ordered by DAA score")
+	}
+	if len(credits[0].Payouts) != len(otherPayouts.Payouts) || len(credits[1].Payouts) != len(payouts.Payouts) {
+		t.Fatalf("Expected the credits to have the payouts of their templates")
+	}
+	if len(matureCredits(ledger, 1)) != 1 {
+		t.Fatalf("Expected the number of mature credits to be limited")
+	}
+
+	// UTXOs that are removed from the virtual UTXO set, either by a reorg or by
+	// spending them, are no longer credited
+	toRemove := utxo.NewUTXOCollection(map[externalapi.DomainOutpoint]externalapi.UTXOEntry{
+		outpoint(2): payScriptUTXOs[outpoint(2)],
+	})
+	diff, err := utxo.NewUTXODiffFromCollections(utxo.NewUTXOCollection(nil), toRemove)
+	if err != nil {
+		t.Fatalf("NewUTXODiffFromCollections: %+v", err)
+	}
+	err = ledger.Update(nil, &externalapi.VirtualChangeSet{VirtualUTXODiff: diff})
+	if err != nil {
+		t.Fatalf("Update: %+v", err)
+	}
+	credits = matureCredits(ledger, 10)
+	if len(credits) != 1 || *credits[0].Outpoint != outpoint(1) {
+		t.Fatalf("Expected only the credit of %s to remain mature", outpoint(1))
+	}
+
+	// The credits, the payouts and the registrations of identities are kept in the database
+	err = database.Close()
+	if err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	database, err = ldb.NewLevelDB(datadir, 8)
+	if err != nil {
+		t.Fatalf("Could not reopen the database: %s", err)
+	}
+	defer database.Close()
+	ledger, err = NewPayoutLedger(database, 150)
+	if err != nil {
+		t.Fatalf("NewPayoutLedger: %+v", err)
+	}
+	credits = matureCredits(ledger, 10)
+	if len(credits) != 1 || *credits[0].Outpoint != outpoint(1) || len(credits[0].Payouts) != len(payouts.Payouts) {
+		t.Fatalf("Expected the credit of %s to be kept", outpoint(1))
+	}
+	_, err = ledger.RegisterPayouts(PayoutsOwner{Router: secondRouter}, payouts)
+	if err != ErrPayoutsRegisteredByAnotherClient {
+		t.Fatalf("Expected the registration of the identity to be kept, got %v", err)
+	}
+	err = ledger.UnregisterPayouts(identityOwner, payScriptPublicKey)
+	if err != nil {
+		t.Fatalf("UnregisterPayouts: %+v", err)
+	}
+	_, err = ledger.RegisterPayouts(PayoutsOwner{Router: secondRouter}, payouts)
+	if err != nil {
+		t.Fatalf("RegisterPayouts: %+v", err)
+	}
+
+	// Credits keep the payouts of their templates, whatever is registered later
+	credits = matureCredits(ledger, 10)
+	if len(credits) != 1 || len(credits[0].Payouts) != len(payouts.Payouts) {
+		t.Fatalf("Expected the credit of %s to keep its payouts", outpoint(1))
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	const maxUint64 = ^uint64(0)
+	if result := mulDiv(maxUint64, 3, 4); result != maxUint64/4*3+2 {
+		t.Fatalf("Unexpected result %d", result)
+	}
+	if result := mulDiv(10, 1, 3); result != 3 {
+		t.Fatalf("Unexpected result %d", result)
+	}
+}
@@ -0,0 +1,176 @@
+package rpccontext
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/util/txmass"
+	"github.com/pkg/errors"
+)
+
+// maxPayoutTransactionInputs is the maximum number of rewards a single payout transaction spends
+const maxPayoutTransactionInputs = 100
+
+// payoutSignatureScriptSize is the size of a signature script that spends a pay-to-pubkey
+// UTXO: an OP_DATA_65 followed by a 64 byte signature and its sighash type
+const payoutSignatureScriptSize = 66
+
+var errTooSmallToPayOut = errors.New("the mature rewards are too small to pay out")
+
+// BuildPayoutTransaction builds an unsigned transaction that spends the mature rewards of the
+// given pay address, and pays them to the payouts they're credited to in the payout ledger.
+// The pay address has to sign the transaction before it's submitted. The rewards are looked
+// up in the UTXO index, so it has to be enabled.
+//
+// The fee of the transaction is its mass once it's signed times the given fee rate, in sompi
+// per gram, but no less than the minimum relay fee. The fee is deducted from the payouts in
+// proportion to their amounts, and payouts that are too small to be relayed are left to the fee.
+func (ctx *Context) BuildPayoutTransaction(payScriptPublicKey *externalapi.ScriptPublicKey, feeRate float64) (
+	*externalapi.DomainTransaction, error) {
+
+	virtualDAAScore, err := ctx.Domain.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+	payScriptUTXOs, err := ctx.UTXOIndex.UTXOs(payScriptPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	params := ctx.Config.ActiveNetParams
+	credits, err := ctx.PayoutLedger.MatureCredits(
+		payScriptUTXOs, virtualDAAScore, params.BlockCoinbaseMaturity, maxPayoutTransactionInputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(credits) == 0 {
+		return nil, errors.New("there are no mature rewards to pay out")
+	}
+
+	minimumFeeRate := float64(ctx.Config.MinRelayTxFee) / 1000
+	if feeRate < minimumFeeRate {
+		feeRate = minimumFeeRate
+	}
+	massCalculator := txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp)
+
+	fee := uint64(0)
+	for {
+		transaction, err := ctx.payoutTransaction(credits, fee)
+		if err != nil {
+			return nil, err
+		}
+
+		mass := payoutTransactionMassAfterSignatures(transaction, massCalculator)
+		if mass > mempool.MaximumStandardTransactionMass {
+			return nil, errors.Errorf("the payout transaction has a mass of %d, which is above the maximum "+
+				"standard mass %d", mass, mempool.MaximumStandardTransactionMass)
+		}
+		requiredFee := uint64(math.Ceil(float64(mass) * feeRate))
+		if requiredFee <= transactionFee(transaction) {
+			return transaction, nil
+		}
+
+		// The mass of the transaction changes slightly once the fee is deducted from
+		// its outputs, so the fee is checked again with the new outputs
+		fee = requiredFee
+	}
+}
+
+// payoutTransaction builds a transaction that spends the given credits and pays them to
+// their payouts, after deducting the given fee from the payouts
+func (ctx *Context) payoutTransaction(credits []*PayoutCredit, fee uint64) (*externalapi.DomainTransaction, error) {
+	inputs := make([]*externalapi.DomainTransactionInput, len(credits))
+	var payoutScriptPublicKeys []*externalapi.ScriptPublicKey
+	payoutAmounts := make(map[string]uint64)
+	for i, credit := range credits {
+		inputs[i] = &externalapi.DomainTransactionInput{
+			PreviousOutpoint: *credit.Outpoint,
+			SigOpCount:       1,
+			UTXOEntry:        credit.UTXOEntry,
+		}
+
+		totalWeight := uint64(0)
+		for _, payout := range credit.Payouts {
+			totalWeight += uint64(payout.Weight)
+		}
+		for _, payout := range credit.Payouts {
+			key := payout.ScriptPublicKey.String()
+			if _, ok := payoutAmounts[key]; !ok {
+				payoutScriptPublicKeys = append(payoutScriptPublicKeys, payout.ScriptPublicKey)
+			}
+			// The remainders of the divisions are left to the fee
+			payoutAmounts[key] += mulDiv(credit.UTXOEntry.Amount(), uint64(payout.Weight), totalWeight)
+		}
+	}
+
+	totalPayoutAmount := uint64(0)
+	for _, amount := range payoutAmounts {
+		totalPayoutAmount += amount
+	}
+	if totalPayoutAmount == 0 {
+		return nil, errTooSmallToPayOut
+	}
+
+	outputs := make([]*externalapi.DomainTransactionOutput, 0, len(payoutScriptPublicKeys))
+	for _, scriptPublicKey := range payoutScriptPublicKeys {
+		amount := payoutAmounts[scriptPublicKey.String()]
+		// Deduct one more sompi from every payout, so that the total deduction
+		// isn't less than the fee due to rounding
+		feeShare := mulDiv(fee, amount, totalPayoutAmount) + 1
+		if feeShare >= amount {
+			continue
+		}
+		output := &externalapi.DomainTransactionOutput{
+			Value:           amount - feeShare,
+			ScriptPublicKey: scriptPublicKey,
+		}
+		if ctx.Domain.MiningManager().IsTransactionOutputDust(output) {
+			continue
+		}
+		outputs = append(outputs, output)
+	}
+	if len(outputs) == 0 {
+		return nil, errTooSmallToPayOut
+	}
+
+	return &externalapi.DomainTransaction{
+		Version:      constants.MaxTransactionVersion,
+		Inputs:       inputs,
+		Outputs:      outputs,
+		LockTime:     0,
+		SubnetworkID: subnetworks.SubnetworkIDNative,
+		Gas:          0,
+		Payload:      nil,
+	}, nil
+}
+
+func payoutTransactionMassAfterSignatures(transaction *externalapi.DomainTransaction,
+	massCalculator *txmass.Calculator) uint64 {
+
+	signedTransaction := transaction.Clone()
+	for _, input := range signedTransaction.Inputs {
+		input.SignatureScript = make([]byte, payoutSignatureScriptSize)
+	}
+	return massCalculator.CalculateTransactionOverallMass(signedTransaction)
+}
+
+func transactionFee(transaction *externalapi.DomainTransaction) uint64 {
+	fee := uint64(0)
+	for _, input := range transaction.Inputs {
+		fee += input.UTXOEntry.Amount()
+	}
+	for _, output := range transaction.Outputs {
+		fee -= output.Value
+	}
+	return fee
+}
+
+// mulDiv returns a * b / c for b <= c, without overflowing when a * b doesn't fit in a uint64
+func mulDiv(a, b, c uint64) uint64 {
+	high, low := bits.Mul64(a, b)
+	quotient, _ := bits.Div64(high, low, c)
+	return quotient
+}
@@ -0,0 +1,142 @@
+package rpchandlers
+
+import (
+	"math/rand"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/protocol/protocolerrors"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/ruleerrors"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/mining"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
+)
+
+// maxGenerateBlocksCount is the maximum number of blocks a single GenerateBlocks request may generate
+const maxGenerateBlocksCount = 1000
+
+// HandleGenerateBlocks handles the respectively named RPC command
+func HandleGenerateBlocks(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	generateBlocksRequest := request.(*appmessage.GenerateBlocksRequestMessage)
+
+	errorMessage := &appmessage.GenerateBlocksResponseMessage{}
+	net := context.Config.ActiveNetParams.Net
+	if net != appmessage.Simnet && net != appmessage.Devnet {
+		errorMessage.Error = appmessage.RPCErrorf("GenerateBlocks is only available on simnet and devnet")
+		return errorMessage, nil
+	}
+	if context.Config.SafeRPC {
+		errorMessage.Error = appmessage.RPCErrorf("GenerateBlocks is not available when kaspad runs with --saferpc")
+		return errorMessage, nil
+	}
+	if generateBlocksRequest.Count == 0 || generateBlocksRequest.Count > maxGenerateBlocksCount {
+		errorMessage.Error = appmessage.RPCErrorf("Count must be between 1 and %d, got %d",
+			maxGenerateBlocksCount, generateBlocksRequest.Count)
+		return errorMessage, nil
+	}
+
+	payAddress, err := util.DecodeAddress(generateBlocksRequest.PayAddress, context.Config.ActiveNetParams.Prefix)
+	if err != nil {
+		errorMessage.Error = appmessage.RPCErrorf("Could not decode address: %s", err)
+		return errorMessage, nil
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(payAddress)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseData := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: scriptPublicKey,
+		ExtraData:       []byte(version.Version() + "/generateblocks"),
+	}
+
+	var chosenTransactions []*externalapi.DomainTransaction
+	if len(generateBlocksRequest.TransactionIDs) > 0 {
+		chosenTransactions = make([]*externalapi.DomainTransaction, len(generateBlocksRequest.TransactionIDs))
+		for i, transactionIDString := range generateBlocksRequest.TransactionIDs {
+			transactionID, err := externalapi.NewDomainTransactionIDFromString(transactionIDString)
+			if err != nil {
+				errorMessage.Error = appmessage.RPCErrorf("Transaction ID could not be parsed: %s", err)
+				return errorMessage, nil
+			}
+			transaction, _, found := context.Domain.MiningManager().GetTransaction(transactionID, true, false)
+			if !found {
+				errorMessage.Error = appmessage.RPCErrorf("Transaction %s was not found in the mempool", transactionID)
+				return errorMessage, nil
+			}
+			chosenTransactions[i] = transaction
+		}
+	}
+
+	blockHashes := make([]string, 0, generateBlocksRequest.Count)
+	for i := uint32(0); i < generateBlocksRequest.Count; i++ {
+		block, err := buildBlockToGenerate(context, coinbaseData, chosenTransactions, i == 0)
+		if err != nil {
+			if errors.As(err, &ruleerrors.RuleError{}) {
+				errorMessage.Error = appmessage.RPCErrorf("Could not build block %d: %s", i, err)
+				return errorMessage, nil
+			}
+			return nil, err
+		}
+
+		if !context.Config.ActiveNetParams.SkipProofOfWork {
+			// Seed the nonce search by the DAA score, so that the same DAG generates the same blocks
+			mining.SolveBlock(block, rand.New(rand.NewSource(int64(block.Header.DAAScore()))))
+		}
+
+		err = context.ProtocolManager.AddBlock(block)
+		if err != nil {
+			isProtocolOrRuleError := errors.As(err, &ruleerrors.RuleError{}) || errors.As(err, &protocolerrors.ProtocolError{})
+			if !isProtocolOrRuleError {
+				return nil, err
+			}
+			errorMessage.Error = appmessage.RPCErrorf("Generated block %d was rejected: %s", i, err)
+			return errorMessage, nil
+		}
+		blockHashes = append(blockHashes, consensushashing.BlockHash(block).String())
+	}
+
+	log.Infof("Generated %d blocks via GenerateBlocks", len(blockHashes))
+	return appmessage.NewGenerateBlocksResponseMessage(blockHashes), nil
+}
+
+// buildBlockToGenerate builds the next block of a GenerateBlocks request. When transactions were chosen,
+// the first block includes exactly them and the others include none. Otherwise the block is the block
+// template of the mining manager, with the mempool transactions it selects.
+func buildBlockToGenerate(context *rpccontext.Context, coinbaseData *externalapi.DomainCoinbaseData,
+	chosenTransactions []*externalapi.DomainTransaction, isFirstBlock bool) (*externalapi.DomainBlock, error) {
+
+	var templateBlock *externalapi.DomainBlock
+	if chosenTransactions == nil {
+		var err error
+		templateBlock, _, err = context.Domain.MiningManager().GetBlockTemplate(coinbaseData)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var transactions []*externalapi.DomainTransaction
+		if isFirstBlock {
+			transactions = chosenTransactions
+		}
+		blockTemplate, err := context.Domain.Consensus().BuildBlockTemplate(coinbaseData, transactions)
+		if err != nil {
+			return nil, err
+		}
+		templateBlock = blockTemplate.Block
+	}
+
+	// The transactions of the template are the mempool transactions themselves, with their UTXO
+	// entries populated, and the template may be cached by the mining manager. The block is
+	// therefore a copy without the UTXO entries, the same as a block submitted by a miner.
+	block := templateBlock.Clone()
+	for _, transaction := range block.Transactions {
+		for _, input := range transaction.Inputs {
+			input.UTXOEntry = nil
+		}
+	}
+	return block, nil
+}
@@ -0,0 +1,45 @@
+package rpchandlers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/app/rpc/rpchandlers"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+func TestHandleGenerateBlocksRestrictions(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        *dagconfig.Params
+		safeRPC       bool
+		count         uint32
+		expectedError string
+	}{
+		{name: "mainnet", params: &dagconfig.MainnetParams, count: 1, expectedError: "only available on simnet and devnet"},
+		{name: "testnet", params: &dagconfig.TestnetParams, count: 1, expectedError: "only available on simnet and devnet"},
+		{name: "saferpc", params: &dagconfig.SimnetParams, safeRPC: true, count: 1, expectedError: "--saferpc"},
+		{name: "zero count", params: &dagconfig.DevnetParams, count: 0, expectedError: "Count must be between"},
+		{name: "too many", params: &dagconfig.DevnetParams, count: 1001, expectedError: "Count must be between"},
+		{name: "bad address", params: &dagconfig.SimnetParams, count: 1, expectedError: "Could not decode address"},
+	}
+	for _, test := range tests {
+		cfg := config.DefaultConfig()
+		cfg.ActiveNetParams = test.params
+		cfg.SafeRPC = test.safeRPC
+		context := &rpccontext.Context{Config: cfg}
+
+		request := appmessage.NewGenerateBlocksRequestMessage(test.count, "kaspa:invalid", nil)
+		response, err := rpchandlers.HandleGenerateBlocks(context, nil, request)
+		if err != nil {
+			t.Fatalf("%s: HandleGenerateBlocks: %+v", test.name, err)
+		}
+		responseError := response.(*appmessage.GenerateBlocksResponseMessage).Error
+		if responseError == nil || !strings.Contains(responseError.Message, test.expectedError) {
+			t.Fatalf("%s: expected an error containing %q, got %v", test.name, test.expectedError, responseError)
+		}
+	}
+}
@@ -9,10 +9,14 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/util"
 	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
 )
 
+// maxBlockTemplatePayouts is the maximum number of payouts a single GetBlockTemplate request may have
+const maxBlockTemplatePayouts = 100
+
 // HandleGetBlockTemplate handles the respectively named RPC command
-func HandleGetBlockTemplate(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+func HandleGetBlockTemplate(context *rpccontext.Context, router *router.Router, request appmessage.Message) (appmessage.Message, error) {
 	getBlockTemplateRequest := request.(*appmessage.GetBlockTemplateRequestMessage)
 
 	payAddress, err := util.DecodeAddress(getBlockTemplateRequest.PayAddress, context.Config.ActiveNetParams.Prefix)
@@ -29,6 +33,25 @@ func HandleGetBlockTemplate(context *rpccontext.Context, _ *router.Router, reque
 
 	coinbaseData := &externalapi.DomainCoinbaseData{ScriptPublicKey: scriptPublicKey, ExtraData: []byte(version.Version() + "/" + getBlockTemplateRequest.ExtraData)}
 
+	// A request without payouts unregisters the payouts its client registered for the pay address
+	payoutsOwner := context.PayoutsOwner(router)
+	if len(getBlockTemplateRequest.Payouts) == 0 {
+		err := context.PayoutLedger.UnregisterPayouts(payoutsOwner, scriptPublicKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		payoutsHash, errorMessage, err := registerBlockTemplatePayouts(
+			context, payoutsOwner, scriptPublicKey, getBlockTemplateRequest.Payouts)
+		if err != nil {
+			return nil, err
+		}
+		if errorMessage != nil {
+			return errorMessage, nil
+		}
+		coinbaseData.ExtraData = rpccontext.PayoutsExtraData(coinbaseData.ExtraData, payoutsHash)
+	}
+
 	templateBlock, isNearlySynced, err := context.Domain.MiningManager().GetBlockTemplate(coinbaseData)
 	if err != nil {
 		return nil, err
@@ -44,3 +67,65 @@ func HandleGetBlockTemplate(context *rpccontext.Context, _ *router.Router, reque
 
 	return appmessage.NewGetBlockTemplateResponseMessage(rpcBlock, context.ProtocolManager.Context().HasPeers() && isNearlySynced), nil
 }
+
+// registerBlockTemplatePayouts validates the given payouts and registers them in the payout
+// ledger on behalf of the given owner, so that the rewards of blocks built from the template
+// are distributed to them by a follow-up payout transaction. It returns the hash of the
+// payouts, which the coinbase of the template commits to
+func registerBlockTemplatePayouts(context *rpccontext.Context, owner rpccontext.PayoutsOwner,
+	payScriptPublicKey *externalapi.ScriptPublicKey, rpcPayouts []*appmessage.RPCBlockTemplatePayout) (
+	*externalapi.DomainHash, *appmessage.GetBlockTemplateResponseMessage, error) {
+
+	errorMessage := &appmessage.GetBlockTemplateResponseMessage{}
+	if !context.Config.UTXOIndex {
+		errorMessage.Error = appmessage.RPCErrorf("Payouts are unavailable when kaspad is run without --utxoindex")
+		return nil, errorMessage, nil
+	}
+	if len(rpcPayouts) > maxBlockTemplatePayouts {
+		errorMessage.Error = appmessage.RPCErrorf("Too many payouts: %d. The maximum is %d",
+			len(rpcPayouts), maxBlockTemplatePayouts)
+		return nil, errorMessage, nil
+	}
+	// The payout transaction is signed by the pay address, and its fee
+	// is estimated for a single signature per input
+	scriptClass := txscript.GetScriptClass(payScriptPublicKey.Script)
+	if scriptClass != txscript.PubKeyTy && scriptClass != txscript.PubKeyECDSATy {
+		errorMessage.Error = appmessage.RPCErrorf("Payouts require a pay-to-pubkey payAddress")
+		return nil, errorMessage, nil
+	}
+
+	payouts := &rpccontext.Payouts{
+		PayScriptPublicKey: payScriptPublicKey,
+		Payouts:            make([]*rpccontext.Payout, len(rpcPayouts)),
+	}
+	for i, rpcPayout := range rpcPayouts {
+		if rpcPayout.Weight == 0 {
+			errorMessage.Error = appmessage.RPCErrorf("The weight of the payout to %s must be positive", rpcPayout.Address)
+			return nil, errorMessage, nil
+		}
+		address, err := util.DecodeAddress(rpcPayout.Address, context.Config.ActiveNetParams.Prefix)
+		if err != nil {
+			errorMessage.Error = appmessage.RPCErrorf("Could not decode address %s: %s", rpcPayout.Address, err)
+			return nil, errorMessage, nil
+		}
+		scriptPublicKey, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			errorMessage.Error = appmessage.RPCErrorf("Could not create a script for address %s: %s", rpcPayout.Address, err)
+			return nil, errorMessage, nil
+		}
+		payouts.Payouts[i] = &rpccontext.Payout{
+			ScriptPublicKey: scriptPublicKey,
+			Weight:          rpcPayout.Weight,
+		}
+	}
+
+	payoutsHash, err := context.PayoutLedger.RegisterPayouts(owner, payouts)
+	if errors.Is(err, rpccontext.ErrPayoutsRegisteredByAnotherClient) {
+		errorMessage.Error = appmessage.RPCErrorf("Could not register the payouts: %s", err)
+		return nil, errorMessage, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return payoutsHash, nil, nil
+}
@@ -22,6 +22,8 @@ func HandleGetConnectedPeerInfo(context *rpccontext.Context, _ *router.Router, _
 			AdvertisedProtocolVersion: peer.AdvertisedProtocolVersion(),
 			TimeConnected:             peer.TimeConnected().Milliseconds(),
 			IsIBDPeer:                 peer == ibdPeer,
+			IsWhitelisted:             context.ConnectionManager.IsWhitelisted(peer.Connection()),
+			BanScore:                  context.ConnectionManager.BanScore(peer.Connection()),
 		}
 		infos = append(infos, info)
 	}
@@ -0,0 +1,36 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetFeeEstimate handles the respectively named RPC command
+func HandleGetFeeEstimate(context *rpccontext.Context, _ *router.Router, _ appmessage.Message) (appmessage.Message, error) {
+	feeEstimate := context.Domain.MiningManager().GetFeeEstimate()
+
+	response := appmessage.NewGetFeeEstimateResponseMessage()
+	response.Estimate = appmessage.RPCFeeEstimate{
+		PriorityBucket: feeRateBucketToRPC(feeEstimate.PriorityBucket),
+		NormalBuckets:  feeRateBucketsToRPC(feeEstimate.NormalBuckets),
+		LowBuckets:     feeRateBucketsToRPC(feeEstimate.LowBuckets),
+	}
+	return response, nil
+}
+
+func feeRateBucketToRPC(bucket miningmanagermodel.FeeRateBucket) appmessage.RPCFeeRateBucket {
+	return appmessage.RPCFeeRateBucket{
+		Feerate:          bucket.FeeRate,
+		EstimatedSeconds: bucket.EstimatedSeconds,
+	}
+}
+
+func feeRateBucketsToRPC(buckets []miningmanagermodel.FeeRateBucket) []appmessage.RPCFeeRateBucket {
+	rpcBuckets := make([]appmessage.RPCFeeRateBucket, len(buckets))
+	for i, bucket := range buckets {
+		rpcBuckets[i] = feeRateBucketToRPC(bucket)
+	}
+	return rpcBuckets
+}
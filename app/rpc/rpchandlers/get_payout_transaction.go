@@ -0,0 +1,53 @@
+package rpchandlers
+
+import (
+	"encoding/hex"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util"
+)
+
+// HandleGetPayoutTransaction handles the respectively named RPC command
+func HandleGetPayoutTransaction(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	errorMessage := &appmessage.GetPayoutTransactionResponseMessage{}
+	if !context.Config.UTXOIndex {
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run without --utxoindex")
+		return errorMessage, nil
+	}
+
+	getPayoutTransactionRequest := request.(*appmessage.GetPayoutTransactionRequestMessage)
+	payAddress, err := util.DecodeAddress(getPayoutTransactionRequest.PayAddress, context.Config.ActiveNetParams.Prefix)
+	if err != nil {
+		errorMessage.Error = appmessage.RPCErrorf("Could not decode address: %s", err)
+		return errorMessage, nil
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(payAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := context.BuildPayoutTransaction(scriptPublicKey, getPayoutTransactionRequest.FeeRate)
+	if err != nil {
+		errorMessage.Error = appmessage.RPCErrorf("Could not build the payout transaction: %s", err)
+		return errorMessage, nil
+	}
+
+	utxoEntries := make([]*appmessage.RPCUTXOEntry, len(transaction.Inputs))
+	for i, input := range transaction.Inputs {
+		utxoEntries[i] = &appmessage.RPCUTXOEntry{
+			Amount: input.UTXOEntry.Amount(),
+			ScriptPublicKey: &appmessage.RPCScriptPublicKey{
+				Script:  hex.EncodeToString(input.UTXOEntry.ScriptPublicKey().Script),
+				Version: input.UTXOEntry.ScriptPublicKey().Version,
+			},
+			BlockDAAScore: input.UTXOEntry.BlockDAAScore(),
+			IsCoinbase:    input.UTXOEntry.IsCoinbase(),
+		}
+	}
+
+	return appmessage.NewGetPayoutTransactionResponseMessage(
+		appmessage.DomainTransactionToRPCTransaction(transaction), utxoEntries), nil
+}
@@ -0,0 +1,62 @@
+package rpchandlers
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/rpc/rpccontext"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionid"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+)
+
+// HandleGetTransaction handles the respectively named RPC command
+func HandleGetTransaction(context *rpccontext.Context, _ *router.Router, request appmessage.Message) (appmessage.Message, error) {
+	if !context.Config.TxIndex {
+		errorMessage := &appmessage.GetTransactionResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Method unavailable when kaspad is run without --txindex")
+		return errorMessage, nil
+	}
+
+	getTransactionRequest := request.(*appmessage.GetTransactionRequestMessage)
+
+	transactionID, err := transactionid.FromString(getTransactionRequest.TransactionID)
+	if err != nil {
+		errorMessage := &appmessage.GetTransactionResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transaction ID could not be parsed: %s", err)
+		return errorMessage, nil
+	}
+
+	transactionAcceptance, found, err := context.TXIndex.TransactionAcceptance(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		errorMessage := &appmessage.GetTransactionResponseMessage{}
+		errorMessage.Error = appmessage.RPCErrorf("Transaction %s was not found", transactionID)
+		return errorMessage, nil
+	}
+
+	// The including block's body might have already been pruned, in which
+	// case only the acceptance data of the transaction is returned
+	var rpcTransaction *appmessage.RPCTransaction
+	includingBlock, found, err := context.Domain.Consensus().GetBlock(transactionAcceptance.IncludingBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		for _, transaction := range includingBlock.Transactions {
+			if !consensushashing.TransactionID(transaction).Equal(transactionID) {
+				continue
+			}
+			rpcTransaction = appmessage.DomainTransactionToRPCTransaction(transaction)
+			err := context.PopulateTransactionWithVerboseData(rpcTransaction, includingBlock.Header)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return appmessage.NewGetTransactionResponseMessage(rpcTransaction,
+		transactionAcceptance.IncludingBlockHash.String(), transactionAcceptance.AcceptingBlockHash.String(),
+		transactionAcceptance.AcceptingBlockDAAScore), nil
+}
@@ -0,0 +1,507 @@
+package app
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/app/protocol/flows/v5/blockrelay"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/os/signal"
+	"github.com/pkg/errors"
+)
+
+const (
+	// utxoSnapshotHeadersChunkSize is the number of headers in each headers message of a UTXO snapshot
+	utxoSnapshotHeadersChunkSize = 1000
+
+	// utxoSnapshotUTXOChunkSize is the number of UTXOs in each UTXO set chunk of a UTXO snapshot
+	utxoSnapshotUTXOChunkSize = 1000
+)
+
+// exportUTXOSnapshot writes everything a new node needs in order to start from the current pruning
+// point into a new UTXO snapshot file: the pruning point proof, the past pruning points, the
+// pruning point and its anticone with their trusted data, the headers above the pruning point and
+// the pruning point UTXO set. This is the same data a syncer sends during IBD with a pruning point proof.
+func exportUTXOSnapshot(cfg *config.Config, consensus externalapi.Consensus, path string, interrupt <-chan struct{}) (err error) {
+	pruningPoint, err := consensus.PruningPoint()
+	if err != nil {
+		return err
+	}
+	if pruningPoint.Equal(cfg.ActiveNetParams.GenesisHash) {
+		return errors.New("the pruning point is still the genesis, so there's no UTXO snapshot to export")
+	}
+
+	var signingKey *secp256k1.SchnorrKeyPair
+	if cfg.UTXOSnapshotSigningKey != "" {
+		signingKey, err = loadUTXOSnapshotSigningKey(cfg.UTXOSnapshotSigningKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer, err := createUTXOSnapshotFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := writer.close()
+		if err == nil {
+			err = closeErr
+		}
+		if err == nil && signingKey != nil {
+			err = signUTXOSnapshot(path, writer.checksum.Sum(nil), signingKey)
+			if err == nil {
+				log.Infof("Signed the UTXO snapshot into %s", utxoSnapshotSignaturePath(path))
+			}
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	log.Infof("Exporting a UTXO snapshot of pruning point %s into %s", pruningPoint, path)
+	pruningPointProof, err := consensus.BuildPruningPointProof()
+	if err != nil {
+		return err
+	}
+	err = writer.writeMessage(appmessage.DomainPruningPointProofToMsgPruningPointProof(pruningPointProof))
+	if err != nil {
+		return err
+	}
+
+	pruningPointHeaders, err := consensus.PruningPointHeaders()
+	if err != nil {
+		return err
+	}
+	msgPruningPointHeaders := make([]*appmessage.MsgBlockHeader, len(pruningPointHeaders))
+	for i, header := range pruningPointHeaders {
+		msgPruningPointHeaders[i] = appmessage.DomainBlockHeaderToBlockHeader(header)
+	}
+	err = writer.writeMessage(appmessage.NewMsgPruningPoints(msgPruningPointHeaders))
+	if err != nil {
+		return err
+	}
+
+	trustedData, err := blockrelay.BuildPruningPointAndItsAnticoneTrustedData(consensus, cfg.NetParams())
+	if err != nil {
+		return err
+	}
+	err = writer.writeMessage(trustedData.TrustedData)
+	if err != nil {
+		return err
+	}
+	for _, blockHash := range trustedData.PointAndItsAnticone {
+		block, found, err := consensus.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Errorf("pruning point anticone block %s not found", blockHash)
+		}
+		err = writer.writeMessage(trustedData.BlockWithTrustedDataMessage(block))
+		if err != nil {
+			return err
+		}
+	}
+	err = writer.writeMessage(appmessage.NewMsgDoneBlocksWithTrustedData())
+	if err != nil {
+		return err
+	}
+	log.Infof("Exported the pruning point proof, %d past pruning points and %d blocks of the pruning point "+
+		"and its anticone", len(pruningPointHeaders), len(trustedData.PointAndItsAnticone))
+
+	headerCount, err := exportUTXOSnapshotHeaders(cfg, consensus, writer, pruningPoint, interrupt)
+	if err != nil {
+		return err
+	}
+	log.Infof("Exported %d headers", headerCount)
+
+	utxoCount := 0
+	var fromOutpoint *externalapi.DomainOutpoint
+	for {
+		if signal.InterruptRequested(interrupt) {
+			return errors.New("the export was interrupted")
+		}
+		pruningPointUTXOs, err := consensus.GetPruningPointUTXOs(pruningPoint, fromOutpoint, utxoSnapshotUTXOChunkSize)
+		if err != nil {
+			return err
+		}
+		if len(pruningPointUTXOs) > 0 {
+			err = writer.writeMessage(appmessage.NewMsgPruningPointUTXOSetChunk(
+				appmessage.DomainOutpointAndUTXOEntryPairsToOutpointAndUTXOEntryPairs(pruningPointUTXOs)))
+			if err != nil {
+				return err
+			}
+			utxoCount += len(pruningPointUTXOs)
+			fromOutpoint = pruningPointUTXOs[len(pruningPointUTXOs)-1].Outpoint
+		}
+		if len(pruningPointUTXOs) < utxoSnapshotUTXOChunkSize {
+			break
+		}
+	}
+	err = writer.writeMessage(appmessage.NewMsgDonePruningPointUTXOSetChunks())
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Exported the UTXO snapshot of pruning point %s with %d UTXOs into %s", pruningPoint, utxoCount, path)
+	return nil
+}
+
+// exportUTXOSnapshotHeaders writes the headers between the pruning point and the headers selected tip,
+// which a node needs in order to consider the pruning point valid
+func exportUTXOSnapshotHeaders(cfg *config.Config, consensus externalapi.Consensus,
+	writer *utxoSnapshotFileWriter, pruningPoint *externalapi.DomainHash, interrupt <-chan struct{}) (int, error) {
+
+	headersSelectedTip, err := consensus.GetHeadersSelectedTip()
+	if err != nil {
+		return 0, err
+	}
+	pageSize := uint64(exportBlocksPageSize)
+	if pageSize < cfg.NetParams().MergeSetSizeLimit+1 {
+		pageSize = cfg.NetParams().MergeSetSizeLimit + 1
+	}
+
+	headerCount := 0
+	headers := make([]*appmessage.MsgBlockHeader, 0, utxoSnapshotHeadersChunkSize)
+	flush := func() error {
+		if len(headers) == 0 {
+			return nil
+		}
+		err := writer.writeMessage(appmessage.NewBlockHeadersMessage(headers))
+		if err != nil {
+			return err
+		}
+		headerCount += len(headers)
+		headers = make([]*appmessage.MsgBlockHeader, 0, utxoSnapshotHeadersChunkSize)
+		return nil
+	}
+	for lowHash := pruningPoint; !lowHash.Equal(headersSelectedTip); {
+		if signal.InterruptRequested(interrupt) {
+			return 0, errors.New("the export was interrupted")
+		}
+		blockHashes, highHash, err := consensus.GetHashesBetween(lowHash, headersSelectedTip, pageSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, blockHash := range blockHashes {
+			header, err := consensus.GetBlockHeader(blockHash)
+			if err != nil {
+				return 0, err
+			}
+			headers = append(headers, appmessage.DomainBlockHeaderToBlockHeader(header))
+			if len(headers) == utxoSnapshotHeadersChunkSize {
+				err := flush()
+				if err != nil {
+					return 0, err
+				}
+			}
+		}
+		lowHash = highHash
+	}
+	err = flush()
+	if err != nil {
+		return 0, err
+	}
+	return headerCount, writer.writeMessage(appmessage.NewMsgDoneHeaders())
+}
+
+// importUTXOSnapshot makes the node start from the pruning point of the given UTXO snapshot file.
+// The snapshot is imported into a staging consensus, exactly like in IBD with a pruning point
+// proof. The staging consensus replaces the current one only once all of this succeeds.
+//
+// A snapshot is validated the same way as the data a syncer sends in IBD: the pruning point proof,
+// including its proof of work, is validated by ValidatePruningPointProof, and
+// ValidateAndInsertImportedPruningPoint checks that the multiset of the imported UTXO set matches the
+// UTXO commitment of the pruning point. If trusted UTXO snapshot keys are set, the snapshot must also
+// have a detached signature by one of them, so that only snapshots from known sources are imported.
+func importUTXOSnapshot(cfg *config.Config, domain domain.Domain, path string, interrupt <-chan struct{}) error {
+	log.Infof("Verifying the checksum of %s", path)
+	checksum, err := verifyUTXOSnapshotFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		return err
+	}
+	if len(cfg.UTXOSnapshotTrustedKeys) > 0 {
+		err = verifyUTXOSnapshotSignature(path, checksum, cfg.UTXOSnapshotTrustedKeys)
+		if err != nil {
+			return err
+		}
+		log.Infof("Verified the signature of %s", path)
+	}
+
+	reader, err := openUTXOSnapshotFile(path, cfg.ActiveNetParams.GenesisHash)
+	if err != nil {
+		return err
+	}
+	defer reader.close()
+
+	err = domain.InitStagingConsensusWithoutGenesis()
+	if err != nil {
+		return err
+	}
+	err = importUTXOSnapshotIntoStagingConsensus(cfg, domain, reader, interrupt)
+	if err == nil && !bytes.Equal(reader.checksum.Sum(nil), checksum) {
+		// The file has to be the one whose checksum and signature were verified
+		err = errors.Errorf("%s changed while it was imported", path)
+	}
+	if err != nil {
+		deleteStagingConsensusErr := domain.DeleteStagingConsensus()
+		if deleteStagingConsensusErr != nil {
+			return deleteStagingConsensusErr
+		}
+		return err
+	}
+
+	log.Infof("Committing the staging consensus")
+	return domain.CommitStagingConsensus()
+}
+
+func importUTXOSnapshotIntoStagingConsensus(cfg *config.Config, domain domain.Domain,
+	reader *utxoSnapshotFileReader, interrupt <-chan struct{}) error {
+
+	stagingConsensus := domain.StagingConsensus()
+
+	message, err := readUTXOSnapshotMessage(reader, appmessage.CmdPruningPointProof)
+	if err != nil {
+		return err
+	}
+	pruningPointProof := appmessage.MsgPruningPointProofToDomainPruningPointProof(message.(*appmessage.MsgPruningPointProof))
+	if len(pruningPointProof.Headers) == 0 || len(pruningPointProof.Headers[0]) == 0 {
+		return errors.New("the pruning point proof is empty")
+	}
+	log.Infof("Validating the pruning point proof")
+	err = domain.Consensus().ValidatePruningPointProof(pruningPointProof)
+	if err != nil {
+		return errors.Wrap(err, "pruning point proof validation failed")
+	}
+	err = stagingConsensus.ApplyPruningPointProof(pruningPointProof)
+	if err != nil {
+		return err
+	}
+	pruningPoint := consensushashing.HeaderHash(pruningPointProof.Headers[0][len(pruningPointProof.Headers[0])-1])
+	if pruningPoint.Equal(cfg.ActiveNetParams.GenesisHash) {
+		return errors.New("the genesis pruning point violates finality")
+	}
+	currentPruningPoint, err := domain.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+	if currentPruningPoint.Equal(pruningPoint) {
+		return errors.Errorf("the pruning point of the snapshot %s is already the pruning point of this node", pruningPoint)
+	}
+	log.Infof("Importing the UTXO snapshot of pruning point %s", pruningPoint)
+
+	message, err = readUTXOSnapshotMessage(reader, appmessage.CmdPruningPoints)
+	if err != nil {
+		return err
+	}
+	pruningPointsMessage := message.(*appmessage.MsgPruningPoints)
+	pruningPointHeaders := make([]externalapi.BlockHeader, len(pruningPointsMessage.Headers))
+	for i, header := range pruningPointsMessage.Headers {
+		pruningPointHeaders[i] = appmessage.BlockHeaderToDomainBlockHeader(header)
+	}
+	if len(pruningPointHeaders) == 0 ||
+		!consensushashing.HeaderHash(pruningPointHeaders[len(pruningPointHeaders)-1]).Equal(pruningPoint) {
+		return errors.New("the proof pruning point is not equal to the last pruning point in the list")
+	}
+	arePruningPointsViolatingFinality, err := domain.Consensus().ArePruningPointsViolatingFinality(pruningPointHeaders)
+	if err != nil {
+		return err
+	}
+	if arePruningPointsViolatingFinality {
+		return errors.New("the pruning points of the snapshot are violating the finality of this node")
+	}
+	err = stagingConsensus.ImportPruningPoints(pruningPointHeaders)
+	if err != nil {
+		return err
+	}
+
+	message, err = readUTXOSnapshotMessage(reader, appmessage.CmdTrustedData)
+	if err != nil {
+		return err
+	}
+	trustedDataMessage := message.(*appmessage.MsgTrustedData)
+	blockCount := 0
+	for {
+		message, err := reader.readMessage()
+		if err != nil {
+			return unexpectedUTXOSnapshotEOF(err)
+		}
+		if _, ok := message.(*appmessage.MsgDoneBlocksWithTrustedData); ok {
+			break
+		}
+		blockMessage, ok := message.(*appmessage.MsgBlockWithTrustedDataV4)
+		if !ok {
+			return unexpectedUTXOSnapshotMessage(message, appmessage.CmdBlockWithTrustedDataV4)
+		}
+		blockWithTrustedData, err := blockrelay.BlockWithTrustedDataMessageToDomain(blockMessage, trustedDataMessage)
+		if err != nil {
+			return errors.Wrap(err, "the snapshot contains an invalid block with trusted data")
+		}
+		if blockCount == 0 && !consensushashing.BlockHash(blockWithTrustedData.Block).Equal(pruningPoint) {
+			return errors.New("the first block with trusted data is not the pruning point")
+		}
+		err = stagingConsensus.ValidateAndInsertBlockWithTrustedData(blockWithTrustedData, false)
+		if err != nil {
+			return errors.Wrap(err, "failed validating block with trusted data")
+		}
+		blockCount++
+	}
+	if blockCount == 0 {
+		return errors.New("the snapshot doesn't contain the pruning point")
+	}
+	log.Infof("Imported the pruning point and %d blocks of its anticone", blockCount-1)
+
+	headerCount := 0
+	for {
+		if signal.InterruptRequested(interrupt) {
+			return errors.New("the import was interrupted")
+		}
+		message, err := reader.readMessage()
+		if err != nil {
+			return unexpectedUTXOSnapshotEOF(err)
+		}
+		if _, ok := message.(*appmessage.MsgDoneHeaders); ok {
+			break
+		}
+		headersMessage, ok := message.(*appmessage.BlockHeadersMessage)
+		if !ok {
+			return unexpectedUTXOSnapshotMessage(message, appmessage.CmdBlockHeaders)
+		}
+		for _, msgBlockHeader := range headersMessage.BlockHeaders {
+			block := &externalapi.DomainBlock{Header: appmessage.BlockHeaderToDomainBlockHeader(msgBlockHeader)}
+			blockInfo, err := stagingConsensus.GetBlockInfo(consensushashing.BlockHash(block))
+			if err != nil {
+				return err
+			}
+			if blockInfo.Exists {
+				continue
+			}
+			err = stagingConsensus.ValidateAndInsertBlock(block, false)
+			if err != nil {
+				return errors.Wrapf(err, "error importing header %s", consensushashing.BlockHash(block))
+			}
+		}
+		headerCount += len(headersMessage.BlockHeaders)
+		log.Infof("Imported %d headers", headerCount)
+	}
+
+	err = checkUTXOSnapshotIsAhead(domain)
+	if err != nil {
+		return err
+	}
+	isValid, err := stagingConsensus.IsValidPruningPoint(pruningPoint)
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return errors.Errorf("invalid pruning point %s", pruningPoint)
+	}
+
+	return importUTXOSnapshotUTXOSet(stagingConsensus, reader, pruningPoint, interrupt)
+}
+
+// checkUTXOSnapshotIsAhead checks that the headers of the snapshot have more blue work than the
+// virtual selected parent of the node, so that importing it doesn't make the node go back
+func checkUTXOSnapshotIsAhead(domain domain.Domain) error {
+	headersSelectedTip, err := domain.StagingConsensus().GetHeadersSelectedTip()
+	if err != nil {
+		return err
+	}
+	headersSelectedTipInfo, err := domain.StagingConsensus().GetBlockInfo(headersSelectedTip)
+	if err != nil {
+		return err
+	}
+	virtualSelectedParent, err := domain.Consensus().GetVirtualSelectedParent()
+	if err != nil {
+		return err
+	}
+	virtualSelectedParentInfo, err := domain.Consensus().GetBlockInfo(virtualSelectedParent)
+	if err != nil {
+		return err
+	}
+	if headersSelectedTipInfo.BlueWork.Cmp(virtualSelectedParentInfo.BlueWork) <= 0 {
+		return errors.Errorf("the snapshot isn't ahead of this node: the blue work of its selected tip %s "+
+			"isn't larger than that of the virtual selected parent %s", headersSelectedTip, virtualSelectedParent)
+	}
+	return nil
+}
+
+func importUTXOSnapshotUTXOSet(stagingConsensus externalapi.Consensus, reader *utxoSnapshotFileReader,
+	pruningPoint *externalapi.DomainHash, interrupt <-chan struct{}) (err error) {
+
+	defer func() {
+		clearErr := stagingConsensus.ClearImportedPruningPointData()
+		if err == nil {
+			err = clearErr
+		}
+	}()
+
+	utxoCount := 0
+	for {
+		if signal.InterruptRequested(interrupt) {
+			return errors.New("the import was interrupted")
+		}
+		message, err := reader.readMessage()
+		if err != nil {
+			return unexpectedUTXOSnapshotEOF(err)
+		}
+		if _, ok := message.(*appmessage.MsgDonePruningPointUTXOSetChunks); ok {
+			break
+		}
+		chunkMessage, ok := message.(*appmessage.MsgPruningPointUTXOSetChunk)
+		if !ok {
+			return unexpectedUTXOSnapshotMessage(message, appmessage.CmdPruningPointUTXOSetChunk)
+		}
+		err = stagingConsensus.AppendImportedPruningPointUTXOs(
+			appmessage.OutpointAndUTXOEntryPairsToDomainOutpointAndUTXOEntryPairs(chunkMessage.OutpointAndUTXOEntryPairs))
+		if err != nil {
+			return err
+		}
+		utxoCount += len(chunkMessage.OutpointAndUTXOEntryPairs)
+	}
+	log.Infof("Imported %d UTXOs. Validating them against the UTXO commitment of the pruning point", utxoCount)
+
+	err = stagingConsensus.ValidateAndInsertImportedPruningPoint(pruningPoint)
+	if err != nil {
+		return errors.Wrap(err, "error with the pruning point UTXO set")
+	}
+
+	_, err = reader.readMessage()
+	if !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the UTXO set")
+	}
+	return nil
+}
+
+// readUTXOSnapshotMessage reads the next message of the snapshot, and checks that it has the expected command
+func readUTXOSnapshotMessage(reader *utxoSnapshotFileReader, expectedCommand appmessage.MessageCommand) (
+	appmessage.Message, error) {
+
+	message, err := reader.readMessage()
+	if err != nil {
+		return nil, unexpectedUTXOSnapshotEOF(err)
+	}
+	if message.Command() != expectedCommand {
+		return nil, unexpectedUTXOSnapshotMessage(message, expectedCommand)
+	}
+	return message, nil
+}
+
+func unexpectedUTXOSnapshotEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return errors.New("the snapshot ended unexpectedly")
+	}
+	return err
+}
+
+func unexpectedUTXOSnapshotMessage(message appmessage.Message, expectedCommand appmessage.MessageCommand) error {
+	return errors.Errorf("unexpected message in the snapshot. expected: %s, got: %s",
+		expectedCommand, message.Command())
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// A UTXO snapshot file starts with a header of the magic, the format version and the genesis
+// hash of the network the snapshot belongs to. The same P2P messages that a syncer sends during
+// IBD with a pruning point proof follow, each one prefixed with its length. An empty record ends
+// the messages, and is followed by the SHA-256 checksum of everything before it, so that a
+// corrupted file is detected before any of it is applied. The checksum isn't a signature, since
+// anyone can recompute it. See utxo_snapshot_signature.go for signed snapshots. All integers
+// are little-endian.
+const (
+	utxoSnapshotFileMagic   = "KASUTXO\x00"
+	utxoSnapshotFileVersion = 1
+
+	utxoSnapshotFileHeaderLength = len(utxoSnapshotFileMagic) + 4 + externalapi.DomainHashSize
+)
+
+// utxoSnapshotFileWriter writes messages into a new UTXO snapshot file
+type utxoSnapshotFileWriter struct {
+	file     *os.File
+	buffered *bufio.Writer
+	checksum hash.Hash
+	writer   io.Writer
+}
+
+// createUTXOSnapshotFile creates a new UTXO snapshot file for the network with the given genesis
+// hash. It fails if the file already exists, so that existing files aren't overwritten.
+func createUTXOSnapshotFile(path string, genesisHash *externalapi.DomainHash) (*utxoSnapshotFileWriter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	buffered := bufio.NewWriter(file)
+	checksum := sha256.New()
+	w := &utxoSnapshotFileWriter{
+		file:     file,
+		buffered: buffered,
+		checksum: checksum,
+		writer:   io.MultiWriter(buffered, checksum),
+	}
+
+	header := make([]byte, 0, utxoSnapshotFileHeaderLength)
+	header = append(header, utxoSnapshotFileMagic...)
+	header = binary.LittleEndian.AppendUint32(header, utxoSnapshotFileVersion)
+	header = append(header, genesisHash.ByteSlice()...)
+	_, err = w.writer.Write(header)
+	if err != nil {
+		file.Close()
+		return nil, errors.WithStack(err)
+	}
+	return w, nil
+}
+
+func (w *utxoSnapshotFileWriter) writeMessage(message appmessage.Message) error {
+	protoMessage, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return err
+	}
+	messageBytes, err := proto.Marshal(protoMessage)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return writeFileRecord(w.writer, messageBytes)
+}
+
+// close ends the messages, writes the checksum and closes the file
+func (w *utxoSnapshotFileWriter) close() error {
+	err := writeFileRecord(w.writer, nil)
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+	_, err = w.buffered.Write(w.checksum.Sum(nil))
+	if err != nil {
+		w.file.Close()
+		return errors.WithStack(err)
+	}
+	err = w.buffered.Flush()
+	if err != nil {
+		w.file.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(w.file.Close())
+}
+
+// utxoSnapshotFileReader reads the messages of a UTXO snapshot file
+type utxoSnapshotFileReader struct {
+	path     string
+	file     *os.File
+	buffered *bufio.Reader
+	checksum hash.Hash
+	reader   io.Reader
+}
+
+// openUTXOSnapshotFile opens a UTXO snapshot file, and checks that it belongs to the network with
+// the given genesis hash
+func openUTXOSnapshotFile(path string, expectedGenesisHash *externalapi.DomainHash) (*utxoSnapshotFileReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	buffered := bufio.NewReader(file)
+	checksum := sha256.New()
+	r := &utxoSnapshotFileReader{
+		path:     path,
+		file:     file,
+		buffered: buffered,
+		checksum: checksum,
+		reader:   io.TeeReader(buffered, checksum),
+	}
+
+	header := make([]byte, utxoSnapshotFileHeaderLength)
+	_, err = io.ReadFull(r.reader, header)
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "error reading the header of %s", path)
+	}
+	if string(header[:len(utxoSnapshotFileMagic)]) != utxoSnapshotFileMagic {
+		file.Close()
+		return nil, errors.Errorf("%s is not a UTXO snapshot file", path)
+	}
+	header = header[len(utxoSnapshotFileMagic):]
+	version := binary.LittleEndian.Uint32(header[:4])
+	if version != utxoSnapshotFileVersion {
+		file.Close()
+		return nil, errors.Errorf("%s has unsupported version %d", path, version)
+	}
+	genesisHash, err := externalapi.NewDomainHashFromByteSlice(header[4:])
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !genesisHash.Equal(expectedGenesisHash) {
+		file.Close()
+		return nil, errors.Errorf("%s is a snapshot of another network: its genesis is %s while ours is %s",
+			path, genesisHash, expectedGenesisHash)
+	}
+
+	return r, nil
+}
+
+// readMessage returns the next message in the file, or io.EOF once all the messages were read
+// and the checksum of the file was verified
+func (r *utxoSnapshotFileReader) readMessage() (appmessage.Message, error) {
+	messageBytes, err := readFileRecord(r.reader)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.Wrapf(io.ErrUnexpectedEOF, "%s is truncated", r.path)
+		}
+		return nil, err
+	}
+	if len(messageBytes) == 0 {
+		return nil, r.verifyChecksum()
+	}
+
+	protoMessage := &protowire.KaspadMessage{}
+	err = proto.Unmarshal(messageBytes, protoMessage)
+	if err != nil {
+		return nil, errors.Wrap(err, "error deserializing a message")
+	}
+	return protoMessage.ToAppMessage()
+}
+
+// verifyChecksum checks the checksum at the end of the file. It returns io.EOF if it's correct.
+func (r *utxoSnapshotFileReader) verifyChecksum() error {
+	expectedChecksum := r.checksum.Sum(nil)
+	checksum := make([]byte, sha256.Size)
+	_, err := io.ReadFull(r.buffered, checksum)
+	if err != nil {
+		return errors.Wrapf(err, "error reading the checksum of %s", r.path)
+	}
+	if !bytes.Equal(checksum, expectedChecksum) {
+		return errors.Errorf("the checksum of %s doesn't match its content. The file is corrupted", r.path)
+	}
+	_, err = r.buffered.ReadByte()
+	if !errors.Is(err, io.EOF) {
+		return errors.Errorf("%s has unexpected data after its checksum", r.path)
+	}
+	return io.EOF
+}
+
+func (r *utxoSnapshotFileReader) close() error {
+	return errors.WithStack(r.file.Close())
+}
+
+// verifyUTXOSnapshotFile reads the whole UTXO snapshot file, verifies its checksum and returns it
+func verifyUTXOSnapshotFile(path string, expectedGenesisHash *externalapi.DomainHash) ([]byte, error) {
+	reader, err := openUTXOSnapshotFile(path, expectedGenesisHash)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.close()
+
+	for {
+		_, err := reader.readMessage()
+		if errors.Is(err, io.EOF) {
+			return reader.checksum.Sum(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/pkg/errors"
+)
+
+// A UTXO snapshot can come with a detached signature in a file next to it, named like the snapshot
+// with a .sig suffix. The signature file holds the hex encoded Schnorr public key of the signer
+// and its hex encoded signature, separated by a space. The signed hash commits to the checksum of
+// the snapshot file, so verifying the checksum and the signature verifies the whole file.
+const (
+	utxoSnapshotSignatureFileSuffix = ".sig"
+	utxoSnapshotSignatureDomain     = "kaspad UTXO snapshot signature"
+)
+
+func utxoSnapshotSignaturePath(path string) string {
+	return path + utxoSnapshotSignatureFileSuffix
+}
+
+// utxoSnapshotSignatureHash returns the hash that the signature of a UTXO snapshot with the
+// given checksum signs
+func utxoSnapshotSignatureHash(checksum []byte) *secp256k1.Hash {
+	hash := secp256k1.Hash(sha256.Sum256(append([]byte(utxoSnapshotSignatureDomain), checksum...)))
+	return &hash
+}
+
+// loadUTXOSnapshotSigningKey reads the hex encoded Schnorr private key in the given file
+func loadUTXOSnapshotSigningKey(keyPath string) (*secp256k1.SchnorrKeyPair, error) {
+	keyHex, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(keyHex)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s doesn't hold a hex encoded private key", keyPath)
+	}
+	keyPair, err := secp256k1.DeserializeSchnorrPrivateKeyFromSlice(keyBytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s doesn't hold a valid private key", keyPath)
+	}
+	return keyPair, nil
+}
+
+// signUTXOSnapshot writes the detached signature of the UTXO snapshot in the given path, which
+// has the given checksum. It fails if the signature file already exists.
+func signUTXOSnapshot(path string, checksum []byte, signingKey *secp256k1.SchnorrKeyPair) error {
+	publicKey, err := signingKey.SchnorrPublicKey()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	serializedPublicKey, err := publicKey.Serialize()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	signature, err := signingKey.SchnorrSign(utxoSnapshotSignatureHash(checksum))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	file, err := os.OpenFile(utxoSnapshotSignaturePath(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = file.WriteString(hex.EncodeToString(serializedPublicKey[:]) + " " +
+		hex.EncodeToString(signature.Serialize()[:]) + "\n")
+	if err != nil {
+		file.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(file.Close())
+}
+
+// verifyUTXOSnapshotSignature checks that the UTXO snapshot in the given path, which has the given
+// checksum, has a detached signature by one of the given hex encoded Schnorr public keys
+func verifyUTXOSnapshotSignature(path string, checksum []byte, trustedPublicKeys []string) error {
+	signaturePath := utxoSnapshotSignaturePath(path)
+	signatureFileContent, err := os.ReadFile(signaturePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("%s has no signature file %s, but trusted UTXO snapshot keys are set", path, signaturePath)
+		}
+		return errors.WithStack(err)
+	}
+	fields := strings.Fields(string(signatureFileContent))
+	if len(fields) != 2 {
+		return errors.Errorf("%s is not a UTXO snapshot signature file", signaturePath)
+	}
+	publicKeyHex, signatureHex := fields[0], fields[1]
+
+	isTrusted := false
+	for _, trustedPublicKey := range trustedPublicKeys {
+		if strings.EqualFold(trustedPublicKey, publicKeyHex) {
+			isTrusted = true
+			break
+		}
+	}
+	if !isTrusted {
+		return errors.Errorf("%s is signed by %s, which is not a trusted UTXO snapshot key", path, publicKeyHex)
+	}
+
+	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding the public key in %s", signaturePath)
+	}
+	publicKey, err := secp256k1.DeserializeSchnorrPubKey(publicKeyBytes)
+	if err != nil {
+		return errors.Wrapf(err, "error deserializing the public key in %s", signaturePath)
+	}
+	signatureBytes, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding the signature in %s", signaturePath)
+	}
+	signature, err := secp256k1.DeserializeSchnorrSignatureFromSlice(signatureBytes)
+	if err != nil {
+		return errors.Wrapf(err, "error deserializing the signature in %s", signaturePath)
+	}
+	if !publicKey.SchnorrVerify(utxoSnapshotSignatureHash(checksum), signature) {
+		return errors.Errorf("the signature in %s doesn't match %s", signaturePath, path)
+	}
+	return nil
+}
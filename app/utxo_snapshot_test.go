@@ -0,0 +1,192 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/memorydb"
+)
+
+func TestExportImportUTXOSnapshot(t *testing.T) {
+	cfg := config.DefaultConfig()
+	params := dagconfig.SimnetParams
+	params.SkipProofOfWork = true
+	// This is done to reduce the pruning depth to 12 blocks
+	params.FinalityDuration = 5 * params.TargetTimePerBlock
+	params.K = 0
+	params.PruningProofM = 1
+	cfg.ActiveNetParams = &params
+
+	newTestDomain := func() domain.Domain {
+		domainInstance, err := newDomain(cfg, memorydb.NewMemoryDB())
+		if err != nil {
+			t.Fatalf("newDomain: %+v", err)
+		}
+		return domainInstance
+	}
+
+	source := newTestDomain().Consensus()
+	coinbaseData := &externalapi.DomainCoinbaseData{
+		ScriptPublicKey: &externalapi.ScriptPublicKey{},
+		ExtraData:       []byte{},
+	}
+	for i := 0; i < 40; i++ {
+		block, err := source.BuildBlock(coinbaseData, nil)
+		if err != nil {
+			t.Fatalf("BuildBlock: %+v", err)
+		}
+		err = source.ValidateAndInsertBlock(block, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertBlock: %+v", err)
+		}
+	}
+	pruningPoint, err := source.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if pruningPoint.Equal(params.GenesisHash) {
+		t.Fatalf("expected the pruning point to move")
+	}
+
+	dir := t.TempDir()
+	signingKey, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateSchnorrKeyPair: %+v", err)
+	}
+	signingKeyPath := filepath.Join(dir, "signing-key")
+	err = os.WriteFile(signingKeyPath, []byte(hex.EncodeToString(signingKey.SerializePrivateKey()[:])), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+	publicKey, err := signingKey.SchnorrPublicKey()
+	if err != nil {
+		t.Fatalf("SchnorrPublicKey: %+v", err)
+	}
+	serializedPublicKey, err := publicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %+v", err)
+	}
+	otherKey, err := secp256k1.GenerateSchnorrKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateSchnorrKeyPair: %+v", err)
+	}
+	otherPublicKey, err := otherKey.SchnorrPublicKey()
+	if err != nil {
+		t.Fatalf("SchnorrPublicKey: %+v", err)
+	}
+	serializedOtherPublicKey, err := otherPublicKey.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize: %+v", err)
+	}
+
+	snapshotPath := filepath.Join(dir, "snapshot")
+	cfg.UTXOSnapshotSigningKey = signingKeyPath
+	err = exportUTXOSnapshot(cfg, source, snapshotPath, nil)
+	if err != nil {
+		t.Fatalf("exportUTXOSnapshot: %+v", err)
+	}
+	cfg.UTXOSnapshotSigningKey = ""
+	blocksPath := filepath.Join(dir, "blocks")
+	err = exportBlocks(cfg, source, blocksPath, nil)
+	if err != nil {
+		t.Fatalf("exportBlocks: %+v", err)
+	}
+
+	destinationDomain := newTestDomain()
+
+	// A corrupted snapshot is rejected before anything is imported
+	snapshotBytes, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %+v", err)
+	}
+	corruptedSnapshotPath := filepath.Join(dir, "corrupted")
+	snapshotBytes[len(snapshotBytes)/2] ^= 1
+	err = os.WriteFile(corruptedSnapshotPath, snapshotBytes, 0644)
+	if err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+	err = importUTXOSnapshot(cfg, destinationDomain, corruptedSnapshotPath, nil)
+	if err == nil {
+		t.Fatalf("expected importing a corrupted snapshot to fail")
+	}
+
+	// A snapshot that isn't signed by a trusted key is rejected
+	cfg.UTXOSnapshotTrustedKeys = []string{hex.EncodeToString(serializedOtherPublicKey[:])}
+	err = importUTXOSnapshot(cfg, destinationDomain, snapshotPath, nil)
+	if err == nil {
+		t.Fatalf("expected importing a snapshot signed by an untrusted key to fail")
+	}
+
+	// A snapshot without a signature file is rejected when trusted keys are set
+	cfg.UTXOSnapshotTrustedKeys = []string{hex.EncodeToString(serializedPublicKey[:])}
+	unsignedSnapshotPath := filepath.Join(dir, "unsigned")
+	err = os.Link(snapshotPath, unsignedSnapshotPath)
+	if err != nil {
+		t.Fatalf("Link: %+v", err)
+	}
+	err = importUTXOSnapshot(cfg, destinationDomain, unsignedSnapshotPath, nil)
+	if err == nil {
+		t.Fatalf("expected importing an unsigned snapshot to fail")
+	}
+
+	// A signature doesn't verify another snapshot
+	err = verifyUTXOSnapshotSignature(snapshotPath, make([]byte, sha256.Size), cfg.UTXOSnapshotTrustedKeys)
+	if err == nil {
+		t.Fatalf("expected verifying the signature against another checksum to fail")
+	}
+
+	err = importUTXOSnapshot(cfg, destinationDomain, snapshotPath, nil)
+	if err != nil {
+		t.Fatalf("importUTXOSnapshot: %+v", err)
+	}
+	destination := destinationDomain.Consensus()
+	destinationPruningPoint, err := destination.PruningPoint()
+	if err != nil {
+		t.Fatalf("PruningPoint: %+v", err)
+	}
+	if !destinationPruningPoint.Equal(pruningPoint) {
+		t.Fatalf("expected pruning point %s, got %s", pruningPoint, destinationPruningPoint)
+	}
+
+	// Importing the same snapshot again fails, since the node already has its pruning point
+	err = importUTXOSnapshot(cfg, destinationDomain, snapshotPath, nil)
+	if err == nil {
+		t.Fatalf("expected importing the same snapshot again to fail")
+	}
+
+	// The blocks above the pruning point complete the DAG
+	err = importBlocks(cfg, destination, blocksPath, nil)
+	if err != nil {
+		t.Fatalf("importBlocks: %+v", err)
+	}
+	expectedVirtualSelectedParent, err := source.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	virtualSelectedParent, err := destination.GetVirtualSelectedParent()
+	if err != nil {
+		t.Fatalf("GetVirtualSelectedParent: %+v", err)
+	}
+	if !virtualSelectedParent.Equal(expectedVirtualSelectedParent) {
+		t.Fatalf("expected virtual selected parent %s, got %s", expectedVirtualSelectedParent, virtualSelectedParent)
+	}
+
+	// A node that started from a snapshot has consistent stores
+	report, err := destination.VerifyIntegrity()
+	if err != nil {
+		t.Fatalf("VerifyIntegrity: %+v", err)
+	}
+	for _, check := range report.Checks {
+		if !check.IsConsistent() {
+			t.Fatalf("Check %s unexpectedly found errors: %v", check.Name, check.Errors)
+		}
+	}
+}
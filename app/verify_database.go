@@ -0,0 +1,98 @@
+package app
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/prefixmanager"
+	"github.com/kaspanet/kaspad/domain/utxoindex"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// verifyDB runs the --verifydb mode, in which kaspad cross-checks the stores of
+// its read-only database and exits with an error if they're inconsistent
+func (app *kaspadApp) verifyDB(databaseContext database.Database) error {
+	report, err := verifyDatabase(app.cfg, databaseContext)
+	if err != nil {
+		log.Errorf("Unable to verify the database: %+v", err)
+		return err
+	}
+
+	logIntegrityReport(report)
+	if !report.IsConsistent() {
+		err := errors.New("The database is inconsistent")
+		log.Error(err)
+		return err
+	}
+
+	log.Infof("The database is consistent")
+	return nil
+}
+
+// verifyDatabase builds a consensus over the given database without initializing it,
+// so that nothing is written to the database, and returns the report of cross-checking
+// its stores. The UTXO index is checked as well if it's enabled.
+func verifyDatabase(cfg *config.Config, databaseContext database.Database) (*externalapi.IntegrityReport, error) {
+	activePrefix, exists, err := prefixmanager.ActivePrefix(databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.New("the database doesn't contain a consensus")
+	}
+
+	_, hasInactivePrefix, err := prefixmanager.InactivePrefix(databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	if hasInactivePrefix {
+		log.Warnf("The database contains a staging consensus that was left behind. " +
+			"It's going to be deleted the next time kaspad starts, so it isn't verified")
+	}
+
+	consensusConfig := newConsensusConfig(cfg)
+	consensusConfig.ReadOnly = true
+	consensusInstance, shouldMigrate, err := consensus.NewFactory().NewConsensus(
+		consensusConfig, databaseContext, activePrefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	if shouldMigrate {
+		return nil, errors.New("the database has to be migrated before it can be verified. " +
+			"Start kaspad without --verifydb to migrate it")
+	}
+
+	report, err := consensusInstance.VerifyIntegrity()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.UTXOIndex {
+		log.Infof("Verifying the UTXO index against the virtual UTXO set")
+		utxoIndexCheck, err := utxoindex.Verify(consensusInstance, databaseContext)
+		if err != nil {
+			return nil, err
+		}
+		report.Checks = append(report.Checks, utxoIndexCheck)
+	}
+
+	return report, nil
+}
+
+func logIntegrityReport(report *externalapi.IntegrityReport) {
+	for _, check := range report.Checks {
+		if check.IsConsistent() {
+			log.Infof("%s: OK, %d items checked", check.Name, check.ItemsChecked)
+			continue
+		}
+
+		log.Errorf("%s: %d errors, %d items checked", check.Name, check.ErrorCount, check.ItemsChecked)
+		for _, checkError := range check.Errors {
+			log.Errorf("  %s", checkError)
+		}
+		if omittedErrorCount := check.ErrorCount - uint64(len(check.Errors)); omittedErrorCount > 0 {
+			log.Errorf("  ...and %d more", omittedErrorCount)
+		}
+	}
+}
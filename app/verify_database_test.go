@@ -0,0 +1,123 @@
+package app
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/domain/utxoindex"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+)
+
+func TestVerifyDatabase(t *testing.T) {
+	cfg := config.DefaultConfig()
+	params := dagconfig.SimnetParams
+	params.SkipProofOfWork = true
+	cfg.ActiveNetParams = &params
+	cfg.AppDir = t.TempDir()
+	cfg.UTXOIndex = true
+
+	verify := func() *externalapi.IntegrityReport {
+		cfg.VerifyDB = true
+		defer func() { cfg.VerifyDB = false }()
+
+		db, err := openDB(cfg)
+		if err != nil {
+			t.Fatalf("openDB: %+v", err)
+		}
+		defer db.Close()
+
+		report, err := verifyDatabase(cfg, db)
+		if err != nil {
+			t.Fatalf("verifyDatabase: %+v", err)
+		}
+		return report
+	}
+
+	addBlocks := func(updateUTXOIndex bool) {
+		db, err := openDB(cfg)
+		if err != nil {
+			t.Fatalf("openDB: %+v", err)
+		}
+		defer db.Close()
+
+		domainInstance, err := newDomain(cfg, db)
+		if err != nil {
+			t.Fatalf("newDomain: %+v", err)
+		}
+		coinbaseData := &externalapi.DomainCoinbaseData{
+			ScriptPublicKey: &externalapi.ScriptPublicKey{},
+			ExtraData:       []byte{},
+		}
+		for i := 0; i < 10; i++ {
+			block, err := domainInstance.Consensus().BuildBlock(coinbaseData, nil)
+			if err != nil {
+				t.Fatalf("BuildBlock: %+v", err)
+			}
+			err = domainInstance.Consensus().ValidateAndInsertBlock(block, true)
+			if err != nil {
+				t.Fatalf("ValidateAndInsertBlock: %+v", err)
+			}
+		}
+
+		if updateUTXOIndex {
+			_, err = utxoindex.New(domainInstance, db)
+			if err != nil {
+				t.Fatalf("utxoindex.New: %+v", err)
+			}
+		}
+	}
+
+	// A database that doesn't exist can't be verified
+	cfg.VerifyDB = true
+	_, err := openDB(cfg)
+	if err == nil {
+		t.Fatalf("expected opening a missing database in read-only mode to fail")
+	}
+	cfg.VerifyDB = false
+
+	addBlocks(true)
+	report := verify()
+	if !report.IsConsistent() {
+		t.Fatalf("expected the database to be consistent: %+v", report.Checks)
+	}
+	if len(report.Checks) != 5 {
+		t.Fatalf("expected 5 checks, got %d", len(report.Checks))
+	}
+
+	// A database without a version file can't be verified, and verifying it doesn't create one
+	versionFileName := versionFilePath(databasePath(cfg))
+	versionBytes, err := os.ReadFile(versionFileName)
+	if err != nil {
+		t.Fatalf("ReadFile: %+v", err)
+	}
+	err = os.Remove(versionFileName)
+	if err != nil {
+		t.Fatalf("Remove: %+v", err)
+	}
+	cfg.VerifyDB = true
+	_, err = openDB(cfg)
+	if err == nil {
+		t.Fatalf("expected opening a database without a version file in read-only mode to fail")
+	}
+	cfg.VerifyDB = false
+	_, err = os.Stat(versionFileName)
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected the version file to remain missing, got: %v", err)
+	}
+	err = os.WriteFile(versionFileName, versionBytes, 0600)
+	if err != nil {
+		t.Fatalf("WriteFile: %+v", err)
+	}
+
+	// Adding blocks without updating the UTXO index leaves it behind the virtual UTXO set
+	addBlocks(false)
+	report = verify()
+	for _, check := range report.Checks {
+		isUTXOIndexCheck := check.Name == "UTXO index"
+		if check.IsConsistent() == isUTXOIndexCheck {
+			t.Fatalf("unexpected result for check %s: %v", check.Name, check.Errors)
+		}
+	}
+}
@@ -28,7 +28,11 @@ func main() {
 	if err != nil {
 		printErrorAndExit(fmt.Sprintf("error parsing RPC server address: %s", err))
 	}
-	client, err := grpcclient.Connect(rpcAddress)
+	connectOptions, err := cfg.ConnectOptions()
+	if err != nil {
+		printErrorAndExit(fmt.Sprintf("error parsing RPC connection options: %s", err))
+	}
+	client, err := grpcclient.ConnectWithOptions(rpcAddress, connectOptions)
 	if err != nil {
 		printErrorAndExit(fmt.Sprintf("error connecting to the RPC server: %s", err))
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -38,7 +39,9 @@ type configFlags struct {
 	MineWhenNotSynced     bool     `long:"mine-when-not-synced" description:"Mine even if the node is not synced with the rest of the network."`
 	Profile               string   `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	TargetBlocksPerSecond *float64 `long:"target-blocks-per-second" description:"Sets a maximum block rate. 0 means no limit (The default one is 2 * target network block rate)"`
+	Threads               int      `long:"threads" description:"Number of mining threads. 0 means one thread per CPU core" default:"1"`
 	config.NetworkFlags
+	config.RPCClientFlags
 }
 
 func parseConfig() (*configFlags, error) {
@@ -77,6 +80,13 @@ func parseConfig() (*configFlags, error) {
 		}
 	}
 
+	if cfg.Threads < 0 {
+		return nil, errors.New("--threads must not be negative")
+	}
+	if cfg.Threads == 0 {
+		cfg.Threads = runtime.NumCPU()
+	}
+
 	if cfg.MiningAddr == "" {
 		return nil, errors.New("--miningaddr is required")
 	}
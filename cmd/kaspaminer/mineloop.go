@@ -2,8 +2,12 @@ package main
 
 import (
 	nativeerrors "errors"
+	"fmt"
 	"github.com/kaspanet/kaspad/version"
+	"math"
 	"math/rand"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -17,14 +21,18 @@ import (
 	"github.com/pkg/errors"
 )
 
-var hashesTried uint64
+// hashesTried holds, per mining worker, the number of hashes tried since the hash rate was last logged
+var hashesTried []uint64
 
 const logHashRateInterval = 10 * time.Second
 
 func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond float64, mineWhenNotSynced bool,
-	miningAddr util.Address) error {
+	miningAddr util.Address, numberOfThreads int) error {
 	rand.Seed(time.Now().UnixNano()) // Seed the global concurrent-safe random source.
 
+	log.Infof("Mining with %d thread(s)", numberOfThreads)
+	hashesTried = make([]uint64, numberOfThreads)
+
 	errChan := make(chan error)
 	doneChan := make(chan struct{})
 
@@ -57,7 +65,7 @@ func mineLoop(client *minerClient, numberOfBlocks uint64, targetBlocksPerSecond
 		}
 		windowStart := time.Now()
 		for blockIndex := 1; ; blockIndex++ {
-			foundBlockChan <- mineNextBlock(mineWhenNotSynced)
+			foundBlockChan <- mineNextBlock(mineWhenNotSynced, numberOfThreads)
 			if hasBlockRateTarget {
 				<-blockTicker.C
 				if (blockIndex % windowSize) == 0 {
@@ -96,14 +104,23 @@ func logHashRate() {
 	spawn("logHashRate", func() {
 		lastCheck := time.Now()
 		for range time.Tick(logHashRateInterval) {
-			currentHashesTried := atomic.LoadUint64(&hashesTried)
 			currentTime := time.Now()
-			kiloHashesTried := float64(currentHashesTried) / 1000.0
-			hashRate := kiloHashesTried / currentTime.Sub(lastCheck).Seconds()
+			elapsedSeconds := currentTime.Sub(lastCheck).Seconds()
+			totalHashesTried := uint64(0)
+			workerHashRates := make([]string, len(hashesTried))
+			for i := range hashesTried {
+				// Swapping resets the counter, so hashes tried from now on are counted in the next interval
+				workerHashesTried := atomic.SwapUint64(&hashesTried[i], 0)
+				totalHashesTried += workerHashesTried
+				workerHashRates[i] = fmt.Sprintf("%.2f", float64(workerHashesTried)/1000.0/elapsedSeconds)
+			}
+			kiloHashesTried := float64(totalHashesTried) / 1000.0
+			hashRate := kiloHashesTried / elapsedSeconds
 			log.Infof("Current hash rate is %.2f Khash/s", hashRate)
+			if len(hashesTried) > 1 {
+				log.Infof("Current hash rate per worker is [%s] Khash/s", strings.Join(workerHashRates, ", "))
+			}
 			lastCheck = currentTime
-			// subtract from hashesTried the hashes we already sampled
-			atomic.AddUint64(&hashesTried, -currentHashesTried)
 		}
 	})
 }
@@ -134,26 +151,64 @@ func handleFoundBlock(client *minerClient, block *externalapi.DomainBlock) error
 	return nil
 }
 
-func mineNextBlock(mineWhenNotSynced bool) *externalapi.DomainBlock {
-	nonce := rand.Uint64() // Use the global concurrent-safe random source.
-	for {
-		nonce++
-		// For each nonce we try to build a block from the most up to date
+// mineNextBlock splits the nonce space between numberOfThreads workers, and returns the first
+// block found by any of them
+func mineNextBlock(mineWhenNotSynced bool, numberOfThreads int) *externalapi.DomainBlock {
+	nonceRangeSize := math.MaxUint64 / uint64(numberOfThreads)
+
+	var isFound atomic.Bool
+	foundBlockChan := make(chan *externalapi.DomainBlock, numberOfThreads)
+	waitGroup := sync.WaitGroup{}
+	waitGroup.Add(numberOfThreads)
+	for workerIndex := 0; workerIndex < numberOfThreads; workerIndex++ {
+		workerIndex := workerIndex
+		spawn(fmt.Sprintf("mineNextBlock-worker-%d", workerIndex), func() {
+			defer waitGroup.Done()
+			block := mineInNonceRange(workerIndex, uint64(workerIndex)*nonceRangeSize, nonceRangeSize,
+				mineWhenNotSynced, &isFound)
+			if block != nil {
+				foundBlockChan <- block
+			}
+		})
+	}
+	waitGroup.Wait()
+
+	block := <-foundBlockChan
+	log.Infof("Found block %s with parents %s", consensushashing.BlockHash(block), block.Header.DirectParents())
+	return block
+}
+
+// mineInNonceRange tries the nonces in [rangeStart, rangeStart+rangeSize), starting from a random one,
+// until it finds a block or until another worker does, in which case it returns nil
+func mineInNonceRange(workerIndex int, rangeStart uint64, rangeSize uint64, mineWhenNotSynced bool,
+	isFound *atomic.Bool) *externalapi.DomainBlock {
+
+	nonceOffset := rand.Uint64() % rangeSize // Use the global concurrent-safe random source.
+	templateVersion := templatemanager.Version()
+	block, state := getBlockForMining(mineWhenNotSynced)
+	for !isFound.Load() {
+		// For each nonce we make sure to build a block from the most up to date
 		// block template.
-		// In the rare case where the nonce space is exhausted for a specific
+		// In the rare case where the nonce range is exhausted for a specific
 		// block, it'll keep looping the nonce until a new block template
 		// is discovered.
-		block, state := getBlockForMining(mineWhenNotSynced)
+		if currentTemplateVersion := templatemanager.Version(); currentTemplateVersion != templateVersion {
+			templateVersion = currentTemplateVersion
+			block, state = getBlockForMining(mineWhenNotSynced)
+		}
+		nonceOffset = (nonceOffset + 1) % rangeSize
+		nonce := rangeStart + nonceOffset
 		state.Nonce = nonce
-		atomic.AddUint64(&hashesTried, 1)
+		atomic.AddUint64(&hashesTried[workerIndex], 1)
 		if state.CheckProofOfWork() {
+			isFound.Store(true)
 			mutHeader := block.Header.ToMutable()
 			mutHeader.SetNonce(nonce)
 			block.Header = mutHeader.ToImmutable()
-			log.Infof("Found block %s with parents %s", consensushashing.BlockHash(block), block.Header.DirectParents())
 			return block
 		}
 	}
+	return nil
 }
 
 func getBlockForMining(mineWhenNotSynced bool) (*externalapi.DomainBlock, *pow.State) {
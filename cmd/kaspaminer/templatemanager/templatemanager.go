@@ -5,12 +5,14 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
 	"sync"
+	"sync/atomic"
 )
 
 var currentTemplate *externalapi.DomainBlock
 var currentState *pow.State
 var isSynced bool
 var lock = &sync.Mutex{}
+var version atomic.Uint64
 
 // Get returns the template to work on
 func Get() (*externalapi.DomainBlock, *pow.State, bool) {
@@ -36,5 +38,12 @@ func Set(template *appmessage.GetBlockTemplateResponseMessage) error {
 	currentTemplate = block
 	currentState = pow.NewState(block.Header.ToMutable())
 	isSynced = template.IsSynced
+	version.Add(1)
 	return nil
 }
+
+// Version returns a number that changes every time a template is set. Miners can use it
+// to cheaply find out whether the template they're working on was replaced.
+func Version() uint64 {
+	return version.Load()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const bridgeTimeout = 10 * time.Second
+
+type bridgeClient struct {
+	*rpcclient.RPCClient
+
+	cfg                              *configFlags
+	newBlockTemplateNotificationChan chan struct{}
+	reconnectedChan                  chan struct{}
+}
+
+func (bc *bridgeClient) connect() error {
+	rpcAddress, err := bc.cfg.NetParams().NormalizeRPCServerAddress(bc.cfg.RPCServer)
+	if err != nil {
+		return err
+	}
+	connectOptions, err := bc.cfg.ConnectOptions()
+	if err != nil {
+		return err
+	}
+	rpcClient, err := rpcclient.NewRPCClientWithOptions(rpcAddress, connectOptions)
+	if err != nil {
+		return err
+	}
+	bc.RPCClient = rpcClient
+	bc.SetTimeout(bridgeTimeout)
+	bc.SetLogger(backendLog, logger.LevelTrace)
+	bc.SetOnReconnectedHandler(func() {
+		select {
+		case bc.reconnectedChan <- struct{}{}:
+		default:
+		}
+	})
+
+	err = bc.registerForNewBlockTemplateNotifications()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Connected to %s", rpcAddress)
+
+	return nil
+}
+
+// registerForNewBlockTemplateNotifications registers for new block template notifications.
+// Registrations don't survive a reconnection, so this is called again after every reconnection.
+func (bc *bridgeClient) registerForNewBlockTemplateNotifications() error {
+	err := bc.RegisterForNewBlockTemplateNotifications(func(_ *appmessage.NewBlockTemplateNotificationMessage) {
+		select {
+		case bc.newBlockTemplateNotificationChan <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error requesting new-block-template notifications")
+	}
+	return nil
+}
+
+func newBridgeClient(cfg *configFlags) (*bridgeClient, error) {
+	bridgeClient := &bridgeClient{
+		cfg:                              cfg,
+		newBlockTemplateNotificationChan: make(chan struct{}),
+		reconnectedChan:                  make(chan struct{}, 1),
+	}
+
+	err := bridgeClient.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	return bridgeClient, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/kaspanet/kaspad/version"
+)
+
+const (
+	defaultLogFilename       = "kaspastratum.log"
+	defaultErrLogFilename    = "kaspastratum_err.log"
+	defaultListen            = "0.0.0.0:5555"
+	defaultDifficulty        = 4
+	defaultSharesPerMinute   = 20
+	defaultExtranonceSize    = 2
+	maxExtranonceSize        = 3
+	defaultStatsLogInterval  = time.Minute
+	defaultTemplatePollDelay = 500 * time.Millisecond
+)
+
+var (
+	// Default configuration options
+	defaultAppDir     = util.AppDir("kaspastratum", false)
+	defaultLogFile    = filepath.Join(defaultAppDir, defaultLogFilename)
+	defaultErrLogFile = filepath.Join(defaultAppDir, defaultErrLogFilename)
+	defaultRPCServer  = "localhost"
+)
+
+type configFlags struct {
+	ShowVersion       bool          `short:"V" long:"version" description:"Display version information and exit"`
+	RPCServer         string        `short:"s" long:"rpcserver" description:"RPC server to connect to"`
+	Listen            string        `long:"listen" description:"Interface/port to listen for stratum connections on"`
+	MiningAddr        string        `long:"miningaddr" description:"Address to mine to"`
+	MineWhenNotSynced bool          `long:"mine-when-not-synced" description:"Hand out jobs even if the node is not synced with the rest of the network."`
+	Difficulty        float64       `long:"difficulty" description:"Initial share difficulty of every worker. A share of difficulty 1 takes 2^32 hashes on average"`
+	SharesPerMinute   float64       `long:"shares-per-minute" description:"Number of shares per minute to aim for when adjusting the share difficulty of each worker. 0 keeps the share difficulty fixed"`
+	ExtranonceSize    int           `long:"extranonce-size" description:"Size, in bytes, of the nonce prefix given to each worker so their nonce spaces don't overlap (0-3). With 0 only a single worker can connect"`
+	StatsLogInterval  time.Duration `long:"stats-log-interval" description:"Interval between logs of the per-worker share statistics"`
+	Profile           string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	config.NetworkFlags
+	config.RPCClientFlags
+}
+
+func parseConfig() (*configFlags, error) {
+	cfg := &configFlags{
+		RPCServer:        defaultRPCServer,
+		Listen:           defaultListen,
+		Difficulty:       defaultDifficulty,
+		SharesPerMinute:  defaultSharesPerMinute,
+		ExtranonceSize:   defaultExtranonceSize,
+		StatsLogInterval: defaultStatsLogInterval,
+	}
+	parser := flags.NewParser(cfg, flags.PrintErrors|flags.HelpFlag)
+	_, err := parser.Parse()
+
+	// Show the version and exit if the version flag was specified.
+	if cfg.ShowVersion {
+		appName := filepath.Base(os.Args[0])
+		appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+		fmt.Println(appName, "version", version.Version())
+		os.Exit(0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cfg.ResolveNetwork(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Profile != "" {
+		profilePort, err := strconv.Atoi(cfg.Profile)
+		if err != nil || profilePort < 1024 || profilePort > 65535 {
+			return nil, errors.New("The profile port must be between 1024 and 65535")
+		}
+	}
+
+	if cfg.MiningAddr == "" {
+		return nil, errors.New("--miningaddr is required")
+	}
+
+	if cfg.Difficulty <= 0 {
+		return nil, errors.New("--difficulty must be positive")
+	}
+	if cfg.SharesPerMinute < 0 {
+		return nil, errors.New("--shares-per-minute must not be negative")
+	}
+	if cfg.ExtranonceSize < 0 || cfg.ExtranonceSize > maxExtranonceSize {
+		return nil, errors.Errorf("--extranonce-size must be between 0 and %d", maxExtranonceSize)
+	}
+	if cfg.StatsLogInterval <= 0 {
+		return nil, errors.New("--stats-log-interval must be positive")
+	}
+
+	initLog(defaultLogFile, defaultErrLogFile)
+
+	return cfg, nil
+}
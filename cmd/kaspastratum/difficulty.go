@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math"
+	"math/big"
+)
+
+// diff1Target is the share target of difficulty 1. Like in Bitcoin's stratum, a share of difficulty 1
+// takes 2^32 hashes on average.
+var diff1Target = new(big.Int).Lsh(big.NewInt(0xffff), 208)
+
+// hashesPerDifficulty is the average number of hashes it takes to find a share of difficulty 1
+const hashesPerDifficulty = 1 << 32
+
+// difficultyToTarget returns the share target of the given share difficulty
+func difficultyToTarget(difficulty float64) *big.Int {
+	target, _ := new(big.Float).Quo(new(big.Float).SetInt(diff1Target), big.NewFloat(difficulty)).Int(nil)
+	return target
+}
+
+const (
+	// minDifficulty is the lowest share difficulty that variable difficulty may set
+	minDifficulty = 1.0 / (1 << 16)
+
+	// maxDifficultyChangeFactor limits how much a single difficulty adjustment may change the difficulty,
+	// so a short streak of luck doesn't throw it off
+	maxDifficultyChangeFactor = 4.0
+
+	// difficultyToleranceFactor is how far the share rate of a worker may drift from the target share
+	// rate before its difficulty is adjusted
+	difficultyToleranceFactor = 2.0
+)
+
+// adjustedDifficulty returns the difficulty that makes a worker that submitted the given share rate
+// at the given difficulty submit the target share rate, or the given difficulty if it's close enough
+func adjustedDifficulty(difficulty float64, sharesPerMinute float64, targetSharesPerMinute float64) float64 {
+	ratio := sharesPerMinute / targetSharesPerMinute
+	if ratio <= difficultyToleranceFactor && ratio >= 1/difficultyToleranceFactor {
+		return difficulty
+	}
+
+	ratio = math.Max(math.Min(ratio, maxDifficultyChangeFactor), 1/maxDifficultyChangeFactor)
+	return math.Max(difficulty*ratio, minDifficulty)
+}
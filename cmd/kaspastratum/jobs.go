@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/pow"
+)
+
+// maxJobs is the number of most recent jobs that shares are accepted for. Shares of older jobs are stale.
+const maxJobs = 16
+
+// job is a block template that is handed out to the workers
+type job struct {
+	id         string
+	block      *externalapi.DomainBlock
+	state      *pow.State
+	prePowHash *externalapi.DomainHash
+	isSynced   bool
+
+	lock            sync.Mutex
+	submittedNonces map[uint64]struct{}
+}
+
+func newJob(id string, block *externalapi.DomainBlock, isSynced bool) *job {
+	header := block.Header.ToMutable()
+	state := pow.NewState(header)
+
+	// The pre-PoW hash is the header hash with a zero timestamp and nonce, the same as in pow.NewState
+	header.SetTimeInMilliseconds(0)
+	header.SetNonce(0)
+	prePowHash := consensushashing.HeaderHash(header)
+
+	return &job{
+		id:              id,
+		block:           block,
+		state:           state,
+		prePowHash:      prePowHash,
+		isSynced:        isSynced,
+		submittedNonces: make(map[uint64]struct{}),
+	}
+}
+
+// notifyParams returns the parameters of the mining.notify message of the job: the job ID, the
+// pre-PoW hash as four little-endian 64-bit words, and the timestamp
+func (j *job) notifyParams() []interface{} {
+	prePowHashBytes := j.prePowHash.ByteSlice()
+	words := make([]uint64, 4)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(prePowHashBytes[i*8:])
+	}
+	return []interface{}{j.id, words, j.state.Timestamp}
+}
+
+// checkNonce returns whether the given nonce satisfies the share target and the block target
+func (j *job) checkNonce(nonce uint64, shareTarget *big.Int) (isShare bool, isBlock bool) {
+	state := *j.state
+	state.Nonce = nonce
+	powValue := state.CalculateProofOfWorkValue()
+
+	isBlock = powValue.Cmp(&state.Target) <= 0
+	isShare = isBlock || powValue.Cmp(shareTarget) <= 0
+	return isShare, isBlock
+}
+
+// markNonceSubmitted returns false if the given nonce was already submitted for this job
+func (j *job) markNonceSubmitted(nonce uint64) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if _, ok := j.submittedNonces[nonce]; ok {
+		return false
+	}
+	j.submittedNonces[nonce] = struct{}{}
+	return true
+}
+
+// solvedBlock returns the block of the job with the given nonce
+func (j *job) solvedBlock(nonce uint64) *externalapi.DomainBlock {
+	// Shallow copy the block so the template itself isn't modified
+	block := *j.block
+	mutableHeader := block.Header.ToMutable()
+	mutableHeader.SetNonce(nonce)
+	block.Header = mutableHeader.ToImmutable()
+	return &block
+}
+
+// jobManager creates jobs from block templates and keeps the most recent ones
+type jobManager struct {
+	lock      sync.RWMutex
+	jobs      map[string]*job
+	jobIDs    []string
+	nextJobID uint64
+}
+
+func newJobManager() *jobManager {
+	return &jobManager{
+		jobs:   make(map[string]*job),
+		jobIDs: make([]string, 0, maxJobs),
+	}
+}
+
+// addTemplate creates a job from the given block template. It returns false if the template is
+// the same as the one of the current job, in which case no job is created.
+func (jm *jobManager) addTemplate(template *appmessage.GetBlockTemplateResponseMessage) (*job, bool, error) {
+	block, err := appmessage.RPCBlockToDomainBlock(template.Block)
+	if err != nil {
+		return nil, false, err
+	}
+
+	jm.lock.Lock()
+	defer jm.lock.Unlock()
+
+	newJob := newJob(strconv.FormatUint(jm.nextJobID, 16), block, template.IsSynced)
+	if currentJob := jm.currentJobNoLock(); currentJob != nil &&
+		currentJob.prePowHash.Equal(newJob.prePowHash) &&
+		currentJob.state.Timestamp == newJob.state.Timestamp &&
+		currentJob.isSynced == newJob.isSynced {
+
+		return currentJob, false, nil
+	}
+	jm.nextJobID++
+
+	if len(jm.jobIDs) == maxJobs {
+		delete(jm.jobs, jm.jobIDs[0])
+		jm.jobIDs = jm.jobIDs[1:]
+	}
+	jm.jobs[newJob.id] = newJob
+	jm.jobIDs = append(jm.jobIDs, newJob.id)
+	return newJob, true, nil
+}
+
+func (jm *jobManager) job(id string) (*job, bool) {
+	jm.lock.RLock()
+	defer jm.lock.RUnlock()
+
+	job, ok := jm.jobs[id]
+	return job, ok
+}
+
+// currentJob returns the most recent job, or nil if there's none yet
+func (jm *jobManager) currentJob() *job {
+	jm.lock.RLock()
+	defer jm.lock.RUnlock()
+
+	return jm.currentJobNoLock()
+}
+
+func (jm *jobManager) currentJobNoLock() *job {
+	if len(jm.jobIDs) == 0 {
+		return nil
+	}
+	return jm.jobs[jm.jobIDs[len(jm.jobIDs)-1]]
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	nativeerrors "errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/version"
+	"github.com/pkg/errors"
+)
+
+// stratumServer hands out the block templates of a kaspad node as stratum jobs, validates the
+// shares of the connected workers and submits the blocks they find
+type stratumServer struct {
+	cfg        *configFlags
+	client     *bridgeClient
+	miningAddr util.Address
+	jobs       *jobManager
+	stats      *statsManager
+
+	lock     sync.Mutex
+	sessions map[*session]struct{}
+
+	// usedExtranonces holds the extranonces of the live sessions, so that no two
+	// workers search the same nonces
+	usedExtranonces map[uint64]struct{}
+	nextExtranonce  uint64
+}
+
+func newStratumServer(cfg *configFlags, client *bridgeClient, miningAddr util.Address) *stratumServer {
+	return &stratumServer{
+		cfg:        cfg,
+		client:     client,
+		miningAddr: miningAddr,
+		jobs:       newJobManager(),
+		stats:      newStatsManager(),
+		sessions:   make(map[*session]struct{}),
+
+		usedExtranonces: make(map[uint64]struct{}),
+	}
+}
+
+func (s *stratumServer) start() error {
+	listener, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return errors.Wrapf(err, "error listening to TCP on %s", s.cfg.Listen)
+	}
+	log.Infof("Listening to stratum connections on %s", s.cfg.Listen)
+
+	spawn("templatesLoop", s.templatesLoop)
+	spawn("logStatsLoop", func() {
+		s.stats.logStatsLoop(s.cfg.StatsLogInterval)
+	})
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			return errors.Wrap(err, "error accepting a stratum connection")
+		}
+		session, err := s.newSession(connection)
+		if err != nil {
+			log.Warnf("Rejecting the stratum connection from %s: %s", connection.RemoteAddr(), err)
+			err := connection.Close()
+			if err != nil {
+				log.Debugf("Error closing the connection to %s: %s", connection.RemoteAddr(), err)
+			}
+			continue
+		}
+		spawn("session.handle", session.handle)
+	}
+}
+
+// newSession creates a session for the given connection with an extranonce that no live session uses.
+// It returns an error if all the extranonces are in use.
+func (s *stratumServer) newSession(connection net.Conn) (*session, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	extranonceModulo := uint64(1) << (8 * s.cfg.ExtranonceSize)
+	if uint64(len(s.usedExtranonces)) >= extranonceModulo {
+		if s.cfg.ExtranonceSize == 0 {
+			return nil, errors.New("only a single worker can connect when --extranonce-size is 0")
+		}
+		return nil, errors.Errorf("all the %d extranonces are in use", extranonceModulo)
+	}
+
+	extranonce := s.nextExtranonce % extranonceModulo
+	for {
+		if _, ok := s.usedExtranonces[extranonce]; !ok {
+			break
+		}
+		extranonce = (extranonce + 1) % extranonceModulo
+	}
+	s.nextExtranonce = extranonce + 1
+	s.usedExtranonces[extranonce] = struct{}{}
+
+	session := newSession(s, connection, extranonce)
+	s.sessions[session] = struct{}{}
+	log.Infof("New stratum connection from %s", connection.RemoteAddr())
+	return session, nil
+}
+
+// removeSession removes the given session and releases its extranonce
+func (s *stratumServer) removeSession(session *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sessions, session)
+	delete(s.usedExtranonces, session.extranonce)
+}
+
+// broadcastJob queues the given job to be sent to all the authorized workers. It doesn't wait
+// for the job to be sent, so that slow workers don't delay the others
+func (s *stratumServer) broadcastJob(job *job) {
+	s.lock.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.lock.Unlock()
+
+	for _, session := range sessions {
+		session.queueJob(job)
+	}
+}
+
+// templatesLoop fetches a new block template whenever the node notifies about one, and at least every
+// defaultTemplatePollDelay in case a notification is missed, and hands it out as a job
+func (s *stratumServer) templatesLoop() {
+	extraData := "kaspastratum-" + version.Version()
+	getBlockTemplate := func() {
+		template, err := s.client.GetBlockTemplate(s.miningAddr.String(), extraData)
+		if nativeerrors.Is(err, router.ErrTimeout) {
+			log.Warnf("Got timeout while requesting block template from %s: %s", s.client.Address(), err)
+			err := s.client.Reconnect()
+			if err != nil {
+				log.Errorf("Error reconnecting to %s: %s", s.client.Address(), err)
+			}
+			return
+		}
+		if nativeerrors.Is(err, router.ErrRouteClosed) {
+			log.Debugf("Got route is closed while requesting block template from %s. "+
+				"The client is most likely reconnecting", s.client.Address())
+			return
+		}
+		if err != nil {
+			log.Errorf("Error getting block template from %s: %s", s.client.Address(), err)
+			return
+		}
+
+		job, isNew, err := s.jobs.addTemplate(template)
+		if err != nil {
+			log.Errorf("Error creating a job from the block template of %s: %s", s.client.Address(), err)
+			return
+		}
+		if !isNew {
+			return
+		}
+		if !job.isSynced && !s.cfg.MineWhenNotSynced {
+			log.Warnf("Kaspad is not synced. Not handing out job %s", job.id)
+			return
+		}
+		log.Debugf("Handing out job %s", job.id)
+		s.broadcastJob(job)
+	}
+
+	getBlockTemplate()
+	ticker := time.NewTicker(defaultTemplatePollDelay)
+	for {
+		select {
+		case <-s.client.newBlockTemplateNotificationChan:
+			getBlockTemplate()
+			ticker.Reset(defaultTemplatePollDelay)
+		case <-s.client.reconnectedChan:
+			err := s.client.registerForNewBlockTemplateNotifications()
+			if err != nil {
+				log.Errorf("Error registering for new block template notifications after reconnecting: %s", err)
+			}
+			getBlockTemplate()
+		case <-ticker.C:
+			getBlockTemplate()
+		}
+	}
+}
+
+// submitBlock submits a block found by the given worker to the node
+func (s *stratumServer) submitBlock(workerName string, block *externalapi.DomainBlock) {
+	blockHash := consensushashing.BlockHash(block)
+	log.Infof("Worker %s found block %s. Submitting it to %s", workerName, blockHash, s.client.Address())
+
+	rejectReason, err := s.client.SubmitBlock(block)
+	if err != nil {
+		if rejectReason == appmessage.RejectReasonIsInIBD {
+			log.Warnf("Block %s was rejected because the node is in IBD", blockHash)
+			return
+		}
+		log.Errorf("Error submitting block %s to %s: %s", blockHash, s.client.Address(), err)
+		return
+	}
+	s.stats.addBlock(workerName)
+}
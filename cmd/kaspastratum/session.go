@@ -0,0 +1,467 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	maxMessageSize = 64 * 1024
+	writeTimeout   = 10 * time.Second
+
+	// difficultyAdjustmentWindow is the minimal time over which the share rate of a worker is measured
+	// before its difficulty is adjusted
+	difficultyAdjustmentWindow = 30 * time.Second
+
+	// jobQueueSize is the number of jobs that may wait to be sent to a worker. When the queue
+	// is full, the oldest queued job is dropped, since it's stale anyway
+	jobQueueSize = 4
+
+	// maxDroppedJobs is the number of jobs that may be dropped before the worker catches up.
+	// Workers that fall further behind are disconnected
+	maxDroppedJobs = 2 * jobQueueSize
+)
+
+// Stratum error codes
+const (
+	errorCodeOther          = 20
+	errorCodeJobNotFound    = 21
+	errorCodeDuplicateShare = 22
+	errorCodeLowDifficulty  = 23
+	errorCodeUnauthorized   = 24
+	errorCodeNotSubscribed  = 25
+)
+
+type stratumRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type stratumResponse struct {
+	ID      json.RawMessage `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result"`
+	Error   interface{}     `json:"error"`
+}
+
+type stratumNotification struct {
+	ID      interface{}   `json:"id"`
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+func stratumError(code int, message string) []interface{} {
+	return []interface{}{code, message, nil}
+}
+
+// session is a single stratum connection
+type session struct {
+	server        *stratumServer
+	connection    net.Conn
+	extranonce    uint64
+	extranonceHex string
+
+	// writeLock makes sure that messages, and pairs of messages that must arrive together,
+	// aren't interleaved
+	writeLock sync.Mutex
+
+	// jobQueue holds the jobs that are waiting to be sent by jobsLoop, so that
+	// a slow worker delays nobody but itself
+	jobQueue chan *job
+	closed   chan struct{}
+
+	lock                   sync.Mutex
+	isSubscribed           bool
+	workerName             string
+	difficulty             float64
+	sentDifficulty         float64
+	jobDifficulties        map[string]float64
+	jobDifficultyOrder     []string
+	difficultyWindowStart  time.Time
+	difficultyWindowShares int
+	droppedJobs            int
+}
+
+func newSession(server *stratumServer, connection net.Conn, extranonce uint64) *session {
+	extranonceHex := ""
+	if server.cfg.ExtranonceSize > 0 {
+		extranonceHex = fmt.Sprintf("%0*x", 2*server.cfg.ExtranonceSize, extranonce)
+	}
+
+	return &session{
+		server:          server,
+		connection:      connection,
+		extranonce:      extranonce,
+		extranonceHex:   extranonceHex,
+		difficulty:      server.cfg.Difficulty,
+		jobDifficulties: make(map[string]float64),
+		jobQueue:        make(chan *job, jobQueueSize),
+		closed:          make(chan struct{}),
+	}
+}
+
+func (s *session) handle() {
+	defer s.close()
+
+	spawn("session.jobsLoop", s.jobsLoop)
+
+	scanner := bufio.NewScanner(s.connection)
+	scanner.Buffer(make([]byte, 0, 4096), maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		request := &stratumRequest{}
+		err := json.Unmarshal(line, request)
+		if err != nil {
+			log.Warnf("Got a malformed message from %s: %s", s.connection.RemoteAddr(), err)
+			return
+		}
+		err = s.handleRequest(request)
+		if err != nil {
+			log.Warnf("Error handling %s from %s: %s", request.Method, s.connection.RemoteAddr(), err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Debugf("Error reading from %s: %s", s.connection.RemoteAddr(), err)
+	}
+}
+
+func (s *session) close() {
+	s.server.removeSession(s)
+	close(s.closed)
+	err := s.connection.Close()
+	if err != nil {
+		log.Debugf("Error closing the connection to %s: %s", s.connection.RemoteAddr(), err)
+	}
+	log.Infof("Stratum connection from %s (worker %s) closed", s.connection.RemoteAddr(), s.name())
+}
+
+func (s *session) name() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.workerName == "" {
+		return "<unauthorized>"
+	}
+	return s.workerName
+}
+
+// handleRequest handles a single request of the worker. Errors that the worker should be told about are
+// sent to it, and only errors that require closing the connection are returned.
+func (s *session) handleRequest(request *stratumRequest) error {
+	switch request.Method {
+	case "mining.subscribe":
+		return s.handleSubscribe(request)
+	case "mining.extranonce.subscribe":
+		return s.respond(request, true, nil)
+	case "mining.authorize":
+		return s.handleAuthorize(request)
+	case "mining.submit":
+		return s.handleSubmit(request)
+	default:
+		log.Debugf("Got unsupported method %s from %s", request.Method, s.connection.RemoteAddr())
+		return s.respond(request, nil, stratumError(errorCodeOther, "Unsupported method"))
+	}
+}
+
+func (s *session) handleSubscribe(request *stratumRequest) error {
+	s.lock.Lock()
+	s.isSubscribed = true
+	s.lock.Unlock()
+
+	err := s.respond(request, []interface{}{true, "EthereumStratum/1.0.0"}, nil)
+	if err != nil {
+		return err
+	}
+	if s.extranonceHex == "" {
+		return nil
+	}
+	// The worker is told its nonce prefix, and the number of nonce bytes that are left for it to fill
+	return s.notify("mining.set_extranonce", s.extranonceHex, 8-s.server.cfg.ExtranonceSize)
+}
+
+func (s *session) handleAuthorize(request *stratumRequest) error {
+	var workerName string
+	if len(request.Params) < 1 || json.Unmarshal(request.Params[0], &workerName) != nil || workerName == "" {
+		return s.respond(request, nil, stratumError(errorCodeOther, "Missing worker name"))
+	}
+
+	s.lock.Lock()
+	if !s.isSubscribed {
+		s.lock.Unlock()
+		return s.respond(request, nil, stratumError(errorCodeNotSubscribed, "Not subscribed"))
+	}
+	s.workerName = workerName
+	s.difficultyWindowStart = time.Now()
+	s.lock.Unlock()
+
+	err := s.respond(request, true, nil)
+	if err != nil {
+		return err
+	}
+	log.Infof("Worker %s authorized from %s", workerName, s.connection.RemoteAddr())
+
+	job := s.server.jobs.currentJob()
+	if job != nil && (job.isSynced || s.server.cfg.MineWhenNotSynced) {
+		s.queueJob(job)
+	}
+	return nil
+}
+
+func (s *session) handleSubmit(request *stratumRequest) error {
+	s.lock.Lock()
+	workerName := s.workerName
+	s.lock.Unlock()
+	if workerName == "" {
+		return s.respond(request, nil, stratumError(errorCodeUnauthorized, "Unauthorized worker"))
+	}
+
+	var jobID, nonceString string
+	if len(request.Params) < 3 ||
+		json.Unmarshal(request.Params[1], &jobID) != nil ||
+		json.Unmarshal(request.Params[2], &nonceString) != nil {
+
+		s.server.stats.addShare(workerName, shareInvalid, 0)
+		return s.respond(request, nil, stratumError(errorCodeOther, "Malformed share"))
+	}
+
+	job, jobExists := s.server.jobs.job(jobID)
+	difficulty, wasJobSent := s.jobDifficulty(jobID)
+	if !jobExists || !wasJobSent {
+		s.server.stats.addShare(workerName, shareStale, 0)
+		return s.respond(request, nil, stratumError(errorCodeJobNotFound, "Job not found"))
+	}
+
+	nonce, err := s.parseNonce(nonceString)
+	if err != nil {
+		s.server.stats.addShare(workerName, shareInvalid, 0)
+		return s.respond(request, nil, stratumError(errorCodeOther, err.Error()))
+	}
+	if !job.markNonceSubmitted(nonce) {
+		s.server.stats.addShare(workerName, shareInvalid, 0)
+		return s.respond(request, nil, stratumError(errorCodeDuplicateShare, "Duplicate share"))
+	}
+
+	isShare, isBlock := job.checkNonce(nonce, difficultyToTarget(difficulty))
+	if isBlock {
+		block := job.solvedBlock(nonce)
+		spawn("submitBlock", func() {
+			s.server.submitBlock(workerName, block)
+		})
+	}
+	if !isShare {
+		s.server.stats.addShare(workerName, shareInvalid, 0)
+		return s.respond(request, nil, stratumError(errorCodeLowDifficulty, "Low difficulty share"))
+	}
+
+	s.server.stats.addShare(workerName, shareAccepted, difficulty)
+	s.lock.Lock()
+	s.difficultyWindowShares++
+	s.lock.Unlock()
+	return s.respond(request, true, nil)
+}
+
+// parseNonce parses a nonce submitted by the worker. The worker may either submit the whole nonce, which
+// must start with its extranonce, or only the part that follows the extranonce.
+func (s *session) parseNonce(nonceString string) (uint64, error) {
+	nonceHex := strings.TrimPrefix(strings.ToLower(nonceString), "0x")
+	if len(nonceHex)+len(s.extranonceHex) == 16 {
+		nonceHex = s.extranonceHex + nonceHex
+	}
+	if len(nonceHex) != 16 {
+		return 0, errors.Errorf("Malformed nonce %s", nonceString)
+	}
+	if !strings.HasPrefix(nonceHex, s.extranonceHex) {
+		return 0, errors.Errorf("Nonce %s doesn't start with the extranonce %s", nonceString, s.extranonceHex)
+	}
+
+	nonce, err := strconv.ParseUint(nonceHex, 16, 64)
+	if err != nil {
+		return 0, errors.Errorf("Malformed nonce %s", nonceString)
+	}
+	return nonce, nil
+}
+
+// queueJob queues the given job to be sent to the worker by jobsLoop. It never blocks: if the queue
+// is full, the oldest queued job is dropped, and if the worker keeps falling behind it's disconnected.
+func (s *session) queueJob(job *job) {
+	for {
+		select {
+		case s.jobQueue <- job:
+			return
+		default:
+		}
+
+		select {
+		case droppedJob := <-s.jobQueue:
+			log.Debugf("Dropped job %s of %s, which is not keeping up", droppedJob.id, s.connection.RemoteAddr())
+			s.lock.Lock()
+			s.droppedJobs++
+			droppedJobs := s.droppedJobs
+			s.lock.Unlock()
+
+			if droppedJobs > maxDroppedJobs {
+				log.Warnf("Disconnecting worker %s at %s, which is not keeping up with the jobs",
+					s.name(), s.connection.RemoteAddr())
+				s.disconnect()
+				return
+			}
+		default:
+			// jobsLoop has just taken a job, so there's room for this one
+		}
+	}
+}
+
+// jobsLoop sends the queued jobs to the worker until the session is closed
+func (s *session) jobsLoop() {
+	for {
+		select {
+		case <-s.closed:
+			return
+		case job := <-s.jobQueue:
+			err := s.sendJob(job)
+			if err != nil {
+				log.Debugf("Error sending job %s to %s: %s", job.id, s.connection.RemoteAddr(), err)
+				s.disconnect()
+				return
+			}
+			if len(s.jobQueue) == 0 {
+				s.lock.Lock()
+				s.droppedJobs = 0
+				s.lock.Unlock()
+			}
+		}
+	}
+}
+
+// disconnect closes the connection to the worker, which makes handle return and close the session
+func (s *session) disconnect() {
+	err := s.connection.Close()
+	if err != nil {
+		log.Debugf("Error closing the connection to %s: %s", s.connection.RemoteAddr(), err)
+	}
+}
+
+// sendJob sends the given job to the worker, preceded by its share difficulty if it changed. The difficulty
+// is adjusted to the share rate of the worker first, and is kept for validating the shares of this job.
+func (s *session) sendJob(job *job) error {
+	s.lock.Lock()
+	if s.workerName == "" {
+		s.lock.Unlock()
+		return nil
+	}
+	s.maybeAdjustDifficultyNoLock()
+	difficulty := s.difficulty
+	isDifficultyChanged := difficulty != s.sentDifficulty
+	s.sentDifficulty = difficulty
+	s.addJobDifficultyNoLock(job.id, difficulty)
+	s.lock.Unlock()
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if isDifficultyChanged {
+		err := s.notifyNoLock("mining.set_difficulty", difficulty)
+		if err != nil {
+			return err
+		}
+	}
+	return s.notifyNoLock("mining.notify", job.notifyParams()...)
+}
+
+// maybeAdjustDifficultyNoLock adjusts the share difficulty so that the worker submits shares at the configured
+// rate, once enough time has passed to measure its current rate
+func (s *session) maybeAdjustDifficultyNoLock() {
+	targetSharesPerMinute := s.server.cfg.SharesPerMinute
+	if targetSharesPerMinute == 0 {
+		return
+	}
+	elapsed := time.Since(s.difficultyWindowStart)
+	if elapsed < difficultyAdjustmentWindow {
+		return
+	}
+
+	sharesPerMinute := float64(s.difficultyWindowShares) / elapsed.Minutes()
+	newDifficulty := adjustedDifficulty(s.difficulty, sharesPerMinute, targetSharesPerMinute)
+	if newDifficulty != s.difficulty {
+		log.Debugf("Changing the share difficulty of worker %s from %g to %g (%.2f shares per minute)",
+			s.workerName, s.difficulty, newDifficulty, sharesPerMinute)
+		s.difficulty = newDifficulty
+	}
+	s.difficultyWindowStart = time.Now()
+	s.difficultyWindowShares = 0
+}
+
+func (s *session) addJobDifficultyNoLock(jobID string, difficulty float64) {
+	if len(s.jobDifficultyOrder) == maxJobs {
+		delete(s.jobDifficulties, s.jobDifficultyOrder[0])
+		s.jobDifficultyOrder = s.jobDifficultyOrder[1:]
+	}
+	s.jobDifficulties[jobID] = difficulty
+	s.jobDifficultyOrder = append(s.jobDifficultyOrder, jobID)
+}
+
+// jobDifficulty returns the share difficulty that was in effect when the given job was sent to the worker
+func (s *session) jobDifficulty(jobID string) (float64, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	difficulty, ok := s.jobDifficulties[jobID]
+	return difficulty, ok
+}
+
+func (s *session) respond(request *stratumRequest, result interface{}, stratumError interface{}) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	return s.writeNoLock(&stratumResponse{
+		ID:      request.ID,
+		JSONRPC: "2.0",
+		Result:  result,
+		Error:   stratumError,
+	})
+}
+
+func (s *session) notify(method string, params ...interface{}) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	return s.notifyNoLock(method, params...)
+}
+
+func (s *session) notifyNoLock(method string, params ...interface{}) error {
+	return s.writeNoLock(&stratumNotification{
+		ID:      nil,
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
+
+func (s *session) writeNoLock(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	err = s.connection.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err != nil {
+		return err
+	}
+	_, err = s.connection.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/blockheader"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/util/difficulty"
+)
+
+func TestSession(t *testing.T) {
+	const shareDifficulty = 1.0 / (1 << 20)
+	cfg := &configFlags{
+		Difficulty:      shareDifficulty,
+		SharesPerMinute: 0,
+		ExtranonceSize:  2,
+	}
+	server := newStratumServer(cfg, nil, nil)
+
+	// Make the block target much harder than the share target, so that no share is a block
+	block := dagconfig.SimnetParams.GenesisBlock.Clone()
+	genesisHeader := block.Header
+	block.Header = blockheader.NewImmutableBlockHeader(genesisHeader.Version(), genesisHeader.Parents(),
+		genesisHeader.HashMerkleRoot(), genesisHeader.AcceptedIDMerkleRoot(), genesisHeader.UTXOCommitment(),
+		genesisHeader.TimeInMilliseconds(), difficulty.BigToCompact(new(big.Int).Lsh(big.NewInt(1), 200)),
+		genesisHeader.Nonce(), genesisHeader.DAAScore(), genesisHeader.BlueScore(), genesisHeader.BlueWork(),
+		genesisHeader.PruningPoint())
+	job, isNew, err := server.jobs.addTemplate(&appmessage.GetBlockTemplateResponseMessage{
+		Block:    appmessage.DomainBlockToRPCBlock(block),
+		IsSynced: true,
+	})
+	if err != nil {
+		t.Fatalf("addTemplate: %s", err)
+	}
+	if !isNew {
+		t.Fatalf("expected the first template to create a new job")
+	}
+
+	serverConnection, clientConnection := net.Pipe()
+	defer clientConnection.Close()
+	session, err := server.newSession(serverConnection)
+	if err != nil {
+		t.Fatalf("newSession: %s", err)
+	}
+	go session.handle()
+
+	reader := bufio.NewReader(clientConnection)
+	send := func(id int, method string, params ...interface{}) {
+		t.Helper()
+		data, err := json.Marshal(map[string]interface{}{"id": id, "method": method, "params": params})
+		if err != nil {
+			t.Fatalf("Marshal: %s", err)
+		}
+		err = clientConnection.SetDeadline(time.Now().Add(10 * time.Second))
+		if err != nil {
+			t.Fatalf("SetDeadline: %s", err)
+		}
+		_, err = clientConnection.Write(append(data, '\n'))
+		if err != nil {
+			t.Fatalf("Write: %s", err)
+		}
+	}
+	receive := func() map[string]interface{} {
+		t.Helper()
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatalf("ReadBytes: %s", err)
+		}
+		message := make(map[string]interface{})
+		err = json.Unmarshal(line, &message)
+		if err != nil {
+			t.Fatalf("Unmarshal: %s", err)
+		}
+		return message
+	}
+	expectResult := func(expectedErrorCode int) {
+		t.Helper()
+		response := receive()
+		if expectedErrorCode == 0 {
+			if response["result"] != true {
+				t.Fatalf("expected a successful response, got %v", response)
+			}
+			return
+		}
+		stratumError, ok := response["error"].([]interface{})
+		if !ok || stratumError[0] != float64(expectedErrorCode) {
+			t.Fatalf("expected error code %d, got %v", expectedErrorCode, response)
+		}
+	}
+
+	send(1, "mining.subscribe", "test-miner")
+	receive()
+	extranonceMessage := receive()
+	if extranonceMessage["method"] != "mining.set_extranonce" {
+		t.Fatalf("expected mining.set_extranonce, got %v", extranonceMessage)
+	}
+	extranonce := extranonceMessage["params"].([]interface{})[0].(string)
+	if extranonce != session.extranonceHex || len(extranonce) != 4 {
+		t.Fatalf("unexpected extranonce %s", extranonce)
+	}
+
+	send(2, "mining.authorize", "kaspasim:qz.rig1", "x")
+	expectResult(0)
+	if method := receive()["method"]; method != "mining.set_difficulty" {
+		t.Fatalf("expected mining.set_difficulty, got %s", method)
+	}
+	notifyMessage := receive()
+	if notifyMessage["method"] != "mining.notify" || notifyMessage["params"].([]interface{})[0] != job.id {
+		t.Fatalf("expected mining.notify of job %s, got %v", job.id, notifyMessage)
+	}
+
+	// Find a share and a non-share with the extranonce of the session, which is 0
+	shareTarget := difficultyToTarget(shareDifficulty)
+	var shareNonce, nonShareNonce uint64
+	foundShare, foundNonShare := false, false
+	for nonce := uint64(0); !foundShare || !foundNonShare; nonce++ {
+		isShare, isBlock := job.checkNonce(nonce, shareTarget)
+		if isBlock {
+			t.Fatalf("didn't expect a block")
+		}
+		if isShare && !foundShare {
+			shareNonce, foundShare = nonce, true
+		}
+		if !isShare && !foundNonShare {
+			nonShareNonce, foundNonShare = nonce, true
+		}
+	}
+
+	send(3, "mining.submit", "kaspasim:qz.rig1", job.id, fmt.Sprintf("%016x", shareNonce))
+	expectResult(0)
+	send(4, "mining.submit", "kaspasim:qz.rig1", job.id, fmt.Sprintf("%016x", shareNonce))
+	expectResult(errorCodeDuplicateShare)
+	// The nonce may also be sent without the extranonce
+	send(5, "mining.submit", "kaspasim:qz.rig1", job.id, fmt.Sprintf("%012x", nonShareNonce))
+	expectResult(errorCodeLowDifficulty)
+	send(6, "mining.submit", "kaspasim:qz.rig1", "unknown", fmt.Sprintf("%016x", shareNonce))
+	expectResult(errorCodeJobNotFound)
+	send(7, "mining.submit", "kaspasim:qz.rig1", job.id, fmt.Sprintf("ffff%012x", shareNonce))
+	expectResult(errorCodeOther)
+
+	stats := server.stats.workers["kaspasim:qz.rig1"]
+	if stats.acceptedShares != 1 || stats.staleShares != 1 || stats.invalidShares != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestAdjustedDifficulty(t *testing.T) {
+	tests := []struct {
+		sharesPerMinute    float64
+		expectedDifficulty float64
+	}{
+		{sharesPerMinute: 20, expectedDifficulty: 8},
+		{sharesPerMinute: 35, expectedDifficulty: 8},
+		{sharesPerMinute: 60, expectedDifficulty: 24},
+		{sharesPerMinute: 1000, expectedDifficulty: 32},
+		{sharesPerMinute: 5, expectedDifficulty: 2},
+		{sharesPerMinute: 0, expectedDifficulty: 2},
+	}
+	for _, test := range tests {
+		difficulty := adjustedDifficulty(8, test.sharesPerMinute, 20)
+		if difficulty != test.expectedDifficulty {
+			t.Errorf("expected difficulty %g for %g shares per minute, got %g",
+				test.expectedDifficulty, test.sharesPerMinute, difficulty)
+		}
+	}
+}
+
+func TestQueueJob(t *testing.T) {
+	server := newStratumServer(&configFlags{}, nil, nil)
+	serverConnection, clientConnection := net.Pipe()
+	defer clientConnection.Close()
+
+	// The jobs loop isn't running, as if the worker stopped reading
+	session, err := server.newSession(serverConnection)
+	if err != nil {
+		t.Fatalf("newSession: %s", err)
+	}
+	jobs := make([]*job, jobQueueSize+maxDroppedJobs+1)
+	for i := range jobs {
+		jobs[i] = &job{id: fmt.Sprintf("%d", i)}
+	}
+
+	for _, job := range jobs[:jobQueueSize+maxDroppedJobs] {
+		session.queueJob(job)
+	}
+	if len(session.jobQueue) != jobQueueSize {
+		t.Fatalf("expected a full job queue, got %d jobs", len(session.jobQueue))
+	}
+	if queuedJob := <-session.jobQueue; queuedJob != jobs[maxDroppedJobs] {
+		t.Fatalf("expected the oldest jobs to be dropped, but job %s is queued", queuedJob.id)
+	}
+
+	// Dropping one more job disconnects the worker
+	session.queueJob(jobs[len(jobs)-2])
+	session.queueJob(jobs[len(jobs)-1])
+	_, err = clientConnection.Read(make([]byte, 1))
+	if err == nil {
+		t.Fatalf("expected the worker to be disconnected")
+	}
+}
+
+func TestNewSessionExtranonces(t *testing.T) {
+	server := newStratumServer(&configFlags{ExtranonceSize: 1}, nil, nil)
+	newSession := func() (*session, error) {
+		serverConnection, clientConnection := net.Pipe()
+		t.Cleanup(func() {
+			serverConnection.Close()
+			clientConnection.Close()
+		})
+		return server.newSession(serverConnection)
+	}
+
+	// Every live session gets a different extranonce, even after the extranonces wrap around
+	sessions := make([]*session, 256)
+	extranonces := make(map[uint64]struct{})
+	for i := range sessions {
+		session, err := newSession()
+		if err != nil {
+			t.Fatalf("newSession: %s", err)
+		}
+		if _, ok := extranonces[session.extranonce]; ok {
+			t.Fatalf("extranonce %d was given to more than one session", session.extranonce)
+		}
+		extranonces[session.extranonce] = struct{}{}
+		sessions[i] = session
+	}
+	_, err := newSession()
+	if err == nil {
+		t.Fatalf("expected a new session to be rejected when all the extranonces are in use")
+	}
+
+	// A closed session releases its extranonce
+	server.removeSession(sessions[10])
+	session, err := newSession()
+	if err != nil {
+		t.Fatalf("newSession: %s", err)
+	}
+	if session.extranonce != sessions[10].extranonce {
+		t.Fatalf("expected the released extranonce %d to be reused, got %d", sessions[10].extranonce, session.extranonce)
+	}
+
+	// Without extranonces only a single worker can connect
+	server = newStratumServer(&configFlags{ExtranonceSize: 0}, nil, nil)
+	first, err := newSession()
+	if err != nil {
+		t.Fatalf("newSession: %s", err)
+	}
+	_, err = newSession()
+	if err == nil {
+		t.Fatalf("expected a second session to be rejected when --extranonce-size is 0")
+	}
+	server.removeSession(first)
+	_, err = newSession()
+	if err != nil {
+		t.Fatalf("newSession: %s", err)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// workerStats are the share statistics of a single worker. Workers are identified by the name they
+// authorized with, so their statistics survive reconnections.
+type workerStats struct {
+	name           string
+	acceptedShares uint64
+	staleShares    uint64
+	invalidShares  uint64
+	blocksFound    uint64
+	lastShareTime  time.Time
+
+	// intervalDifficulty is the sum of the difficulties of the shares accepted since the
+	// statistics were last logged, which is used to estimate the hash rate of the worker
+	intervalDifficulty float64
+}
+
+type shareResult int
+
+const (
+	shareAccepted shareResult = iota
+	shareStale
+	shareInvalid
+)
+
+type statsManager struct {
+	lock          sync.Mutex
+	workers       map[string]*workerStats
+	lastLogTime   time.Time
+	totalAccepted uint64
+	totalBlocks   uint64
+}
+
+func newStatsManager() *statsManager {
+	return &statsManager{
+		workers:     make(map[string]*workerStats),
+		lastLogTime: time.Now(),
+	}
+}
+
+func (sm *statsManager) workerStatsNoLock(workerName string) *workerStats {
+	stats, ok := sm.workers[workerName]
+	if !ok {
+		stats = &workerStats{name: workerName}
+		sm.workers[workerName] = stats
+	}
+	return stats
+}
+
+// addShare records a share submitted by the given worker
+func (sm *statsManager) addShare(workerName string, result shareResult, difficulty float64) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	stats := sm.workerStatsNoLock(workerName)
+	switch result {
+	case shareAccepted:
+		stats.acceptedShares++
+		stats.intervalDifficulty += difficulty
+		stats.lastShareTime = time.Now()
+		sm.totalAccepted++
+	case shareStale:
+		stats.staleShares++
+	case shareInvalid:
+		stats.invalidShares++
+	}
+}
+
+// addBlock records a block found by the given worker
+func (sm *statsManager) addBlock(workerName string) {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	sm.workerStatsNoLock(workerName).blocksFound++
+	sm.totalBlocks++
+}
+
+// logStats logs the statistics of all the workers, and the hash rate estimated from the shares
+// accepted since the statistics were last logged
+func (sm *statsManager) logStats() {
+	sm.lock.Lock()
+	defer sm.lock.Unlock()
+
+	now := time.Now()
+	elapsedSeconds := now.Sub(sm.lastLogTime).Seconds()
+	sm.lastLogTime = now
+
+	names := make([]string, 0, len(sm.workers))
+	for name := range sm.workers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	totalHashRate := 0.0
+	for _, name := range names {
+		stats := sm.workers[name]
+		hashRate := stats.intervalDifficulty * hashesPerDifficulty / elapsedSeconds
+		stats.intervalDifficulty = 0
+		totalHashRate += hashRate
+
+		lastShare := "never"
+		if !stats.lastShareTime.IsZero() {
+			lastShare = now.Sub(stats.lastShareTime).Round(time.Second).String() + " ago"
+		}
+		log.Infof("Worker %s: %.2f Mhash/s, %d accepted, %d stale, %d invalid shares, %d blocks, last share %s",
+			name, hashRate/1e6, stats.acceptedShares, stats.staleShares, stats.invalidShares, stats.blocksFound, lastShare)
+	}
+	log.Infof("%d workers: %.2f Mhash/s, %d accepted shares, %d blocks",
+		len(names), totalHashRate/1e6, sm.totalAccepted, sm.totalBlocks)
+}
+
+func (sm *statsManager) logStatsLoop(interval time.Duration) {
+	for range time.Tick(interval) {
+		sm.logStats()
+	}
+}
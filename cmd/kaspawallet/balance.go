@@ -29,10 +29,11 @@ func balance(conf *balanceConfig) error {
 	}
 	if conf.Verbose {
 		pendingSuffix = ""
-		println("Address                                                                       Available             Pending")
+		println("Address                                                                       Available             Pending  Label")
 		println("-----------------------------------------------------------------------------------------------------------")
 		for _, addressBalance := range response.AddressBalances {
-			fmt.Printf("%s %s %s\n", addressBalance.Address, utils.FormatKas(addressBalance.Available), utils.FormatKas(addressBalance.Pending))
+			fmt.Printf("%s %s %s  %s\n", addressBalance.Address, utils.FormatKas(addressBalance.Available),
+				utils.FormatKas(addressBalance.Pending), addressBalance.Label)
 		}
 		println("-----------------------------------------------------------------------------------------------------------")
 		print("                                                 ")
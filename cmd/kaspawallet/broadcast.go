@@ -3,12 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"strings"
 
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/pskt"
 	"github.com/pkg/errors"
 )
 
@@ -22,28 +22,33 @@ func broadcast(conf *broadcastConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
 
-	if conf.Transactions == "" && conf.TransactionsFile == "" {
-		return errors.Errorf("Either --transaction or --transaction-file is required")
-	}
-	if conf.Transactions != "" && conf.TransactionsFile != "" {
-		return errors.Errorf("Both --transaction and --transaction-file cannot be passed at the same time")
+	data, err := readTransactionsInput(conf.Transactions, conf.TransactionsFile)
+	if err != nil {
+		return err
 	}
 
-	transactionsHex := conf.Transactions
-	if conf.TransactionsFile != "" {
-		transactionHexBytes, err := ioutil.ReadFile(conf.TransactionsFile)
+	request := &pb.BroadcastRequest{}
+	if pskt.IsPSKT(data) {
+		psktFile, err := decodePSKT(data, conf.NetParams())
 		if err != nil {
-			return errors.Wrapf(err, "Could not read hex from %s", conf.TransactionsFile)
+			return err
+		}
+		if !psktFile.IsFinalized() {
+			return errors.New("The PSKT isn't finalized. Use \"kaspawallet finalize\" to finalize it first")
+		}
+		request.Transactions, err = psktFile.FinalizedTransactions()
+		if err != nil {
+			return err
+		}
+		request.IsDomain = true
+	} else {
+		request.Transactions, err = server.DecodeTransactionsFromHex(strings.TrimSpace(string(data)))
+		if err != nil {
+			return err
 		}
-		transactionsHex = strings.TrimSpace(string(transactionHexBytes))
-	}
-
-	transactions, err := server.DecodeTransactionsFromHex(transactionsHex)
-	if err != nil {
-		return err
 	}
 
-	response, err := daemonClient.Broadcast(ctx, &pb.BroadcastRequest{Transactions: transactions})
+	response, err := daemonClient.Broadcast(ctx, request)
 	if err != nil {
 		return err
 	}
@@ -19,6 +19,10 @@ func bumpFee(conf *bumpFeeConfig) error {
 		return err
 	}
 
+	if keysFile.IsWatchOnly() {
+		return errors.Errorf("Cannot use 'bump-fee' command for a watch-only wallet")
+	}
+
 	if len(keysFile.ExtendedPublicKeys) > len(keysFile.EncryptedMnemonics) {
 		return errors.Errorf("Cannot use 'bump-fee' command for multisig wallet without all of the keys")
 	}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/pskt"
+	"github.com/pkg/errors"
+)
+
+func combine(conf *combineConfig) error {
+	if len(conf.TransactionFiles) < 2 {
+		return errors.New("At least two PSKT files are required (adding -F before each)")
+	}
+
+	psktFiles := make([]*pskt.PSKT, len(conf.TransactionFiles))
+	for i, transactionFile := range conf.TransactionFiles {
+		var err error
+		psktFiles[i], err = readPSKT("", transactionFile, conf.NetParams())
+		if err != nil {
+			return errors.Wrapf(err, "Error reading %s", transactionFile)
+		}
+	}
+
+	combined, err := pskt.Combine(psktFiles...)
+	if err != nil {
+		return err
+	}
+
+	if combined.IsFullySigned() {
+		fmt.Fprintln(os.Stderr, "The transactions are fully signed. Use \"kaspawallet finalize\" to make them ready to broadcast")
+	} else {
+		fmt.Fprintln(os.Stderr, "Successfully combined the signatures. Some signatures are still missing")
+	}
+	return printPSKT(combined)
+}
@@ -27,6 +27,16 @@ const (
 	bumpFeeSubCmd                   = "bump-fee"
 	bumpFeeUnsignedSubCmd           = "bump-fee-unsigned"
 	broadcastReplacementSubCmd      = "broadcast-replacement"
+	historySubCmd                   = "history"
+	exportPSKTSubCmd                = "export-pskt"
+	importPSKTSubCmd                = "import-pskt"
+	combineSubCmd                   = "combine"
+	finalizeSubCmd                  = "finalize"
+	inspectSubCmd                   = "inspect"
+	labelAddressSubCmd              = "label-address"
+	addContactSubCmd                = "add-contact"
+	removeContactSubCmd             = "remove-contact"
+	showContactsSubCmd              = "show-contacts"
 )
 
 const (
@@ -40,14 +50,16 @@ type configFlags struct {
 }
 
 type createConfig struct {
-	KeysFile          string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kaspawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
-	Password          string `long:"password" short:"p" description:"Wallet password"`
-	Yes               bool   `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
-	MinimumSignatures uint32 `long:"min-signatures" short:"m" description:"Minimum required signatures" default:"1"`
-	NumPrivateKeys    uint32 `long:"num-private-keys" short:"k" description:"Number of private keys" default:"1"`
-	NumPublicKeys     uint32 `long:"num-public-keys" short:"n" description:"Total number of keys" default:"1"`
-	ECDSA             bool   `long:"ecdsa" description:"Create an ECDSA wallet"`
-	Import            bool   `long:"import" short:"i" description:"Import private keys (as opposed to generating them)"`
+	KeysFile           string   `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kaspawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Password           string   `long:"password" short:"p" description:"Wallet password"`
+	Yes                bool     `long:"yes" short:"y" description:"Assume \"yes\" to all questions"`
+	MinimumSignatures  uint32   `long:"min-signatures" short:"m" description:"Minimum required signatures" default:"1"`
+	NumPrivateKeys     uint32   `long:"num-private-keys" short:"k" description:"Number of private keys" default:"1"`
+	NumPublicKeys      uint32   `long:"num-public-keys" short:"n" description:"Total number of keys" default:"1"`
+	ECDSA              bool     `long:"ecdsa" description:"Create an ECDSA wallet"`
+	Import             bool     `long:"import" short:"i" description:"Import private keys (as opposed to generating them)"`
+	WatchOnly          bool     `long:"watch-only" description:"Create a watch-only wallet that holds no private keys and can't sign transactions"`
+	ExtendedPublicKeys []string `long:"xpub" description:"Extended public key of a watch-only wallet. Repeat multiple times (adding --xpub before each) to create a multisig wallet. If omitted, the keys are asked for interactively"`
 	config.NetworkFlags
 }
 
@@ -61,8 +73,9 @@ type sendConfig struct {
 	KeysFile                 string   `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kaspawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
 	Password                 string   `long:"password" short:"p" description:"Wallet password"`
 	DaemonAddress            string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
-	ToAddress                string   `long:"to-address" short:"t" description:"The public address to send Kaspa to" required:"true"`
-	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address to send Kaspa from. Repeat multiple times (adding -a before each) to accept several addresses" required:"false"`
+	ToAddress                string   `long:"to-address" short:"t" description:"The public address or contact name to send Kaspa to"`
+	PaymentsFile             string   `long:"payments-file" description:"A CSV (address,amount per line) or JSON ([{\"address\": ..., \"amount\": ...}]) file of payments to send, which are split between several transactions if needed, with amounts in Kaspa (mutually exclusive with --to-address)"`
+	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address (or address label) to send Kaspa from. Repeat multiple times (adding -a before each) to accept several addresses" required:"false"`
 	SendAmount               string   `long:"send-amount" short:"v" description:"An amount to send in Kaspa (e.g. 1234.12345678)"`
 	IsSendAll                bool     `long:"send-all" description:"Send all the Kaspa in the wallet (mutually exclusive with --send-amount). If --from-address was used, will send all only from the specified addresses."`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
@@ -81,8 +94,9 @@ type sweepConfig struct {
 
 type createUnsignedTransactionConfig struct {
 	DaemonAddress            string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
-	ToAddress                string   `long:"to-address" short:"t" description:"The public address to send Kaspa to" required:"true"`
-	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address to send Kaspa from. Use multiple times to accept several addresses" required:"false"`
+	ToAddress                string   `long:"to-address" short:"t" description:"The public address or contact name to send Kaspa to"`
+	PaymentsFile             string   `long:"payments-file" description:"A CSV (address,amount per line) or JSON ([{\"address\": ..., \"amount\": ...}]) file of payments to send, which are split between several transactions if needed, with amounts in Kaspa (mutually exclusive with --to-address)"`
+	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address (or address label) to send Kaspa from. Use multiple times to accept several addresses" required:"false"`
 	SendAmount               string   `long:"send-amount" short:"v" description:"An amount to send in Kaspa (e.g. 1234.12345678)"`
 	IsSendAll                bool     `long:"send-all" description:"Send all the Kaspa in the wallet (mutually exclusive with --send-amount)"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
@@ -95,15 +109,45 @@ type createUnsignedTransactionConfig struct {
 type signConfig struct {
 	KeysFile        string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kaspawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
 	Password        string `long:"password" short:"p" description:"Wallet password"`
-	Transaction     string `long:"transaction" short:"t" description:"The unsigned transaction(s) to sign on (encoded in hex)"`
-	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction(s) to sign on (encoded in hex)"`
+	Transaction     string `long:"transaction" short:"t" description:"The unsigned transaction(s) to sign on (encoded in hex or as a PSKT)"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction(s) to sign on (encoded in hex or as a PSKT)"`
 	config.NetworkFlags
 }
 
 type broadcastConfig struct {
 	DaemonAddress    string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
-	Transactions     string `long:"transaction" short:"t" description:"The signed transaction to broadcast (encoded in hex)"`
-	TransactionsFile string `long:"transaction-file" short:"F" description:"The file containing the unsigned transaction to sign on (encoded in hex)"`
+	Transactions     string `long:"transaction" short:"t" description:"The signed transaction to broadcast (encoded in hex or as a finalized PSKT)"`
+	TransactionsFile string `long:"transaction-file" short:"F" description:"The file containing the signed transaction to broadcast (encoded in hex or as a finalized PSKT)"`
+	config.NetworkFlags
+}
+
+type exportPSKTConfig struct {
+	KeysFile        string `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kaspawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
+	Transaction     string `long:"transaction" short:"t" description:"The transaction(s) to convert (encoded in hex)"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the transaction(s) to convert (encoded in hex)"`
+	config.NetworkFlags
+}
+
+type importPSKTConfig struct {
+	Transaction     string `long:"transaction" short:"t" description:"The PSKT to convert"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the PSKT to convert"`
+	config.NetworkFlags
+}
+
+type combineConfig struct {
+	TransactionFiles []string `long:"transaction-file" short:"F" description:"A file containing a PSKT signed by one of the cosigners. Repeat multiple times (adding -F before each) to combine several PSKTs"`
+	config.NetworkFlags
+}
+
+type finalizeConfig struct {
+	Transaction     string `long:"transaction" short:"t" description:"The fully signed PSKT to finalize"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the fully signed PSKT to finalize"`
+	config.NetworkFlags
+}
+
+type inspectConfig struct {
+	Transaction     string `long:"transaction" short:"t" description:"The transaction(s) to inspect (encoded in hex or as a PSKT)"`
+	TransactionFile string `long:"transaction-file" short:"F" description:"The file containing the transaction(s) to inspect (encoded in hex or as a PSKT)"`
 	config.NetworkFlags
 }
 
@@ -120,7 +164,40 @@ type showAddressesConfig struct {
 	config.NetworkFlags
 }
 
+type historyConfig struct {
+	DaemonAddress string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Addresses     []string `long:"address" short:"a" description:"Show only transactions that pay to or spend from this address (or address label). Repeat multiple times (adding -a before each) to accept several addresses"`
+	Verbose       bool     `long:"verbose" short:"v" description:"Verbose: show the addresses and amounts of each transaction"`
+	config.NetworkFlags
+}
+
 type newAddressConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Label         string `long:"label" short:"l" description:"A label for the new address, which can be used instead of the address in send and history"`
+	config.NetworkFlags
+}
+
+type labelAddressConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Address       string `long:"address" short:"a" description:"The wallet address to label" required:"true"`
+	Label         string `long:"label" short:"l" description:"The label of the address. If omitted, the current label is removed"`
+	config.NetworkFlags
+}
+
+type addContactConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Name          string `long:"name" short:"n" description:"The name of the contact, which can be used instead of its address in send" required:"true"`
+	Address       string `long:"address" short:"a" description:"The address of the contact" required:"true"`
+	config.NetworkFlags
+}
+
+type removeContactConfig struct {
+	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
+	Name          string `long:"name" short:"n" description:"The name of the contact to remove" required:"true"`
+	config.NetworkFlags
+}
+
+type showContactsConfig struct {
 	DaemonAddress string `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
 	config.NetworkFlags
 }
@@ -132,7 +209,10 @@ type startDaemonConfig struct {
 	Listen    string `long:"listen" short:"l" description:"Address to listen on (default: 0.0.0.0:8082)"`
 	Timeout   uint32 `long:"wait-timeout" short:"w" description:"Waiting timeout for RPC calls, seconds (default: 30 s)"`
 	Profile   string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+
+	MempoolRefreshInterval uint32 `long:"mempool-refresh-interval" description:"Interval for refreshing the wallet transactions in the mempool, seconds. It's also refreshed after every broadcast (default: 60 s)"`
 	config.NetworkFlags
+	config.RPCClientFlags
 }
 
 type dumpUnencryptedDataConfig struct {
@@ -145,7 +225,7 @@ type dumpUnencryptedDataConfig struct {
 type bumpFeeUnsignedConfig struct {
 	TxID                     string   `long:"txid" short:"i" description:"The transaction ID to bump the fee for"`
 	DaemonAddress            string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
-	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address to send Kaspa from. Use multiple times to accept several addresses" required:"false"`
+	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address (or address label) to send Kaspa from. Use multiple times to accept several addresses" required:"false"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
 	MaxFeeRate               float64  `long:"max-fee-rate" short:"m" description:"Maximum fee rate in Sompi/gram to use for the transaction. The wallet will take the minimum between the fee rate estimate from the connected node and this value."`
 	FeeRate                  float64  `long:"fee-rate" short:"r" description:"Fee rate in Sompi/gram to use for the transaction. This option will override any fee estimate from the connected node."`
@@ -158,7 +238,7 @@ type bumpFeeConfig struct {
 	KeysFile                 string   `long:"keys-file" short:"f" description:"Keys file location (default: ~/.kaspawallet/keys.json (*nix), %USERPROFILE%\\AppData\\Local\\Kaspawallet\\key.json (Windows))"`
 	Password                 string   `long:"password" short:"p" description:"Wallet password"`
 	DaemonAddress            string   `long:"daemonaddress" short:"d" description:"Wallet daemon server to connect to"`
-	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address to send Kaspa from. Repeat multiple times (adding -a before each) to accept several addresses" required:"false"`
+	FromAddresses            []string `long:"from-address" short:"a" description:"Specific public address (or address label) to send Kaspa from. Repeat multiple times (adding -a before each) to accept several addresses" required:"false"`
 	UseExistingChangeAddress bool     `long:"use-existing-change-address" short:"u" description:"Will use an existing change address (in case no change address was ever used, it will use a new one)"`
 	MaxFeeRate               float64  `long:"max-fee-rate" short:"m" description:"Maximum fee rate in Sompi/gram to use for the transaction. The wallet will take the minimum between the fee rate estimate from the connected node and this value."`
 	FeeRate                  float64  `long:"fee-rate" short:"r" description:"Fee rate in Sompi/gram to use for the transaction. This option will override any fee estimate from the connected node."`
@@ -239,6 +319,41 @@ func parseCommandLine() (subCommand string, config interface{}) {
 	parser.AddCommand(bumpFeeUnsignedSubCmd, "Bump transaction fee (without signing)", "Bump transaction fee (without signing)", bumpFeeUnsignedConf)
 	parser.AddCommand(broadcastReplacementSubCmd, "Broadcast the given transaction replacement",
 		"Broadcast the given transaction replacement", broadcastConf)
+	historyConf := &historyConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(historySubCmd, "Shows the transaction history of the current wallet",
+		"Shows the transactions that paid to or spent from the current wallet, as recorded by the wallet daemon", historyConf)
+
+	exportPSKTConf := &exportPSKTConfig{}
+	parser.AddCommand(exportPSKTSubCmd, "Convert hex encoded transactions to a PSKT file",
+		"Convert the hex encoded transactions created by create-unsigned-transaction or sign to a PSKT "+
+			"(Partially Signed Kaspa Transaction) file", exportPSKTConf)
+	importPSKTConf := &importPSKTConfig{}
+	parser.AddCommand(importPSKTSubCmd, "Convert a PSKT file to hex encoded transactions",
+		"Convert a PSKT (Partially Signed Kaspa Transaction) file to the hex encoded transactions used by older wallet versions", importPSKTConf)
+	combineConf := &combineConfig{}
+	parser.AddCommand(combineSubCmd, "Combine the signatures of several PSKT files",
+		"Combine the signatures of several PSKT files of the same transactions, each signed by different cosigners", combineConf)
+	finalizeConf := &finalizeConfig{}
+	parser.AddCommand(finalizeSubCmd, "Finalize a fully signed PSKT file",
+		"Build the broadcastable transactions of a fully signed PSKT file", finalizeConf)
+	inspectConf := &inspectConfig{}
+	parser.AddCommand(inspectSubCmd, "Inspect the given transactions and their signing status",
+		"Inspect the given transactions (encoded in hex or as a PSKT) and show which signatures they are missing", inspectConf)
+
+	labelAddressConf := &labelAddressConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(labelAddressSubCmd, "Set or remove the label of a wallet address",
+		"Set or remove the label of a wallet address. Labels are shown by show-addresses, balance and history, "+
+			"and can be used instead of the address in send and history", labelAddressConf)
+	addContactConf := &addContactConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(addContactSubCmd, "Add a recipient address to the contacts book",
+		"Add a recipient address to the contacts book, or replace the address of an existing contact. "+
+			"The contact name can be used instead of the address in send", addContactConf)
+	removeContactConf := &removeContactConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(removeContactSubCmd, "Remove a contact from the contacts book",
+		"Remove a contact from the contacts book", removeContactConf)
+	showContactsConf := &showContactsConfig{DaemonAddress: defaultListen}
+	parser.AddCommand(showContactsSubCmd, "Shows the contacts book",
+		"Shows the contacts book", showContactsConf)
 
 	_, err := parser.Parse()
 	if err != nil {
@@ -258,6 +373,10 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		if err != nil {
 			printErrorAndExit(err)
 		}
+		err = validateCreateConfig(createConf)
+		if err != nil {
+			printErrorAndExit(err)
+		}
 		config = createConf
 	case balanceSubCmd:
 		combineNetworkFlags(&balanceConf.NetworkFlags, &cfg.NetworkFlags)
@@ -380,16 +499,85 @@ func parseCommandLine() (subCommand string, config interface{}) {
 		}
 
 		config = bumpFeeUnsignedConf
+	case historySubCmd:
+		combineNetworkFlags(&historyConf.NetworkFlags, &cfg.NetworkFlags)
+		err := historyConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = historyConf
+	case exportPSKTSubCmd:
+		combineNetworkFlags(&exportPSKTConf.NetworkFlags, &cfg.NetworkFlags)
+		err := exportPSKTConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = exportPSKTConf
+	case importPSKTSubCmd:
+		combineNetworkFlags(&importPSKTConf.NetworkFlags, &cfg.NetworkFlags)
+		err := importPSKTConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = importPSKTConf
+	case combineSubCmd:
+		combineNetworkFlags(&combineConf.NetworkFlags, &cfg.NetworkFlags)
+		err := combineConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = combineConf
+	case finalizeSubCmd:
+		combineNetworkFlags(&finalizeConf.NetworkFlags, &cfg.NetworkFlags)
+		err := finalizeConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = finalizeConf
+	case inspectSubCmd:
+		combineNetworkFlags(&inspectConf.NetworkFlags, &cfg.NetworkFlags)
+		err := inspectConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = inspectConf
+	case labelAddressSubCmd:
+		combineNetworkFlags(&labelAddressConf.NetworkFlags, &cfg.NetworkFlags)
+		err := labelAddressConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = labelAddressConf
+	case addContactSubCmd:
+		combineNetworkFlags(&addContactConf.NetworkFlags, &cfg.NetworkFlags)
+		err := addContactConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = addContactConf
+	case removeContactSubCmd:
+		combineNetworkFlags(&removeContactConf.NetworkFlags, &cfg.NetworkFlags)
+		err := removeContactConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = removeContactConf
+	case showContactsSubCmd:
+		combineNetworkFlags(&showContactsConf.NetworkFlags, &cfg.NetworkFlags)
+		err := showContactsConf.ResolveNetwork(parser)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		config = showContactsConf
 	}
 
 	return parser.Command.Active.Name, config
 }
 
 func validateCreateUnsignedTransactionConf(conf *createUnsignedTransactionConfig) error {
-	if (!conf.IsSendAll && conf.SendAmount == "") ||
-		(conf.IsSendAll && conf.SendAmount != "") {
-
-		return errors.New("exactly one of '--send-amount' or '--all' must be specified")
+	err := validatePaymentFlags(conf.ToAddress, conf.PaymentsFile, conf.SendAmount, conf.IsSendAll)
+	if err != nil {
+		return err
 	}
 
 	if conf.MaxFeeRate < 0 {
@@ -407,13 +595,56 @@ func validateCreateUnsignedTransactionConf(conf *createUnsignedTransactionConfig
 	return nil
 }
 
-func validateSendConfig(conf *sendConfig) error {
-	if (!conf.IsSendAll && conf.SendAmount == "") ||
-		(conf.IsSendAll && conf.SendAmount != "") {
+func validatePaymentFlags(toAddress string, paymentsFile string, sendAmount string, isSendAll bool) error {
+	if (toAddress == "") == (paymentsFile == "") {
+		return errors.New("exactly one of '--to-address' or '--payments-file' must be specified")
+	}
+
+	if paymentsFile != "" {
+		if sendAmount != "" || isSendAll {
+			return errors.New("'--send-amount' and '--send-all' cannot be used with '--payments-file'")
+		}
+		return nil
+	}
+
+	if (!isSendAll && sendAmount == "") ||
+		(isSendAll && sendAmount != "") {
 
 		return errors.New("exactly one of '--send-amount' or '--all' must be specified")
 	}
 
+	return nil
+}
+
+func validateCreateConfig(conf *createConfig) error {
+	if !conf.WatchOnly {
+		if len(conf.ExtendedPublicKeys) > 0 {
+			return errors.New("'--xpub' can only be used together with '--watch-only'")
+		}
+		return nil
+	}
+
+	if conf.Import {
+		return errors.New("'--import' and '--watch-only' cannot be used together")
+	}
+
+	numPublicKeys := conf.NumPublicKeys
+	if len(conf.ExtendedPublicKeys) > 0 {
+		numPublicKeys = uint32(len(conf.ExtendedPublicKeys))
+	}
+	if conf.MinimumSignatures == 0 || conf.MinimumSignatures > numPublicKeys {
+		return errors.Errorf("'--min-signatures' must be between 1 and the number of public keys (%d)", numPublicKeys)
+	}
+
+	return nil
+}
+
+func validateSendConfig(conf *sendConfig) error {
+	err := validatePaymentFlags(conf.ToAddress, conf.PaymentsFile, conf.SendAmount, conf.IsSendAll)
+	if err != nil {
+		return err
+	}
+
 	if conf.MaxFeeRate < 0 {
 		return errors.New("--max-fee-rate must be a positive number")
 	}
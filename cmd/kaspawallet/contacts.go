@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+)
+
+func addContact(conf *addContactConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.AddContact(ctx, &pb.AddContactRequest{Contact: &pb.Contact{Name: conf.Name, Address: conf.Address}})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved contact '%s' with address %s\n", conf.Name, conf.Address)
+	return nil
+}
+
+func removeContact(conf *removeContactConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.RemoveContact(ctx, &pb.RemoveContactRequest{Name: conf.Name})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed contact '%s'\n", conf.Name)
+	return nil
+}
+
+func showContacts(conf *showContactsConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	response, err := daemonClient.GetContacts(ctx, &pb.GetContactsRequest{})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Contacts (%d):\n", len(response.Contacts))
+	for _, contact := range response.Contacts {
+		fmt.Printf("%s\t%s\n", contact.Address, contact.Name)
+	}
+	return nil
+}
@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
-	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/bip32"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
 	"github.com/pkg/errors"
 
@@ -17,42 +16,66 @@ func create(conf *createConfig) error {
 	var encryptedMnemonics []*keys.EncryptedMnemonic
 	var signerExtendedPublicKeys []string
 	var err error
-	isMultisig := conf.NumPublicKeys > 1
-	if !conf.Import {
-		encryptedMnemonics, signerExtendedPublicKeys, err = keys.CreateMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
-	} else {
-		encryptedMnemonics, signerExtendedPublicKeys, err = keys.ImportMnemonics(conf.NetParams(), conf.NumPrivateKeys, conf.Password, isMultisig)
-	}
-	if err != nil {
-		return err
-	}
 
-	for i, extendedPublicKey := range signerExtendedPublicKeys {
-		fmt.Printf("Extended public key of mnemonic #%d:\n%s\n\n", i+1, extendedPublicKey)
+	numPrivateKeys := conf.NumPrivateKeys
+	numPublicKeys := conf.NumPublicKeys
+	if conf.WatchOnly {
+		// A watch-only wallet holds no private keys, so all of its keys are given as extended public keys
+		numPrivateKeys = 0
+		if len(conf.ExtendedPublicKeys) > 0 {
+			numPublicKeys = uint32(len(conf.ExtendedPublicKeys))
+		}
 	}
 
-	fmt.Printf("Notice the above is neither a secret key to your wallet " +
-		"(use \"kaspawallet dump-unencrypted-data\" to see a secret seed phrase) " +
-		"nor a wallet public address (use \"kaspawallet new-address\" to create and see one)\n\n")
+	isMultisig := numPublicKeys > 1
+	if !conf.WatchOnly {
+		if !conf.Import {
+			encryptedMnemonics, signerExtendedPublicKeys, err = keys.CreateMnemonics(conf.NetParams(), numPrivateKeys, conf.Password, isMultisig)
+		} else {
+			encryptedMnemonics, signerExtendedPublicKeys, err = keys.ImportMnemonics(conf.NetParams(), numPrivateKeys, conf.Password, isMultisig)
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, extendedPublicKey := range signerExtendedPublicKeys {
+			fmt.Printf("Extended public key of mnemonic #%d:\n%s\n\n", i+1, extendedPublicKey)
+		}
 
-	extendedPublicKeys := make([]string, conf.NumPrivateKeys, conf.NumPublicKeys)
+		fmt.Printf("Notice the above is neither a secret key to your wallet " +
+			"(use \"kaspawallet dump-unencrypted-data\" to see a secret seed phrase) " +
+			"nor a wallet public address (use \"kaspawallet new-address\" to create and see one)\n\n")
+	}
+
+	extendedPublicKeys := make([]string, numPrivateKeys, numPublicKeys)
 	copy(extendedPublicKeys, signerExtendedPublicKeys)
 	reader := bufio.NewReader(os.Stdin)
-	for i := conf.NumPrivateKeys; i < conf.NumPublicKeys; i++ {
-		fmt.Printf("Enter public key #%d here:\n", i+1)
-		extendedPublicKey, err := utils.ReadLine(reader)
-		if err != nil {
-			return err
+	for i := numPrivateKeys; i < numPublicKeys; i++ {
+		var extendedPublicKey string
+		if len(conf.ExtendedPublicKeys) > 0 {
+			extendedPublicKey = conf.ExtendedPublicKeys[i]
+		} else {
+			fmt.Printf("Enter public key #%d here:\n", i+1)
+			line, err := utils.ReadLine(reader)
+			if err != nil {
+				return err
+			}
+			fmt.Println()
+			extendedPublicKey = string(line)
 		}
 
-		_, err = bip32.DeserializeExtendedKey(string(extendedPublicKey))
+		err = libkaspawallet.ValidateExtendedPublicKey(conf.NetParams(), extendedPublicKey)
 		if err != nil {
-			return errors.Wrapf(err, "%s is invalid extended public key", string(extendedPublicKey))
+			return err
 		}
 
-		fmt.Println()
+		for _, otherExtendedPublicKey := range extendedPublicKeys {
+			if extendedPublicKey == otherExtendedPublicKey {
+				return errors.Errorf("the extended public key %s was given more than once", extendedPublicKey)
+			}
+		}
 
-		extendedPublicKeys = append(extendedPublicKeys, string(extendedPublicKey))
+		extendedPublicKeys = append(extendedPublicKeys, extendedPublicKey)
 	}
 
 	// For a read only wallet the cosigner index is 0
@@ -89,5 +112,9 @@ func create(conf *createConfig) error {
 	}
 
 	fmt.Printf("Wrote the keys into %s\n", file.Path())
+	if file.IsWatchOnly() {
+		fmt.Println("This is a watch-only wallet: it can show addresses and balances and create unsigned transactions, " +
+			"but the transactions must be signed with a wallet that holds the private keys")
+	}
 	return nil
 }
@@ -23,13 +23,21 @@ func createUnsignedTransaction(conf *createUnsignedTransactionConfig) error {
 
 	var sendAmountSompi uint64
 
-	if !conf.IsSendAll {
+	if !conf.IsSendAll && conf.PaymentsFile == "" {
 		sendAmountSompi, err = utils.KasToSompi(conf.SendAmount)
 		if err != nil {
 			return err
 		}
 	}
 
+	var payments []*pb.Payment
+	if conf.PaymentsFile != "" {
+		payments, err = readPaymentsFile(conf.PaymentsFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	var feePolicy *pb.FeePolicy
 	if conf.FeeRate > 0 {
 		feePolicy = &pb.FeePolicy{
@@ -51,6 +59,7 @@ func createUnsignedTransaction(conf *createUnsignedTransactionConfig) error {
 		From:                     conf.FromAddresses,
 		Address:                  conf.ToAddress,
 		Amount:                   sendAmountSompi,
+		Payments:                 payments,
 		IsSendAll:                conf.IsSendAll,
 		UseExistingChangeAddress: conf.UseExistingChangeAddress,
 		FeePolicy:                feePolicy,
@@ -53,6 +53,20 @@ func (s *server) ShowAddresses(_ context.Context, request *pb.ShowAddressesReque
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
 
+	addresses, err := s.externalAddresses()
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, len(addresses))
+	for i, address := range addresses {
+		labels[i] = s.addressBook.label(address)
+	}
+
+	return &pb.ShowAddressesResponse{Address: addresses, Label: labels}, nil
+}
+
+// externalAddresses returns all the addresses that were created by NewAddress
+func (s *server) externalAddresses() ([]string, error) {
 	addresses := make([]string, s.keysFile.LastUsedExternalIndex())
 	for i := uint32(1); i <= s.keysFile.LastUsedExternalIndex(); i++ {
 		walletAddr := &walletAddress{
@@ -60,15 +74,13 @@ func (s *server) ShowAddresses(_ context.Context, request *pb.ShowAddressesReque
 			cosignerIndex: s.keysFile.CosignerIndex,
 			keyChain:      libkaspawallet.ExternalKeychain,
 		}
-		path := s.walletAddressPath(walletAddr)
-		address, err := libkaspawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
+		address, err := s.walletAddressString(walletAddr)
 		if err != nil {
 			return nil, err
 		}
-		addresses[i-1] = address.String()
+		addresses[i-1] = address
 	}
-
-	return &pb.ShowAddressesResponse{Address: addresses}, nil
+	return addresses, nil
 }
 
 func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*pb.NewAddressResponse, error) {
@@ -79,6 +91,17 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
 
+	// Validate the label before creating the address, so an invalid label doesn't skip an address index
+	if request.Label != "" {
+		err := validateLabel(request.Label)
+		if err != nil {
+			return nil, err
+		}
+		if labeledAddress, ok := s.addressBook.addressByLabel(request.Label); ok {
+			return nil, errors.Errorf("the label '%s' is already used for %s", request.Label, labeledAddress)
+		}
+	}
+
 	err := s.keysFile.SetLastUsedExternalIndex(s.keysFile.LastUsedExternalIndex() + 1)
 	if err != nil {
 		return nil, err
@@ -89,6 +112,9 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 		return nil, err
 	}
 
+	// Let syncLoop watch the addresses after the new one
+	s.forceSync()
+
 	walletAddr := &walletAddress{
 		index:         s.keysFile.LastUsedExternalIndex(),
 		cosignerIndex: s.keysFile.CosignerIndex,
@@ -100,9 +126,71 @@ func (s *server) NewAddress(_ context.Context, request *pb.NewAddressRequest) (*
 		return nil, err
 	}
 
+	if request.Label != "" {
+		err := s.addressBook.setLabel(address.String(), request.Label)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &pb.NewAddressResponse{Address: address.String()}, nil
 }
 
+func (s *server) SetAddressLabel(_ context.Context, request *pb.SetAddressLabelRequest) (*pb.SetAddressLabelResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	isWalletAddress, err := s.isWalletAddress(request.Address)
+	if err != nil {
+		return nil, err
+	}
+	if !isWalletAddress {
+		return nil, errors.Errorf("%s is not an address of this wallet", request.Address)
+	}
+
+	err = s.addressBook.setLabel(request.Address, request.Label)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetAddressLabelResponse{}, nil
+}
+
+// isWalletAddress returns whether the given address is either a synced wallet address, or was created by NewAddress
+// and wasn't synced yet
+func (s *server) isWalletAddress(address string) (bool, error) {
+	if _, ok := s.addressSet[address]; ok {
+		return true, nil
+	}
+
+	externalAddresses, err := s.externalAddresses()
+	if err != nil {
+		return false, err
+	}
+	for _, externalAddress := range externalAddresses {
+		if externalAddress == address {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolveWalletAddress returns the wallet address with the given label, or addressOrLabel itself if there's no such label
+func (s *server) resolveWalletAddress(addressOrLabel string) string {
+	if address, ok := s.addressBook.addressByLabel(addressOrLabel); ok {
+		return address
+	}
+	return addressOrLabel
+}
+
+// addressLabel returns the label of the given wallet address, or the name of the contact with the given
+// address, or an empty string if it's neither
+func (s *server) addressLabel(address string) string {
+	if label := s.addressBook.label(address); label != "" {
+		return label
+	}
+	return s.addressBook.contactName(address)
+}
+
 func (s *server) walletAddressString(wAddr *walletAddress) (string, error) {
 	path := s.walletAddressPath(wAddr)
 	addr, err := libkaspawallet.Address(s.params, s.keysFile.ExtendedPublicKeys, s.keysFile.MinimumSignatures, path, s.keysFile.ECDSA)
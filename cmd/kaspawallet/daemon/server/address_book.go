@@ -0,0 +1,238 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/pkg/errors"
+)
+
+// maxLabelLength is the maximum length, in bytes, of address labels and contact names
+const maxLabelLength = 100
+
+// addressBook holds the labels of the wallet's own addresses and the contacts book, which
+// names recipient addresses. It's kept in a JSON file next to the keys file, and saved on
+// every change.
+type addressBook struct {
+	path string
+
+	// labels maps wallet addresses to their labels
+	labels map[string]string
+
+	// contacts maps contact names to their addresses
+	contacts map[string]string
+
+	// contactNames maps contact addresses to their names. If several contacts share an address,
+	// the first name in sorted order is used
+	contactNames map[string]string
+}
+
+type addressBookJSON struct {
+	Labels   map[string]string `json:"labels"`
+	Contacts map[string]string `json:"contacts"`
+}
+
+// newAddressBook reads the address book in the given path, or returns an empty one if it doesn't exist yet
+func newAddressBook(path string) (*addressBook, error) {
+	book := &addressBook{
+		path:         path,
+		labels:       make(map[string]string),
+		contacts:     make(map[string]string),
+		contactNames: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return book, nil
+		}
+		return nil, err
+	}
+
+	bookJSON := &addressBookJSON{}
+	err = json.Unmarshal(data, bookJSON)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the address book %s", path)
+	}
+	for address, label := range bookJSON.Labels {
+		book.labels[address] = label
+	}
+	for name, address := range bookJSON.Contacts {
+		book.contacts[name] = address
+		if contactName, ok := book.contactNames[address]; !ok || name < contactName {
+			book.contactNames[address] = name
+		}
+	}
+	return book, nil
+}
+
+// save writes the address book to a temporary file and then renames it, so a crash
+// never leaves a partially written address book behind
+func (ab *addressBook) save() error {
+	data, err := json.MarshalIndent(&addressBookJSON{Labels: ab.labels, Contacts: ab.contacts}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(ab.path), 0700)
+	if err != nil {
+		return err
+	}
+	temporaryPath := ab.path + ".tmp"
+	err = os.WriteFile(temporaryPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, ab.path)
+}
+
+// validateLabel makes sure the given label or contact name can't be confused with an address
+func validateLabel(label string) error {
+	if label == "" {
+		return errors.New("the label must not be empty")
+	}
+	if label != strings.TrimSpace(label) {
+		return errors.Errorf("the label '%s' must not start or end with whitespace", label)
+	}
+	if len(label) > maxLabelLength {
+		return errors.Errorf("the label '%s' is longer than %d bytes", label, maxLabelLength)
+	}
+	// Addresses always contain a ':' between their prefix and payload
+	if strings.Contains(label, ":") {
+		return errors.Errorf("the label '%s' must not contain ':'", label)
+	}
+	return nil
+}
+
+// setLabel sets the label of the given wallet address. An empty label removes the current one.
+// If the address book can't be saved, the label is left unchanged.
+func (ab *addressBook) setLabel(address string, label string) error {
+	if label != "" {
+		err := validateLabel(label)
+		if err != nil {
+			return err
+		}
+		if labeledAddress, ok := ab.addressByLabel(label); ok && labeledAddress != address {
+			return errors.Errorf("the label '%s' is already used for %s", label, labeledAddress)
+		}
+	}
+
+	previousLabel, hadLabel := ab.labels[address]
+	if label == "" {
+		delete(ab.labels, address)
+	} else {
+		ab.labels[address] = label
+	}
+
+	err := ab.save()
+	if err != nil {
+		if hadLabel {
+			ab.labels[address] = previousLabel
+		} else {
+			delete(ab.labels, address)
+		}
+		return err
+	}
+	return nil
+}
+
+// label returns the label of the given wallet address, or an empty string if it has none
+func (ab *addressBook) label(address string) string {
+	return ab.labels[address]
+}
+
+func (ab *addressBook) addressByLabel(label string) (string, bool) {
+	for address, addressLabel := range ab.labels {
+		if addressLabel == label {
+			return address, true
+		}
+	}
+	return "", false
+}
+
+// addContact adds a contact with the given name, or replaces the address of an existing one.
+// If the address book can't be saved, the contacts are left unchanged.
+func (ab *addressBook) addContact(name string, address string) error {
+	err := validateLabel(name)
+	if err != nil {
+		return err
+	}
+
+	previousAddress, hadContact := ab.contacts[name]
+	ab.contacts[name] = address
+	err = ab.save()
+	if err != nil {
+		if hadContact {
+			ab.contacts[name] = previousAddress
+		} else {
+			delete(ab.contacts, name)
+		}
+		return err
+	}
+
+	if hadContact {
+		ab.updateContactName(previousAddress)
+	}
+	ab.updateContactName(address)
+	return nil
+}
+
+// removeContact removes the contact with the given name. If the address book can't be saved,
+// the contact is kept.
+func (ab *addressBook) removeContact(name string) error {
+	address, ok := ab.contacts[name]
+	if !ok {
+		return errors.Errorf("there's no contact named '%s'", name)
+	}
+
+	delete(ab.contacts, name)
+	err := ab.save()
+	if err != nil {
+		ab.contacts[name] = address
+		return err
+	}
+
+	ab.updateContactName(address)
+	return nil
+}
+
+// updateContactName updates the name that contactName returns for the given address
+// after the contacts with this address changed
+func (ab *addressBook) updateContactName(address string) {
+	contactName := ""
+	for name, contactAddress := range ab.contacts {
+		if contactAddress == address && (contactName == "" || name < contactName) {
+			contactName = name
+		}
+	}
+
+	if contactName == "" {
+		delete(ab.contactNames, address)
+		return
+	}
+	ab.contactNames[address] = contactName
+}
+
+func (ab *addressBook) contact(name string) (string, bool) {
+	address, ok := ab.contacts[name]
+	return address, ok
+}
+
+// contactName returns the name of the contact with the given address, or an empty string if there's none
+func (ab *addressBook) contactName(address string) string {
+	return ab.contactNames[address]
+}
+
+// sortedContacts returns all the contacts sorted by their name
+func (ab *addressBook) sortedContacts() []*pb.Contact {
+	contacts := make([]*pb.Contact, 0, len(ab.contacts))
+	for name, address := range ab.contacts {
+		contacts = append(contacts, &pb.Contact{Name: name, Address: address})
+	}
+	sort.Slice(contacts, func(i, j int) bool { return contacts[i].Name < contacts[j].Name })
+	return contacts
+}
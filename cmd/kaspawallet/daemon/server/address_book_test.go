@@ -0,0 +1,103 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddressBook(t *testing.T) {
+	datadir, err := os.MkdirTemp("", "TestAddressBook")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %s", err)
+	}
+	defer os.RemoveAll(datadir)
+
+	path := filepath.Join(datadir, "keys.json.addressbook")
+	book, err := newAddressBook(path)
+	if err != nil {
+		t.Fatalf("newAddressBook: %s", err)
+	}
+
+	const firstAddress = "kaspa:qqkqkzjvr7zwxxmjxjkmxxdwju9kjs6e9u82uh59z07vgaks6gg62v8707g73"
+	const secondAddress = "kaspa:qr0lr4ml9fn3chekrqmjdkergxl93l4wrk3dankcgvjq776s9wn9jkdskewva"
+
+	err = book.setLabel(firstAddress, "customer 1 deposits")
+	if err != nil {
+		t.Fatalf("setLabel: %s", err)
+	}
+	err = book.setLabel(secondAddress, "customer 1 deposits")
+	if err == nil {
+		t.Fatalf("expected a duplicate label to be rejected")
+	}
+	for _, invalidLabel := range []string{" padded", "kaspa:label", string(make([]byte, maxLabelLength+1))} {
+		err = book.setLabel(secondAddress, invalidLabel)
+		if err == nil {
+			t.Fatalf("expected the label '%s' to be rejected", invalidLabel)
+		}
+	}
+
+	err = book.addContact("exchange", secondAddress)
+	if err != nil {
+		t.Fatalf("addContact: %s", err)
+	}
+	err = book.addContact("old exchange", firstAddress)
+	if err != nil {
+		t.Fatalf("addContact: %s", err)
+	}
+	err = book.removeContact("old exchange")
+	if err != nil {
+		t.Fatalf("removeContact: %s", err)
+	}
+	err = book.removeContact("old exchange")
+	if err == nil {
+		t.Fatalf("expected removing a missing contact to fail")
+	}
+
+	// The address book is expected to survive a restart
+	book, err = newAddressBook(path)
+	if err != nil {
+		t.Fatalf("newAddressBook: %s", err)
+	}
+	if book.label(firstAddress) != "customer 1 deposits" {
+		t.Fatalf("unexpected label %s", book.label(firstAddress))
+	}
+	if address, ok := book.addressByLabel("customer 1 deposits"); !ok || address != firstAddress {
+		t.Fatalf("unexpected labeled address %s", address)
+	}
+	contacts := book.sortedContacts()
+	if len(contacts) != 1 || contacts[0].Name != "exchange" || contacts[0].Address != secondAddress {
+		t.Fatalf("unexpected contacts %v", contacts)
+	}
+	if book.contactName(secondAddress) != "exchange" {
+		t.Fatalf("unexpected contact name %s", book.contactName(secondAddress))
+	}
+
+	err = book.setLabel(firstAddress, "")
+	if err != nil {
+		t.Fatalf("setLabel: %s", err)
+	}
+	if book.label(firstAddress) != "" {
+		t.Fatalf("expected the label to be removed")
+	}
+
+	// A failed save leaves the address book unchanged
+	book.path = filepath.Join(datadir, "keys.json.addressbook", "not-a-directory")
+	err = book.setLabel(firstAddress, "customer 2 deposits")
+	if err == nil {
+		t.Fatalf("expected setLabel to fail when the address book can't be saved")
+	}
+	if book.label(firstAddress) != "" {
+		t.Fatalf("expected the label to be rolled back, got %s", book.label(firstAddress))
+	}
+	err = book.addContact("exchange", firstAddress)
+	if err == nil {
+		t.Fatalf("expected addContact to fail when the address book can't be saved")
+	}
+	if address, ok := book.contact("exchange"); !ok || address != secondAddress {
+		t.Fatalf("expected the contact to be rolled back, got %s", address)
+	}
+	if book.contactName(secondAddress) != "exchange" || book.contactName(firstAddress) != "" {
+		t.Fatalf("expected the contact names to be unchanged")
+	}
+}
@@ -52,6 +52,7 @@ func (s *server) GetBalance(_ context.Context, _ *pb.GetBalanceRequest) (*pb.Get
 			Address:   address.String(),
 			Available: balances.available,
 			Pending:   balances.pending,
+			Label:     s.addressBook.label(address.String()),
 		}
 		i++
 		available += balances.available
@@ -54,6 +54,11 @@ func (s *server) broadcast(transactions [][]byte, isDomain bool) ([]string, erro
 		for _, input := range tx.Inputs {
 			s.usedOutpoints[input.PreviousOutpoint] = time.Now()
 		}
+
+		err = s.recordBroadcastTransaction(tx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	s.forceSync()
@@ -66,6 +66,11 @@ func (s *server) broadcastReplacement(transactions [][]byte, isDomain bool) ([]s
 		for _, input := range tx.Inputs {
 			s.usedOutpoints[input.PreviousOutpoint] = time.Now()
 		}
+
+		err = s.recordBroadcastTransaction(tx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	s.forceSync()
@@ -76,7 +76,7 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 
 	var fromAddresses []*walletAddress
 	for _, from := range request.From {
-		fromAddress, exists := s.addressSet[from]
+		fromAddress, exists := s.addressSet[s.resolveWalletAddress(from)]
 		if !exists {
 			return nil, errors.Errorf("specified from address %s does not exists", from)
 		}
@@ -87,7 +87,7 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 	for outpoint := range outpointsToInputs {
 		allowUsed[outpoint] = struct{}{}
 	}
-	selectedUTXOs, spendValue, changeSompi, err := s.selectUTXOsWithPreselected([]*walletUTXO{maxUTXO}, allowUsed, domainTx.Outputs[0].Value, false, newFeeRate, maxFee, fromAddresses)
+	selectedUTXOs, spendValue, changeSompi, err := s.selectUTXOsWithPreselected([]*walletUTXO{maxUTXO}, allowUsed, nil, []uint64{domainTx.Outputs[0].Value}, false, newFeeRate, maxFee, fromAddresses)
 	if err != nil {
 		return nil, err
 	}
@@ -110,25 +110,26 @@ func (s *server) BumpFee(_ context.Context, request *pb.BumpFeeRequest) (*pb.Bum
 		Address: toAddress,
 		Amount:  spendValue,
 	}}
+	outputs := append([]*libkaspawallet.Payment{}, payments...)
 	if changeSompi > 0 {
 		changeAddress, _, err := s.changeAddress(request.UseExistingChangeAddress, fromAddresses)
 		if err != nil {
 			return nil, err
 		}
 
-		payments = append(payments, &libkaspawallet.Payment{
+		outputs = append(outputs, &libkaspawallet.Payment{
 			Address: changeAddress,
 			Amount:  changeSompi,
 		})
 	}
 	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
 		s.keysFile.MinimumSignatures,
-		payments, selectedUTXOs)
+		outputs, selectedUTXOs)
 	if err != nil {
 		return nil, err
 	}
 
-	unsignedTransactions, err := s.maybeAutoCompoundTransaction(unsignedTransaction, toAddress, changeAddress, changeWalletAddress, newFeeRate, maxFee)
+	unsignedTransactions, err := s.maybeAutoCompoundTransaction(unsignedTransaction, payments, changeAddress, changeWalletAddress, newFeeRate, maxFee, nil)
 	if err != nil {
 		return nil, err
 	}
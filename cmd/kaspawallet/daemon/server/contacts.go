@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/pkg/errors"
+)
+
+func (s *server) AddContact(_ context.Context, request *pb.AddContactRequest) (*pb.AddContactResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if request.Contact == nil {
+		return nil, errors.New("a contact is required")
+	}
+	address, err := util.DecodeAddress(request.Contact.Address, s.params.Prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid address for contact '%s'", request.Contact.Name)
+	}
+
+	err = s.addressBook.addContact(request.Contact.Name, address.String())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AddContactResponse{}, nil
+}
+
+func (s *server) RemoveContact(_ context.Context, request *pb.RemoveContactRequest) (*pb.RemoveContactResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := s.addressBook.removeContact(request.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RemoveContactResponse{}, nil
+}
+
+func (s *server) GetContacts(_ context.Context, _ *pb.GetContactsRequest) (*pb.GetContactsResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return &pb.GetContactsResponse{Contacts: s.addressBook.sortedContacts()}, nil
+}
+
+// resolveRecipientAddress decodes the given address, or the address of the contact with the given name
+func (s *server) resolveRecipientAddress(addressOrContactName string) (util.Address, error) {
+	address, err := util.DecodeAddress(addressOrContactName, s.params.Prefix)
+	if err == nil {
+		return address, nil
+	}
+
+	contactAddress, ok := s.addressBook.contact(addressOrContactName)
+	if !ok {
+		return nil, errors.Wrapf(err, "'%s' is neither a valid address nor a contact name", addressOrContactName)
+	}
+	return util.DecodeAddress(contactAddress, s.params.Prefix)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
@@ -31,7 +32,12 @@ func (s *server) CreateUnsignedTransactions(_ context.Context, request *pb.Creat
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	unsignedTransactions, err := s.createUnsignedTransactions(request.Address, request.Amount, request.IsSendAll,
+	payments, err := requestPayments(request.Address, request.Amount, request.Payments, request.IsSendAll)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedTransactions, err := s.createUnsignedTransactions(payments, request.IsSendAll,
 		request.From, request.UseExistingChangeAddress, request.FeePolicy)
 	if err != nil {
 		return nil, err
@@ -40,6 +46,22 @@ func (s *server) CreateUnsignedTransactions(_ context.Context, request *pb.Creat
 	return &pb.CreateUnsignedTransactionsResponse{UnsignedTransactions: unsignedTransactions}, nil
 }
 
+// requestPayments returns the payments of a request, which either has a single address and amount
+// or a list of payments
+func requestPayments(address string, amount uint64, payments []*pb.Payment, isSendAll bool) ([]*pb.Payment, error) {
+	if len(payments) == 0 {
+		return []*pb.Payment{{Address: address, Amount: amount}}, nil
+	}
+
+	if address != "" || amount != 0 {
+		return nil, errors.Errorf("a single address and amount cannot be specified together with a list of payments")
+	}
+	if isSendAll {
+		return nil, errors.Errorf("send all cannot be used with a list of payments")
+	}
+	return payments, nil
+}
+
 func (s *server) calculateFeeLimits(requestFeePolicy *pb.FeePolicy) (feeRate float64, maxFee uint64, err error) {
 	feeRate = minFeeRate
 	maxFee = math.MaxUint64
@@ -83,7 +105,7 @@ func (s *server) calculateFeeLimits(requestFeePolicy *pb.FeePolicy) (feeRate flo
 	return feeRate, maxFee, nil
 }
 
-func (s *server) createUnsignedTransactions(address string, amount uint64, isSendAll bool, fromAddressesString []string, useExistingChangeAddress bool, requestFeePolicy *pb.FeePolicy) ([][]byte, error) {
+func (s *server) createUnsignedTransactions(requestPayments []*pb.Payment, isSendAll bool, fromAddressesString []string, useExistingChangeAddress bool, requestFeePolicy *pb.FeePolicy) ([][]byte, error) {
 	if !s.isSynced() {
 		return nil, errors.Errorf("wallet daemon is not synced yet, %s", s.formatSyncStateReport())
 	}
@@ -93,16 +115,26 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 		return nil, err
 	}
 
-	// make sure address string is correct before proceeding to a
+	// make sure address strings are correct before proceeding to a
 	// potentially long UTXO refreshment operation
-	toAddress, err := util.DecodeAddress(address, s.params.Prefix)
-	if err != nil {
-		return nil, err
+	payments := make([]*libkaspawallet.Payment, len(requestPayments))
+	for i, requestPayment := range requestPayments {
+		toAddress, err := s.resolveRecipientAddress(requestPayment.Address)
+		if err != nil {
+			return nil, err
+		}
+		if !isSendAll && requestPayment.Amount == 0 {
+			return nil, errors.Errorf("the amount to send to %s must be positive", requestPayment.Address)
+		}
+		payments[i] = &libkaspawallet.Payment{
+			Address: toAddress,
+			Amount:  requestPayment.Amount,
+		}
 	}
 
 	var fromAddresses []*walletAddress
 	for _, from := range fromAddressesString {
-		fromAddress, exists := s.addressSet[from]
+		fromAddress, exists := s.addressSet[s.resolveWalletAddress(from)]
 		if !exists {
 			return nil, fmt.Errorf("specified from address %s does not exists", from)
 		}
@@ -114,7 +146,49 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 		return nil, err
 	}
 
-	selectedUTXOs, spendValue, changeSompi, err := s.selectUTXOs(amount, isSendAll, feeRate, maxFee, fromAddresses)
+	paymentBatches, err := s.paymentBatches(payments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every batch of payments is paid by its own transactions, and the UTXOs spent by
+	// the transactions of a batch aren't selected again for the next batches
+	spentOutpoints := make(map[externalapi.DomainOutpoint]struct{})
+	var unsignedTransactions [][]byte
+	for _, paymentBatch := range paymentBatches {
+		batchUnsignedTransactions, err := s.createUnsignedTransactionsForPayments(paymentBatch, isSendAll,
+			fromAddresses, changeAddress, changeWalletAddress, feeRate, maxFee, spentOutpoints)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, unsignedTransactionBytes := range batchUnsignedTransactions {
+			unsignedTransaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransactionBytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, input := range unsignedTransaction.Tx.Inputs {
+				spentOutpoints[input.PreviousOutpoint] = struct{}{}
+			}
+		}
+		unsignedTransactions = append(unsignedTransactions, batchUnsignedTransactions...)
+	}
+	return unsignedTransactions, nil
+}
+
+// createUnsignedTransactionsForPayments creates the unsigned transactions that pay the given payments,
+// without spending any of excludedOutpoints
+func (s *server) createUnsignedTransactionsForPayments(payments []*libkaspawallet.Payment, isSendAll bool,
+	fromAddresses []*walletAddress, changeAddress util.Address, changeWalletAddress *walletAddress,
+	feeRate float64, maxFee uint64, excludedOutpoints map[externalapi.DomainOutpoint]struct{}) ([][]byte, error) {
+
+	spendAmounts := make([]uint64, len(payments))
+	for i, payment := range payments {
+		spendAmounts[i] = payment.Amount
+	}
+
+	selectedUTXOs, spendValue, changeSompi, err := s.selectUTXOs(spendAmounts, isSendAll, feeRate, maxFee,
+		fromAddresses, excludedOutpoints)
 	if err != nil {
 		return nil, err
 	}
@@ -123,38 +197,49 @@ func (s *server) createUnsignedTransactions(address string, amount uint64, isSen
 		return nil, errors.Errorf("couldn't find funds to spend")
 	}
 
-	payments := []*libkaspawallet.Payment{{
-		Address: toAddress,
-		Amount:  spendValue,
-	}}
+	if isSendAll {
+		payments[0].Amount = spendValue
+	}
+	outputs := append([]*libkaspawallet.Payment{}, payments...)
 	if changeSompi > 0 {
-		payments = append(payments, &libkaspawallet.Payment{
+		outputs = append(outputs, &libkaspawallet.Payment{
 			Address: changeAddress,
 			Amount:  changeSompi,
 		})
 	}
 	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
 		s.keysFile.MinimumSignatures,
-		payments, selectedUTXOs)
+		outputs, selectedUTXOs)
 	if err != nil {
 		return nil, err
 	}
 
-	unsignedTransactions, err := s.maybeAutoCompoundTransaction(unsignedTransaction, toAddress, changeAddress, changeWalletAddress, feeRate, maxFee)
-	if err != nil {
-		return nil, err
-	}
-	return unsignedTransactions, nil
+	return s.maybeAutoCompoundTransaction(unsignedTransaction, payments, changeAddress, changeWalletAddress,
+		feeRate, maxFee, excludedOutpoints)
 }
 
-func (s *server) selectUTXOs(spendAmount uint64, isSendAll bool, feeRate float64, maxFee uint64, fromAddresses []*walletAddress) (
+func (s *server) selectUTXOs(spendAmounts []uint64, isSendAll bool, feeRate float64, maxFee uint64, fromAddresses []*walletAddress,
+	excludedOutpoints map[externalapi.DomainOutpoint]struct{}) (
 	selectedUTXOs []*libkaspawallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
-	return s.selectUTXOsWithPreselected(nil, map[externalapi.DomainOutpoint]struct{}{}, spendAmount, isSendAll, feeRate, maxFee, fromAddresses)
+	return s.selectUTXOsWithPreselected(nil, map[externalapi.DomainOutpoint]struct{}{}, excludedOutpoints, spendAmounts, isSendAll, feeRate, maxFee, fromAddresses)
 }
 
-func (s *server) selectUTXOsWithPreselected(preSelectedUTXOs []*walletUTXO, allowUsed map[externalapi.DomainOutpoint]struct{}, spendAmount uint64, isSendAll bool, feeRate float64, maxFee uint64, fromAddresses []*walletAddress) (
+// selectUTXOsWithPreselected selects UTXOs to pay for all of spendAmounts, each of which goes to a separate
+// recipient. If isSendAll is set, spendAmounts is expected to contain a single, ignored, amount.
+// UTXOs in excludedOutpoints are never selected
+func (s *server) selectUTXOsWithPreselected(preSelectedUTXOs []*walletUTXO, allowUsed map[externalapi.DomainOutpoint]struct{},
+	excludedOutpoints map[externalapi.DomainOutpoint]struct{}, spendAmounts []uint64, isSendAll bool, feeRate float64, maxFee uint64,
+	fromAddresses []*walletAddress) (
 	selectedUTXOs []*libkaspawallet.UTXO, totalReceived uint64, changeSompi uint64, err error) {
 
+	spendAmount := uint64(0)
+	for _, amount := range spendAmounts {
+		if spendAmount+amount < spendAmount {
+			return nil, 0, 0, errors.Errorf("the total amount to send overflows")
+		}
+		spendAmount += amount
+	}
+
 	preSelectedSet := make(map[externalapi.DomainOutpoint]struct{})
 	for _, utxo := range preSelectedUTXOs {
 		preSelectedSet[*utxo.Outpoint] = struct{}{}
@@ -172,6 +257,9 @@ func (s *server) selectUTXOsWithPreselected(preSelectedUTXOs []*walletUTXO, allo
 			!s.isUTXOSpendable(utxo, dagInfo.VirtualDAAScore) {
 			return true, nil
 		}
+		if _, ok := excludedOutpoints[*utxo.Outpoint]; ok {
+			return true, nil
+		}
 
 		if broadcastTime, ok := s.usedOutpoints[*utxo.Outpoint]; ok {
 			if _, ok := allowUsed[*utxo.Outpoint]; !ok {
@@ -196,20 +284,20 @@ func (s *server) selectUTXOsWithPreselected(preSelectedUTXOs []*walletUTXO, allo
 		})
 
 		totalValue += utxo.UTXOEntry.Amount()
-		estimatedRecipientValue := spendAmount
+		estimatedRecipientValues := spendAmounts
 		if isSendAll {
-			estimatedRecipientValue = totalValue
+			estimatedRecipientValues = []uint64{totalValue}
 		}
 
-		fee, err = s.estimateFee(selectedUTXOs, feeRate, maxFee, estimatedRecipientValue)
+		fee, err = s.estimateFee(selectedUTXOs, feeRate, maxFee, estimatedRecipientValues)
 		if err != nil {
 			return false, err
 		}
 
 		totalSpend := spendAmount + fee
 		// Two break cases (if not send all):
-		// 		1. totalValue == totalSpend, so there's no change needed -> number of outputs = 1, so a single input is sufficient
-		// 		2. totalValue > totalSpend, so there will be change and 2 outputs, therefor in order to not struggle with --
+		// 		1. totalValue == totalSpend, so there's no change needed -> there's an output per payment only, and the selected inputs pay for it exactly
+		// 		2. totalValue > totalSpend, so there will be a change output as well, therefor in order to not struggle with --
 		//		   2.1 go-nodes dust patch we try and find at least 2 inputs (even though the next one is not necessary in terms of spend value)
 		// 		   2.2 KIP9 we try and make sure that the change amount is not too small
 		if !isSendAll && (totalValue == totalSpend || (totalValue >= totalSpend+minChangeTarget && len(selectedUTXOs) > 1)) {
@@ -260,7 +348,9 @@ func (s *server) selectUTXOsWithPreselected(preSelectedUTXOs []*walletUTXO, allo
 	return selectedUTXOs, totalReceived, totalValue - totalSpend, nil
 }
 
-func (s *server) estimateFee(selectedUTXOs []*libkaspawallet.UTXO, feeRate float64, maxFee uint64, recipientValue uint64) (uint64, error) {
+// estimateFee estimates the fee of a transaction spending selectedUTXOs, with an output for each of
+// recipientValues, and a change output if there's any value left
+func (s *server) estimateFee(selectedUTXOs []*libkaspawallet.UTXO, feeRate float64, maxFee uint64, recipientValues []uint64) (uint64, error) {
 	fakePubKey := [util.PublicKeySizeECDSA]byte{}
 	fakeAddr, err := util.NewAddressPublicKeyECDSA(fakePubKey[:], s.params.Prefix) // We assume the worst case where the recipient address is ECDSA. In this case the scriptPubKey will be the longest.
 	if err != nil {
@@ -272,26 +362,23 @@ func (s *server) estimateFee(selectedUTXOs []*libkaspawallet.UTXO, feeRate float
 		totalValue += utxo.UTXOEntry.Amount()
 	}
 
-	// This is an approximation for the distribution of value between the recipient output and the change output.
-	var mockPayments []*libkaspawallet.Payment
-	if totalValue > recipientValue {
-		mockPayments = []*libkaspawallet.Payment{
-			{
-				Address: fakeAddr,
-				Amount:  recipientValue,
-			},
-			{
-				Address: fakeAddr,
-				Amount:  totalValue - recipientValue, // We ignore the fee since we expect it to be insignificant in mass calculation.
-			},
-		}
-	} else {
-		mockPayments = []*libkaspawallet.Payment{
-			{
-				Address: fakeAddr,
-				Amount:  totalValue,
-			},
-		}
+	// This is an approximation for the distribution of value between the recipient outputs and the change output.
+	mockPayments := make([]*libkaspawallet.Payment, 0, len(recipientValues)+1)
+	totalRecipientValue := uint64(0)
+	for _, recipientValue := range recipientValues {
+		mockPayments = append(mockPayments, &libkaspawallet.Payment{
+			Address: fakeAddr,
+			Amount:  recipientValue,
+		})
+		totalRecipientValue += recipientValue
+	}
+	if totalValue > totalRecipientValue {
+		mockPayments = append(mockPayments, &libkaspawallet.Payment{
+			Address: fakeAddr,
+			Amount:  totalValue - totalRecipientValue, // We ignore the fee since we expect it to be insignificant in mass calculation.
+		})
+	} else if len(mockPayments) == 1 {
+		mockPayments[0].Amount = totalValue
 	}
 
 	mockTx, err := libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
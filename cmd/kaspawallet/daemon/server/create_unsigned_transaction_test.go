@@ -0,0 +1,201 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app"
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+	"github.com/kaspanet/kaspad/util"
+	"github.com/kaspanet/kaspad/util/txmass"
+)
+
+func TestCreateUnsignedTransactionsForManyRecipients(t *testing.T) {
+	const p2pAddress = "127.0.0.1:54335"
+	const rpcAddress = "127.0.0.1:12365"
+	const recipientCount = 300
+	const amountPerRecipient = 10 * constants.SompiPerKaspa
+
+	// Start a simnet node with a UTXO index to fund the wallet from
+	appDir, err := os.MkdirTemp("", "TestCreateUnsignedTransactionsForManyRecipients")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %s", err)
+	}
+	defer os.RemoveAll(appDir)
+
+	params := dagconfig.SimnetParams
+	params.BlockCoinbaseMaturity = 10
+	cfg := config.DefaultConfig()
+	cfg.ActiveNetParams = &params
+	cfg.Simnet = true
+	cfg.AppDir = appDir
+	cfg.Listeners = []string{p2pAddress}
+	cfg.RPCListeners = []string{rpcAddress}
+	cfg.UTXOIndex = true
+	cfg.TargetOutboundPeers = 0
+	cfg.DisableDNSSeed = true
+	cfg.AllowSubmitBlockWhenNotSynced = true
+
+	database, err := ldb.NewLevelDB(filepath.Join(appDir, "db"), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %s", err)
+	}
+	defer database.Close()
+	kaspad, err := app.NewComponentManager(cfg, database, make(chan struct{}))
+	if err != nil {
+		t.Fatalf("NewComponentManager: %+v", err)
+	}
+	kaspad.Start()
+	defer kaspad.Stop()
+
+	rpcClient, err := rpcclient.NewRPCClient(rpcAddress)
+	if err != nil {
+		t.Fatalf("NewRPCClient: %+v", err)
+	}
+	defer rpcClient.Close()
+	rpcClient.SetTimeout(10 * time.Second)
+
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+	extendedPublicKey, err := libkaspawallet.MasterPublicKeyFromMnemonic(&params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+	}
+	serverInstance := &server{
+		rpcClient:            rpcClient,
+		backgroundRPCClient:  rpcClient,
+		params:               &params,
+		coinbaseMaturity:     params.BlockCoinbaseMaturity,
+		utxosSortedByAmount:  []*walletUTXO{},
+		mempoolExcludedUTXOs: map[externalapi.DomainOutpoint]*walletUTXO{},
+		nextSyncStartIndex:   1,
+		keysFile:             &keys.File{ExtendedPublicKeys: []string{extendedPublicKey}, MinimumSignatures: 1},
+		shutdown:             make(chan struct{}),
+		addressSet:           make(walletAddressSet),
+		txMassCalculator:     txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+		usedOutpoints:        map[externalapi.DomainOutpoint]time.Time{},
+	}
+	fundedWalletAddress := &walletAddress{index: 0, cosignerIndex: 0, keyChain: libkaspawallet.ExternalKeychain}
+	fundedAddress, err := serverInstance.walletAddressString(fundedWalletAddress)
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+	serverInstance.addressSet[fundedAddress] = fundedWalletAddress
+
+	// Fund the wallet with enough mature coinbase UTXOs to pay all the recipients
+	_, err = rpcClient.GenerateBlocks(uint32(params.BlockCoinbaseMaturity)+30, fundedAddress, nil)
+	if err != nil {
+		t.Fatalf("GenerateBlocks: %+v", err)
+	}
+	getUTXOsByAddressesResponse, err := rpcClient.GetUTXOsByAddresses([]string{fundedAddress})
+	if err != nil {
+		t.Fatalf("GetUTXOsByAddresses: %+v", err)
+	}
+	utxos, _, err := serverInstance.walletUTXOsFromEntries(getUTXOsByAddressesResponse.Entries, nil)
+	if err != nil {
+		t.Fatalf("walletUTXOsFromEntries: %+v", err)
+	}
+	sortUTXOsByAmount(utxos)
+	serverInstance.utxosSortedByAmount = utxos
+	serverInstance.firstSyncDone.Store(true)
+
+	// Pay a separate recipient in every payment. All of them together have more mass than a standard transaction allows
+	payments := make([]*pb.Payment, recipientCount)
+	expectedAmounts := make(map[string]uint64, recipientCount)
+	for i := range payments {
+		publicKey := make([]byte, util.PublicKeySize)
+		publicKey[0] = byte(i >> 8)
+		publicKey[1] = byte(i)
+		recipient, err := util.NewAddressPublicKey(publicKey, params.Prefix)
+		if err != nil {
+			t.Fatalf("NewAddressPublicKey: %+v", err)
+		}
+		payments[i] = &pb.Payment{Address: recipient.String(), Amount: amountPerRecipient}
+		expectedAmounts[recipient.String()] = amountPerRecipient
+	}
+	feePolicy := &pb.FeePolicy{FeePolicy: &pb.FeePolicy_ExactFeeRate{ExactFeeRate: minFeeRate}}
+	changeAddress, err := serverInstance.walletAddressString(
+		&walletAddress{index: 0, cosignerIndex: 0, keyChain: libkaspawallet.InternalKeychain})
+	if err != nil {
+		t.Fatalf("walletAddressString: %+v", err)
+	}
+	unsignedTransactions, err := serverInstance.createUnsignedTransactions(payments, false, nil, true, feePolicy)
+	if err != nil {
+		t.Fatalf("createUnsignedTransactions: %+v", err)
+	}
+	if len(unsignedTransactions) < 2 {
+		t.Fatalf("Expected the payments to be split between several transactions, got %d", len(unsignedTransactions))
+	}
+
+	// Every recipient is paid exactly once, every UTXO is spent at most once, and every transaction is accepted
+	// to the mempool as a standard transaction
+	spentOutpoints := make(map[externalapi.DomainOutpoint]struct{})
+	for _, unsignedTransaction := range unsignedTransactions {
+		partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(unsignedTransaction)
+		if err != nil {
+			t.Fatalf("DeserializePartiallySignedTransaction: %+v", err)
+		}
+		mass, err := serverInstance.estimateMassAfterSignatures(partiallySignedTransaction)
+		if err != nil {
+			t.Fatalf("estimateMassAfterSignatures: %+v", err)
+		}
+		if mass > mempool.MaximumStandardTransactionMass {
+			t.Fatalf("Transaction has a mass of %d, which is above the maximum standard mass", mass)
+		}
+
+		signedTransaction, err := libkaspawallet.Sign(&params, []string{mnemonic}, unsignedTransaction, false)
+		if err != nil {
+			t.Fatalf("Sign: %+v", err)
+		}
+		transaction, err := libkaspawallet.ExtractTransaction(signedTransaction, false)
+		if err != nil {
+			t.Fatalf("ExtractTransaction: %+v", err)
+		}
+		for _, input := range transaction.Inputs {
+			if _, ok := spentOutpoints[input.PreviousOutpoint]; ok {
+				t.Fatalf("Outpoint %s is spent by more than one transaction", input.PreviousOutpoint)
+			}
+			spentOutpoints[input.PreviousOutpoint] = struct{}{}
+		}
+		for _, output := range transaction.Outputs {
+			_, outputAddress, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, &params)
+			if err != nil {
+				t.Fatalf("ExtractScriptPubKeyAddress: %+v", err)
+			}
+			address := outputAddress.String()
+			if address == changeAddress {
+				continue
+			}
+			if expectedAmounts[address] != output.Value {
+				t.Fatalf("Expected %s to be paid %d once, got an output of %d", address, expectedAmounts[address], output.Value)
+			}
+			delete(expectedAmounts, address)
+		}
+
+		_, err = rpcClient.SubmitTransaction(appmessage.DomainTransactionToRPCTransaction(transaction),
+			consensushashing.TransactionID(transaction).String(), false)
+		if err != nil {
+			t.Fatalf("SubmitTransaction: %+v", err)
+		}
+	}
+	if len(expectedAmounts) != 0 {
+		t.Fatalf("%d recipients weren't paid", len(expectedAmounts))
+	}
+}
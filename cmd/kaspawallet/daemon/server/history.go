@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/pkg/errors"
+)
+
+func (s *server) GetTransactionHistory(_ context.Context, request *pb.GetTransactionHistoryRequest) (
+	*pb.GetTransactionHistoryResponse, error) {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, len(request.Addresses))
+	for i, addressOrLabel := range request.Addresses {
+		addresses[i] = s.resolveWalletAddress(addressOrLabel)
+	}
+
+	transactions := s.transactionHistory.sortedTransactions(addresses)
+	entries := make([]*pb.TransactionHistoryEntry, len(transactions))
+	for i, transaction := range transactions {
+		confirmations := uint64(0)
+		if transaction.IsAccepted && dagInfo.VirtualDAAScore >= transaction.DAAScore {
+			confirmations = dagInfo.VirtualDAAScore - transaction.DAAScore + 1
+		}
+
+		inputs := make([]*pb.TransactionHistoryAmount, 0, len(transaction.Inputs))
+		outpoints := make([]string, 0, len(transaction.Inputs))
+		for outpoint := range transaction.Inputs {
+			outpoints = append(outpoints, outpoint)
+		}
+		sort.Strings(outpoints)
+		for _, outpoint := range outpoints {
+			input := transaction.Inputs[outpoint]
+			inputs = append(inputs, &pb.TransactionHistoryAmount{
+				Address: input.Address,
+				Amount:  input.Amount,
+				Label:   s.addressLabel(input.Address),
+			})
+		}
+
+		outputs := make([]*pb.TransactionHistoryAmount, 0, len(transaction.Outputs))
+		indexes := make([]uint32, 0, len(transaction.Outputs))
+		for index := range transaction.Outputs {
+			indexes = append(indexes, index)
+		}
+		sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+		for _, index := range indexes {
+			output := transaction.Outputs[index]
+			outputs = append(outputs, &pb.TransactionHistoryAmount{
+				Address: output.Address,
+				Amount:  output.Amount,
+				Label:   s.addressLabel(output.Address),
+			})
+		}
+
+		entries[i] = &pb.TransactionHistoryEntry{
+			TransactionId:      transaction.TransactionID,
+			Inputs:             inputs,
+			Outputs:            outputs,
+			ReceivedAmount:     transaction.receivedAmount(),
+			SentAmount:         transaction.sentAmount(),
+			Fee:                transaction.Fee,
+			IsAccepted:         transaction.IsAccepted,
+			AcceptingBlockHash: transaction.AcceptingBlockHash,
+			DaaScore:           transaction.DAAScore,
+			Confirmations:      confirmations,
+			FirstSeenTimestamp: transaction.FirstSeenTimestamp,
+		}
+	}
+
+	return &pb.GetTransactionHistoryResponse{Transactions: entries}, nil
+}
+
+func historyOutpoint(transactionID string, index uint32) string {
+	return fmt.Sprintf("%s:%d", transactionID, index)
+}
+
+// updateTransactionHistory records the transactions that created the wallet's UTXOs, and the mempool
+// transactions that pay to or spend from the wallet.
+// Wallet UTXOs that were spent by transactions that were never seen in the mempool (e.g. ones that were
+// broadcast and accepted between two mempool refreshes by another wallet with the same keys) can't be recorded.
+func (s *server) updateTransactionHistory(entries []*appmessage.UTXOsByAddressesEntry,
+	mempoolEntries []*appmessage.MempoolEntryByAddress) error {
+
+	timestamp := time.Now().UnixMilli()
+	walletOutputs := make(map[string]*historyAmount, len(entries))
+
+	for _, entry := range entries {
+		outpoint := historyOutpoint(entry.Outpoint.TransactionID, entry.Outpoint.Index)
+		walletOutputs[outpoint] = &historyAmount{Address: entry.Address, Amount: entry.UTXOEntry.Amount}
+
+		s.transactionHistory.addOutput(entry.Outpoint.TransactionID, entry.Outpoint.Index,
+			entry.Address, entry.UTXOEntry.Amount, timestamp)
+		// Any transaction whose outputs are in the UTXO set is accepted, but its accepting block is unknown here
+		s.transactionHistory.accept(entry.Outpoint.TransactionID, "", entry.UTXOEntry.BlockDAAScore)
+	}
+
+	return s.updateMempoolTransactionHistory(walletOutputs, mempoolEntries)
+}
+
+// updateMempoolTransactionHistory records the mempool transactions that pay to the wallet, or that
+// spend any of the given wallet outputs, by their outpoint
+func (s *server) updateMempoolTransactionHistory(walletOutputs map[string]*historyAmount,
+	mempoolEntries []*appmessage.MempoolEntryByAddress) error {
+
+	timestamp := time.Now().UnixMilli()
+
+	var receivingEntries, sendingEntries []*appmessage.MempoolEntry
+	for _, entriesByAddress := range mempoolEntries {
+		receivingEntries = append(receivingEntries, entriesByAddress.Receiving...)
+		sendingEntries = append(sendingEntries, entriesByAddress.Sending...)
+	}
+
+	for _, entry := range append(receivingEntries, sendingEntries...) {
+		if entry.Transaction.VerboseData == nil {
+			return errors.Errorf("mempool entry is missing its transaction verbose data")
+		}
+		transactionID := entry.Transaction.VerboseData.TransactionID
+		for i, output := range entry.Transaction.Outputs {
+			if output.VerboseData == nil {
+				continue
+			}
+			address := output.VerboseData.ScriptPublicKeyAddress
+			if _, ok := s.addressSet[address]; !ok {
+				continue
+			}
+			walletOutputs[historyOutpoint(transactionID, uint32(i))] = &historyAmount{Address: address, Amount: output.Amount}
+			s.transactionHistory.addOutput(transactionID, uint32(i), address, output.Amount, timestamp)
+		}
+	}
+
+	for _, entry := range sendingEntries {
+		transactionID := entry.Transaction.VerboseData.TransactionID
+		for _, input := range entry.Transaction.Inputs {
+			outpoint := historyOutpoint(input.PreviousOutpoint.TransactionID, input.PreviousOutpoint.Index)
+			walletOutput, ok := walletOutputs[outpoint]
+			if !ok {
+				continue
+			}
+			s.transactionHistory.addInput(transactionID, outpoint, walletOutput.Address, walletOutput.Amount, timestamp)
+		}
+		s.transactionHistory.setFee(transactionID, entry.Fee)
+	}
+
+	return s.transactionHistory.commit()
+}
+
+// recordBroadcastTransaction records a transaction that was broadcast by this wallet
+func (s *server) recordBroadcastTransaction(tx *externalapi.DomainTransaction) error {
+	timestamp := time.Now().UnixMilli()
+	transactionID := consensushashing.TransactionID(tx).String()
+
+	inputsValue := uint64(0)
+	isFeeKnown := true
+	for _, input := range tx.Inputs {
+		if input.UTXOEntry == nil {
+			isFeeKnown = false
+			continue
+		}
+		inputsValue += input.UTXOEntry.Amount()
+
+		_, address, err := txscript.ExtractScriptPubKeyAddress(input.UTXOEntry.ScriptPublicKey(), s.params)
+		if err != nil {
+			return err
+		}
+		s.transactionHistory.addInput(transactionID,
+			historyOutpoint(input.PreviousOutpoint.TransactionID.String(), input.PreviousOutpoint.Index),
+			address.String(), input.UTXOEntry.Amount(), timestamp)
+	}
+
+	outputsValue := uint64(0)
+	for i, output := range tx.Outputs {
+		outputsValue += output.Value
+
+		_, address, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, s.params)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.addressSet[address.String()]; !ok {
+			continue
+		}
+		s.transactionHistory.addOutput(transactionID, uint32(i), address.String(), output.Value, timestamp)
+	}
+
+	if isFeeKnown && inputsValue >= outputsValue {
+		s.transactionHistory.setFee(transactionID, inputsValue-outputsValue)
+	}
+
+	return s.transactionHistory.commit()
+}
+
+// updateTransactionHistoryAcceptance updates the acceptance state of the recorded transactions
+// according to the changes in the virtual selected parent chain
+func (s *server) updateTransactionHistoryAcceptance(
+	notification *appmessage.VirtualSelectedParentChainChangedNotificationMessage) error {
+
+	for _, removedChainBlockHash := range notification.RemovedChainBlockHashes {
+		s.transactionHistory.unaccept(removedChainBlockHash)
+	}
+
+	// Transactions that were recorded as accepted from the UTXO set have no accepting block hash,
+	// so any of them that were accepted at or above the lowest removed chain block are unaccepted
+	if len(notification.RemovedChainBlockHashes) > 0 && s.transactionHistory.hasUnknownAcceptingBlocks() {
+		lowestRemovedDAAScore := uint64(math.MaxUint64)
+		for _, removedChainBlockHash := range notification.RemovedChainBlockHashes {
+			getBlockResponse, err := s.backgroundRPCClient.GetBlock(removedChainBlockHash, false)
+			if err != nil {
+				return err
+			}
+			if getBlockResponse.Block.Header.DAAScore < lowestRemovedDAAScore {
+				lowestRemovedDAAScore = getBlockResponse.Block.Header.DAAScore
+			}
+		}
+		s.transactionHistory.unacceptUnknownFromDAAScore(lowestRemovedDAAScore)
+	}
+
+	for _, acceptedTransactionIDs := range notification.AcceptedTransactionIDs {
+		isDAAScoreKnown := false
+		daaScore := uint64(0)
+		for _, transactionID := range acceptedTransactionIDs.AcceptedTransactionIDs {
+			if !s.transactionHistory.contains(transactionID) {
+				continue
+			}
+
+			if !isDAAScoreKnown {
+				getBlockResponse, err := s.backgroundRPCClient.GetBlock(acceptedTransactionIDs.AcceptingBlockHash, false)
+				if err != nil {
+					return err
+				}
+				daaScore = getBlockResponse.Block.Header.DAAScore
+				isDAAScoreKnown = true
+			}
+			s.transactionHistory.accept(transactionID, acceptedTransactionIDs.AcceptingBlockHash, daaScore)
+		}
+	}
+
+	return s.transactionHistory.commit()
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+// utxosChangedChanSize is the number of UTXOs changed notifications that can wait
+// for syncLoop. Notifications that don't fit are dropped, and a full refresh is done instead
+const utxosChangedChanSize = 100
+
+// registerForNotifications registers for all the notifications the wallet uses, except for UTXOs changed
+// notifications, which are registered for by subscribeToUTXOsChanged once the wallet addresses are known.
+// Registrations don't survive a reconnection, so this should be called again after every reconnection.
+func (s *server) registerForNotifications() error {
+	s.isRegisteredForUTXOsChanged = false
+	s.subscribedAddresses = make(map[string]struct{})
+
+	err := s.backgroundRPCClient.RegisterForVirtualSelectedParentChainChangedNotifications(true,
+		func(notification *appmessage.VirtualSelectedParentChainChangedNotificationMessage) {
+			err := s.updateTransactionHistoryAcceptance(notification)
+			if err != nil {
+				log.Errorf("Error updating the transaction history: %s", err)
+			}
+		})
+	if err != nil {
+		return errors.Wrapf(err, "Error registering for chain changed notifications")
+	}
+
+	err = s.backgroundRPCClient.RegisterPruningPointUTXOSetNotifications(func() {
+		select {
+		case s.pruningPointUTXOSetOverrideChan <- struct{}{}:
+		default:
+			// A full refresh is already pending
+		}
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Error registering for pruning point UTXO set override notifications")
+	}
+
+	return nil
+}
+
+// onReconnected is called by the background RPC client every time it reconnects to the node.
+// It's called from the RPC client's goroutine, so it only signals syncLoop to do the work.
+func (s *server) onReconnected() {
+	select {
+	case s.reconnectedChan <- struct{}{}:
+	default:
+		// A reconnection is already pending
+	}
+}
+
+// subscribeToUTXOsChanged subscribes to UTXOs changed notifications for the given addresses
+// that aren't subscribed to yet. It must only be called from syncLoop.
+func (s *server) subscribeToUTXOsChanged(addresses []string) error {
+	newAddresses := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if _, ok := s.subscribedAddresses[address]; !ok {
+			newAddresses = append(newAddresses, address)
+		}
+	}
+
+	// Note that registering with no addresses at all means subscribing to all the addresses in the DAG
+	if len(newAddresses) == 0 {
+		return nil
+	}
+
+	if !s.isRegisteredForUTXOsChanged {
+		err := s.backgroundRPCClient.RegisterForUTXOsChangedNotifications(newAddresses,
+			func(notification *appmessage.UTXOsChangedNotificationMessage) {
+				// Blocking here would block the RPC client, and with it the RPCs syncLoop is waiting for
+				select {
+				case s.utxosChangedChan <- notification:
+				default:
+					select {
+					case s.utxosChangedOverflowChan <- struct{}{}:
+					default:
+						// A full refresh is already pending
+					}
+				}
+			})
+		if err != nil {
+			return errors.Wrapf(err, "Error registering for UTXOs changed notifications")
+		}
+		s.isRegisteredForUTXOsChanged = true
+	} else {
+		err := s.backgroundRPCClient.AddUTXOsChangedNotificationAddresses(newAddresses)
+		if err != nil {
+			return errors.Wrapf(err, "Error adding addresses to the UTXOs changed notifications")
+		}
+	}
+
+	for _, address := range newAddresses {
+		s.subscribedAddresses[address] = struct{}{}
+	}
+	return nil
+}
+
+// drainUTXOsChangedNotifications discards the pending UTXOs changed notifications.
+// This is used right before a full refresh, which already includes their changes.
+func (s *server) drainUTXOsChangedNotifications() {
+	for {
+		select {
+		case <-s.utxosChangedChan:
+		default:
+			return
+		}
+	}
+}
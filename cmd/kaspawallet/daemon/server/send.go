@@ -11,7 +11,12 @@ func (s *server) Send(_ context.Context, request *pb.SendRequest) (*pb.SendRespo
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	unsignedTransactions, err := s.createUnsignedTransactions(request.ToAddress, request.Amount, request.IsSendAll,
+	payments, err := requestPayments(request.ToAddress, request.Amount, request.Payments, request.IsSendAll)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedTransactions, err := s.createUnsignedTransactions(payments, request.IsSendAll,
 		request.From, request.UseExistingChangeAddress, request.FeePolicy)
 
 	if err != nil {
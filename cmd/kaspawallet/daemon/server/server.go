@@ -8,6 +8,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 	"github.com/kaspanet/kaspad/version"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
@@ -19,6 +21,7 @@ import (
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
 	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
 	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
 	"github.com/kaspanet/kaspad/infrastructure/os/signal"
 	"github.com/kaspanet/kaspad/util/panics"
@@ -35,6 +38,8 @@ type server struct {
 	params              *dagconfig.Params
 	coinbaseMaturity    uint64 // Is different from default if we use testnet-11
 
+	mempoolRefreshInterval time.Duration
+
 	lock                            sync.RWMutex
 	utxosSortedByAmount             []*walletUTXO
 	mempoolExcludedUTXOs            map[externalapi.DomainOutpoint]*walletUTXO
@@ -47,6 +52,18 @@ type server struct {
 	txMassCalculator                *txmass.Calculator
 	usedOutpoints                   map[externalapi.DomainOutpoint]time.Time
 	firstSyncDone                   atomic.Bool
+	transactionHistory              *transactionHistory
+	addressBook                     *addressBook
+
+	// The following fields are used by syncLoop to keep the UTXO set up to date
+	utxosChangedChan                chan *appmessage.UTXOsChangedNotificationMessage
+	utxosChangedOverflowChan        chan struct{}
+	reconnectedChan                 chan struct{}
+	pruningPointUTXOSetOverrideChan chan struct{}
+	isRegisteredForUTXOsChanged     bool
+	subscribedAddresses             map[string]struct{}
+	watchedAddresses                walletAddressSet
+	mempoolEntries                  []*appmessage.MempoolEntryByAddress
 
 	isLogFinalProgressLineShown bool
 	maxUsedAddressesForLog      uint32
@@ -57,8 +74,15 @@ type server struct {
 // Currently, set to 100MB
 const MaxDaemonSendMsgSize = 100_000_000
 
+const historyDatabaseCacheSizeMiB = 8
+
+// defaultMempoolRefreshInterval is used when no mempool refresh interval is given to Start
+const defaultMempoolRefreshInterval = time.Minute
+
 // Start starts the kaspawalletd server
-func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath string, profile string, timeout uint32) error {
+func Start(params *dagconfig.Params, listen, rpcServer string, rpcConnectOptions *rpcauth.ConnectOptions,
+	keysFilePath string, profile string, timeout uint32, mempoolRefreshInterval uint32) error {
+
 	initLog(defaultLogFile, defaultErrLogFile)
 
 	defer panics.HandlePanic(log, "MAIN", nil)
@@ -76,11 +100,11 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 	log.Infof("Listening to TCP on %s", listen)
 
 	log.Infof("Connecting to a node at %s...", rpcServer)
-	rpcClient, err := connectToRPC(params, rpcServer, timeout)
+	rpcClient, err := connectToRPC(params, rpcServer, rpcConnectOptions, timeout)
 	if err != nil {
 		return (errors.Wrapf(err, "Error connecting to RPC server %s", rpcServer))
 	}
-	backgroundRPCClient, err := connectToRPC(params, rpcServer, timeout)
+	backgroundRPCClient, err := connectToRPC(params, rpcServer, rpcConnectOptions, timeout)
 	if err != nil {
 		return (errors.Wrapf(err, "Error making a second connection to RPC server %s", rpcServer))
 	}
@@ -96,6 +120,28 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		return err
 	}
 
+	if keysFile.IsWatchOnly() {
+		log.Infof("The keys file is watch-only, signing transactions is disabled")
+	}
+
+	historyDatabasePath := keysFile.Path() + ".history"
+	historyDatabase, err := ldb.NewLevelDB(historyDatabasePath, historyDatabaseCacheSizeMiB)
+	if err != nil {
+		return errors.Wrapf(err, "Error opening the transaction history database %s", historyDatabasePath)
+	}
+	defer historyDatabase.Close()
+
+	transactionHistory, err := newTransactionHistory(historyDatabase)
+	if err != nil {
+		return errors.Wrapf(err, "Error reading the transaction history database %s", historyDatabasePath)
+	}
+
+	addressBookPath := keysFile.Path() + ".addressbook"
+	addressBook, err := newAddressBook(addressBookPath)
+	if err != nil {
+		return errors.Wrapf(err, "Error reading the address book %s", addressBookPath)
+	}
+
 	dagInfo, err := rpcClient.GetBlockDAGInfo()
 	if err != nil {
 		return nil
@@ -106,25 +152,41 @@ func Start(params *dagconfig.Params, listen, rpcServer string, keysFilePath stri
 		coinbaseMaturity = 1000
 	}
 
+	mempoolRefreshIntervalDuration := defaultMempoolRefreshInterval
+	if mempoolRefreshInterval != 0 {
+		mempoolRefreshIntervalDuration = time.Duration(mempoolRefreshInterval) * time.Second
+	}
+
 	serverInstance := &server{
-		rpcClient:                   rpcClient,
-		backgroundRPCClient:         backgroundRPCClient,
-		params:                      params,
-		coinbaseMaturity:            coinbaseMaturity,
-		utxosSortedByAmount:         []*walletUTXO{},
-		mempoolExcludedUTXOs:        map[externalapi.DomainOutpoint]*walletUTXO{},
-		nextSyncStartIndex:          0,
-		keysFile:                    keysFile,
-		shutdown:                    make(chan struct{}),
-		forceSyncChan:               make(chan struct{}),
-		addressSet:                  make(walletAddressSet),
-		txMassCalculator:            txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
-		usedOutpoints:               map[externalapi.DomainOutpoint]time.Time{},
-		isLogFinalProgressLineShown: false,
-		maxUsedAddressesForLog:      0,
-		maxProcessedAddressesForLog: 0,
+		rpcClient:                       rpcClient,
+		backgroundRPCClient:             backgroundRPCClient,
+		params:                          params,
+		coinbaseMaturity:                coinbaseMaturity,
+		mempoolRefreshInterval:          mempoolRefreshIntervalDuration,
+		utxosSortedByAmount:             []*walletUTXO{},
+		mempoolExcludedUTXOs:            map[externalapi.DomainOutpoint]*walletUTXO{},
+		nextSyncStartIndex:              0,
+		keysFile:                        keysFile,
+		shutdown:                        make(chan struct{}),
+		forceSyncChan:                   make(chan struct{}),
+		addressSet:                      make(walletAddressSet),
+		txMassCalculator:                txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp),
+		usedOutpoints:                   map[externalapi.DomainOutpoint]time.Time{},
+		transactionHistory:              transactionHistory,
+		addressBook:                     addressBook,
+		utxosChangedChan:                make(chan *appmessage.UTXOsChangedNotificationMessage, utxosChangedChanSize),
+		utxosChangedOverflowChan:        make(chan struct{}, 1),
+		reconnectedChan:                 make(chan struct{}, 1),
+		pruningPointUTXOSetOverrideChan: make(chan struct{}, 1),
+		subscribedAddresses:             make(map[string]struct{}),
+		watchedAddresses:                make(walletAddressSet),
+		isLogFinalProgressLineShown:     false,
+		maxUsedAddressesForLog:          0,
+		maxProcessedAddressesForLog:     0,
 	}
 
+	backgroundRPCClient.SetOnReconnectedHandler(serverInstance.onReconnected)
+
 	log.Infof("Read, syncing the wallet...")
 	spawn("serverInstance.syncLoop", func() {
 		err := serverInstance.syncLoop()
@@ -9,6 +9,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
 	"github.com/kaspanet/kaspad/domain/miningmanager/mempool"
 	"github.com/kaspanet/kaspad/util"
@@ -19,12 +20,14 @@ import (
 // transaction.
 // If it is - the transaction is split into multiple transactions, each with a portion of the inputs and a single output
 // into a change address.
-// An additional `mergeTransaction` is generated - which merges the outputs of the above splits into a single output
-// paying to the original transaction's payee.
-func (s *server) maybeAutoCompoundTransaction(transaction *serialization.PartiallySignedTransaction, toAddress util.Address,
-	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate float64, maxFee uint64) ([][]byte, error) {
-
-	splitTransactions, err := s.maybeSplitAndMergeTransaction(transaction, toAddress, changeAddress, changeWalletAddress, feeRate, maxFee)
+// An additional `mergeTransaction` is generated - which merges the outputs of the above splits into the
+// original transaction's payments. If the merge transaction needs more UTXOs, none of excludedOutpoints are used.
+func (s *server) maybeAutoCompoundTransaction(transaction *serialization.PartiallySignedTransaction, payments []*libkaspawallet.Payment,
+	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate float64, maxFee uint64,
+	excludedOutpoints map[externalapi.DomainOutpoint]struct{}) ([][]byte, error) {
+
+	splitTransactions, err := s.maybeSplitAndMergeTransaction(transaction, payments, changeAddress, changeWalletAddress, feeRate, maxFee,
+		excludedOutpoints)
 	if err != nil {
 		return nil, err
 	}
@@ -38,26 +41,94 @@ func (s *server) maybeAutoCompoundTransaction(transaction *serialization.Partial
 	return splitTransactionsBytes, nil
 }
 
+// maxPaymentBatchOutputsMass is the maximum mass of the outputs of a single transaction, which leaves the
+// other half of the maximum standard mass for its inputs
+const maxPaymentBatchOutputsMass = mempool.MaximumStandardTransactionMass / 2
+
+// paymentBatches splits the given payments into batches that are each paid by separate transactions, so
+// that sending to many recipients doesn't exceed the maximum standard transaction mass. The outputs of a
+// batch, along with a change output, are kept below maxPaymentBatchOutputsMass, and their storage mass
+// (see KIP9) is kept below the maximum standard mass even before it's reduced by the inputs.
+// A single payment that is too large by itself is still put in a batch of its own
+func (s *server) paymentBatches(payments []*libkaspawallet.Payment) ([][]*libkaspawallet.Payment, error) {
+	storageMassParameter := s.txMassCalculator.StorageMassParameter()
+	changeOutputStorageMass := storageMassParameter / minChangeTarget
+
+	// The change output is assumed to have the longest possible script, which is that of an ECDSA address
+	changeOutput := &externalapi.DomainTransactionOutput{
+		Value:           minChangeTarget,
+		ScriptPublicKey: &externalapi.ScriptPublicKey{Script: make([]byte, util.PublicKeySizeECDSA+2)},
+	}
+	transactionWithOnlyChange := &externalapi.DomainTransaction{
+		Outputs: []*externalapi.DomainTransactionOutput{changeOutput},
+	}
+	changeOnlyMass := s.txMassCalculator.CalculateTransactionMass(transactionWithOnlyChange)
+
+	var batches [][]*libkaspawallet.Payment
+	var batch []*libkaspawallet.Payment
+	batchMass := changeOnlyMass
+	batchStorageMass := changeOutputStorageMass
+	for _, payment := range payments {
+		scriptPublicKey, err := txscript.PayToAddrScript(payment.Address)
+		if err != nil {
+			return nil, err
+		}
+		transactionWithPayment := &externalapi.DomainTransaction{
+			Outputs: []*externalapi.DomainTransactionOutput{changeOutput, {
+				Value:           payment.Amount,
+				ScriptPublicKey: scriptPublicKey,
+			}},
+		}
+		paymentMass := s.txMassCalculator.CalculateTransactionMass(transactionWithPayment) - changeOnlyMass
+		paymentStorageMass := storageMassParameter
+		if payment.Amount > 0 {
+			paymentStorageMass = storageMassParameter / payment.Amount
+		}
+
+		if len(batch) > 0 && (batchMass+paymentMass >= maxPaymentBatchOutputsMass ||
+			batchStorageMass+paymentStorageMass >= mempool.MaximumStandardTransactionMass) {
+
+			batches = append(batches, batch)
+			batch = nil
+			batchMass = changeOnlyMass
+			batchStorageMass = changeOutputStorageMass
+		}
+		batch = append(batch, payment)
+		batchMass += paymentMass
+		batchStorageMass += paymentStorageMass
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
 func (s *server) mergeTransaction(
 	splitTransactions []*serialization.PartiallySignedTransaction,
 	originalTransaction *serialization.PartiallySignedTransaction,
-	toAddress util.Address,
+	payments []*libkaspawallet.Payment,
 	changeAddress util.Address,
 	changeWalletAddress *walletAddress,
 	feeRate float64,
 	maxFee uint64,
+	excludedOutpoints map[externalapi.DomainOutpoint]struct{},
 ) (*serialization.PartiallySignedTransaction, error) {
 	numOutputs := len(originalTransaction.Tx.Outputs)
-	if numOutputs > 2 || numOutputs == 0 {
-		// This is a sanity check to make sure originalTransaction has either 1 or 2 outputs:
-		// 1. For the payment itself
+	if numOutputs > len(payments)+1 || numOutputs < len(payments) {
+		// This is a sanity check to make sure originalTransaction has either len(payments) or len(payments)+1 outputs:
+		// 1. For the payments themselves
 		// 2. (optional) for change
-		return nil, errors.Errorf("original transaction has %d outputs, while 1 or 2 are expected",
-			len(originalTransaction.Tx.Outputs))
+		return nil, errors.Errorf("original transaction has %d outputs, while %d or %d are expected",
+			len(originalTransaction.Tx.Outputs), len(payments), len(payments)+1)
 	}
 
 	totalValue := uint64(0)
-	sentValue := originalTransaction.Tx.Outputs[0].Value
+	sentValue := uint64(0)
+	sentValues := make([]uint64, len(payments))
+	for i, payment := range payments {
+		sentValue += payment.Amount
+		sentValues[i] = payment.Amount
+	}
 	utxos := make([]*libkaspawallet.UTXO, len(splitTransactions))
 	for i, splitTransaction := range splitTransactions {
 		output := splitTransaction.Tx.Outputs[0]
@@ -72,7 +143,7 @@ func (s *server) mergeTransaction(
 		totalValue += output.Value
 	}
 	// We're overestimating a bit by assuming that any transaction will have a change output
-	fee, err := s.estimateFee(utxos, feeRate, maxFee, sentValue)
+	fee, err := s.estimateFee(utxos, feeRate, maxFee, sentValues)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +153,7 @@ func (s *server) mergeTransaction(
 	if totalValue < sentValue {
 		// sometimes the fees from compound transactions make the total output higher than what's available from selected
 		// utxos, in such cases - find one more UTXO and use it.
-		additionalUTXOs, totalValueAdded, err := s.moreUTXOsForMergeTransaction(utxos, sentValue-totalValue, feeRate)
+		additionalUTXOs, totalValueAdded, err := s.moreUTXOsForMergeTransaction(utxos, excludedOutpoints, sentValue-totalValue, feeRate)
 		if err != nil {
 			return nil, err
 		}
@@ -90,19 +161,16 @@ func (s *server) mergeTransaction(
 		totalValue += totalValueAdded
 	}
 
-	payments := []*libkaspawallet.Payment{{
-		Address: toAddress,
-		Amount:  sentValue,
-	}}
+	outputs := append([]*libkaspawallet.Payment{}, payments...)
 	if totalValue > sentValue {
-		payments = append(payments, &libkaspawallet.Payment{
+		outputs = append(outputs, &libkaspawallet.Payment{
 			Address: changeAddress,
 			Amount:  totalValue - sentValue,
 		})
 	}
 
 	return libkaspawallet.CreateUnsignedTransaction(s.keysFile.ExtendedPublicKeys,
-		s.keysFile.MinimumSignatures, payments, utxos)
+		s.keysFile.MinimumSignatures, outputs, utxos)
 }
 
 func (s *server) transactionFeeRate(psTx *serialization.PartiallySignedTransaction) (float64, error) {
@@ -140,8 +208,9 @@ func (s *server) checkTransactionFeeRate(psTx *serialization.PartiallySignedTran
 	return nil
 }
 
-func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.PartiallySignedTransaction, toAddress util.Address,
-	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate float64, maxFee uint64) ([]*serialization.PartiallySignedTransaction, error) {
+func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.PartiallySignedTransaction, payments []*libkaspawallet.Payment,
+	changeAddress util.Address, changeWalletAddress *walletAddress, feeRate float64, maxFee uint64,
+	excludedOutpoints map[externalapi.DomainOutpoint]struct{}) ([]*serialization.PartiallySignedTransaction, error) {
 
 	err := s.checkTransactionFeeRate(transaction, maxFee)
 	if err != nil {
@@ -179,12 +248,14 @@ func (s *server) maybeSplitAndMergeTransaction(transaction *serialization.Partia
 	}
 
 	if len(splitTransactions) > 1 {
-		mergeTransaction, err := s.mergeTransaction(splitTransactions, transaction, toAddress, changeAddress, changeWalletAddress, feeRate, maxFee)
+		mergeTransaction, err := s.mergeTransaction(splitTransactions, transaction, payments, changeAddress, changeWalletAddress, feeRate, maxFee,
+			excludedOutpoints)
 		if err != nil {
 			return nil, err
 		}
 		// Recursion will be 2-3 iterations deep even in the rarest` cases, so considered safe..
-		splitMergeTransaction, err := s.maybeSplitAndMergeTransaction(mergeTransaction, toAddress, changeAddress, changeWalletAddress, feeRate, maxFee)
+		splitMergeTransaction, err := s.maybeSplitAndMergeTransaction(mergeTransaction, payments, changeAddress, changeWalletAddress, feeRate, maxFee,
+			excludedOutpoints)
 		if err != nil {
 			return nil, err
 		}
@@ -207,6 +278,14 @@ func (s *server) splitAndInputPerSplitCounts(transaction *serialization.Partiall
 
 	massOfAllInputs := transactionMass - massWithoutInputs
 
+	// Since the merge transaction contains all the outputs of the original transaction, splitting the
+	// inputs doesn't help if the outputs alone don't leave room for the inputs of the merge transaction.
+	// paymentBatches keeps the outputs of every transaction below this limit
+	if massWithoutInputs >= maxPaymentBatchOutputsMass {
+		return 0, 0, errors.Errorf("the transaction outputs have a mass of %d, which doesn't leave enough room for "+
+			"its inputs", massWithoutInputs)
+	}
+
 	// Since the transaction was generated by kaspawallet, we assume all inputs have the same number of signatures, and
 	// thus - the same mass.
 	inputCount := len(transaction.Tx.Inputs)
@@ -253,7 +332,7 @@ func (s *server) createSplitTransaction(transaction *serialization.PartiallySign
 		totalSompi += selectedUTXOs[i-startIndex].UTXOEntry.Amount()
 	}
 	if len(selectedUTXOs) != 0 {
-		fee, err := s.estimateFee(selectedUTXOs, feeRate, maxFee, totalSompi)
+		fee, err := s.estimateFee(selectedUTXOs, feeRate, maxFee, []uint64{totalSompi})
 		if err != nil {
 			return nil, err
 		}
@@ -317,7 +396,8 @@ func EstimateMassAfterSignatures(transaction *serialization.PartiallySignedTrans
 	return txMassCalculator.CalculateTransactionOverallMass(transactionWithSignatures), nil
 }
 
-func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libkaspawallet.UTXO, requiredAmount uint64, feeRate float64) (
+func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libkaspawallet.UTXO,
+	excludedOutpoints map[externalapi.DomainOutpoint]struct{}, requiredAmount uint64, feeRate float64) (
 	additionalUTXOs []*libkaspawallet.UTXO, totalValueAdded uint64, err error) {
 
 	dagInfo, err := s.rpcClient.GetBlockDAGInfo()
@@ -338,6 +418,9 @@ func (s *server) moreUTXOsForMergeTransaction(alreadySelectedUTXOs []*libkaspawa
 		if _, ok := alreadySelectedUTXOsMap[*utxo.Outpoint]; ok {
 			continue
 		}
+		if _, ok := excludedOutpoints[*utxo.Outpoint]; ok {
+			continue
+		}
 		if !s.isUTXOSpendable(utxo, dagInfo.VirtualDAAScore) {
 			continue
 		}
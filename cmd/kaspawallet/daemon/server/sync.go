@@ -24,11 +24,25 @@ func (was walletAddressSet) strings() []string {
 	return addresses
 }
 
+// syncLoop keeps the wallet UTXO set up to date. After an initial full refresh, the UTXO set is
+// updated incrementally from UTXOs changed notifications, and a full refresh is done again only
+// after a reconnection to the node, after its UTXO set was overridden by a new pruning point, or
+// after notifications were dropped because syncLoop fell behind.
+// The wallet also subscribes to the changes of the unused addresses up to numIndexesToWatchAfterLastUsed
+// indexes after the last used one, so that new addresses are discovered only when funds are sent to them,
+// instead of by polling the node.
+// Since notifications don't cover the mempool, the wallet's mempool transactions are refreshed after
+// every broadcast, and otherwise once every mempoolRefreshInterval.
 func (s *server) syncLoop() error {
-	ticker := time.NewTicker(time.Second)
+	ticker := time.NewTicker(s.mempoolRefreshInterval)
 	defer ticker.Stop()
 
-	err := s.collectRecentAddresses()
+	err := s.registerForNotifications()
+	if err != nil {
+		return err
+	}
+
+	err = s.collectRecentAddresses()
 	if err != nil {
 		return err
 	}
@@ -45,32 +59,62 @@ func (s *server) syncLoop() error {
 		select {
 		case <-ticker.C:
 		case <-s.forceSyncChan:
+			// New addresses might have been handed out, so the watched addresses are extended
+			err := s.watchAddresses()
+			if err != nil {
+				return err
+			}
+		case notification := <-s.utxosChangedChan:
+			err := s.applyUTXOsChangedNotification(notification)
+			if err != nil {
+				return err
+			}
+			continue
+		case <-s.reconnectedChan:
+			log.Infof("Reconnected to the node, refreshing the wallet UTXO set")
+			err := s.registerForNotifications()
+			if err != nil {
+				return err
+			}
+			err = s.refreshUTXOs()
+			if err != nil {
+				return err
+			}
+			continue
+		case <-s.pruningPointUTXOSetOverrideChan:
+			log.Infof("The node's UTXO set was overridden by a new pruning point, refreshing the wallet UTXO set")
+			err := s.refreshUTXOs()
+			if err != nil {
+				return err
+			}
+			continue
+		case <-s.utxosChangedOverflowChan:
+			log.Warnf("UTXOs changed notifications were dropped, refreshing the wallet UTXO set")
+			err := s.refreshUTXOs()
+			if err != nil {
+				return err
+			}
+			continue
 		}
 
-		err := s.sync()
+		err := s.refreshMempool()
 		if err != nil {
 			return err
 		}
-	}
-}
 
-func (s *server) sync() error {
-	err := s.collectFarAddresses()
-	if err != nil {
-		return err
-	}
-
-	err = s.collectRecentAddresses()
-	if err != nil {
-		return err
+		err = s.refreshUsedOutpoints()
+		if err != nil {
+			return err
+		}
 	}
-
-	return s.refreshUTXOs()
 }
 
 const (
-	numIndexesToQueryForFarAddresses    = 100
 	numIndexesToQueryForRecentAddresses = 1000
+
+	// numIndexesToWatchAfterLastUsed is the number of unused address indexes after the
+	// last used one whose changes the wallet subscribes to, in order to discover them
+	numIndexesToWatchAfterLastUsed = 100
 )
 
 // addressesToQuery scans the addresses in the given range. Because
@@ -99,18 +143,48 @@ func (s *server) addressesToQuery(start, end uint32) (walletAddressSet, error) {
 	return addresses, nil
 }
 
-// collectFarAddresses collects numIndexesToQueryForFarAddresses addresses
-// from the last point it stopped in the previous call.
-func (s *server) collectFarAddresses() error {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	err := s.collectAddresses(s.nextSyncStartIndex, s.nextSyncStartIndex+numIndexesToQueryForFarAddresses)
-	if err != nil {
-		return err
-	}
+// watchAddresses extends the watched addresses up to numIndexesToWatchAfterLastUsed indexes after the
+// last used one. The new addresses are subscribed to before their balances are checked, so that no
+// funds sent to them are missed, and the UTXOs of the ones that were already used are fetched.
+// It must only be called from syncLoop.
+func (s *server) watchAddresses() error {
+	for {
+		s.lock.RLock()
+		start := s.nextSyncStartIndex
+		end := s.maxUsedIndex() + numIndexesToWatchAfterLastUsed + 1
+		s.lock.RUnlock()
+		if start >= end {
+			return nil
+		}
 
-	s.nextSyncStartIndex += numIndexesToQueryForFarAddresses
-	return nil
+		addressSet, err := s.addressesToQuery(start, end)
+		if err != nil {
+			return err
+		}
+
+		err = s.subscribeToUTXOsChanged(addressSet.strings())
+		if err != nil {
+			return err
+		}
+
+		s.lock.Lock()
+		for address, walletAddress := range addressSet {
+			s.watchedAddresses[address] = walletAddress
+		}
+		usedAddresses, err := s.collectUsedAddresses(addressSet)
+		if err == nil {
+			s.nextSyncStartIndex = end
+		}
+		s.lock.Unlock()
+		if err != nil {
+			return err
+		}
+
+		err = s.refreshAddressesUTXOs(usedAddresses.strings())
+		if err != nil {
+			return err
+		}
+	}
 }
 
 func (s *server) maxUsedIndexWithLock() uint32 {
@@ -169,35 +243,54 @@ func (s *server) collectAddresses(start, end uint32) error {
 		return err
 	}
 
-	getBalancesByAddressesResponse, err := s.backgroundRPCClient.GetBalancesByAddresses(addressSet.strings())
-	if err != nil {
-		return err
+	for address, walletAddress := range addressSet {
+		s.watchedAddresses[address] = walletAddress
 	}
 
-	err = s.updateAddressesAndLastUsedIndexes(addressSet, getBalancesByAddressesResponse)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	_, err = s.collectUsedAddresses(addressSet)
+	return err
 }
 
-func (s *server) updateAddressesAndLastUsedIndexes(requestedAddressSet walletAddressSet,
-	getBalancesByAddressesResponse *appmessage.GetBalancesByAddressesResponseMessage) error {
-	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex()
-	lastUsedInternalIndex := s.keysFile.LastUsedInternalIndex()
+// collectUsedAddresses adds the addresses in the given set that have a balance to the wallet addresses,
+// and returns them
+func (s *server) collectUsedAddresses(addressSet walletAddressSet) (walletAddressSet, error) {
+	if len(addressSet) == 0 {
+		return walletAddressSet{}, nil
+	}
 
+	getBalancesByAddressesResponse, err := s.backgroundRPCClient.GetBalancesByAddresses(addressSet.strings())
+	if err != nil {
+		return nil, err
+	}
+
+	usedAddresses := make(walletAddressSet)
 	for _, entry := range getBalancesByAddressesResponse.Entries {
-		walletAddress, ok := requestedAddressSet[entry.Address]
+		walletAddress, ok := addressSet[entry.Address]
 		if !ok {
-			return errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
+			return nil, errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
 		}
 
 		if entry.Balance == 0 {
 			continue
 		}
+		usedAddresses[entry.Address] = walletAddress
+	}
 
-		s.addressSet[entry.Address] = walletAddress
+	err = s.addUsedAddresses(usedAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	return usedAddresses, nil
+}
+
+// addUsedAddresses adds the given addresses to the wallet addresses, and updates the last used indexes accordingly
+func (s *server) addUsedAddresses(usedAddresses walletAddressSet) error {
+	lastUsedExternalIndex := s.keysFile.LastUsedExternalIndex()
+	lastUsedInternalIndex := s.keysFile.LastUsedInternalIndex()
+
+	for address, walletAddress := range usedAddresses {
+		s.addressSet[address] = walletAddress
 
 		if walletAddress.keyChain == libkaspawallet.ExternalKeychain {
 			if walletAddress.index > lastUsedExternalIndex {
@@ -228,35 +321,35 @@ func (s *server) usedOutpointHasExpired(outpointBroadcastTime time.Time) bool {
 	return s.startTimeOfLastCompletedRefresh.After(outpointBroadcastTime.Add(time.Minute))
 }
 
-// updateUTXOSet clears the current UTXO set, and re-fills it with the given entries
-func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, mempoolEntries []*appmessage.MempoolEntryByAddress, refreshStart time.Time) error {
-	utxos := make([]*walletUTXO, 0, len(entries))
+// walletUTXOsFromEntries converts the given entries to wallet UTXOs. The UTXOs that are spent by
+// any of the given mempool entries are returned separately.
+func (s *server) walletUTXOsFromEntries(entries []*appmessage.UTXOsByAddressesEntry,
+	mempoolEntries []*appmessage.MempoolEntryByAddress) (
+	utxos []*walletUTXO, mempoolExcludedUTXOs map[externalapi.DomainOutpoint]*walletUTXO, err error) {
 
-	exclude := make(map[appmessage.RPCOutpoint]struct{})
-	for _, entriesByAddress := range mempoolEntries {
-		for _, entry := range entriesByAddress.Sending {
-			for _, input := range entry.Transaction.Inputs {
-				exclude[*input.PreviousOutpoint] = struct{}{}
-			}
-		}
+	utxos = make([]*walletUTXO, 0, len(entries))
+
+	exclude, err := mempoolSpentOutpoints(mempoolEntries)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	mempoolExcludedUTXOs := make(map[externalapi.DomainOutpoint]*walletUTXO)
+	mempoolExcludedUTXOs = make(map[externalapi.DomainOutpoint]*walletUTXO)
 	for _, entry := range entries {
 		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		utxoEntry, err := appmessage.RPCUTXOEntryToUTXOEntry(entry.UTXOEntry)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		// No need to lock for reading since the only writer of this set is on `syncLoop` on the same goroutine.
 		address, ok := s.addressSet[entry.Address]
 		if !ok {
-			return errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
+			return nil, nil, errors.Errorf("Got result from address %s even though it wasn't requested", entry.Address)
 		}
 
 		utxo := &walletUTXO{
@@ -265,7 +358,7 @@ func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, memp
 			address:   address,
 		}
 
-		if _, ok := exclude[*entry.Outpoint]; ok {
+		if _, ok := exclude[*outpoint]; ok {
 			mempoolExcludedUTXOs[*outpoint] = utxo
 		} else {
 			utxos = append(utxos, &walletUTXO{
@@ -276,29 +369,200 @@ func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, memp
 		}
 	}
 
+	return utxos, mempoolExcludedUTXOs, nil
+}
+
+// mempoolSpentOutpoints returns the outpoints spent by the given mempool entries
+func mempoolSpentOutpoints(mempoolEntries []*appmessage.MempoolEntryByAddress) (
+	map[externalapi.DomainOutpoint]struct{}, error) {
+
+	spentOutpoints := make(map[externalapi.DomainOutpoint]struct{})
+	for _, entriesByAddress := range mempoolEntries {
+		for _, entry := range entriesByAddress.Sending {
+			for _, input := range entry.Transaction.Inputs {
+				outpoint, err := appmessage.RPCOutpointToDomainOutpoint(input.PreviousOutpoint)
+				if err != nil {
+					return nil, err
+				}
+				spentOutpoints[*outpoint] = struct{}{}
+			}
+		}
+	}
+	return spentOutpoints, nil
+}
+
+func sortUTXOsByAmount(utxos []*walletUTXO) {
 	sort.Slice(utxos, func(i, j int) bool { return utxos[i].UTXOEntry.Amount() > utxos[j].UTXOEntry.Amount() })
+}
+
+// updateUTXOSet clears the current UTXO set, and re-fills it with the given entries
+func (s *server) updateUTXOSet(entries []*appmessage.UTXOsByAddressesEntry, mempoolEntries []*appmessage.MempoolEntryByAddress, refreshStart time.Time) error {
+	utxos, mempoolExcludedUTXOs, err := s.walletUTXOsFromEntries(entries, mempoolEntries)
+	if err != nil {
+		return err
+	}
+
+	sortUTXOsByAmount(utxos)
+
+	err = s.updateTransactionHistory(entries, mempoolEntries)
+	if err != nil {
+		return err
+	}
 
 	s.lock.Lock()
 	s.startTimeOfLastCompletedRefresh = refreshStart
 	s.utxosSortedByAmount = utxos
 	s.mempoolExcludedUTXOs = mempoolExcludedUTXOs
+	s.cleanupExpiredUsedOutpoints()
+	s.lock.Unlock()
 
-	// Cleanup expired used outpoints to avoid a memory leak
+	return nil
+}
+
+// cleanupExpiredUsedOutpoints removes the expired used outpoints to avoid a memory leak.
+// This function is not thread safe
+func (s *server) cleanupExpiredUsedOutpoints() {
 	for outpoint, broadcastTime := range s.usedOutpoints {
 		if s.usedOutpointHasExpired(broadcastTime) {
 			delete(s.usedOutpoints, outpoint)
 		}
 	}
-	s.lock.Unlock()
+}
+
+// updateUTXOSetIncrementally adds the given added entries to the UTXO set, and removes the given removed
+// entries from it. UTXOs that are spent by any of the given mempool entries are kept aside, like in updateUTXOSet.
+func (s *server) updateUTXOSetIncrementally(added []*appmessage.UTXOsByAddressesEntry,
+	removed []*appmessage.UTXOsByAddressesEntry, mempoolEntries []*appmessage.MempoolEntryByAddress) error {
+
+	addedUTXOs, addedMempoolExcludedUTXOs, err := s.walletUTXOsFromEntries(added, mempoolEntries)
+	if err != nil {
+		return err
+	}
+
+	// Outpoints that are re-added are removed first, so that applying the same change twice has no effect
+	removedOutpoints := make(map[externalapi.DomainOutpoint]struct{}, len(added)+len(removed))
+	for _, entry := range append(added, removed...) {
+		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return err
+		}
+		removedOutpoints[*outpoint] = struct{}{}
+	}
+
+	err = s.updateTransactionHistory(added, mempoolEntries)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	utxos := make([]*walletUTXO, 0, len(s.utxosSortedByAmount)+len(addedUTXOs))
+	for _, utxo := range s.utxosSortedByAmount {
+		if _, ok := removedOutpoints[*utxo.Outpoint]; ok {
+			continue
+		}
+		utxos = append(utxos, utxo)
+	}
+	utxos = append(utxos, addedUTXOs...)
+	sortUTXOsByAmount(utxos)
+	s.utxosSortedByAmount = utxos
+
+	for outpoint := range removedOutpoints {
+		delete(s.mempoolExcludedUTXOs, outpoint)
+	}
+	for outpoint, utxo := range addedMempoolExcludedUTXOs {
+		s.mempoolExcludedUTXOs[outpoint] = utxo
+	}
+
+	// A removed UTXO was spent, so there's no need to remember that the wallet used it anymore
+	for _, entry := range removed {
+		outpoint, err := appmessage.RPCOutpointToDomainOutpoint(entry.Outpoint)
+		if err != nil {
+			return err
+		}
+		delete(s.usedOutpoints, *outpoint)
+	}
 
 	return nil
 }
 
+// applyUTXOsChangedNotification applies the changes in the given notification to the UTXO set. Watched
+// addresses that appear in it for the first time are added to the wallet addresses, and the watched
+// addresses are extended past them.
+func (s *server) applyUTXOsChangedNotification(notification *appmessage.UTXOsChangedNotificationMessage) error {
+	// The node might still send changes of addresses the wallet isn't aware of, for example right
+	// after a reconnection, so changes are applied only to known addresses
+	added := make([]*appmessage.UTXOsByAddressesEntry, 0, len(notification.Added))
+	discoveredAddresses := make(walletAddressSet)
+	for _, entry := range notification.Added {
+		if _, ok := s.addressSet[entry.Address]; ok {
+			added = append(added, entry)
+			continue
+		}
+		if walletAddress, ok := s.watchedAddresses[entry.Address]; ok {
+			discoveredAddresses[entry.Address] = walletAddress
+		}
+	}
+
+	err := s.updateUTXOSetIncrementally(added, notification.Removed, s.mempoolEntries)
+	if err != nil {
+		return err
+	}
+
+	if len(discoveredAddresses) == 0 {
+		return nil
+	}
+
+	s.lock.Lock()
+	err = s.addUsedAddresses(discoveredAddresses)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	err = s.refreshAddressesUTXOs(discoveredAddresses.strings())
+	if err != nil {
+		return err
+	}
+
+	return s.watchAddresses()
+}
+
+// refreshUTXOs refreshes the whole UTXO set from the node, and subscribes to the changes of all the
+// watched addresses. Watched addresses that were used while the wallet wasn't subscribed to them
+// are added to the wallet addresses.
 func (s *server) refreshUTXOs() error {
 	refreshStart := time.Now()
 
-	// No need to lock for reading since the only writer of this set is on `syncLoop` on the same goroutine.
+	// Pending notifications predate the refresh, so they are already reflected in it.
+	// Notifications that arrive from now on are applied after the refresh.
+	s.drainUTXOsChangedNotifications()
+
+	// No need to lock for reading since the only writer of these sets is on `syncLoop` on the same goroutine.
+	err := s.subscribeToUTXOsChanged(s.watchedAddresses.strings())
+	if err != nil {
+		return err
+	}
+	unusedAddresses := make(walletAddressSet)
+	for address, walletAddress := range s.watchedAddresses {
+		if _, ok := s.addressSet[address]; !ok {
+			unusedAddresses[address] = walletAddress
+		}
+	}
+	s.lock.Lock()
+	_, err = s.collectUsedAddresses(unusedAddresses)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
 	addresses := s.addressSet.strings()
+	err = s.subscribeToUTXOsChanged(addresses)
+	if err != nil {
+		return err
+	}
+
 	// It's important to check the mempool before calling `GetUTXOsByAddresses`:
 	// If we would do it the other way around an output can be spent in the mempool
 	// and not in consensus, and between the calls its spending transaction will be
@@ -314,7 +578,179 @@ func (s *server) refreshUTXOs() error {
 		return err
 	}
 
-	return s.updateUTXOSet(getUTXOsByAddressesResponse.Entries, mempoolEntriesByAddresses.Entries, refreshStart)
+	s.mempoolEntries = mempoolEntriesByAddresses.Entries
+	err = s.updateUTXOSet(getUTXOsByAddressesResponse.Entries, mempoolEntriesByAddresses.Entries, refreshStart)
+	if err != nil {
+		return err
+	}
+
+	return s.watchAddresses()
+}
+
+// refreshMempool fetches the mempool transactions that pay to or spend from the wallet addresses. The UTXOs
+// they spend are kept aside, like in updateUTXOSet, and they are recorded in the transaction history.
+// This is needed even though the UTXO set is kept up to date by notifications, since these don't cover the mempool.
+func (s *server) refreshMempool() error {
+	// No need to lock for reading since the only writer of this set is on `syncLoop` on the same goroutine.
+	addresses := s.addressSet.strings()
+
+	// Note that querying no addresses at all means querying all the addresses in the mempool
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	mempoolEntriesByAddresses, err := s.backgroundRPCClient.GetMempoolEntriesByAddresses(addresses, true, true)
+	if err != nil {
+		return err
+	}
+	s.mempoolEntries = mempoolEntriesByAddresses.Entries
+
+	exclude, err := mempoolSpentOutpoints(s.mempoolEntries)
+	if err != nil {
+		return err
+	}
+
+	walletOutputs, err := s.repartitionMempoolExcludedUTXOs(exclude)
+	if err != nil {
+		return err
+	}
+
+	return s.updateMempoolTransactionHistory(walletOutputs, s.mempoolEntries)
+}
+
+// repartitionMempoolExcludedUTXOs keeps aside the UTXOs whose outpoints are in exclude, and returns
+// the UTXOs that were kept aside as history outputs, by their outpoint
+func (s *server) repartitionMempoolExcludedUTXOs(exclude map[externalapi.DomainOutpoint]struct{}) (
+	map[string]*historyAmount, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	utxos := make([]*walletUTXO, 0, len(s.utxosSortedByAmount)+len(s.mempoolExcludedUTXOs))
+	mempoolExcludedUTXOs := make(map[externalapi.DomainOutpoint]*walletUTXO)
+	walletOutputs := make(map[string]*historyAmount)
+	partition := func(utxo *walletUTXO) error {
+		if _, ok := exclude[*utxo.Outpoint]; !ok {
+			utxos = append(utxos, utxo)
+			return nil
+		}
+
+		mempoolExcludedUTXOs[*utxo.Outpoint] = utxo
+		address, err := s.walletAddressString(utxo.address)
+		if err != nil {
+			return err
+		}
+		outpoint := historyOutpoint(utxo.Outpoint.TransactionID.String(), utxo.Outpoint.Index)
+		walletOutputs[outpoint] = &historyAmount{Address: address, Amount: utxo.UTXOEntry.Amount()}
+		return nil
+	}
+
+	for _, utxo := range s.utxosSortedByAmount {
+		err := partition(utxo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, utxo := range s.mempoolExcludedUTXOs {
+		err := partition(utxo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sortUTXOsByAmount(utxos)
+	s.utxosSortedByAmount = utxos
+	s.mempoolExcludedUTXOs = mempoolExcludedUTXOs
+
+	return walletOutputs, nil
+}
+
+// refreshAddressesUTXOs fetches the UTXOs of the given addresses, which were found since the last refresh,
+// and subscribes to their changes
+func (s *server) refreshAddressesUTXOs(newAddresses []string) error {
+	if len(newAddresses) == 0 {
+		return nil
+	}
+
+	// Subscribing before fetching makes sure that no change is missed. Changes that are already
+	// included in the fetched UTXOs have no effect when their notifications are applied.
+	err := s.subscribeToUTXOsChanged(newAddresses)
+	if err != nil {
+		return err
+	}
+
+	// See refreshUTXOs for why the mempool is checked first
+	mempoolEntriesByAddresses, err := s.backgroundRPCClient.GetMempoolEntriesByAddresses(newAddresses, true, true)
+	if err != nil {
+		return err
+	}
+
+	getUTXOsByAddressesResponse, err := s.backgroundRPCClient.GetUTXOsByAddresses(newAddresses)
+	if err != nil {
+		return err
+	}
+
+	return s.updateUTXOSetIncrementally(getUTXOsByAddressesResponse.Entries, nil, mempoolEntriesByAddresses.Entries)
+}
+
+// refreshUsedOutpoints checks whether the used outpoints that are about to expire are still spent by transactions
+// in the mempool, and if so keeps them used. Unlike a full refresh, only the addresses of these outpoints are queried.
+func (s *server) refreshUsedOutpoints() error {
+	refreshStart := time.Now()
+
+	s.lock.RLock()
+	var expiringAddresses []string
+	expiringAddressSet := make(map[string]struct{})
+	for _, utxo := range s.utxosSortedByAmount {
+		broadcastTime, ok := s.usedOutpoints[*utxo.Outpoint]
+		if !ok || !refreshStart.After(broadcastTime.Add(time.Minute)) {
+			continue
+		}
+		address, err := s.walletAddressString(utxo.address)
+		if err != nil {
+			s.lock.RUnlock()
+			return err
+		}
+		if _, ok := expiringAddressSet[address]; !ok {
+			expiringAddressSet[address] = struct{}{}
+			expiringAddresses = append(expiringAddresses, address)
+		}
+	}
+	s.lock.RUnlock()
+
+	var mempoolSpentOutpoints []*externalapi.DomainOutpoint
+	if len(expiringAddresses) > 0 {
+		mempoolEntriesByAddresses, err := s.backgroundRPCClient.GetMempoolEntriesByAddresses(expiringAddresses, true, true)
+		if err != nil {
+			return err
+		}
+		for _, entriesByAddress := range mempoolEntriesByAddresses.Entries {
+			for _, entry := range entriesByAddress.Sending {
+				for _, input := range entry.Transaction.Inputs {
+					outpoint, err := appmessage.RPCOutpointToDomainOutpoint(input.PreviousOutpoint)
+					if err != nil {
+						return err
+					}
+					mempoolSpentOutpoints = append(mempoolSpentOutpoints, outpoint)
+				}
+			}
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, outpoint := range mempoolSpentOutpoints {
+		if _, ok := s.usedOutpoints[*outpoint]; ok {
+			s.usedOutpoints[*outpoint] = refreshStart
+		}
+	}
+
+	// The UTXO set is kept up to date by the notifications, so it's as fresh as a completed full refresh
+	s.startTimeOfLastCompletedRefresh = refreshStart
+	s.cleanupExpiredUsedOutpoints()
+
+	return nil
 }
 
 func (s *server) forceSync() {
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestUpdateUTXOSetIncrementally(t *testing.T) {
+	datadir, err := os.MkdirTemp("", "TestUpdateUTXOSetIncrementally")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %s", err)
+	}
+	defer os.RemoveAll(datadir)
+
+	database, err := ldb.NewLevelDB(datadir, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %s", err)
+	}
+	defer database.Close()
+
+	history, err := newTransactionHistory(database)
+	if err != nil {
+		t.Fatalf("newTransactionHistory: %s", err)
+	}
+
+	const address = "kaspa:qqkqkzjvr7zwxxmjxjkmxxdwju9kjs6e9u82uh59z07vgaks6gg62v8707g73"
+	const unknownAddress = "kaspa:qr0lr4ml9fn3chekrqmjdkergxl93l4wrk3dankcgvjq776s9wn9jkdskewva"
+
+	serverInstance := &server{
+		addressSet:           walletAddressSet{address: &walletAddress{}},
+		utxosSortedByAmount:  []*walletUTXO{},
+		mempoolExcludedUTXOs: map[externalapi.DomainOutpoint]*walletUTXO{},
+		usedOutpoints:        map[externalapi.DomainOutpoint]time.Time{},
+		transactionHistory:   history,
+	}
+
+	entry := func(address string, transactionIDChar string, amount uint64) *appmessage.UTXOsByAddressesEntry {
+		return &appmessage.UTXOsByAddressesEntry{
+			Address:  address,
+			Outpoint: &appmessage.RPCOutpoint{TransactionID: strings.Repeat(transactionIDChar, 64), Index: 0},
+			UTXOEntry: &appmessage.RPCUTXOEntry{
+				Amount:          amount,
+				ScriptPublicKey: &appmessage.RPCScriptPublicKey{Version: 0, Script: ""},
+				BlockDAAScore:   1,
+			},
+		}
+	}
+	utxoAmounts := func() []uint64 {
+		amounts := make([]uint64, len(serverInstance.utxosSortedByAmount))
+		for i, utxo := range serverInstance.utxosSortedByAmount {
+			amounts[i] = utxo.UTXOEntry.Amount()
+		}
+		return amounts
+	}
+	expectUTXOAmounts := func(expectedAmounts ...uint64) {
+		t.Helper()
+		amounts := utxoAmounts()
+		if len(amounts) != len(expectedAmounts) {
+			t.Fatalf("expected UTXO amounts %v, got %v", expectedAmounts, amounts)
+		}
+		for i := range amounts {
+			if amounts[i] != expectedAmounts[i] {
+				t.Fatalf("expected UTXO amounts %v, got %v", expectedAmounts, amounts)
+			}
+		}
+	}
+
+	first, second, third := entry(address, "a", 100), entry(address, "b", 300), entry(address, "c", 200)
+
+	notification := &appmessage.UTXOsChangedNotificationMessage{
+		Added: []*appmessage.UTXOsByAddressesEntry{first, second, entry(unknownAddress, "d", 400)},
+	}
+	err = serverInstance.applyUTXOsChangedNotification(notification)
+	if err != nil {
+		t.Fatalf("applyUTXOsChangedNotification: %s", err)
+	}
+	expectUTXOAmounts(300, 100)
+
+	// Applying the same change twice has no effect
+	err = serverInstance.applyUTXOsChangedNotification(notification)
+	if err != nil {
+		t.Fatalf("applyUTXOsChangedNotification: %s", err)
+	}
+	expectUTXOAmounts(300, 100)
+
+	firstOutpoint, err := appmessage.RPCOutpointToDomainOutpoint(first.Outpoint)
+	if err != nil {
+		t.Fatalf("RPCOutpointToDomainOutpoint: %s", err)
+	}
+	serverInstance.usedOutpoints[*firstOutpoint] = time.Now()
+	err = serverInstance.applyUTXOsChangedNotification(&appmessage.UTXOsChangedNotificationMessage{
+		Removed: []*appmessage.UTXOsByAddressesEntry{first},
+	})
+	if err != nil {
+		t.Fatalf("applyUTXOsChangedNotification: %s", err)
+	}
+	expectUTXOAmounts(300)
+	if len(serverInstance.usedOutpoints) != 0 {
+		t.Fatalf("expected the spent outpoint to no longer be used")
+	}
+
+	// An added UTXO that is spent in the mempool is kept aside
+	mempoolEntries := []*appmessage.MempoolEntryByAddress{{
+		Address: address,
+		Sending: []*appmessage.MempoolEntry{{
+			Transaction: &appmessage.RPCTransaction{
+				Inputs:      []*appmessage.RPCTransactionInput{{PreviousOutpoint: third.Outpoint}},
+				VerboseData: &appmessage.RPCTransactionVerboseData{TransactionID: strings.Repeat("e", 64)},
+			},
+		}},
+	}}
+	err = serverInstance.updateUTXOSetIncrementally([]*appmessage.UTXOsByAddressesEntry{third}, nil, mempoolEntries)
+	if err != nil {
+		t.Fatalf("updateUTXOSetIncrementally: %s", err)
+	}
+	expectUTXOAmounts(300)
+	if len(serverInstance.mempoolExcludedUTXOs) != 1 {
+		t.Fatalf("expected 1 mempool excluded UTXO, got %d", len(serverInstance.mempoolExcludedUTXOs))
+	}
+
+	err = serverInstance.applyUTXOsChangedNotification(&appmessage.UTXOsChangedNotificationMessage{
+		Removed: []*appmessage.UTXOsByAddressesEntry{third},
+	})
+	if err != nil {
+		t.Fatalf("applyUTXOsChangedNotification: %s", err)
+	}
+	if len(serverInstance.mempoolExcludedUTXOs) != 0 {
+		t.Fatalf("expected the spent mempool excluded UTXO to be removed")
+	}
+}
+
+func TestRepartitionMempoolExcludedUTXOs(t *testing.T) {
+	params := &dagconfig.SimnetParams
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %s", err)
+	}
+	extendedPublicKey, err := libkaspawallet.MasterPublicKeyFromMnemonic(params, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %s", err)
+	}
+
+	serverInstance := &server{
+		params:   params,
+		keysFile: &keys.File{ExtendedPublicKeys: []string{extendedPublicKey}, MinimumSignatures: 1},
+	}
+
+	address := &walletAddress{}
+	newUTXO := func(transactionIDByte byte, amount uint64) *walletUTXO {
+		return &walletUTXO{
+			Outpoint: &externalapi.DomainOutpoint{
+				TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{transactionIDByte}),
+			},
+			UTXOEntry: utxo.NewUTXOEntry(amount, &externalapi.ScriptPublicKey{}, false, 1),
+			address:   address,
+		}
+	}
+	spentInMempool, notSpent, noLongerSpentInMempool := newUTXO(1, 100), newUTXO(2, 200), newUTXO(3, 300)
+	serverInstance.utxosSortedByAmount = []*walletUTXO{notSpent, spentInMempool}
+	serverInstance.mempoolExcludedUTXOs = map[externalapi.DomainOutpoint]*walletUTXO{
+		*noLongerSpentInMempool.Outpoint: noLongerSpentInMempool,
+	}
+
+	walletOutputs, err := serverInstance.repartitionMempoolExcludedUTXOs(
+		map[externalapi.DomainOutpoint]struct{}{*spentInMempool.Outpoint: {}})
+	if err != nil {
+		t.Fatalf("repartitionMempoolExcludedUTXOs: %s", err)
+	}
+
+	utxos := serverInstance.utxosSortedByAmount
+	if len(utxos) != 2 || utxos[0] != noLongerSpentInMempool || utxos[1] != notSpent {
+		t.Fatalf("expected only the UTXOs that are not spent in the mempool to be available")
+	}
+	if len(serverInstance.mempoolExcludedUTXOs) != 1 ||
+		serverInstance.mempoolExcludedUTXOs[*spentInMempool.Outpoint] != spentInMempool {
+		t.Fatalf("expected only the UTXO that is spent in the mempool to be kept aside")
+	}
+
+	expectedAddress, err := serverInstance.walletAddressString(address)
+	if err != nil {
+		t.Fatalf("walletAddressString: %s", err)
+	}
+	walletOutput, ok := walletOutputs[historyOutpoint(spentInMempool.Outpoint.TransactionID.String(), 0)]
+	if len(walletOutputs) != 1 || !ok || walletOutput.Address != expectedAddress || walletOutput.Amount != 100 {
+		t.Fatalf("unexpected wallet outputs %v", walletOutputs)
+	}
+}
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+var transactionHistoryBucket = database.MakeBucket([]byte("transaction-history"))
+
+// historyAmount is an amount paid to or spent from a wallet address
+type historyAmount struct {
+	Address string `json:"address"`
+	Amount  uint64 `json:"amount"`
+}
+
+// historyTransaction is a transaction that either pays to or spends from the wallet
+type historyTransaction struct {
+	TransactionID string `json:"transactionId"`
+
+	// Inputs are the wallet outputs this transaction spends, by their outpoint
+	Inputs map[string]*historyAmount `json:"inputs"`
+
+	// Outputs are the outputs of this transaction that pay to the wallet, by their index
+	Outputs map[uint32]*historyAmount `json:"outputs"`
+
+	// Fee is the fee of this transaction, or 0 if it's unknown
+	Fee uint64 `json:"fee"`
+
+	IsAccepted         bool   `json:"isAccepted"`
+	AcceptingBlockHash string `json:"acceptingBlockHash,omitempty"`
+	DAAScore           uint64 `json:"daaScore,omitempty"`
+
+	// FirstSeenTimestamp is the time, in unix milliseconds, in which the wallet first saw this transaction
+	FirstSeenTimestamp int64 `json:"firstSeenTimestamp"`
+}
+
+func (ht *historyTransaction) clone() *historyTransaction {
+	clone := *ht
+	clone.Inputs = make(map[string]*historyAmount, len(ht.Inputs))
+	for outpoint, input := range ht.Inputs {
+		inputClone := *input
+		clone.Inputs[outpoint] = &inputClone
+	}
+	clone.Outputs = make(map[uint32]*historyAmount, len(ht.Outputs))
+	for index, output := range ht.Outputs {
+		outputClone := *output
+		clone.Outputs[index] = &outputClone
+	}
+	return &clone
+}
+
+func (ht *historyTransaction) receivedAmount() uint64 {
+	receivedAmount := uint64(0)
+	for _, output := range ht.Outputs {
+		receivedAmount += output.Amount
+	}
+	return receivedAmount
+}
+
+func (ht *historyTransaction) sentAmount() uint64 {
+	sentAmount := uint64(0)
+	for _, input := range ht.Inputs {
+		sentAmount += input.Amount
+	}
+	return sentAmount
+}
+
+func (ht *historyTransaction) involvesAnyAddress(addresses map[string]struct{}) bool {
+	for _, input := range ht.Inputs {
+		if _, ok := addresses[input.Address]; ok {
+			return true
+		}
+	}
+	for _, output := range ht.Outputs {
+		if _, ok := addresses[output.Address]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionHistory keeps the transactions that pay to or spend from the wallet, and
+// persists them into a local database
+type transactionHistory struct {
+	database     database.Database
+	transactions map[string]*historyTransaction
+	dirty        map[string]struct{}
+	removed      map[string]struct{}
+	lock         sync.Mutex
+}
+
+func newTransactionHistory(database database.Database) (*transactionHistory, error) {
+	history := &transactionHistory{
+		database:     database,
+		transactions: make(map[string]*historyTransaction),
+		dirty:        make(map[string]struct{}),
+		removed:      make(map[string]struct{}),
+	}
+
+	cursor, err := database.Cursor(transactionHistoryBucket)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		serializedTransaction, err := cursor.Value()
+		if err != nil {
+			return nil, err
+		}
+		transaction := &historyTransaction{}
+		err = json.Unmarshal(serializedTransaction, transaction)
+		if err != nil {
+			return nil, err
+		}
+		history.transactions[transaction.TransactionID] = transaction
+	}
+
+	return history, nil
+}
+
+// transaction returns the transaction with the given ID, and creates it if it doesn't exist yet.
+// This function is not thread safe
+func (th *transactionHistory) transaction(transactionID string, timestamp int64) *historyTransaction {
+	transaction, ok := th.transactions[transactionID]
+	if !ok {
+		transaction = &historyTransaction{
+			TransactionID:      transactionID,
+			Inputs:             make(map[string]*historyAmount),
+			Outputs:            make(map[uint32]*historyAmount),
+			FirstSeenTimestamp: timestamp,
+		}
+		th.transactions[transactionID] = transaction
+		delete(th.removed, transactionID)
+	}
+	th.dirty[transactionID] = struct{}{}
+	return transaction
+}
+
+// addOutput records that the given output of the given transaction pays to a wallet address
+func (th *transactionHistory) addOutput(transactionID string, index uint32, address string, amount uint64, timestamp int64) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	if transaction, ok := th.transactions[transactionID]; ok {
+		if _, ok := transaction.Outputs[index]; ok {
+			return
+		}
+	}
+	th.transaction(transactionID, timestamp).Outputs[index] = &historyAmount{Address: address, Amount: amount}
+}
+
+// addInput records that the given transaction spends the given wallet outpoint.
+// Pending transactions that spend the same outpoint were replaced, so they are removed
+func (th *transactionHistory) addInput(transactionID string, outpoint string, address string, amount uint64, timestamp int64) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	if transaction, ok := th.transactions[transactionID]; ok {
+		if _, ok := transaction.Inputs[outpoint]; ok {
+			return
+		}
+	}
+
+	for otherTransactionID, otherTransaction := range th.transactions {
+		if otherTransaction.IsAccepted {
+			continue
+		}
+		if _, ok := otherTransaction.Inputs[outpoint]; ok {
+			delete(th.transactions, otherTransactionID)
+			delete(th.dirty, otherTransactionID)
+			th.removed[otherTransactionID] = struct{}{}
+		}
+	}
+
+	th.transaction(transactionID, timestamp).Inputs[outpoint] = &historyAmount{Address: address, Amount: amount}
+}
+
+// setFee sets the fee of the given transaction, if it's already recorded
+func (th *transactionHistory) setFee(transactionID string, fee uint64) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	transaction, ok := th.transactions[transactionID]
+	if !ok || transaction.Fee == fee {
+		return
+	}
+	transaction.Fee = fee
+	th.dirty[transactionID] = struct{}{}
+}
+
+// contains returns whether the given transaction is recorded
+func (th *transactionHistory) contains(transactionID string) bool {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	_, ok := th.transactions[transactionID]
+	return ok
+}
+
+// accept marks the given transaction, if it's recorded, as accepted at the given DAA score.
+// acceptingBlockHash may be empty if it's unknown, in which case the acceptance is reverted
+// by unacceptUnknownFromDAAScore rather than by unaccept on reorgs
+func (th *transactionHistory) accept(transactionID string, acceptingBlockHash string, daaScore uint64) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	transaction, ok := th.transactions[transactionID]
+	if !ok {
+		return
+	}
+	if transaction.IsAccepted && (acceptingBlockHash == "" || acceptingBlockHash == transaction.AcceptingBlockHash) {
+		return
+	}
+	transaction.IsAccepted = true
+	transaction.AcceptingBlockHash = acceptingBlockHash
+	transaction.DAAScore = daaScore
+	th.dirty[transactionID] = struct{}{}
+}
+
+// unaccept marks all the transactions accepted by the given block as not accepted,
+// since the block was removed from the selected chain
+func (th *transactionHistory) unaccept(acceptingBlockHash string) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	for transactionID, transaction := range th.transactions {
+		if !transaction.IsAccepted || transaction.AcceptingBlockHash != acceptingBlockHash {
+			continue
+		}
+		transaction.IsAccepted = false
+		transaction.AcceptingBlockHash = ""
+		transaction.DAAScore = 0
+		th.dirty[transactionID] = struct{}{}
+	}
+}
+
+// hasUnknownAcceptingBlocks returns whether any transaction was accepted by an unknown block
+func (th *transactionHistory) hasUnknownAcceptingBlocks() bool {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	for _, transaction := range th.transactions {
+		if transaction.IsAccepted && transaction.AcceptingBlockHash == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// unacceptUnknownFromDAAScore marks all the transactions that were accepted by an unknown block
+// at the given DAA score or above as not accepted, since they might have been accepted by a block
+// that was removed from the selected chain. Transactions that are still accepted are expected to
+// be accepted again by the new selected chain blocks.
+func (th *transactionHistory) unacceptUnknownFromDAAScore(daaScore uint64) {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	for transactionID, transaction := range th.transactions {
+		if !transaction.IsAccepted || transaction.AcceptingBlockHash != "" || transaction.DAAScore < daaScore {
+			continue
+		}
+		transaction.IsAccepted = false
+		transaction.DAAScore = 0
+		th.dirty[transactionID] = struct{}{}
+	}
+}
+
+// commit persists all the transactions that changed since the last commit
+func (th *transactionHistory) commit() error {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	if len(th.dirty) == 0 && len(th.removed) == 0 {
+		return nil
+	}
+
+	dbTransaction, err := th.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.RollbackUnlessClosed()
+
+	for transactionID := range th.removed {
+		err := dbTransaction.Delete(transactionHistoryBucket.Key([]byte(transactionID)))
+		if err != nil {
+			return err
+		}
+	}
+
+	for transactionID := range th.dirty {
+		serializedTransaction, err := json.Marshal(th.transactions[transactionID])
+		if err != nil {
+			return err
+		}
+		err = dbTransaction.Put(transactionHistoryBucket.Key([]byte(transactionID)), serializedTransaction)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = dbTransaction.Commit()
+	if err != nil {
+		return err
+	}
+
+	th.dirty = make(map[string]struct{})
+	th.removed = make(map[string]struct{})
+	return nil
+}
+
+// sortedTransactions returns copies of the recorded transactions that involve any of the given addresses,
+// or all of them if no addresses are given. Pending transactions come first, and then accepted transactions
+// from the most recent to the oldest.
+func (th *transactionHistory) sortedTransactions(addresses []string) []*historyTransaction {
+	th.lock.Lock()
+	defer th.lock.Unlock()
+
+	addressSet := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		addressSet[address] = struct{}{}
+	}
+
+	transactions := make([]*historyTransaction, 0, len(th.transactions))
+	for _, transaction := range th.transactions {
+		if len(addressSet) > 0 && !transaction.involvesAnyAddress(addressSet) {
+			continue
+		}
+		transactions = append(transactions, transaction.clone())
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].IsAccepted != transactions[j].IsAccepted {
+			return !transactions[i].IsAccepted
+		}
+		if transactions[i].DAAScore != transactions[j].DAAScore {
+			return transactions[i].DAAScore > transactions[j].DAAScore
+		}
+		if transactions[i].FirstSeenTimestamp != transactions[j].FirstSeenTimestamp {
+			return transactions[i].FirstSeenTimestamp > transactions[j].FirstSeenTimestamp
+		}
+		return transactions[i].TransactionID < transactions[j].TransactionID
+	})
+
+	return transactions
+}
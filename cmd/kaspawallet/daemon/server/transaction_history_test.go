@@ -0,0 +1,104 @@
+package server
+
+import (
+	"os"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestTransactionHistory(t *testing.T) {
+	datadir, err := os.MkdirTemp("", "TestTransactionHistory")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %s", err)
+	}
+	defer os.RemoveAll(datadir)
+
+	database, err := ldb.NewLevelDB(datadir, 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %s", err)
+	}
+	defer database.Close()
+
+	history, err := newTransactionHistory(database)
+	if err != nil {
+		t.Fatalf("newTransactionHistory: %s", err)
+	}
+
+	const address = "kaspa:qqkqkzjvr7zwxxmjxjkmxxdwju9kjs6e9u82uh59z07vgaks6gg62v8707g73"
+	const otherAddress = "kaspa:qr0lr4ml9fn3chekrqmjdkergxl93l4wrk3dankcgvjq776s9wn9jkdskewva"
+
+	// A received transaction that was accepted, and a transaction that spends its output
+	history.addOutput("received", 0, address, 1000, 1)
+	history.accept("received", "block1", 10)
+	history.addInput("spending", historyOutpoint("received", 0), address, 1000, 2)
+	history.addOutput("spending", 1, address, 400, 2)
+	history.setFee("spending", 100)
+
+	// A replacement spends the same outpoint, so the original pending transaction is removed
+	history.addInput("replacement", historyOutpoint("received", 0), address, 1000, 3)
+	history.addOutput("replacement", 1, otherAddress, 300, 3)
+	if history.contains("spending") {
+		t.Fatalf("expected the replaced transaction to be removed")
+	}
+
+	err = history.commit()
+	if err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+
+	history.accept("replacement", "block2", 20)
+	history.unaccept("block1")
+	err = history.commit()
+	if err != nil {
+		t.Fatalf("commit: %s", err)
+	}
+
+	reloadedHistory, err := newTransactionHistory(database)
+	if err != nil {
+		t.Fatalf("newTransactionHistory: %s", err)
+	}
+
+	transactions := reloadedHistory.sortedTransactions(nil)
+	if len(transactions) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(transactions))
+	}
+	if transactions[0].TransactionID != "received" || transactions[0].IsAccepted {
+		t.Errorf("expected the unaccepted transaction to be first, got %s", transactions[0].TransactionID)
+	}
+	replacement := transactions[1]
+	if replacement.TransactionID != "replacement" || !replacement.IsAccepted || replacement.DAAScore != 20 ||
+		replacement.AcceptingBlockHash != "block2" {
+		t.Errorf("unexpected replacement transaction %+v", replacement)
+	}
+	if replacement.sentAmount() != 1000 || replacement.receivedAmount() != 300 {
+		t.Errorf("unexpected replacement amounts: sent %d, received %d",
+			replacement.sentAmount(), replacement.receivedAmount())
+	}
+
+	filteredTransactions := reloadedHistory.sortedTransactions([]string{otherAddress})
+	if len(filteredTransactions) != 1 || filteredTransactions[0].TransactionID != "replacement" {
+		t.Errorf("expected only the replacement transaction to involve %s", otherAddress)
+	}
+
+	// Transactions accepted by an unknown block are only unaccepted by reorgs that reach their DAA score
+	reloadedHistory.addOutput("fromUTXOSet", 0, address, 500, 4)
+	reloadedHistory.accept("fromUTXOSet", "", 30)
+	if !reloadedHistory.hasUnknownAcceptingBlocks() {
+		t.Fatalf("expected a transaction to be accepted by an unknown block")
+	}
+	reloadedHistory.unacceptUnknownFromDAAScore(31)
+	if !reloadedHistory.transactions["fromUTXOSet"].IsAccepted {
+		t.Fatalf("expected a reorg above the acceptance DAA score to keep the transaction accepted")
+	}
+	reloadedHistory.unacceptUnknownFromDAAScore(25)
+	if reloadedHistory.transactions["fromUTXOSet"].IsAccepted {
+		t.Fatalf("expected a reorg below the acceptance DAA score to unaccept the transaction")
+	}
+	if !reloadedHistory.transactions["replacement"].IsAccepted {
+		t.Fatalf("expected a transaction with a known accepting block to be left to unaccept")
+	}
+	if reloadedHistory.hasUnknownAcceptingBlocks() {
+		t.Fatalf("expected no transaction to be accepted by an unknown block")
+	}
+}
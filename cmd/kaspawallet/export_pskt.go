@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/pskt"
+	"github.com/pkg/errors"
+)
+
+func exportPSKT(conf *exportPSKTConfig) error {
+	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := readTransactionsInput(conf.Transaction, conf.TransactionFile)
+	if err != nil {
+		return err
+	}
+	if pskt.IsPSKT(data) {
+		return errors.New("The transactions are already a PSKT")
+	}
+
+	transactions, _, err := decodeTransactionsInput(data, conf.NetParams())
+	if err != nil {
+		return err
+	}
+
+	psktFile, err := pskt.New(conf.NetParams().Name, keysFile.ECDSA, transactions)
+	if err != nil {
+		return err
+	}
+	return printPSKT(psktFile)
+}
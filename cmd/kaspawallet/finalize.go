@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func finalize(conf *finalizeConfig) error {
+	psktFile, err := readPSKT(conf.Transaction, conf.TransactionFile, conf.NetParams())
+	if err != nil {
+		return err
+	}
+
+	err = psktFile.Finalize()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "The transactions are finalized and ready to broadcast")
+	return printPSKT(psktFile)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+)
+
+func history(conf *historyConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+	response, err := daemonClient.GetTransactionHistory(ctx, &pb.GetTransactionHistoryRequest{Addresses: conf.Addresses})
+	if err != nil {
+		return err
+	}
+
+	if len(response.Transactions) == 0 {
+		fmt.Println("No transactions found")
+		return nil
+	}
+
+	println("Transaction ID                                                     Amount, KAS             Fee, KAS  Status")
+	println("---------------------------------------------------------------------------------------------------------------------------")
+	for _, transaction := range response.Transactions {
+		fmt.Printf("%s %s %s  %s\n", transaction.TransactionId, formatNetAmount(transaction),
+			utils.FormatKas(transaction.Fee), formatHistoryStatus(transaction))
+
+		if conf.Verbose {
+			fmt.Printf("    First seen: %s\n", time.UnixMilli(transaction.FirstSeenTimestamp).Format(time.RFC3339))
+			for _, input := range transaction.Inputs {
+				fmt.Printf("    Spent    %s from %s%s\n", utils.FormatKas(input.Amount), input.Address, formatLabel(input.Label))
+			}
+			for _, output := range transaction.Outputs {
+				fmt.Printf("    Received %s to   %s%s\n", utils.FormatKas(output.Amount), output.Address, formatLabel(output.Label))
+			}
+		}
+	}
+
+	return nil
+}
+
+// formatNetAmount formats the amount the transaction added to or removed from the wallet, including fees
+func formatNetAmount(transaction *pb.TransactionHistoryEntry) string {
+	sign, amount := "+", transaction.ReceivedAmount-transaction.SentAmount
+	if transaction.SentAmount > transaction.ReceivedAmount {
+		sign, amount = "-", transaction.SentAmount-transaction.ReceivedAmount
+	}
+	if amount == 0 {
+		return fmt.Sprintf("%20s", "0")
+	}
+	return fmt.Sprintf("%20s", sign+strings.TrimSpace(utils.FormatKas(amount)))
+}
+
+func formatLabel(label string) string {
+	if label == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", label)
+}
+
+func formatHistoryStatus(transaction *pb.TransactionHistoryEntry) string {
+	if !transaction.IsAccepted {
+		return "pending"
+	}
+	return fmt.Sprintf("%d confirmations (DAA score %d)", transaction.Confirmations, transaction.DaaScore)
+}
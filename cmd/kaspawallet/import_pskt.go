@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
+)
+
+func importPSKT(conf *importPSKTConfig) error {
+	psktFile, err := readPSKT(conf.Transaction, conf.TransactionFile, conf.NetParams())
+	if err != nil {
+		return err
+	}
+
+	transactions, err := psktFile.PartiallySignedTransactions()
+	if err != nil {
+		return err
+	}
+	fmt.Println(server.EncodeTransactionsToHex(transactions))
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/pskt"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+func inspect(conf *inspectConfig) error {
+	data, err := readTransactionsInput(conf.Transaction, conf.TransactionFile)
+	if err != nil {
+		return err
+	}
+
+	transactions, psktFile, err := decodeTransactionsInput(data, conf.NetParams())
+	if err != nil {
+		return err
+	}
+	if psktFile == nil {
+		// Transactions in the hex format don't carry the signature type, so their mass is estimated with
+		// Schnorr signatures
+		psktFile, err = pskt.New(conf.NetParams().Name, false, transactions)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Format: \thex")
+	} else {
+		fmt.Printf("Format: \tPSKT version %d\n", psktFile.Version)
+	}
+	fmt.Printf("Network: \t%s\n", psktFile.Network)
+	fmt.Println()
+
+	for i, transaction := range psktFile.Transactions {
+		err := inspectTransaction(i, transaction, conf.NetParams(), psktFile.ECDSA)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inspectTransaction(index int, transaction *pskt.Transaction, params *dagconfig.Params, ecdsa bool) error {
+	fmt.Printf("Transaction #%d ID: \t%s\n", index+1, transaction.ID())
+	missingSignatures := transaction.MissingSignatures()
+	switch {
+	case transaction.FinalizedTransaction != nil:
+		fmt.Println("Status: \tfinalized and ready to broadcast")
+	case missingSignatures == 0:
+		fmt.Println("Status: \tfully signed, not finalized yet")
+	default:
+		fmt.Printf("Status: \tmissing %d signature(s)\n", missingSignatures)
+	}
+	fmt.Println()
+
+	partiallySignedTransaction := transaction.PartiallySignedTransaction
+	minimumSignatures := uint32(0)
+	for inputIndex, input := range partiallySignedTransaction.Tx.Inputs {
+		partiallySignedInput := partiallySignedTransaction.PartiallySignedInputs[inputIndex]
+
+		numSignatures := uint32(0)
+		for _, pair := range partiallySignedInput.PubKeySignaturePairs {
+			if pair.Signature != nil {
+				numSignatures++
+			}
+		}
+		fmt.Printf("Input %d: \tOutpoint: %s:%d \tAmount: %.2f Kaspa \tSignatures: %d of %d\n", inputIndex,
+			input.PreviousOutpoint.TransactionID, input.PreviousOutpoint.Index,
+			float64(partiallySignedInput.PrevOutput.Value)/float64(constants.SompiPerKaspa),
+			numSignatures, partiallySignedInput.MinimumSignatures)
+		for _, pair := range partiallySignedInput.PubKeySignaturePairs {
+			signedString := "unsigned"
+			if pair.Signature != nil {
+				signedString = "signed"
+			}
+			fmt.Printf("\t\t%s \t%s\n", signedString, pair.ExtendedPublicKey)
+		}
+
+		if partiallySignedInput.MinimumSignatures > minimumSignatures {
+			minimumSignatures = partiallySignedInput.MinimumSignatures
+		}
+	}
+	fmt.Println()
+
+	err := printOutputsAndFee(partiallySignedTransaction, params, ecdsa, minimumSignatures)
+	if err != nil {
+		return errors.Wrapf(err, "Transaction #%d", index+1)
+	}
+	fmt.Println()
+	return nil
+}
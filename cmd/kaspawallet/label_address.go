@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/client"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+)
+
+func labelAddress(conf *labelAddressConfig) error {
+	daemonClient, tearDown, err := client.Connect(conf.DaemonAddress)
+	if err != nil {
+		return err
+	}
+	defer tearDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
+	defer cancel()
+
+	_, err = daemonClient.SetAddressLabel(ctx, &pb.SetAddressLabelRequest{Address: conf.Address, Label: conf.Label})
+	if err != nil {
+		return err
+	}
+
+	if conf.Label == "" {
+		fmt.Printf("Removed the label of %s\n", conf.Address)
+	} else {
+		fmt.Printf("Labeled %s as '%s'\n", conf.Address, conf.Label)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package libkaspawallet_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/bip32"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestValidateExtendedPublicKey(t *testing.T) {
+	mnemonic, err := libkaspawallet.CreateMnemonic()
+	if err != nil {
+		t.Fatalf("CreateMnemonic: %+v", err)
+	}
+
+	extendedPublicKey, err := libkaspawallet.MasterPublicKeyFromMnemonic(&dagconfig.MainnetParams, mnemonic, false)
+	if err != nil {
+		t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+	}
+
+	err = libkaspawallet.ValidateExtendedPublicKey(&dagconfig.MainnetParams, extendedPublicKey)
+	if err != nil {
+		t.Fatalf("ValidateExtendedPublicKey: %+v", err)
+	}
+
+	err = libkaspawallet.ValidateExtendedPublicKey(&dagconfig.TestnetParams, extendedPublicKey)
+	if err == nil {
+		t.Fatalf("expected a mainnet extended public key to be invalid on testnet")
+	}
+
+	extendedPrivateKey, err := bip32.NewMasterWithPath(bip39.NewSeed(mnemonic, ""), bip32.KaspaMainnetPrivate, "m/44'/111111'/0'")
+	if err != nil {
+		t.Fatalf("NewMasterWithPath: %+v", err)
+	}
+	err = libkaspawallet.ValidateExtendedPublicKey(&dagconfig.MainnetParams, extendedPrivateKey.String())
+	if err == nil {
+		t.Fatalf("expected an extended private key to be rejected")
+	}
+
+	err = libkaspawallet.ValidateExtendedPublicKey(&dagconfig.MainnetParams, "not a key")
+	if err == nil {
+		t.Fatalf("expected an invalid string to be rejected")
+	}
+}
@@ -0,0 +1,422 @@
+/*
+Package pskt implements PSKT (Partially Signed Kaspa Transaction) files, a versioned
+format for passing unsigned and partially signed transactions between the parties
+that have to sign them.
+
+A PSKT file is a JSON document of the following form:
+
+	{
+	  "type": "kaspa-pskt",
+	  "version": 1,
+	  "network": "kaspa-mainnet",
+	  "ecdsa": false,
+	  "transactions": [
+	    {
+	      "id": "<transaction ID>",
+	      "partiallySignedTransaction": "<hex>",
+	      "finalizedTransaction": "<hex>"
+	    }
+	  ]
+	}
+
+The type is always "kaspa-pskt", and version is the version of the format. Readers reject
+versions newer than the ones they know. The network is the name of the network the transactions
+belong to, and ecdsa is whether the keys of the wallet that created them are ECDSA keys rather
+than Schnorr keys.
+
+Each transaction has an id, which doesn't depend on the signatures and so stays the same
+throughout the signing process. The partiallySignedTransaction is the protobuf serialization
+of the transaction together with the data needed for signing it: the outputs it spends, their
+derivation paths, and the extended public key and signature (if any) of every required signer.
+This is the same data the kaspawallet hex format holds. The finalizedTransaction is the protobuf
+serialization of the fully signed transaction, ready for broadcasting, and it's only present
+after the transaction was finalized.
+
+The transactions of a single file are signed, combined and finalized together.
+*/
+package pskt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/pkg/errors"
+)
+
+// Type is the value of the type field of every PSKT file
+const Type = "kaspa-pskt"
+
+// Version is the most up to date PSKT format version
+const Version = 1
+
+// PSKT is a set of partially signed transactions, together with the metadata needed to sign,
+// combine and finalize them
+type PSKT struct {
+	// Version is the format version of the file the PSKT was decoded from, or Version for a new PSKT.
+	// A PSKT is always encoded with the most up to date format version.
+	Version      uint32
+	Network      string
+	ECDSA        bool
+	Transactions []*Transaction
+}
+
+// Transaction is a single transaction in a PSKT
+type Transaction struct {
+	PartiallySignedTransaction *serialization.PartiallySignedTransaction
+
+	// FinalizedTransaction is nil until the transaction is finalized
+	FinalizedTransaction *externalapi.DomainTransaction
+}
+
+// ID returns the ID of the transaction
+func (t *Transaction) ID() *externalapi.DomainTransactionID {
+	return consensushashing.TransactionID(t.PartiallySignedTransaction.Tx)
+}
+
+// MissingSignatures returns the number of signatures that are still required, over all the inputs of the
+// transaction, before it can be finalized
+func (t *Transaction) MissingSignatures() uint32 {
+	missingSignatures := uint32(0)
+	for _, input := range t.PartiallySignedTransaction.PartiallySignedInputs {
+		numSignatures := uint32(0)
+		for _, pair := range input.PubKeySignaturePairs {
+			if pair.Signature != nil {
+				numSignatures++
+			}
+		}
+		if numSignatures < input.MinimumSignatures {
+			missingSignatures += input.MinimumSignatures - numSignatures
+		}
+	}
+	return missingSignatures
+}
+
+type psktJSON struct {
+	Type         string             `json:"type"`
+	Version      uint32             `json:"version"`
+	Network      string             `json:"network"`
+	ECDSA        bool               `json:"ecdsa"`
+	Transactions []*transactionJSON `json:"transactions"`
+}
+
+type transactionJSON struct {
+	ID                         string `json:"id"`
+	PartiallySignedTransaction string `json:"partiallySignedTransaction"`
+	FinalizedTransaction       string `json:"finalizedTransaction,omitempty"`
+}
+
+// New creates a PSKT from the given serialized partially signed transactions
+func New(network string, ecdsa bool, partiallySignedTransactions [][]byte) (*PSKT, error) {
+	if len(partiallySignedTransactions) == 0 {
+		return nil, errors.New("a PSKT must contain at least one transaction")
+	}
+
+	pskt := &PSKT{
+		Version:      Version,
+		Network:      network,
+		ECDSA:        ecdsa,
+		Transactions: make([]*Transaction, len(partiallySignedTransactions)),
+	}
+	for i, partiallySignedTransactionBytes := range partiallySignedTransactions {
+		partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(partiallySignedTransactionBytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error deserializing transaction #%d", i+1)
+		}
+		pskt.Transactions[i] = &Transaction{PartiallySignedTransaction: partiallySignedTransaction}
+	}
+	return pskt, nil
+}
+
+// IsPSKT returns whether the given data looks like a PSKT file, as opposed to the hex format
+func IsPSKT(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// Decode parses the given PSKT file
+func Decode(data []byte) (*PSKT, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	decoded := &psktJSON{}
+	err := decoder.Decode(decoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing the PSKT")
+	}
+
+	if decoded.Type != Type {
+		return nil, errors.Errorf("unexpected PSKT type %q", decoded.Type)
+	}
+	if decoded.Version == 0 || decoded.Version > Version {
+		return nil, errors.Errorf("unsupported PSKT version %d. The highest supported version is %d",
+			decoded.Version, Version)
+	}
+	if len(decoded.Transactions) == 0 {
+		return nil, errors.New("the PSKT doesn't contain any transactions")
+	}
+
+	pskt := &PSKT{
+		Version:      decoded.Version,
+		Network:      decoded.Network,
+		ECDSA:        decoded.ECDSA,
+		Transactions: make([]*Transaction, len(decoded.Transactions)),
+	}
+	for i, transactionJSON := range decoded.Transactions {
+		transaction, err := transactionFromJSON(transactionJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing transaction #%d", i+1)
+		}
+		pskt.Transactions[i] = transaction
+	}
+	return pskt, nil
+}
+
+func transactionFromJSON(transactionJSON *transactionJSON) (*Transaction, error) {
+	partiallySignedTransactionBytes, err := hex.DecodeString(transactionJSON.PartiallySignedTransaction)
+	if err != nil {
+		return nil, err
+	}
+	partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(partiallySignedTransactionBytes)
+	if err != nil {
+		return nil, err
+	}
+	transaction := &Transaction{PartiallySignedTransaction: partiallySignedTransaction}
+
+	if transaction.ID().String() != transactionJSON.ID {
+		return nil, errors.Errorf("the transaction ID %s doesn't match the transaction, whose ID is %s",
+			transactionJSON.ID, transaction.ID())
+	}
+
+	if transactionJSON.FinalizedTransaction != "" {
+		finalizedTransactionBytes, err := hex.DecodeString(transactionJSON.FinalizedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		transaction.FinalizedTransaction, err = serialization.DeserializeDomainTransaction(finalizedTransactionBytes)
+		if err != nil {
+			return nil, err
+		}
+		if !consensushashing.TransactionID(transaction.FinalizedTransaction).Equal(transaction.ID()) {
+			return nil, errors.Errorf("the finalized transaction of %s is a different transaction", transactionJSON.ID)
+		}
+	}
+
+	return transaction, nil
+}
+
+// Encode serializes the PSKT into a PSKT file
+func (p *PSKT) Encode() ([]byte, error) {
+	encoded := &psktJSON{
+		Type:         Type,
+		Version:      Version,
+		Network:      p.Network,
+		ECDSA:        p.ECDSA,
+		Transactions: make([]*transactionJSON, len(p.Transactions)),
+	}
+	for i, transaction := range p.Transactions {
+		partiallySignedTransactionBytes, err := serialization.SerializePartiallySignedTransaction(transaction.PartiallySignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Transactions[i] = &transactionJSON{
+			ID:                         transaction.ID().String(),
+			PartiallySignedTransaction: hex.EncodeToString(partiallySignedTransactionBytes),
+		}
+
+		if transaction.FinalizedTransaction != nil {
+			finalizedTransactionBytes, err := serialization.SerializeDomainTransaction(transaction.FinalizedTransaction)
+			if err != nil {
+				return nil, err
+			}
+			encoded.Transactions[i].FinalizedTransaction = hex.EncodeToString(finalizedTransactionBytes)
+		}
+	}
+
+	return json.MarshalIndent(encoded, "", "  ")
+}
+
+// PartiallySignedTransactions returns the serialized partially signed transactions of the PSKT,
+// in the format the rest of kaspawallet uses
+func (p *PSKT) PartiallySignedTransactions() ([][]byte, error) {
+	partiallySignedTransactions := make([][]byte, len(p.Transactions))
+	for i, transaction := range p.Transactions {
+		var err error
+		partiallySignedTransactions[i], err = serialization.SerializePartiallySignedTransaction(transaction.PartiallySignedTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return partiallySignedTransactions, nil
+}
+
+// SetPartiallySignedTransactions replaces the partially signed transactions of the PSKT with the given
+// ones, for example after they were signed. The transactions must be the same transactions in the same order.
+func (p *PSKT) SetPartiallySignedTransactions(partiallySignedTransactions [][]byte) error {
+	updated, err := New(p.Network, p.ECDSA, partiallySignedTransactions)
+	if err != nil {
+		return err
+	}
+	if len(updated.Transactions) != len(p.Transactions) {
+		return errors.Errorf("expected %d transactions but got %d", len(p.Transactions), len(updated.Transactions))
+	}
+	for i, transaction := range updated.Transactions {
+		if !transaction.ID().Equal(p.Transactions[i].ID()) {
+			return errors.Errorf("transaction #%d was replaced by a different transaction", i+1)
+		}
+	}
+
+	// The signatures might have changed, so the transactions have to be finalized again
+	p.Transactions = updated.Transactions
+	return nil
+}
+
+// IsFullySigned returns whether all the transactions of the PSKT have enough signatures to be finalized
+func (p *PSKT) IsFullySigned() bool {
+	for _, transaction := range p.Transactions {
+		if transaction.MissingSignatures() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFinalized returns whether all the transactions of the PSKT are finalized
+func (p *PSKT) IsFinalized() bool {
+	for _, transaction := range p.Transactions {
+		if transaction.FinalizedTransaction == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Finalize builds the broadcastable transactions of the PSKT. It fails if any of the transactions is
+// missing signatures.
+func (p *PSKT) Finalize() error {
+	for i, transaction := range p.Transactions {
+		if transaction.FinalizedTransaction != nil {
+			continue
+		}
+
+		finalizedTransaction, err := libkaspawallet.ExtractTransactionDeserialized(
+			transaction.PartiallySignedTransaction.Clone(), p.ECDSA)
+		if err != nil {
+			return errors.Wrapf(err, "error finalizing transaction #%d (%s)", i+1, transaction.ID())
+		}
+		// The UTXO entries are only used for signing, and aren't part of the finalized transaction
+		for _, input := range finalizedTransaction.Inputs {
+			input.UTXOEntry = nil
+		}
+		transaction.FinalizedTransaction = finalizedTransaction
+	}
+	return nil
+}
+
+// FinalizedTransactions returns the serialized finalized transactions of the PSKT.
+// The PSKT must be finalized.
+func (p *PSKT) FinalizedTransactions() ([][]byte, error) {
+	if !p.IsFinalized() {
+		return nil, errors.New("the PSKT isn't finalized")
+	}
+
+	finalizedTransactions := make([][]byte, len(p.Transactions))
+	for i, transaction := range p.Transactions {
+		var err error
+		finalizedTransactions[i], err = serialization.SerializeDomainTransaction(transaction.FinalizedTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return finalizedTransactions, nil
+}
+
+// Combine merges the signatures of the given PSKTs, which must all hold the same transactions.
+// The result isn't finalized.
+func Combine(pskts ...*PSKT) (*PSKT, error) {
+	if len(pskts) == 0 {
+		return nil, errors.New("no PSKTs to combine")
+	}
+
+	first := pskts[0]
+	combined := &PSKT{
+		Version:      Version,
+		Network:      first.Network,
+		ECDSA:        first.ECDSA,
+		Transactions: make([]*Transaction, len(first.Transactions)),
+	}
+	for i, transaction := range first.Transactions {
+		combined.Transactions[i] = &Transaction{PartiallySignedTransaction: transaction.PartiallySignedTransaction.Clone()}
+	}
+
+	for i, other := range pskts[1:] {
+		if other.Network != combined.Network || other.ECDSA != combined.ECDSA {
+			return nil, errors.Errorf("PSKT #%d belongs to a different network or wallet type than PSKT #1", i+2)
+		}
+		if len(other.Transactions) != len(combined.Transactions) {
+			return nil, errors.Errorf("PSKT #%d has %d transactions while PSKT #1 has %d",
+				i+2, len(other.Transactions), len(combined.Transactions))
+		}
+
+		for j, transaction := range combined.Transactions {
+			err := combineTransaction(transaction.PartiallySignedTransaction, other.Transactions[j].PartiallySignedTransaction)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error combining transaction #%d of PSKT #%d", j+1, i+2)
+			}
+		}
+	}
+
+	return combined, nil
+}
+
+// combineTransaction copies into target the signatures from source that target is missing
+func combineTransaction(target, source *serialization.PartiallySignedTransaction) error {
+	isSame, err := isSameUnsignedTransaction(target, source)
+	if err != nil {
+		return err
+	}
+	if !isSame {
+		return errors.Errorf("transaction %s is not the same as transaction %s",
+			consensushashing.TransactionID(source.Tx), consensushashing.TransactionID(target.Tx))
+	}
+
+	for i, input := range target.PartiallySignedInputs {
+		for j, pair := range input.PubKeySignaturePairs {
+			sourceSignature := source.PartiallySignedInputs[i].PubKeySignaturePairs[j].Signature
+			if pair.Signature == nil && sourceSignature != nil {
+				pair.Signature = make([]byte, len(sourceSignature))
+				copy(pair.Signature, sourceSignature)
+			}
+		}
+	}
+	return nil
+}
+
+// isSameUnsignedTransaction returns whether the given transactions are the same, ignoring their signatures
+func isSameUnsignedTransaction(a, b *serialization.PartiallySignedTransaction) (bool, error) {
+	aBytes, err := serializeWithoutSignatures(a)
+	if err != nil {
+		return false, err
+	}
+	bBytes, err := serializeWithoutSignatures(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+func serializeWithoutSignatures(partiallySignedTransaction *serialization.PartiallySignedTransaction) ([]byte, error) {
+	clone := partiallySignedTransaction.Clone()
+	for _, input := range clone.Tx.Inputs {
+		input.SignatureScript = nil
+		input.SigOpCount = 0
+	}
+	for _, input := range clone.PartiallySignedInputs {
+		for _, pair := range input.PubKeySignaturePairs {
+			pair.Signature = nil
+		}
+	}
+	return serialization.SerializePartiallySignedTransaction(clone)
+}
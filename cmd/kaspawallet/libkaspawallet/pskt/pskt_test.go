@@ -0,0 +1,174 @@
+package pskt_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/pskt"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/serialization"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+)
+
+func TestCombineAndFinalize(t *testing.T) {
+	params := &dagconfig.SimnetParams
+
+	const numKeys = 3
+	const minimumSignatures = 2
+	mnemonics := make([]string, numKeys)
+	publicKeys := make([]string, numKeys)
+	for i := 0; i < numKeys; i++ {
+		var err error
+		mnemonics[i], err = libkaspawallet.CreateMnemonic()
+		if err != nil {
+			t.Fatalf("CreateMnemonic: %+v", err)
+		}
+		publicKeys[i], err = libkaspawallet.MasterPublicKeyFromMnemonic(params, mnemonics[i], true)
+		if err != nil {
+			t.Fatalf("MasterPublicKeyFromMnemonic: %+v", err)
+		}
+	}
+
+	const path = "m/1/2/3"
+	address, err := libkaspawallet.Address(params, publicKeys, minimumSignatures, path, false)
+	if err != nil {
+		t.Fatalf("Address: %+v", err)
+	}
+	scriptPublicKey, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: %+v", err)
+	}
+
+	selectedUTXOs := []*libkaspawallet.UTXO{{
+		Outpoint: &externalapi.DomainOutpoint{
+			TransactionID: *externalapi.NewDomainTransactionIDFromByteArray(&[externalapi.DomainHashSize]byte{1}),
+			Index:         0,
+		},
+		UTXOEntry:      utxo.NewUTXOEntry(1000, scriptPublicKey, false, 0),
+		DerivationPath: path,
+	}}
+	unsignedTransaction, err := libkaspawallet.CreateUnsignedTransaction(publicKeys, minimumSignatures,
+		[]*libkaspawallet.Payment{{Address: address, Amount: 900}}, selectedUTXOs)
+	if err != nil {
+		t.Fatalf("CreateUnsignedTransaction: %+v", err)
+	}
+	unsignedTransactionBytes, err := serialization.SerializePartiallySignedTransaction(unsignedTransaction)
+	if err != nil {
+		t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+	}
+
+	// Each cosigner gets the PSKT file, signs it and passes it back
+	signedPSKTs := make([]*pskt.PSKT, minimumSignatures)
+	for i := range signedPSKTs {
+		unsignedPSKT, err := pskt.New(params.Name, false, [][]byte{unsignedTransactionBytes})
+		if err != nil {
+			t.Fatalf("New: %+v", err)
+		}
+		encoded, err := unsignedPSKT.Encode()
+		if err != nil {
+			t.Fatalf("Encode: %+v", err)
+		}
+		if !pskt.IsPSKT(encoded) {
+			t.Fatalf("expected the encoded PSKT to be recognized as one")
+		}
+
+		decoded, err := pskt.Decode(encoded)
+		if err != nil {
+			t.Fatalf("Decode: %+v", err)
+		}
+		partiallySignedTransactions, err := decoded.PartiallySignedTransactions()
+		if err != nil {
+			t.Fatalf("PartiallySignedTransactions: %+v", err)
+		}
+		signedTransaction, err := libkaspawallet.Sign(params, mnemonics[i:i+1], partiallySignedTransactions[0], false)
+		if err != nil {
+			t.Fatalf("Sign: %+v", err)
+		}
+		err = decoded.SetPartiallySignedTransactions([][]byte{signedTransaction})
+		if err != nil {
+			t.Fatalf("SetPartiallySignedTransactions: %+v", err)
+		}
+		signedPSKTs[i] = decoded
+	}
+
+	if signedPSKTs[0].IsFullySigned() || signedPSKTs[0].Transactions[0].MissingSignatures() != 1 {
+		t.Fatalf("expected a single signature to be missing")
+	}
+	err = signedPSKTs[0].Finalize()
+	if err == nil {
+		t.Fatalf("expected finalizing a transaction with a single signature to fail")
+	}
+
+	combined, err := pskt.Combine(signedPSKTs...)
+	if err != nil {
+		t.Fatalf("Combine: %+v", err)
+	}
+	if !combined.IsFullySigned() {
+		t.Fatalf("expected the combined PSKT to be fully signed")
+	}
+	err = combined.Finalize()
+	if err != nil {
+		t.Fatalf("Finalize: %+v", err)
+	}
+
+	encoded, err := combined.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %+v", err)
+	}
+	decoded, err := pskt.Decode(encoded)
+	if err != nil {
+		t.Fatalf("Decode: %+v", err)
+	}
+	if !decoded.IsFinalized() {
+		t.Fatalf("expected the decoded PSKT to be finalized")
+	}
+
+	finalizedTransactions, err := decoded.FinalizedTransactions()
+	if err != nil {
+		t.Fatalf("FinalizedTransactions: %+v", err)
+	}
+	finalizedTransaction, err := serialization.DeserializeDomainTransaction(finalizedTransactions[0])
+	if err != nil {
+		t.Fatalf("DeserializeDomainTransaction: %+v", err)
+	}
+	if !consensushashing.TransactionID(finalizedTransaction).Equal(consensushashing.TransactionID(unsignedTransaction.Tx)) {
+		t.Fatalf("the finalized transaction has an unexpected ID")
+	}
+	if len(finalizedTransaction.Inputs[0].SignatureScript) == 0 {
+		t.Fatalf("expected the finalized transaction to be signed")
+	}
+
+	// A PSKT of a different transaction can't be combined
+	otherTransaction, err := libkaspawallet.CreateUnsignedTransaction(publicKeys, minimumSignatures,
+		[]*libkaspawallet.Payment{{Address: address, Amount: 800}}, selectedUTXOs)
+	if err != nil {
+		t.Fatalf("CreateUnsignedTransaction: %+v", err)
+	}
+	otherTransactionBytes, err := serialization.SerializePartiallySignedTransaction(otherTransaction)
+	if err != nil {
+		t.Fatalf("SerializePartiallySignedTransaction: %+v", err)
+	}
+	otherPSKT, err := pskt.New(params.Name, false, [][]byte{otherTransactionBytes})
+	if err != nil {
+		t.Fatalf("New: %+v", err)
+	}
+	_, err = pskt.Combine(signedPSKTs[0], otherPSKT)
+	if err == nil {
+		t.Fatalf("expected combining different transactions to fail")
+	}
+}
+
+func TestDecodeRejectsUnsupportedVersions(t *testing.T) {
+	_, err := pskt.Decode([]byte(`{"type": "kaspa-pskt", "version": 2, "network": "kaspa-mainnet", "transactions": []}`))
+	if err == nil {
+		t.Fatalf("expected an unsupported version to be rejected")
+	}
+
+	_, err = pskt.Decode([]byte(`{"type": "something-else", "version": 1, "network": "kaspa-mainnet", "transactions": []}`))
+	if err == nil {
+		t.Fatalf("expected an unknown type to be rejected")
+	}
+}
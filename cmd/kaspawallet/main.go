@@ -42,6 +42,26 @@ func main() {
 		err = bumpFee(config.(*bumpFeeConfig))
 	case bumpFeeUnsignedSubCmd:
 		err = bumpFeeUnsigned(config.(*bumpFeeUnsignedConfig))
+	case historySubCmd:
+		err = history(config.(*historyConfig))
+	case exportPSKTSubCmd:
+		err = exportPSKT(config.(*exportPSKTConfig))
+	case importPSKTSubCmd:
+		err = importPSKT(config.(*importPSKTConfig))
+	case combineSubCmd:
+		err = combine(config.(*combineConfig))
+	case finalizeSubCmd:
+		err = finalize(config.(*finalizeConfig))
+	case inspectSubCmd:
+		err = inspect(config.(*inspectConfig))
+	case labelAddressSubCmd:
+		err = labelAddress(config.(*labelAddressConfig))
+	case addContactSubCmd:
+		err = addContact(config.(*addContactConfig))
+	case removeContactSubCmd:
+		err = removeContact(config.(*removeContactConfig))
+	case showContactsSubCmd:
+		err = showContacts(config.(*showContactsConfig))
 	default:
 		err = errors.Errorf("Unknown sub-command '%s'\n", subCmd)
 	}
@@ -17,7 +17,7 @@ func newAddress(conf *newAddressConfig) error {
 	ctx, cancel := context.WithTimeout(context.Background(), daemonTimeout)
 	defer cancel()
 
-	response, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{})
+	response, err := daemonClient.NewAddress(ctx, &pb.NewAddressRequest{Label: conf.Label})
 	if err != nil {
 		return err
 	}
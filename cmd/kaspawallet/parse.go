@@ -12,6 +12,7 @@ import (
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/constants"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 	"github.com/kaspanet/kaspad/util/txmass"
 	"github.com/pkg/errors"
 )
@@ -43,7 +44,6 @@ func parse(conf *parseConfig) error {
 		return err
 	}
 
-	txMassCalculator := txmass.NewCalculator(conf.NetParams().MassPerTxByte, conf.NetParams().MassPerScriptPubKeyByte, conf.NetParams().MassPerSigOp)
 	for i, transaction := range transactions {
 
 		partiallySignedTransaction, err := serialization.DeserializePartiallySignedTransaction(transaction)
@@ -54,52 +54,68 @@ func parse(conf *parseConfig) error {
 		fmt.Printf("Transaction #%d ID: \t%s\n", i+1, consensushashing.TransactionID(partiallySignedTransaction.Tx))
 		fmt.Println()
 
-		allInputSompi := uint64(0)
-		for index, input := range partiallySignedTransaction.Tx.Inputs {
-			partiallySignedInput := partiallySignedTransaction.PartiallySignedInputs[index]
-
-			if conf.Verbose {
+		if conf.Verbose {
+			for index, input := range partiallySignedTransaction.Tx.Inputs {
+				partiallySignedInput := partiallySignedTransaction.PartiallySignedInputs[index]
 				fmt.Printf("Input %d: \tOutpoint: %s:%d \tAmount: %.2f Kaspa\n", index, input.PreviousOutpoint.TransactionID,
 					input.PreviousOutpoint.Index, float64(partiallySignedInput.PrevOutput.Value)/float64(constants.SompiPerKaspa))
 			}
-
-			allInputSompi += partiallySignedInput.PrevOutput.Value
-		}
-		if conf.Verbose {
 			fmt.Println()
 		}
 
-		allOutputSompi := uint64(0)
-		for index, output := range partiallySignedTransaction.Tx.Outputs {
-			scriptPublicKeyType, scriptPublicKeyAddress, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, conf.ActiveNetParams)
-			if err != nil {
-				return err
-			}
+		err = printOutputsAndFee(partiallySignedTransaction, conf.NetParams(), keysFile.ECDSA, keysFile.MinimumSignatures)
+		if err != nil {
+			return errors.Wrapf(err, "Transaction #%d", i+1)
+		}
+	}
 
-			addressString := scriptPublicKeyAddress.EncodeAddress()
-			if scriptPublicKeyType == txscript.NonStandardTy {
-				scriptPublicKeyHex := hex.EncodeToString(output.ScriptPublicKey.Script)
-				addressString = fmt.Sprintf("<Non-standard transaction script public key: %s>", scriptPublicKeyHex)
-			}
+	return nil
+}
 
-			fmt.Printf("Output %d: \tRecipient: %s \tAmount: %.2f Kaspa\n",
-				index, addressString, float64(output.Value)/float64(constants.SompiPerKaspa))
+// printOutputsAndFee prints the outputs of the given transaction, followed by its fee, its mass once
+// it's fully signed, and its fee rate
+func printOutputsAndFee(partiallySignedTransaction *serialization.PartiallySignedTransaction, params *dagconfig.Params,
+	ecdsa bool, minimumSignatures uint32) error {
 
-			allOutputSompi += output.Value
-		}
-		fmt.Println()
+	allInputSompi := uint64(0)
+	for _, partiallySignedInput := range partiallySignedTransaction.PartiallySignedInputs {
+		allInputSompi += partiallySignedInput.PrevOutput.Value
+	}
 
-		fee := allInputSompi - allOutputSompi
-		fmt.Printf("Fee:\t%d Sompi (%f KAS)\n", fee, float64(fee)/float64(constants.SompiPerKaspa))
-		mass, err := server.EstimateMassAfterSignatures(partiallySignedTransaction, keysFile.ECDSA, keysFile.MinimumSignatures, txMassCalculator)
+	allOutputSompi := uint64(0)
+	for index, output := range partiallySignedTransaction.Tx.Outputs {
+		scriptPublicKeyType, scriptPublicKeyAddress, err := txscript.ExtractScriptPubKeyAddress(output.ScriptPublicKey, params)
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Mass: %d grams\n", mass)
-		feeRate := float64(fee) / float64(mass)
-		fmt.Printf("Fee rate: %.2f Sompi/Gram\n", feeRate)
+		addressString := scriptPublicKeyAddress.EncodeAddress()
+		if scriptPublicKeyType == txscript.NonStandardTy {
+			scriptPublicKeyHex := hex.EncodeToString(output.ScriptPublicKey.Script)
+			addressString = fmt.Sprintf("<Non-standard transaction script public key: %s>", scriptPublicKeyHex)
+		}
+
+		fmt.Printf("Output %d: \tRecipient: %s \tAmount: %.2f Kaspa\n",
+			index, addressString, float64(output.Value)/float64(constants.SompiPerKaspa))
+
+		allOutputSompi += output.Value
+	}
+	fmt.Println()
+
+	if allOutputSompi > allInputSompi {
+		return errors.New("the transaction spends more than its inputs")
+	}
+	fee := allInputSompi - allOutputSompi
+	fmt.Printf("Fee:\t%d Sompi (%f KAS)\n", fee, float64(fee)/float64(constants.SompiPerKaspa))
+
+	txMassCalculator := txmass.NewCalculator(params.MassPerTxByte, params.MassPerScriptPubKeyByte, params.MassPerSigOp)
+	mass, err := server.EstimateMassAfterSignatures(partiallySignedTransaction, ecdsa, minimumSignatures, txMassCalculator)
+	if err != nil {
+		return err
 	}
 
+	fmt.Printf("Mass: %d grams\n", mass)
+	feeRate := float64(fee) / float64(mass)
+	fmt.Printf("Fee rate: %.2f Sompi/Gram\n", feeRate)
 	return nil
 }
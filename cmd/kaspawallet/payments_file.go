@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/utils"
+	"github.com/pkg/errors"
+)
+
+// readPaymentsFile reads a list of payments from the given file. Files with a .json extension are
+// expected to contain an array of {"address": ..., "amount": ...} objects, and any other file is
+// expected to contain a CSV line of address,amount per payment. Amounts are in Kaspa, and
+// addresses may be replaced by contact names.
+func readPaymentsFile(path string) ([]*pb.Payment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseJSONPayments(file)
+	}
+	return parseCSVPayments(file)
+}
+
+type jsonPayment struct {
+	Address string      `json:"address"`
+	Amount  json.Number `json:"amount"`
+}
+
+func parseJSONPayments(reader io.Reader) ([]*pb.Payment, error) {
+	var jsonPayments []jsonPayment
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	err := decoder.Decode(&jsonPayments)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing the payments file")
+	}
+
+	payments := make([]*pb.Payment, len(jsonPayments))
+	for i, jsonPayment := range jsonPayments {
+		payments[i], err = parsePayment(jsonPayment.Address, jsonPayment.Amount.String())
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing payment #%d", i+1)
+		}
+	}
+	return validatePayments(payments)
+}
+
+func parseCSVPayments(reader io.Reader) ([]*pb.Payment, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 2
+	csvReader.TrimLeadingSpace = true
+
+	var payments []*pb.Payment
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing the payments file")
+		}
+
+		payment, err := parsePayment(record[0], record[1])
+		if err != nil {
+			line, _ := csvReader.FieldPos(0)
+			return nil, errors.Wrapf(err, "error parsing the payment in line %d", line)
+		}
+		payments = append(payments, payment)
+	}
+	return validatePayments(payments)
+}
+
+func parsePayment(address string, amount string) (*pb.Payment, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, errors.New("missing address")
+	}
+
+	amountSompi, err := utils.KasToSompi(strings.TrimSpace(amount))
+	if err != nil {
+		return nil, err
+	}
+	if amountSompi == 0 {
+		return nil, errors.Errorf("the amount to send to %s must be positive", address)
+	}
+
+	return &pb.Payment{Address: address, Amount: amountSompi}, nil
+}
+
+func validatePayments(payments []*pb.Payment) ([]*pb.Payment, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("the payments file doesn't contain any payments")
+	}
+	return payments, nil
+}
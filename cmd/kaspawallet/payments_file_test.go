@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/pb"
+)
+
+func TestParsePayments(t *testing.T) {
+	const firstAddress = "kaspa:qqkqkzjvr7zwxxmjxjkmxxdwju9kjs6e9u82uh59z07vgaks6gg62v8707g73"
+	const secondAddress = "kaspa:qr0lr4ml9fn3chekrqmjdkergxl93l4wrk3dankcgvjq776s9wn9jkdskewva"
+
+	csvPayments, err := parseCSVPayments(strings.NewReader(
+		"# address,amount\n" + firstAddress + ",1.5\n" + secondAddress + ", 0.00000001\n"))
+	if err != nil {
+		t.Fatalf("parseCSVPayments: %s", err)
+	}
+
+	jsonPayments, err := parseJSONPayments(strings.NewReader(
+		`[{"address": "` + firstAddress + `", "amount": 1.5}, {"address": "` + secondAddress + `", "amount": "0.00000001"}]`))
+	if err != nil {
+		t.Fatalf("parseJSONPayments: %s", err)
+	}
+
+	for name, payments := range map[string][]*pb.Payment{"CSV": csvPayments, "JSON": jsonPayments} {
+		if len(payments) != 2 {
+			t.Fatalf("%s: expected 2 payments, got %d", name, len(payments))
+		}
+		if payments[0].Address != firstAddress || payments[0].Amount != 150_000_000 {
+			t.Errorf("%s: unexpected first payment %s", name, payments[0])
+		}
+		if payments[1].Address != secondAddress || payments[1].Amount != 1 {
+			t.Errorf("%s: unexpected second payment %s", name, payments[1])
+		}
+	}
+
+	invalidCSVs := []string{
+		"",
+		firstAddress + "\n",
+		firstAddress + ",0\n",
+		firstAddress + ",-1\n",
+		firstAddress + ",1,2\n",
+		",1\n",
+	}
+	for _, invalidCSV := range invalidCSVs {
+		_, err := parseCSVPayments(strings.NewReader(invalidCSV))
+		if err == nil {
+			t.Errorf("expected an error for the payments %q", invalidCSV)
+		}
+	}
+
+	_, err = parseJSONPayments(strings.NewReader(`[]`))
+	if err == nil {
+		t.Errorf("expected an error for an empty JSON payments list")
+	}
+}
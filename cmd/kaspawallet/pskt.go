@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
+	"github.com/kaspanet/kaspad/cmd/kaspawallet/libkaspawallet/pskt"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
+	"github.com/pkg/errors"
+)
+
+// readTransactionsInput returns the transactions given either directly by --transaction or in the file given by
+// --transaction-file. Exactly one of them must be given.
+func readTransactionsInput(transaction string, transactionFile string) ([]byte, error) {
+	if transaction == "" && transactionFile == "" {
+		return nil, errors.Errorf("Either --transaction or --transaction-file is required")
+	}
+	if transaction != "" && transactionFile != "" {
+		return nil, errors.Errorf("Both --transaction and --transaction-file cannot be passed at the same time")
+	}
+
+	if transactionFile == "" {
+		return []byte(transaction), nil
+	}
+
+	data, err := ioutil.ReadFile(transactionFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not read %s", transactionFile)
+	}
+	return data, nil
+}
+
+// decodeTransactionsInput decodes transactions that are given either as a PSKT or in the hex format.
+// The PSKT is returned as well, or nil if the transactions were given in the hex format.
+func decodeTransactionsInput(data []byte, params *dagconfig.Params) ([][]byte, *pskt.PSKT, error) {
+	if !pskt.IsPSKT(data) {
+		transactions, err := server.DecodeTransactionsFromHex(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, nil, err
+		}
+		return transactions, nil, nil
+	}
+
+	psktFile, err := decodePSKT(data, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, err := psktFile.PartiallySignedTransactions()
+	if err != nil {
+		return nil, nil, err
+	}
+	return transactions, psktFile, nil
+}
+
+// decodePSKT decodes the given PSKT, and makes sure it belongs to the given network
+func decodePSKT(data []byte, params *dagconfig.Params) (*pskt.PSKT, error) {
+	psktFile, err := pskt.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if psktFile.Network != params.Name {
+		return nil, errors.Errorf("The PSKT belongs to %s, but the wallet is set to %s. Use the matching network flag",
+			psktFile.Network, params.Name)
+	}
+	return psktFile, nil
+}
+
+// readPSKT reads a PSKT given either directly by --transaction or in the file given by --transaction-file
+func readPSKT(transaction string, transactionFile string, params *dagconfig.Params) (*pskt.PSKT, error) {
+	data, err := readTransactionsInput(transaction, transactionFile)
+	if err != nil {
+		return nil, err
+	}
+	if !pskt.IsPSKT(data) {
+		return nil, errors.New("The transactions are in the hex format. Use \"kaspawallet export-pskt\" to convert them to a PSKT")
+	}
+	return decodePSKT(data, params)
+}
+
+func printPSKT(psktFile *pskt.PSKT) error {
+	encoded, err := psktFile.Encode()
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
@@ -20,6 +20,10 @@ func send(conf *sendConfig) error {
 		return err
 	}
 
+	if keysFile.IsWatchOnly() {
+		return errors.Errorf("Cannot use 'send' command for a watch-only wallet")
+	}
+
 	if len(keysFile.ExtendedPublicKeys) > len(keysFile.EncryptedMnemonics) {
 		return errors.Errorf("Cannot use 'send' command for multisig wallet without all of the keys")
 	}
@@ -34,7 +38,7 @@ func send(conf *sendConfig) error {
 	defer cancel()
 
 	var sendAmountSompi uint64
-	if !conf.IsSendAll {
+	if !conf.IsSendAll && conf.PaymentsFile == "" {
 		sendAmountSompi, err = utils.KasToSompi(conf.SendAmount)
 
 		if err != nil {
@@ -42,6 +46,14 @@ func send(conf *sendConfig) error {
 		}
 	}
 
+	var payments []*pb.Payment
+	if conf.PaymentsFile != "" {
+		payments, err = readPaymentsFile(conf.PaymentsFile)
+		if err != nil {
+			return err
+		}
+	}
+
 	var feePolicy *pb.FeePolicy
 	if conf.FeeRate > 0 {
 		feePolicy = &pb.FeePolicy{
@@ -64,6 +76,7 @@ func send(conf *sendConfig) error {
 			From:                     conf.FromAddresses,
 			Address:                  conf.ToAddress,
 			Amount:                   sendAmountSompi,
+			Payments:                 payments,
 			IsSendAll:                conf.IsSendAll,
 			UseExistingChangeAddress: conf.UseExistingChangeAddress,
 			FeePolicy:                feePolicy,
@@ -23,7 +23,11 @@ func showAddresses(conf *showAddressesConfig) error {
 	}
 
 	fmt.Printf("Addresses (%d):\n", len(response.Address))
-	for _, address := range response.Address {
+	for i, address := range response.Address {
+		if i < len(response.Label) && response.Label[i] != "" {
+			fmt.Printf("%s\t%s\n", address, response.Label[i])
+			continue
+		}
 		fmt.Println(address)
 	}
 
@@ -2,9 +2,7 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
-	"strings"
 
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
 	"github.com/kaspanet/kaspad/cmd/kaspawallet/keys"
@@ -13,11 +11,9 @@ import (
 )
 
 func sign(conf *signConfig) error {
-	if conf.Transaction == "" && conf.TransactionFile == "" {
-		return errors.Errorf("Either --transaction or --transaction-file is required")
-	}
-	if conf.Transaction != "" && conf.TransactionFile != "" {
-		return errors.Errorf("Both --transaction and --transaction-file cannot be passed at the same time")
+	data, err := readTransactionsInput(conf.Transaction, conf.TransactionFile)
+	if err != nil {
+		return err
 	}
 
 	keysFile, err := keys.ReadKeysFile(conf.NetParams(), conf.KeysFile)
@@ -25,6 +21,10 @@ func sign(conf *signConfig) error {
 		return err
 	}
 
+	if keysFile.IsWatchOnly() {
+		return errors.Errorf("The wallet in %s is watch-only and has no private keys", keysFile.Path())
+	}
+
 	if len(conf.Password) == 0 {
 		conf.Password = keys.GetPassword("Password:")
 	}
@@ -33,18 +33,13 @@ func sign(conf *signConfig) error {
 		return err
 	}
 
-	transactionsHex := conf.Transaction
-	if conf.TransactionFile != "" {
-		transactionHexBytes, err := ioutil.ReadFile(conf.TransactionFile)
-		if err != nil {
-			return errors.Wrapf(err, "Could not read hex from %s", conf.TransactionFile)
-		}
-		transactionsHex = strings.TrimSpace(string(transactionHexBytes))
-	}
-	partiallySignedTransactions, err := server.DecodeTransactionsFromHex(transactionsHex)
+	partiallySignedTransactions, psktFile, err := decodeTransactionsInput(data, conf.NetParams())
 	if err != nil {
 		return err
 	}
+	if psktFile != nil && psktFile.ECDSA != keysFile.ECDSA {
+		return errors.Errorf("The PSKT and the wallet in %s use different signature types", keysFile.Path())
+	}
 
 	updatedPartiallySignedTransactions := make([][]byte, len(partiallySignedTransactions))
 	for i, partiallySignedTransaction := range partiallySignedTransactions {
@@ -73,6 +68,14 @@ func sign(conf *signConfig) error {
 		fmt.Fprintln(os.Stderr, "Successfully signed transaction")
 	}
 
+	if psktFile != nil {
+		err := psktFile.SetPartiallySignedTransactions(updatedPartiallySignedTransactions)
+		if err != nil {
+			return err
+		}
+		return printPSKT(psktFile)
+	}
+
 	fmt.Println(server.EncodeTransactionsToHex(updatedPartiallySignedTransactions))
 	return nil
 }
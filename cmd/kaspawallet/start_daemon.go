@@ -3,5 +3,10 @@ package main
 import "github.com/kaspanet/kaspad/cmd/kaspawallet/daemon/server"
 
 func startDaemon(conf *startDaemonConfig) error {
-	return server.Start(conf.NetParams(), conf.Listen, conf.RPCServer, conf.KeysFile, conf.Profile, conf.Timeout)
+	connectOptions, err := conf.ConnectOptions()
+	if err != nil {
+		return err
+	}
+	return server.Start(conf.NetParams(), conf.Listen, conf.RPCServer, connectOptions, conf.KeysFile, conf.Profile, conf.Timeout,
+		conf.MempoolRefreshInterval)
 }
@@ -73,6 +73,10 @@ type Config struct {
 	EnableSanityCheckPruningUTXOSet bool
 
 	SkipAddingGenesis bool
+	// ReadOnly skips initializing and recovering the consensus when it's created, so
+	// that creating it doesn't write to the database. It's meant to be used with
+	// a read-only database, for inspecting the stores as they are.
+	ReadOnly bool
 }
 
 // Factory instantiates new Consensuses
@@ -526,6 +530,10 @@ func (f *factory) NewConsensus(config *Config, db infrastructuredatabase.Databas
 		return c, true, nil
 	}
 
+	if config.ReadOnly {
+		return c, false, nil
+	}
+
 	err = c.Init(config.SkipAddingGenesis)
 	if err != nil {
 		return nil, false, err
@@ -0,0 +1,52 @@
+package externalapi
+
+import "fmt"
+
+// MaxIntegrityCheckErrors is the maximum amount of errors an IntegrityCheck
+// keeps. Any error beyond it is only counted in ErrorCount
+const MaxIntegrityCheckErrors = 100
+
+// IntegrityReport is the result of cross-checking the stores of a database
+// against each other
+type IntegrityReport struct {
+	Checks []*IntegrityCheck
+}
+
+// IsConsistent returns whether none of the checks in the report found any error
+func (ir *IntegrityReport) IsConsistent() bool {
+	for _, check := range ir.Checks {
+		if !check.IsConsistent() {
+			return false
+		}
+	}
+	return true
+}
+
+// IntegrityCheck is the result of a single check within an IntegrityReport
+type IntegrityCheck struct {
+	Name         string
+	ItemsChecked uint64
+	ErrorCount   uint64
+	Errors       []string
+}
+
+// NewIntegrityCheck returns a new IntegrityCheck with the given name and no errors
+func NewIntegrityCheck(name string) *IntegrityCheck {
+	return &IntegrityCheck{
+		Name:   name,
+		Errors: []string{},
+	}
+}
+
+// AddError records an inconsistency that was found by the check
+func (ic *IntegrityCheck) AddError(format string, args ...interface{}) {
+	ic.ErrorCount++
+	if len(ic.Errors) < MaxIntegrityCheckErrors {
+		ic.Errors = append(ic.Errors, fmt.Sprintf(format, args...))
+	}
+}
+
+// IsConsistent returns whether the check didn't find any error
+func (ic *IntegrityCheck) IsConsistent() bool {
+	return ic.ErrorCount == 0
+}
@@ -11,6 +11,18 @@ import (
 	"github.com/kaspanet/kaspad/util/mstime"
 )
 
+// BlockProcessedHook is called by LogBlock for every processed block
+type BlockProcessedHook func(isHeaderOnly bool, transactionCount int)
+
+var blockProcessedHook BlockProcessedHook
+
+// SetBlockProcessedHook sets the hook that is called for every processed block,
+// which lets outer layers collect statistics about them.
+// It must be called before any block is processed.
+func SetBlockProcessedHook(hook BlockProcessedHook) {
+	blockProcessedHook = hook
+}
+
 // BlockLogger is a type tracking the amount of blocks/headers/transactions to log the time it took to receive them
 type BlockLogger struct {
 	receivedLogBlocks       int64
@@ -40,6 +52,9 @@ func (bl *BlockLogger) LogBlock(block *externalapi.DomainBlock) {
 	}
 
 	bl.receivedLogTransactions += int64(len(block.Transactions))
+	if blockProcessedHook != nil {
+		blockProcessedHook(len(block.Transactions) == 0, len(block.Transactions))
+	}
 
 	now := time.Now()
 	duration := now.Sub(bl.lastBlockLogTime)
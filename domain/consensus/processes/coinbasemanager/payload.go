@@ -64,6 +64,14 @@ func ModifyCoinbasePayload(payload []byte, coinbaseData *externalapi.DomainCoinb
 func (c *coinbaseManager) ExtractCoinbaseDataBlueScoreAndSubsidy(coinbaseTx *externalapi.DomainTransaction) (
 	blueScore uint64, coinbaseData *externalapi.DomainCoinbaseData, subsidy uint64, err error) {
 
+	return ExtractCoinbaseDataBlueScoreAndSubsidy(coinbaseTx, c.coinbasePayloadScriptPublicKeyMaxLength)
+}
+
+// ExtractCoinbaseDataBlueScoreAndSubsidy deserializes the coinbase payload to its component (scriptPubKey, extra data, and subsidy),
+// given the maximum length of the script public key in the payload.
+func ExtractCoinbaseDataBlueScoreAndSubsidy(coinbaseTx *externalapi.DomainTransaction, coinbasePayloadScriptPublicKeyMaxLength uint8) (
+	blueScore uint64, coinbaseData *externalapi.DomainCoinbaseData, subsidy uint64, err error) {
+
 	minLength := uint64Len + lengthOfSubsidy + lengthOfVersionScriptPubKey + lengthOfScriptPubKeyLength
 	if len(coinbaseTx.Payload) < minLength {
 		return 0, nil, 0, errors.Wrapf(ruleerrors.ErrBadCoinbasePayloadLen,
@@ -77,9 +85,9 @@ func (c *coinbaseManager) ExtractCoinbaseDataBlueScoreAndSubsidy(coinbaseTx *ext
 
 	scriptPubKeyScriptLength := coinbaseTx.Payload[uint64Len+lengthOfSubsidy+lengthOfVersionScriptPubKey]
 
-	if scriptPubKeyScriptLength > c.coinbasePayloadScriptPublicKeyMaxLength {
+	if scriptPubKeyScriptLength > coinbasePayloadScriptPublicKeyMaxLength {
 		return 0, nil, 0, errors.Wrapf(ruleerrors.ErrBadCoinbasePayloadLen, "coinbase's payload script public key is "+
-			"longer than the max allowed length of %d", c.coinbasePayloadScriptPublicKeyMaxLength)
+			"longer than the max allowed length of %d", coinbasePayloadScriptPublicKeyMaxLength)
 	}
 
 	if len(coinbaseTx.Payload) < minLength+int(scriptPubKeyScriptLength) {
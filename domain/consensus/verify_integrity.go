@@ -0,0 +1,372 @@
+package consensus
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/database"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/hashset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/multiset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/utxo"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+// VerifyIntegrity cross-checks the consensus stores against each other and
+// returns a report of every inconsistency that was found. It never writes
+// to the database, so it's safe to run on a database that was left in an
+// unknown state by a crash.
+func (s *consensus) VerifyIntegrity() (*externalapi.IntegrityReport, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "VerifyIntegrity")
+	defer onEnd()
+
+	stagingArea := model.NewStagingArea()
+
+	log.Infof("Verifying the block relations")
+	blockRelationsCheck, dagBlocks, err := s.verifyBlockRelations(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Verifying the data of %d blocks", len(dagBlocks))
+	blockDataCheck, err := s.verifyBlockData(stagingArea, dagBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Verifying the reachability data of %d blocks", len(dagBlocks))
+	reachabilityCheck, err := s.verifyReachability(stagingArea, dagBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Verifying the virtual UTXO set against its multiset")
+	virtualUTXOCommitmentCheck, err := s.verifyVirtualUTXOCommitment(stagingArea)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalapi.IntegrityReport{
+		Checks: []*externalapi.IntegrityCheck{
+			blockRelationsCheck,
+			blockDataCheck,
+			reachabilityCheck,
+			virtualUTXOCommitmentCheck,
+		},
+	}, nil
+}
+
+// verifyBlockRelations traverses the DAG from the virtual genesis up through the children and
+// checks that every parent lists its children and vice versa. It returns all the blocks it
+// reached, excluding the virtual genesis and the virtual.
+func (s *consensus) verifyBlockRelations(stagingArea *model.StagingArea) (
+	*externalapi.IntegrityCheck, []*externalapi.DomainHash, error) {
+
+	check := externalapi.NewIntegrityCheck("block relations")
+	blockRelationStore := s.blockRelationStores[0]
+
+	var dagBlocks []*externalapi.DomainHash
+	visited := hashset.NewFromSlice(model.VirtualGenesisBlockHash)
+	queue := []*externalapi.DomainHash{model.VirtualGenesisBlockHash}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		relations, err := blockRelationStore.BlockRelation(s.databaseContext, stagingArea, current)
+		if database.IsNotFoundError(err) {
+			check.AddError("block %s is missing its relations", current)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		check.ItemsChecked++
+
+		if !current.Equal(model.VirtualGenesisBlockHash) && !current.Equal(model.VirtualBlockHash) {
+			dagBlocks = append(dagBlocks, current)
+		}
+
+		for _, parent := range relations.Parents {
+			parentRelations, err := blockRelationStore.BlockRelation(s.databaseContext, stagingArea, parent)
+			if database.IsNotFoundError(err) {
+				check.AddError("parent %s of block %s is missing its relations", parent, current)
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			if !containsHash(parentRelations.Children, current) {
+				check.AddError("block %s is a parent of block %s but doesn't list it as a child", parent, current)
+			}
+		}
+
+		for _, child := range relations.Children {
+			childRelations, err := blockRelationStore.BlockRelation(s.databaseContext, stagingArea, child)
+			if database.IsNotFoundError(err) {
+				check.AddError("child %s of block %s is missing its relations", child, current)
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			if !containsHash(childRelations.Parents, current) {
+				check.AddError("block %s is a child of block %s but doesn't list it as a parent", child, current)
+			}
+
+			if !visited.Contains(child) {
+				visited.Add(child)
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return check, dagBlocks, nil
+}
+
+// verifyBlockData checks that every block in the DAG, as well as every block that
+// has a body, has a header, a status and GHOSTDAG data
+func (s *consensus) verifyBlockData(stagingArea *model.StagingArea, dagBlocks []*externalapi.DomainHash) (
+	*externalapi.IntegrityCheck, error) {
+
+	check := externalapi.NewIntegrityCheck("block data")
+
+	for _, blockHash := range dagBlocks {
+		err := s.verifySingleBlockData(stagingArea, check, blockHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dagBlocksSet := hashset.NewFromSlice(dagBlocks...)
+	iterator, err := s.blockStore.AllBlockHashesIterator(s.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		blockHash, err := iterator.Get()
+		if err != nil {
+			return nil, err
+		}
+		if dagBlocksSet.Contains(blockHash) {
+			continue
+		}
+
+		check.AddError("block %s has a body but is missing from the block relations", blockHash)
+		err = s.verifySingleBlockData(stagingArea, check, blockHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, model.VirtualBlockHash, false)
+	if database.IsNotFoundError(err) {
+		check.AddError("the virtual is missing its GHOSTDAG data")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
+func (s *consensus) verifySingleBlockData(stagingArea *model.StagingArea, check *externalapi.IntegrityCheck,
+	blockHash *externalapi.DomainHash) error {
+
+	check.ItemsChecked++
+
+	hasHeader, err := s.blockHeaderStore.HasBlockHeader(s.databaseContext, stagingArea, blockHash)
+	if err != nil {
+		return err
+	}
+	if !hasHeader {
+		check.AddError("block %s is missing its header", blockHash)
+	}
+
+	_, err = s.ghostdagDataStores[0].Get(s.databaseContext, stagingArea, blockHash, false)
+	if database.IsNotFoundError(err) {
+		check.AddError("block %s is missing its GHOSTDAG data", blockHash)
+	} else if err != nil {
+		return err
+	}
+
+	status, err := s.blockStatusStore.Get(s.databaseContext, stagingArea, blockHash)
+	if database.IsNotFoundError(err) {
+		check.AddError("block %s is missing its status", blockHash)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if status == externalapi.StatusUTXOValid || status == externalapi.StatusUTXOPendingVerification ||
+		status == externalapi.StatusDisqualifiedFromChain {
+
+		hasBlock, err := s.blockStore.HasBlock(s.databaseContext, stagingArea, blockHash)
+		if err != nil {
+			return err
+		}
+		if !hasBlock {
+			check.AddError("block %s has status %s but is missing its body", blockHash, status)
+		}
+	}
+
+	return nil
+}
+
+// verifyReachability checks that the reachability tree is well formed, and that every
+// parent in the block relations is a DAG ancestor of its child according to reachability
+func (s *consensus) verifyReachability(stagingArea *model.StagingArea, dagBlocks []*externalapi.DomainHash) (
+	*externalapi.IntegrityCheck, error) {
+
+	check := externalapi.NewIntegrityCheck("reachability")
+	blockRelationStore := s.blockRelationStores[0]
+
+	blocks := append([]*externalapi.DomainHash{model.VirtualGenesisBlockHash}, dagBlocks...)
+	for _, blockHash := range blocks {
+		reachabilityData, err := s.reachabilityDataStore.ReachabilityData(s.databaseContext, stagingArea, blockHash)
+		if database.IsNotFoundError(err) {
+			check.AddError("block %s is missing its reachability data", blockHash)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		check.ItemsChecked++
+
+		interval := reachabilityData.Interval()
+		var previousChildInterval *model.ReachabilityInterval
+		for _, child := range reachabilityData.Children() {
+			childReachabilityData, err := s.reachabilityDataStore.ReachabilityData(s.databaseContext, stagingArea, child)
+			if database.IsNotFoundError(err) {
+				check.AddError("reachability tree child %s of block %s is missing its reachability data",
+					child, blockHash)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if !blockHash.Equal(childReachabilityData.Parent()) {
+				check.AddError("block %s is a reachability tree child of block %s but its tree parent is %s",
+					child, blockHash, childReachabilityData.Parent())
+			}
+
+			childInterval := childReachabilityData.Interval()
+			if childInterval.Start < interval.Start || childInterval.End > interval.End {
+				check.AddError("the reachability interval %s of block %s isn't contained in the interval %s "+
+					"of its tree parent %s", childInterval, child, interval, blockHash)
+			}
+			if previousChildInterval != nil && previousChildInterval.End >= childInterval.Start {
+				check.AddError("the reachability intervals of the tree children of block %s "+
+					"are not ordered and disjoint", blockHash)
+			}
+			previousChildInterval = childInterval
+		}
+
+		if blockHash.Equal(model.VirtualGenesisBlockHash) {
+			continue
+		}
+
+		treeParent := reachabilityData.Parent()
+		treeParentReachabilityData, err := s.reachabilityDataStore.ReachabilityData(s.databaseContext, stagingArea, treeParent)
+		if database.IsNotFoundError(err) {
+			check.AddError("reachability tree parent %s of block %s is missing its reachability data",
+				treeParent, blockHash)
+		} else if err != nil {
+			return nil, err
+		} else if !containsHash(treeParentReachabilityData.Children(), blockHash) {
+			check.AddError("block %s is the reachability tree parent of block %s but doesn't list it as a child",
+				treeParent, blockHash)
+		}
+
+		relations, err := blockRelationStore.BlockRelation(s.databaseContext, stagingArea, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range relations.Parents {
+			hasReachabilityData, err := s.reachabilityDataStore.HasReachabilityData(s.databaseContext, stagingArea, parent)
+			if err != nil {
+				return nil, err
+			}
+			if !hasReachabilityData {
+				// This is reported when the parent itself is checked
+				continue
+			}
+
+			isDAGAncestorOf, err := s.reachabilityManager.IsDAGAncestorOf(stagingArea, parent, blockHash)
+			if err != nil {
+				return nil, err
+			}
+			if !isDAGAncestorOf {
+				check.AddError("block %s is a parent of block %s but isn't its DAG ancestor according "+
+					"to reachability", parent, blockHash)
+			}
+		}
+	}
+
+	return check, nil
+}
+
+// verifyVirtualUTXOCommitment recomputes the multiset of the virtual UTXO set and
+// compares it to the stored multiset of the virtual
+func (s *consensus) verifyVirtualUTXOCommitment(stagingArea *model.StagingArea) (*externalapi.IntegrityCheck, error) {
+	check := externalapi.NewIntegrityCheck("virtual UTXO commitment")
+
+	hadStartedImportingPruningPointUTXOSet, err :=
+		s.consensusStateStore.HadStartedImportingPruningPointUTXOSet(s.databaseContext)
+	if err != nil {
+		return nil, err
+	}
+	if hadStartedImportingPruningPointUTXOSet {
+		check.AddError("importing the pruning point UTXO set into the virtual UTXO set was interrupted. " +
+			"It's going to be imported again the next time kaspad starts")
+		return check, nil
+	}
+
+	iterator, err := s.consensusStateStore.VirtualUTXOSetIterator(s.databaseContext, stagingArea)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+
+	virtualUTXOSetMultiset := multiset.New()
+	for ok := iterator.First(); ok; ok = iterator.Next() {
+		outpoint, utxoEntry, err := iterator.Get()
+		if err != nil {
+			return nil, err
+		}
+		serializedUTXO, err := utxo.SerializeUTXO(utxoEntry, outpoint)
+		if err != nil {
+			return nil, err
+		}
+		virtualUTXOSetMultiset.Add(serializedUTXO)
+		check.ItemsChecked++
+	}
+
+	virtualMultiset, err := s.multisetStore.Get(s.databaseContext, stagingArea, model.VirtualBlockHash)
+	if database.IsNotFoundError(err) {
+		check.AddError("the virtual is missing its multiset")
+		return check, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !virtualUTXOSetMultiset.Hash().Equal(virtualMultiset.Hash()) {
+		check.AddError("the virtual UTXO set hashes to %s but the stored multiset of the virtual is %s",
+			virtualUTXOSetMultiset.Hash(), virtualMultiset.Hash())
+	}
+
+	return check, nil
+}
+
+func containsHash(hashes []*externalapi.DomainHash, hash *externalapi.DomainHash) bool {
+	for _, h := range hashes {
+		if h.Equal(hash) {
+			return true
+		}
+	}
+	return false
+}
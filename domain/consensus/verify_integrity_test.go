@@ -0,0 +1,103 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus"
+	"github.com/kaspanet/kaspad/domain/consensus/model"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/multiset"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/testutils"
+	"github.com/kaspanet/kaspad/util/staging"
+)
+
+func TestVerifyIntegrity(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestVerifyIntegrity")
+		if err != nil {
+			t.Fatalf("Error setting up consensus: %+v", err)
+		}
+		defer teardown(false)
+
+		tip := consensusConfig.GenesisHash
+		for i := 0; i < 10; i++ {
+			tip, _, err = tc.AddBlock([]*externalapi.DomainHash{tip}, nil, nil)
+			if err != nil {
+				t.Fatalf("AddBlock: %+v", err)
+			}
+		}
+		blockA, _, err := tc.AddBlock([]*externalapi.DomainHash{tip}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		blockB, _, err := tc.AddBlock([]*externalapi.DomainHash{tip}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+		_, _, err = tc.AddBlock([]*externalapi.DomainHash{blockA, blockB}, nil, nil)
+		if err != nil {
+			t.Fatalf("AddBlock: %+v", err)
+		}
+
+		report, err := tc.VerifyIntegrity()
+		if err != nil {
+			t.Fatalf("VerifyIntegrity: %+v", err)
+		}
+		for _, check := range report.Checks {
+			if !check.IsConsistent() {
+				t.Fatalf("Check %s unexpectedly found errors: %v", check.Name, check.Errors)
+			}
+			if check.ItemsChecked == 0 {
+				t.Fatalf("Check %s didn't check anything", check.Name)
+			}
+		}
+
+		// Corrupt the virtual multiset
+		stagingArea := model.NewStagingArea()
+		tc.MultisetStore().Stage(stagingArea, model.VirtualBlockHash, multiset.New())
+		err = staging.CommitAllChanges(tc.DatabaseContext(), stagingArea)
+		if err != nil {
+			t.Fatalf("CommitAllChanges: %+v", err)
+		}
+
+		// Remove blockC from the children of blockB, while it's still reachable through blockA
+		stagingArea = model.NewStagingArea()
+		blockBRelations, err := tc.BlockRelationStore().BlockRelation(tc.DatabaseContext(), stagingArea, blockB)
+		if err != nil {
+			t.Fatalf("BlockRelation: %+v", err)
+		}
+		blockBRelations = blockBRelations.Clone()
+		blockBRelations.Children = nil
+		tc.BlockRelationStore().StageBlockRelation(stagingArea, blockB, blockBRelations)
+		err = staging.CommitAllChanges(tc.DatabaseContext(), stagingArea)
+		if err != nil {
+			t.Fatalf("CommitAllChanges: %+v", err)
+		}
+
+		report, err = tc.VerifyIntegrity()
+		if err != nil {
+			t.Fatalf("VerifyIntegrity: %+v", err)
+		}
+		if report.IsConsistent() {
+			t.Fatalf("Expected the report to be inconsistent")
+		}
+
+		expectedErrorCounts := map[string]uint64{
+			"block relations":         1,
+			"block data":              0,
+			"reachability":            0,
+			"virtual UTXO commitment": 1,
+		}
+		for _, check := range report.Checks {
+			expectedErrorCount, ok := expectedErrorCounts[check.Name]
+			if !ok {
+				t.Fatalf("Unexpected check %s", check.Name)
+			}
+			if check.ErrorCount != expectedErrorCount {
+				t.Fatalf("Expected check %s to find %d errors but got %d: %v",
+					check.Name, expectedErrorCount, check.ErrorCount, check.Errors)
+			}
+		}
+	})
+}
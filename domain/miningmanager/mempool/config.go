@@ -47,6 +47,7 @@ type Config struct {
 	MaximumOrphanTransactionCount         uint64
 	AcceptNonStandard                     bool
 	MaximumMassPerBlock                   uint64
+	TargetTimePerBlock                    time.Duration
 	MinimumRelayTransactionFee            util.Amount
 	MinimumStandardTransactionVersion     uint16
 	MaximumStandardTransactionVersion     uint16
@@ -67,6 +68,7 @@ func DefaultConfig(dagParams *dagconfig.Params) *Config {
 		MaximumOrphanTransactionCount:         defaultMaximumOrphanTransactionCount,
 		AcceptNonStandard:                     dagParams.RelayNonStdTxs,
 		MaximumMassPerBlock:                   dagParams.MaxBlockMass,
+		TargetTimePerBlock:                    dagParams.TargetTimePerBlock,
 		MinimumRelayTransactionFee:            defaultMinimumRelayTransactionFee,
 		MinimumStandardTransactionVersion:     defaultMinimumStandardTransactionVersion,
 		MaximumStandardTransactionVersion:     defaultMaximumStandardTransactionVersion,
@@ -52,6 +52,7 @@ const (
 	RejectImmatureSpend   RejectCode = 0x45
 	RejectBadOrphan       RejectCode = 0x64
 	RejectSpamTx          RejectCode = 0x65
+	RejectReplacement     RejectCode = 0x66
 )
 
 // Map of reject codes back strings for pretty printing.
@@ -68,6 +69,8 @@ var rejectCodeStrings = map[RejectCode]string{
 	RejectNotRequested:    "REJECT_NOT_REQUESTED",
 	RejectImmatureSpend:   "REJECT_IMMATURE_SPEND",
 	RejectBadOrphan:       "REJECT_BAD_ORPHAN",
+	RejectSpamTx:          "REJECT_SPAM_TX",
+	RejectReplacement:     "REJECT_REPLACEMENT",
 }
 
 // String returns the RejectCode in human-readable form.
@@ -0,0 +1,127 @@
+package mempool
+
+import (
+	"math"
+	"time"
+
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+)
+
+// normalBucketsTargetSeconds and lowBucketsTargetSeconds are the inclusion times for which
+// normal and low fee rate buckets are estimated. The first normal bucket targets
+// sub-minute inclusion and the first low bucket targets sub-hour inclusion.
+var (
+	normalBucketsTargetSeconds = []float64{60, 300, 900}
+	lowBucketsTargetSeconds    = []float64{3600}
+)
+
+// outbidFeeRatePercent is the percentage of the fee rate of the marginal transaction
+// that an estimate adds to it in order to outbid it
+const outbidFeeRatePercent = 5
+
+// feeRateAndMass describes a single mempool transaction for the purpose of fee estimation
+type feeRateAndMass struct {
+	feeRate float64
+	mass    uint64
+}
+
+// feeEstimator estimates the fee rate required for a transaction to be included
+// within a given time. It models the mempool as a queue ordered by fee rate, which
+// is drained by one block's worth of mass every targetTimePerBlock.
+type feeEstimator struct {
+	// transactions are ordered by descending fee rate
+	transactions        []feeRateAndMass
+	maximumMassPerBlock uint64
+	targetTimePerBlock  time.Duration
+	minimumFeeRate      float64
+}
+
+func (mp *mempool) EstimateFees() *miningmanagermodel.FeeEstimate {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	orderedTransactions := mp.transactionsPool.transactionsOrderedByFeeRate
+	transactions := make([]feeRateAndMass, orderedTransactions.Len())
+	for i := range transactions {
+		// transactionsOrderedByFeeRate is ordered by ascending fee rate, so we reverse it
+		transaction := orderedTransactions.GetByIndex(orderedTransactions.Len() - 1 - i).Transaction()
+		transactions[i] = feeRateAndMass{
+			feeRate: float64(transaction.Fee) / float64(transaction.Mass),
+			mass:    transaction.Mass,
+		}
+	}
+
+	estimator := &feeEstimator{
+		transactions:        transactions,
+		maximumMassPerBlock: mp.config.MaximumMassPerBlock,
+		targetTimePerBlock:  mp.config.TargetTimePerBlock,
+		// MinimumRelayTransactionFee is specified in sompi per 1000 grams
+		minimumFeeRate: float64(mp.config.MinimumRelayTransactionFee) / 1000,
+	}
+	return estimator.estimate()
+}
+
+func (fe *feeEstimator) estimate() *miningmanagermodel.FeeEstimate {
+	return &miningmanagermodel.FeeEstimate{
+		PriorityBucket: fe.bucketForTargetSeconds(fe.targetTimePerBlock.Seconds()),
+		NormalBuckets:  fe.bucketsForTargetSeconds(normalBucketsTargetSeconds),
+		LowBuckets:     fe.bucketsForTargetSeconds(lowBucketsTargetSeconds),
+	}
+}
+
+func (fe *feeEstimator) bucketsForTargetSeconds(targetSeconds []float64) []miningmanagermodel.FeeRateBucket {
+	buckets := make([]miningmanagermodel.FeeRateBucket, len(targetSeconds))
+	for i, target := range targetSeconds {
+		buckets[i] = fe.bucketForTargetSeconds(target)
+	}
+	return buckets
+}
+
+// bucketForTargetSeconds returns the lowest fee rate that is expected to be included
+// within the given amount of seconds, along with its actual estimated inclusion time
+func (fe *feeEstimator) bucketForTargetSeconds(targetSeconds float64) miningmanagermodel.FeeRateBucket {
+	targetBlocks := uint64(math.Floor(targetSeconds / fe.targetTimePerBlock.Seconds()))
+	if targetBlocks < 1 {
+		targetBlocks = 1
+	}
+	massCapacity := targetBlocks * fe.maximumMassPerBlock
+
+	// Any transaction paying at least the minimum fee rate gets in within the
+	// target time, unless the mempool holds more mass than can be drained in it.
+	// In that case, one has to outbid the marginal transaction, which is the last
+	// one to get in. Paying the same fee rate as it only ties with it, which doesn't
+	// reliably get in, so the estimate is raised above it by outbidFeeRatePercent of
+	// its fee rate. The estimate never goes below the minimum fee rate.
+	feeRate := fe.minimumFeeRate
+	accumulatedMass := uint64(0)
+	for _, transaction := range fe.transactions {
+		accumulatedMass += transaction.mass
+		if accumulatedMass >= massCapacity {
+			outbidFeeRate := transaction.feeRate + transaction.feeRate*outbidFeeRatePercent/100
+			feeRate = math.Max(feeRate, outbidFeeRate)
+			break
+		}
+	}
+
+	return miningmanagermodel.FeeRateBucket{
+		FeeRate:          feeRate,
+		EstimatedSeconds: fe.estimatedSeconds(feeRate),
+	}
+}
+
+// estimatedSeconds returns the estimated time it would take for a transaction with the given
+// fee rate to be included, given that all transactions with higher or equal fee rates are included first
+func (fe *feeEstimator) estimatedSeconds(feeRate float64) float64 {
+	massAhead := uint64(0)
+	for _, transaction := range fe.transactions {
+		// Transactions with the same fee rate were there first, so they are
+		// not assumed to be included after this one
+		if transaction.feeRate < feeRate {
+			break
+		}
+		massAhead += transaction.mass
+	}
+
+	blocksUntilInclusion := massAhead/fe.maximumMassPerBlock + 1
+	return float64(blocksUntilInclusion) * fe.targetTimePerBlock.Seconds()
+}
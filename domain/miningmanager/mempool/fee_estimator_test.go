@@ -0,0 +1,176 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeeEstimator(t *testing.T) {
+	const maximumMassPerBlock = 1000
+	const minimumFeeRate = 1
+
+	tests := []struct {
+		name                    string
+		transactions            []feeRateAndMass
+		expectedPriorityFeeRate float64
+		expectedPrioritySeconds float64
+		expectedNormalFeeRates  []float64
+		expectedLowFeeRates     []float64
+	}{
+		{
+			name:                    "empty mempool",
+			transactions:            nil,
+			expectedPriorityFeeRate: minimumFeeRate,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{minimumFeeRate, minimumFeeRate, minimumFeeRate},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+		{
+			name: "mempool smaller than a block",
+			transactions: []feeRateAndMass{
+				{feeRate: 10, mass: 400},
+				{feeRate: 5, mass: 400},
+			},
+			expectedPriorityFeeRate: minimumFeeRate,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{minimumFeeRate, minimumFeeRate, minimumFeeRate},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+		{
+			name: "mempool larger than a block",
+			transactions: []feeRateAndMass{
+				{feeRate: 10, mass: 600},
+				{feeRate: 5, mass: 600},
+				{feeRate: 2, mass: 600},
+			},
+			expectedPriorityFeeRate: 5.25,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{minimumFeeRate, minimumFeeRate, minimumFeeRate},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+		{
+			name: "mempool larger than a minute of blocks",
+			transactions: []feeRateAndMass{
+				{feeRate: 100, mass: 30_000},
+				{feeRate: 50, mass: 30_000},
+				{feeRate: 20, mass: 300_000},
+				{feeRate: 10, mass: 3_000_000},
+			},
+			expectedPriorityFeeRate: 105,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{52.5, 21, 10.5},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+		{
+			name: "marginal transaction fills the block exactly",
+			transactions: []feeRateAndMass{
+				{feeRate: 5, mass: 1000},
+				{feeRate: 5, mass: 1000},
+			},
+			expectedPriorityFeeRate: 5.25,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{minimumFeeRate, minimumFeeRate, minimumFeeRate},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+		{
+			name: "marginal transaction pays the minimum fee rate",
+			transactions: []feeRateAndMass{
+				{feeRate: minimumFeeRate, mass: 1000},
+				{feeRate: minimumFeeRate, mass: 1000},
+			},
+			expectedPriorityFeeRate: 1.05,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{minimumFeeRate, minimumFeeRate, minimumFeeRate},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+		{
+			name: "marginal transaction pays a high fee rate",
+			transactions: []feeRateAndMass{
+				{feeRate: 10_000, mass: 1000},
+				{feeRate: 10_000, mass: 1000},
+			},
+			expectedPriorityFeeRate: 10_500,
+			expectedPrioritySeconds: 1,
+			expectedNormalFeeRates:  []float64{minimumFeeRate, minimumFeeRate, minimumFeeRate},
+			expectedLowFeeRates:     []float64{minimumFeeRate},
+		},
+	}
+
+	for _, test := range tests {
+		estimator := &feeEstimator{
+			transactions:        test.transactions,
+			maximumMassPerBlock: maximumMassPerBlock,
+			targetTimePerBlock:  time.Second,
+			minimumFeeRate:      minimumFeeRate,
+		}
+		estimate := estimator.estimate()
+
+		if estimate.PriorityBucket.FeeRate != test.expectedPriorityFeeRate {
+			t.Errorf("%s: expected priority fee rate %f but got %f",
+				test.name, test.expectedPriorityFeeRate, estimate.PriorityBucket.FeeRate)
+		}
+		if estimate.PriorityBucket.EstimatedSeconds != test.expectedPrioritySeconds {
+			t.Errorf("%s: expected priority estimated seconds %f but got %f",
+				test.name, test.expectedPrioritySeconds, estimate.PriorityBucket.EstimatedSeconds)
+		}
+
+		if len(estimate.NormalBuckets) != len(test.expectedNormalFeeRates) {
+			t.Fatalf("%s: expected %d normal buckets but got %d",
+				test.name, len(test.expectedNormalFeeRates), len(estimate.NormalBuckets))
+		}
+		for i, bucket := range estimate.NormalBuckets {
+			if bucket.FeeRate != test.expectedNormalFeeRates[i] {
+				t.Errorf("%s: expected normal bucket %d fee rate %f but got %f",
+					test.name, i, test.expectedNormalFeeRates[i], bucket.FeeRate)
+			}
+			if bucket.EstimatedSeconds > normalBucketsTargetSeconds[i] {
+				t.Errorf("%s: normal bucket %d estimated seconds %f exceed the target of %f",
+					test.name, i, bucket.EstimatedSeconds, normalBucketsTargetSeconds[i])
+			}
+		}
+
+		if len(estimate.LowBuckets) != len(test.expectedLowFeeRates) {
+			t.Fatalf("%s: expected %d low buckets but got %d",
+				test.name, len(test.expectedLowFeeRates), len(estimate.LowBuckets))
+		}
+		for i, bucket := range estimate.LowBuckets {
+			if bucket.FeeRate != test.expectedLowFeeRates[i] {
+				t.Errorf("%s: expected low bucket %d fee rate %f but got %f",
+					test.name, i, test.expectedLowFeeRates[i], bucket.FeeRate)
+			}
+			if bucket.EstimatedSeconds > lowBucketsTargetSeconds[i] {
+				t.Errorf("%s: low bucket %d estimated seconds %f exceed the target of %f",
+					test.name, i, bucket.EstimatedSeconds, lowBucketsTargetSeconds[i])
+			}
+		}
+	}
+}
+
+func TestFeeEstimatorOutbidsMarginalTransaction(t *testing.T) {
+	estimator := &feeEstimator{
+		transactions: []feeRateAndMass{
+			{feeRate: 5, mass: 1000},
+			{feeRate: 5, mass: 1000},
+		},
+		maximumMassPerBlock: 1000,
+		targetTimePerBlock:  time.Second,
+		minimumFeeRate:      1,
+	}
+
+	// Tying with the transactions already in the mempool means waiting for all of them
+	tiedSeconds := estimator.estimatedSeconds(5)
+	if tiedSeconds != 3 {
+		t.Fatalf("expected a transaction tying with the mempool to be included in 3 seconds but got %f",
+			tiedSeconds)
+	}
+
+	priorityBucket := estimator.bucketForTargetSeconds(1)
+	if priorityBucket.FeeRate <= 5 {
+		t.Fatalf("expected the priority fee rate to outbid the marginal fee rate of 5 but got %f",
+			priorityBucket.FeeRate)
+	}
+	if priorityBucket.EstimatedSeconds != 1 {
+		t.Fatalf("expected the priority bucket to be included in 1 second but got %f",
+			priorityBucket.EstimatedSeconds)
+	}
+}
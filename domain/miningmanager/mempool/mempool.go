@@ -45,7 +45,19 @@ func (mp *mempool) ValidateAndInsertTransaction(transaction *externalapi.DomainT
 	mp.mtx.Lock()
 	defer mp.mtx.Unlock()
 
-	return mp.validateAndInsertTransaction(transaction, isHighPriority, allowOrphan)
+	acceptedTransactions, _, err = mp.validateAndInsertTransaction(
+		transaction, isHighPriority, allowOrphan, miningmanagermodel.RBFPolicyForbidden)
+	return acceptedTransactions, err
+}
+
+func (mp *mempool) ValidateAndInsertTransactionWithReplacement(transaction *externalapi.DomainTransaction,
+	isHighPriority bool, allowOrphan bool, rbfPolicy miningmanagermodel.RBFPolicy) (
+	acceptedTransactions []*externalapi.DomainTransaction, replacedTransaction *externalapi.DomainTransaction, err error) {
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	return mp.validateAndInsertTransaction(transaction, isHighPriority, allowOrphan, rbfPolicy)
 }
 
 func (mp *mempool) GetTransaction(transactionID *externalapi.DomainTransactionID,
@@ -133,6 +145,22 @@ func (mp *mempool) TransactionCount(includeTransactionPool bool, includeOrphanPo
 	return transactionCount
 }
 
+func (mp *mempool) TransactionMass(includeTransactionPool bool, includeOrphanPool bool) uint64 {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	transactionMass := uint64(0)
+
+	if includeOrphanPool {
+		transactionMass += mp.orphansPool.orphanTransactionsMass()
+	}
+	if includeTransactionPool {
+		transactionMass += mp.transactionsPool.transactionsMass()
+	}
+
+	return transactionMass
+}
+
 func (mp *mempool) HandleNewBlockTransactions(transactions []*externalapi.DomainTransaction) (
 	acceptedOrphans []*externalapi.DomainTransaction, err error) {
 
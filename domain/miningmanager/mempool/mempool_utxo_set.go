@@ -80,3 +80,20 @@ func (mpus *mempoolUTXOSet) checkDoubleSpends(transaction *externalapi.DomainTra
 
 	return nil
 }
+
+// getConflictingTransactions returns the distinct mempool transactions that spend
+// any of the outpoints spent by the given transaction
+func (mpus *mempoolUTXOSet) getConflictingTransactions(transaction *externalapi.DomainTransaction) []*model.MempoolTransaction {
+	conflictingTransactions := model.IDToTransactionMap{}
+	for _, input := range transaction.Inputs {
+		if existingTransaction, exists := mpus.transactionByPreviousOutpoint[input.PreviousOutpoint]; exists {
+			conflictingTransactions[*existingTransaction.TransactionID()] = existingTransaction
+		}
+	}
+
+	result := make([]*model.MempoolTransaction, 0, len(conflictingTransactions))
+	for _, conflictingTransaction := range conflictingTransactions {
+		result = append(result, conflictingTransaction)
+	}
+	return result
+}
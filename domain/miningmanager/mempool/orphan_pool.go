@@ -373,3 +373,11 @@ func (op *orphansPool) getAllOrphanTransactions() []*externalapi.DomainTransacti
 func (op *orphansPool) orphanTransactionCount() int {
 	return len(op.allOrphans)
 }
+
+func (op *orphansPool) orphanTransactionsMass() uint64 {
+	mass := uint64(0)
+	for _, orphanTransaction := range op.allOrphans {
+		mass += orphanTransaction.Transaction().Mass
+	}
+	return mass
+}
@@ -0,0 +1,117 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
+)
+
+// getReplaceableTransaction returns the mempool transaction that the given transaction
+// replaces according to rbfPolicy, or nil if it does not replace any transaction.
+// Only a single mempool transaction may be replaced at a time.
+func (mp *mempool) getReplaceableTransaction(transaction *externalapi.DomainTransaction,
+	rbfPolicy miningmanagermodel.RBFPolicy) (*model.MempoolTransaction, error) {
+
+	if rbfPolicy == miningmanagermodel.RBFPolicyForbidden {
+		return nil, mp.mempoolUTXOSet.checkDoubleSpends(transaction)
+	}
+
+	conflictingTransactions := mp.mempoolUTXOSet.getConflictingTransactions(transaction)
+	switch len(conflictingTransactions) {
+	case 0:
+		str := fmt.Sprintf("transaction %s does not double-spend any transaction in the mempool, "+
+			"so there is nothing to replace", consensushashing.TransactionID(transaction))
+		return nil, transactionRuleError(RejectReplacement, str)
+	case 1:
+		return conflictingTransactions[0], nil
+	default:
+		str := fmt.Sprintf("transaction %s double-spends %d transactions in the mempool, "+
+			"but only one transaction may be replaced", consensushashing.TransactionID(transaction), len(conflictingTransactions))
+		return nil, transactionRuleError(RejectReplacement, str)
+	}
+}
+
+// validateReplacement makes sure that the given transaction is allowed to replace
+// conflictingTransaction: it must pay a strictly higher fee rate, and it must not
+// spend outputs of any of the transactions that are evicted along with the replacement.
+// The transaction is expected to have its fee and mass populated.
+func (mp *mempool) validateReplacement(transaction *externalapi.DomainTransaction,
+	parentsInPool model.IDToTransactionMap, conflictingTransaction *model.MempoolTransaction) error {
+
+	transactionID := consensushashing.TransactionID(transaction)
+
+	evictedTransactions := append([]*model.MempoolTransaction{conflictingTransaction},
+		mp.transactionsPool.getRedeemers(conflictingTransaction)...)
+	for _, evictedTransaction := range evictedTransactions {
+		if _, ok := parentsInPool[*evictedTransaction.TransactionID()]; ok {
+			str := fmt.Sprintf("transaction %s spends an output of transaction %s, which it would evict "+
+				"by replacing transaction %s", transactionID, evictedTransaction.TransactionID(),
+				conflictingTransaction.TransactionID())
+			return transactionRuleError(RejectReplacement, str)
+		}
+	}
+
+	feeRate := float64(transaction.Fee) / float64(transaction.Mass)
+	conflictingFeeRate := float64(conflictingTransaction.Transaction().Fee) /
+		float64(conflictingTransaction.Transaction().Mass)
+	if feeRate <= conflictingFeeRate {
+		str := fmt.Sprintf("transaction %s has a fee rate of %f sompi/gram, which is not higher than the "+
+			"fee rate of %f sompi/gram of transaction %s it attempts to replace",
+			transactionID, feeRate, conflictingFeeRate, conflictingTransaction.TransactionID())
+		return transactionRuleError(RejectInsufficientFee, str)
+	}
+
+	return nil
+}
+
+// replaceTransaction evicts the given transaction and all of its redeemers from
+// the mempool. It returns the evicted transaction, along with everything that was
+// evicted from the transactions pool so that it could be restored by
+// restoreEvictedTransactions if inserting the replacing transaction fails.
+func (mp *mempool) replaceTransaction(conflictingTransaction *model.MempoolTransaction) (
+	replacedTransaction *externalapi.DomainTransaction, evictedTransactions []*model.MempoolTransaction, err error) {
+
+	replacedTransaction = conflictingTransaction.Transaction().Clone() //this pointer leaves the mempool, hence we clone.
+	evictedTransactions = append([]*model.MempoolTransaction{conflictingTransaction},
+		mp.transactionsPool.getRedeemers(conflictingTransaction)...)
+
+	log.Debugf("Replacing transaction %s and its redeemers", conflictingTransaction.TransactionID())
+	err = mp.removeTransaction(conflictingTransaction.TransactionID(), true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return replacedTransaction, evictedTransactions, nil
+}
+
+// restoreEvictedTransactions undoes replaceTransaction after the replacing transaction
+// failed to be inserted: it removes whatever was inserted of the replacing transaction
+// and returns the evicted transactions to the transactions pool. Orphans that spent
+// the evicted transactions are not restored.
+func (mp *mempool) restoreEvictedTransactions(replacingTransaction *model.MempoolTransaction,
+	evictedTransactions []*model.MempoolTransaction) error {
+
+	err := mp.removeTransaction(replacingTransaction.TransactionID(), false)
+	if err != nil {
+		return err
+	}
+
+	// evictedTransactions lists every transaction after its parents, but may list
+	// a transaction more than once if it redeems several evicted transactions.
+	for _, evictedTransaction := range evictedTransactions {
+		if _, ok := mp.transactionsPool.allTransactions[*evictedTransaction.TransactionID()]; ok {
+			continue
+		}
+		err := mp.transactionsPool.addMempoolTransaction(evictedTransaction)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Restored transaction %s and its redeemers after failing to replace it",
+		evictedTransactions[0].TransactionID())
+	return nil
+}
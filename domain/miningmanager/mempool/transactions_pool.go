@@ -35,14 +35,11 @@ func newTransactionsPool(mp *mempool) *transactionsPool {
 func (tp *transactionsPool) addTransaction(transaction *externalapi.DomainTransaction,
 	parentTransactionsInPool model.IDToTransactionMap, isHighPriority bool) (*model.MempoolTransaction, error) {
 
-	virtualDAAScore, err := tp.mempool.consensusReference.Consensus().GetVirtualDAAScore()
+	mempoolTransaction, err := tp.newMempoolTransaction(transaction, parentTransactionsInPool, isHighPriority)
 	if err != nil {
 		return nil, err
 	}
 
-	mempoolTransaction := model.NewMempoolTransaction(
-		transaction, parentTransactionsInPool, isHighPriority, virtualDAAScore)
-
 	err = tp.addMempoolTransaction(mempoolTransaction)
 	if err != nil {
 		return nil, err
@@ -51,6 +48,19 @@ func (tp *transactionsPool) addTransaction(transaction *externalapi.DomainTransa
 	return mempoolTransaction, nil
 }
 
+// newMempoolTransaction wraps the given transaction as a mempool transaction added at
+// the current virtual DAA score, without adding it to the pool
+func (tp *transactionsPool) newMempoolTransaction(transaction *externalapi.DomainTransaction,
+	parentTransactionsInPool model.IDToTransactionMap, isHighPriority bool) (*model.MempoolTransaction, error) {
+
+	virtualDAAScore, err := tp.mempool.consensusReference.Consensus().GetVirtualDAAScore()
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewMempoolTransaction(transaction, parentTransactionsInPool, isHighPriority, virtualDAAScore), nil
+}
+
 func (tp *transactionsPool) addMempoolTransaction(transaction *model.MempoolTransaction) error {
 	tp.allTransactions[*transaction.TransactionID()] = transaction
 
@@ -250,3 +260,11 @@ func (tp *transactionsPool) getAllTransactions() []*externalapi.DomainTransactio
 func (tp *transactionsPool) transactionCount() int {
 	return len(tp.allTransactions)
 }
+
+func (tp *transactionsPool) transactionsMass() uint64 {
+	mass := uint64(0)
+	for _, mempoolTransaction := range tp.allTransactions {
+		mass += mempoolTransaction.Transaction().Mass
+	}
+	return mass
+}
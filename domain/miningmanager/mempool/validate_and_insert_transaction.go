@@ -6,10 +6,13 @@ import (
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
 	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/miningmanager/mempool/model"
+	miningmanagermodel "github.com/kaspanet/kaspad/domain/miningmanager/model"
 )
 
 func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool,
-	allowOrphan bool) (acceptedTransactions []*externalapi.DomainTransaction, err error) {
+	allowOrphan bool, rbfPolicy miningmanagermodel.RBFPolicy) (
+	acceptedTransactions []*externalapi.DomainTransaction, replacedTransaction *externalapi.DomainTransaction, err error) {
 
 	onEnd := logger.LogAndMeasureExecutionTime(log,
 		fmt.Sprintf("validateAndInsertTransaction %s", consensushashing.TransactionID(transaction)))
@@ -18,47 +21,83 @@ func (mp *mempool) validateAndInsertTransaction(transaction *externalapi.DomainT
 	// Populate mass in the beginning, it will be used in multiple places throughout the validation and insertion.
 	mp.consensusReference.Consensus().PopulateMass(transaction)
 
-	err = mp.validateTransactionPreUTXOEntry(transaction)
+	err = mp.validateTransactionInIsolation(transaction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	conflictingTransaction, err := mp.getReplaceableTransaction(transaction, rbfPolicy)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	parentsInPool, missingOutpoints, err := mp.fillInputsAndGetMissingParents(transaction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if len(missingOutpoints) > 0 {
+		if conflictingTransaction != nil {
+			str := fmt.Sprintf("Transaction %s is an orphan and cannot replace transaction %s",
+				consensushashing.TransactionID(transaction), conflictingTransaction.TransactionID())
+			return nil, nil, transactionRuleError(RejectReplacement, str)
+		}
 		if !allowOrphan {
 			str := fmt.Sprintf("Transaction %s is an orphan, where allowOrphan = false",
 				consensushashing.TransactionID(transaction))
-			return nil, transactionRuleError(RejectBadOrphan, str)
+			return nil, nil, transactionRuleError(RejectBadOrphan, str)
 		}
 
-		return nil, mp.orphansPool.maybeAddOrphan(transaction, isHighPriority)
+		return nil, nil, mp.orphansPool.maybeAddOrphan(transaction, isHighPriority)
+	}
+
+	if conflictingTransaction != nil {
+		err = mp.validateReplacement(transaction, parentsInPool, conflictingTransaction)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	err = mp.validateTransactionInContext(transaction)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	mempoolTransaction, err := mp.transactionsPool.addTransaction(transaction, parentsInPool, isHighPriority)
+	mempoolTransaction, err := mp.transactionsPool.newMempoolTransaction(transaction, parentsInPool, isHighPriority)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	var evictedTransactions []*model.MempoolTransaction
+	if conflictingTransaction != nil {
+		replacedTransaction, evictedTransactions, err = mp.replaceTransaction(conflictingTransaction)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = mp.transactionsPool.addMempoolTransaction(mempoolTransaction)
+	if err != nil {
+		if len(evictedTransactions) > 0 {
+			restoreErr := mp.restoreEvictedTransactions(mempoolTransaction, evictedTransactions)
+			if restoreErr != nil {
+				return nil, nil, restoreErr
+			}
+		}
+		return nil, nil, err
 	}
 
 	acceptedOrphans, err := mp.orphansPool.processOrphansAfterAcceptedTransaction(mempoolTransaction.Transaction())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	acceptedTransactions = append([]*externalapi.DomainTransaction{transaction.Clone()}, acceptedOrphans...) //these pointer leave the mempool, hence we clone.
 
 	err = mp.transactionsPool.limitTransactionCount()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return acceptedTransactions, nil
+	return acceptedTransactions, replacedTransaction, nil
 }
@@ -29,10 +29,16 @@ type MiningManager interface {
 		transactionPoolTransactions []*externalapi.DomainTransaction,
 		orphanPoolTransactions []*externalapi.DomainTransaction)
 	TransactionCount(includeTransactionPool bool, includeOrphanPool bool) int
+	TransactionMass(includeTransactionPool bool, includeOrphanPool bool) uint64
 	HandleNewBlockTransactions(txs []*externalapi.DomainTransaction) ([]*externalapi.DomainTransaction, error)
 	ValidateAndInsertTransaction(transaction *externalapi.DomainTransaction, isHighPriority bool, allowOrphan bool) (
 		acceptedTransactions []*externalapi.DomainTransaction, err error)
+	ValidateAndInsertTransactionWithReplacement(transaction *externalapi.DomainTransaction, isHighPriority bool,
+		allowOrphan bool, rbfPolicy miningmanagermodel.RBFPolicy) (
+		acceptedTransactions []*externalapi.DomainTransaction, replacedTransaction *externalapi.DomainTransaction, err error)
 	RevalidateHighPriorityTransactions() (validTransactions []*externalapi.DomainTransaction, err error)
+	GetFeeEstimate() *miningmanagermodel.FeeEstimate
+	IsTransactionOutputDust(output *externalapi.DomainTransactionOutput) bool
 }
 
 type miningManager struct {
@@ -117,6 +123,17 @@ func (mm *miningManager) ValidateAndInsertTransaction(transaction *externalapi.D
 	return mm.mempool.ValidateAndInsertTransaction(transaction, isHighPriority, allowOrphan)
 }
 
+// ValidateAndInsertTransactionWithReplacement validates the given transaction and adds it to
+// the set of known transactions that have not yet been added to any block. Depending on
+// rbfPolicy, the transaction may replace a mempool transaction it double-spends, in which
+// case the replaced transaction is returned
+func (mm *miningManager) ValidateAndInsertTransactionWithReplacement(transaction *externalapi.DomainTransaction,
+	isHighPriority bool, allowOrphan bool, rbfPolicy miningmanagermodel.RBFPolicy) (
+	acceptedTransactions []*externalapi.DomainTransaction, replacedTransaction *externalapi.DomainTransaction, err error) {
+
+	return mm.mempool.ValidateAndInsertTransactionWithReplacement(transaction, isHighPriority, allowOrphan, rbfPolicy)
+}
+
 func (mm *miningManager) GetTransaction(
 	transactionID *externalapi.DomainTransactionID,
 	includeTransactionPool bool,
@@ -149,8 +166,24 @@ func (mm *miningManager) TransactionCount(includeTransactionPool bool, includeOr
 	return mm.mempool.TransactionCount(includeTransactionPool, includeOrphanPool)
 }
 
+func (mm *miningManager) TransactionMass(includeTransactionPool bool, includeOrphanPool bool) uint64 {
+	return mm.mempool.TransactionMass(includeTransactionPool, includeOrphanPool)
+}
+
 func (mm *miningManager) RevalidateHighPriorityTransactions() (
 	validTransactions []*externalapi.DomainTransaction, err error) {
 
 	return mm.mempool.RevalidateHighPriorityTransactions()
 }
+
+// GetFeeEstimate returns fee rate recommendations based on the current mempool
+// fee rate distribution and the block mass capacity
+func (mm *miningManager) GetFeeEstimate() *miningmanagermodel.FeeEstimate {
+	return mm.mempool.EstimateFees()
+}
+
+// IsTransactionOutputDust returns whether the given output is too small to be
+// relayed by the mempool
+func (mm *miningManager) IsTransactionOutputDust(output *externalapi.DomainTransactionOutput) bool {
+	return mm.mempool.IsTransactionOutputDust(output)
+}
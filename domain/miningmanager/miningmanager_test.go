@@ -172,6 +172,92 @@ func TestDoubleSpendInMempool(t *testing.T) {
 	})
 }
 
+// TestReplaceByFee verifies that a transaction double-spending a mempool transaction replaces it
+// along with its redeemers only when it pays a higher fee rate.
+func TestReplaceByFee(t *testing.T) {
+	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
+		consensusConfig.BlockCoinbaseMaturity = 0
+		factory := consensus.NewFactory()
+		tc, teardown, err := factory.NewTestConsensus(consensusConfig, "TestReplaceByFee")
+		if err != nil {
+			t.Fatalf("Error setting up TestConsensus: %+v", err)
+		}
+		defer teardown(false)
+
+		miningFactory := miningmanager.NewFactory()
+		tcAsConsensus := tc.(externalapi.Consensus)
+		tcAsConsensusPointer := &tcAsConsensus
+		consensusReference := consensusreference.NewConsensusReference(&tcAsConsensusPointer)
+		miningManager := miningFactory.NewMiningManager(consensusReference, &consensusConfig.Params, mempool.DefaultConfig(&consensusConfig.Params))
+
+		nonConflictingTransaction := createTransactionWithUTXOEntry(t, 0, 0)
+		_, _, err = miningManager.ValidateAndInsertTransactionWithReplacement(
+			nonConflictingTransaction, false, false, model.RBFPolicyMandatory)
+		if err == nil || !strings.Contains(err.Error(), "nothing to replace") {
+			t.Fatalf("ValidateAndInsertTransactionWithReplacement: expected a nothing to replace error, got: %v", err)
+		}
+
+		transaction, err := createChildAndParentTxsAndAddParentToConsensus(tc)
+		if err != nil {
+			t.Fatalf("Error creating transaction: %+v", err)
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(transaction, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+		redeemer, err := testutils.CreateTransaction(transaction, 1000)
+		if err != nil {
+			t.Fatalf("Error creating transaction: %+v", err)
+		}
+		_, err = miningManager.ValidateAndInsertTransaction(redeemer, false, true)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransaction: %v", err)
+		}
+
+		lowerFeeTransaction := transaction.Clone()
+		lowerFeeTransaction.ID = nil
+		lowerFeeTransaction.Outputs[0].Value++
+		_, _, err = miningManager.ValidateAndInsertTransactionWithReplacement(
+			lowerFeeTransaction, false, false, model.RBFPolicyMandatory)
+		if err == nil || !strings.Contains(err.Error(), "is not higher than the fee rate") {
+			t.Fatalf("ValidateAndInsertTransactionWithReplacement: expected an insufficient fee error, got: %v", err)
+		}
+
+		_, _, err = miningManager.ValidateAndInsertTransactionWithReplacement(
+			lowerFeeTransaction, false, false, model.RBFPolicyForbidden)
+		if err == nil || !strings.Contains(err.Error(), "already spent by transaction") {
+			t.Fatalf("ValidateAndInsertTransactionWithReplacement: expected a double spend error, got: %v", err)
+		}
+
+		transactionsFromMempool, _ := miningManager.AllTransactions(true, false)
+		if len(transactionsFromMempool) != 2 || !contains(transaction, transactionsFromMempool) ||
+			!contains(redeemer, transactionsFromMempool) {
+			t.Fatalf("Expected a rejected replacement to leave the mempool untouched")
+		}
+
+		higherFeeTransaction := transaction.Clone()
+		higherFeeTransaction.ID = nil
+		higherFeeTransaction.Outputs[0].Value -= 1000
+		acceptedTransactions, replacedTransaction, err := miningManager.ValidateAndInsertTransactionWithReplacement(
+			higherFeeTransaction, false, false, model.RBFPolicyMandatory)
+		if err != nil {
+			t.Fatalf("ValidateAndInsertTransactionWithReplacement: %v", err)
+		}
+		if !consensushashing.TransactionID(replacedTransaction).Equal(consensushashing.TransactionID(transaction)) {
+			t.Fatalf("Expected replaced transaction %s, got %s",
+				consensushashing.TransactionID(transaction), consensushashing.TransactionID(replacedTransaction))
+		}
+		if len(acceptedTransactions) != 1 || !acceptedTransactions[0].Equal(higherFeeTransaction) {
+			t.Fatalf("Expected the replacing transaction to be the only accepted transaction")
+		}
+
+		transactionsFromMempool, _ = miningManager.AllTransactions(true, false)
+		if len(transactionsFromMempool) != 1 || !contains(higherFeeTransaction, transactionsFromMempool) {
+			t.Fatalf("Expected the mempool to contain only the replacing transaction")
+		}
+	})
+}
+
 // TestHandleNewBlockTransactions verifies that all the transactions in the block were successfully removed from the mempool.
 func TestHandleNewBlockTransactions(t *testing.T) {
 	testutils.ForAllNets(t, true, func(t *testing.T, consensusConfig *consensus.Config) {
@@ -0,0 +1,17 @@
+package model
+
+// FeeRateBucket is a fee rate recommendation paired with the estimated time
+// it would take a transaction paying it to be included in the DAG.
+// Fee rates are measured in sompi per gram of transaction mass.
+type FeeRateBucket struct {
+	FeeRate          float64
+	EstimatedSeconds float64
+}
+
+// FeeEstimate is a set of fee rate recommendations, ordered by descending fee rate
+// and ascending estimated inclusion time when chaining priority | normal | low
+type FeeEstimate struct {
+	PriorityBucket FeeRateBucket
+	NormalBuckets  []FeeRateBucket
+	LowBuckets     []FeeRateBucket
+}
@@ -0,0 +1,14 @@
+package model
+
+// RBFPolicy specifies how the mempool handles a transaction that double-spends
+// the inputs of transactions it already holds (replace-by-fee)
+type RBFPolicy uint8
+
+const (
+	// RBFPolicyForbidden rejects any transaction that conflicts with a mempool transaction
+	RBFPolicyForbidden RBFPolicy = iota
+
+	// RBFPolicyMandatory requires a transaction to replace the mempool transaction it conflicts
+	// with, provided that it pays a higher fee rate. Transactions with no conflicts are rejected
+	RBFPolicyMandatory
+)
@@ -0,0 +1,16 @@
+package txindex
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+)
+
+// TransactionAcceptance describes where a transaction was included in the DAG
+// and which chain block accepted it
+type TransactionAcceptance struct {
+	IncludingBlockHash     *externalapi.DomainHash
+	AcceptingBlockHash     *externalapi.DomainHash
+	AcceptingBlockDAAScore uint64
+}
+
+// TransactionAcceptances is a map between transaction IDs and their acceptance data
+type TransactionAcceptances map[externalapi.DomainTransactionID]*TransactionAcceptance
@@ -0,0 +1,84 @@
+package txindex
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+const serializedTransactionAcceptanceSize = 2*externalapi.DomainHashSize + 8
+
+func serializeTransactionAcceptance(transactionAcceptance *TransactionAcceptance) []byte {
+	serializedTransactionAcceptance := make([]byte, serializedTransactionAcceptanceSize)
+	copy(serializedTransactionAcceptance[:externalapi.DomainHashSize],
+		transactionAcceptance.IncludingBlockHash.ByteSlice())
+	copy(serializedTransactionAcceptance[externalapi.DomainHashSize:2*externalapi.DomainHashSize],
+		transactionAcceptance.AcceptingBlockHash.ByteSlice())
+	binary.LittleEndian.PutUint64(serializedTransactionAcceptance[2*externalapi.DomainHashSize:],
+		transactionAcceptance.AcceptingBlockDAAScore)
+	return serializedTransactionAcceptance
+}
+
+func deserializeTransactionAcceptance(serializedTransactionAcceptance []byte) (*TransactionAcceptance, error) {
+	if len(serializedTransactionAcceptance) != serializedTransactionAcceptanceSize {
+		return nil, errors.Wrapf(io.ErrUnexpectedEOF, "unexpected length %d while deserializing "+
+			"transaction acceptance", len(serializedTransactionAcceptance))
+	}
+
+	includingBlockHash, err := externalapi.NewDomainHashFromByteSlice(
+		serializedTransactionAcceptance[:externalapi.DomainHashSize])
+	if err != nil {
+		return nil, err
+	}
+	acceptingBlockHash, err := externalapi.NewDomainHashFromByteSlice(
+		serializedTransactionAcceptance[externalapi.DomainHashSize : 2*externalapi.DomainHashSize])
+	if err != nil {
+		return nil, err
+	}
+	acceptingBlockDAAScore := binary.LittleEndian.Uint64(serializedTransactionAcceptance[2*externalapi.DomainHashSize:])
+
+	return &TransactionAcceptance{
+		IncludingBlockHash:     includingBlockHash,
+		AcceptingBlockHash:     acceptingBlockHash,
+		AcceptingBlockDAAScore: acceptingBlockDAAScore,
+	}, nil
+}
+
+const hashesLengthSize = 8
+
+func serializeHashes(hashes []*externalapi.DomainHash) []byte {
+	serializedHashes := make([]byte, hashesLengthSize+externalapi.DomainHashSize*len(hashes))
+	binary.LittleEndian.PutUint64(serializedHashes[:hashesLengthSize], uint64(len(hashes)))
+	for i, hash := range hashes {
+		start := hashesLengthSize + externalapi.DomainHashSize*i
+		end := start + externalapi.DomainHashSize
+		copy(serializedHashes[start:end], hash.ByteSlice())
+	}
+	return serializedHashes
+}
+
+func deserializeHashes(serializedHashes []byte) ([]*externalapi.DomainHash, error) {
+	if len(serializedHashes) < hashesLengthSize {
+		return nil, errors.Wrapf(io.ErrUnexpectedEOF, "unexpected EOF while deserializing hashes")
+	}
+	length := binary.LittleEndian.Uint64(serializedHashes[:hashesLengthSize])
+	hashes := make([]*externalapi.DomainHash, length)
+	for i := uint64(0); i < length; i++ {
+		start := hashesLengthSize + externalapi.DomainHashSize*i
+		end := start + externalapi.DomainHashSize
+
+		if end > uint64(len(serializedHashes)) {
+			return nil, errors.Wrapf(io.ErrUnexpectedEOF, "unexpected EOF while deserializing hashes")
+		}
+
+		var err error
+		hashes[i], err = externalapi.NewDomainHashFromByteSlice(serializedHashes[start:end])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,66 @@
+package txindex
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/pkg/errors"
+)
+
+func Test_serializeTransactionAcceptance(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+
+	for i := 0; i < 32; i++ {
+		var includingBlockHashBytes, acceptingBlockHashBytes [externalapi.DomainHashSize]byte
+		r.Read(includingBlockHashBytes[:])
+		r.Read(acceptingBlockHashBytes[:])
+		transactionAcceptance := &TransactionAcceptance{
+			IncludingBlockHash:     externalapi.NewDomainHashFromByteArray(&includingBlockHashBytes),
+			AcceptingBlockHash:     externalapi.NewDomainHashFromByteArray(&acceptingBlockHashBytes),
+			AcceptingBlockDAAScore: r.Uint64(),
+		}
+		result, err := deserializeTransactionAcceptance(serializeTransactionAcceptance(transactionAcceptance))
+		if err != nil {
+			t.Fatalf("Failed deserializing transaction acceptance: %v", err)
+		}
+		if !result.IncludingBlockHash.Equal(transactionAcceptance.IncludingBlockHash) ||
+			!result.AcceptingBlockHash.Equal(transactionAcceptance.AcceptingBlockHash) ||
+			result.AcceptingBlockDAAScore != transactionAcceptance.AcceptingBlockDAAScore {
+			t.Fatalf("Expected \n %+v \n==\n %+v\n", transactionAcceptance, result)
+		}
+	}
+}
+
+func Test_deserializeTransactionAcceptanceFailure(t *testing.T) {
+	serialized := serializeTransactionAcceptance(&TransactionAcceptance{
+		IncludingBlockHash:     externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1}),
+		AcceptingBlockHash:     externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{2}),
+		AcceptingBlockDAAScore: 3,
+	})
+	_, err := deserializeTransactionAcceptance(serialized[:len(serialized)-1])
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Expected error to be EOF, instead got: %v", err)
+	}
+}
+
+func Test_serializeHashes(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+
+	for length := 0; length < 32; length++ {
+		hashes := make([]*externalapi.DomainHash, length)
+		for i := range hashes {
+			var hashBytes [externalapi.DomainHashSize]byte
+			r.Read(hashBytes[:])
+			hashes[i] = externalapi.NewDomainHashFromByteArray(&hashBytes)
+		}
+		result, err := deserializeHashes(serializeHashes(hashes))
+		if err != nil {
+			t.Fatalf("Failed deserializing hashes: %v", err)
+		}
+		if !externalapi.HashesEqual(hashes, result) {
+			t.Fatalf("Expected \n %s \n==\n %s\n", hashes, result)
+		}
+	}
+}
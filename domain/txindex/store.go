@@ -0,0 +1,238 @@
+package txindex
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+	"github.com/pkg/errors"
+)
+
+var txIndexBucket = database.MakeBucket([]byte("tx-index"))
+var virtualParentsKey = database.MakeBucket([]byte("")).Key([]byte("tx-index-virtual-parents"))
+var pruningPointKey = database.MakeBucket([]byte("")).Key([]byte("tx-index-pruning-point"))
+
+type txIndexStore struct {
+	database database.Database
+	toAdd    TransactionAcceptances
+	toRemove map[externalapi.DomainTransactionID]struct{}
+
+	virtualParents []*externalapi.DomainHash
+}
+
+func newTXIndexStore(database database.Database) *txIndexStore {
+	return &txIndexStore{
+		database: database,
+		toAdd:    make(TransactionAcceptances),
+		toRemove: make(map[externalapi.DomainTransactionID]struct{}),
+	}
+}
+
+func (tis *txIndexStore) add(transactionID externalapi.DomainTransactionID, transactionAcceptance *TransactionAcceptance) {
+	log.Tracef("Adding transaction %s accepted by block %s", transactionID, transactionAcceptance.AcceptingBlockHash)
+
+	delete(tis.toRemove, transactionID)
+	tis.toAdd[transactionID] = transactionAcceptance
+}
+
+func (tis *txIndexStore) remove(transactionID externalapi.DomainTransactionID) {
+	log.Tracef("Removing transaction %s", transactionID)
+
+	delete(tis.toAdd, transactionID)
+	tis.toRemove[transactionID] = struct{}{}
+}
+
+func (tis *txIndexStore) updateVirtualParents(virtualParents []*externalapi.DomainHash) {
+	tis.virtualParents = virtualParents
+}
+
+func (tis *txIndexStore) discard() {
+	tis.toAdd = make(TransactionAcceptances)
+	tis.toRemove = make(map[externalapi.DomainTransactionID]struct{})
+	tis.virtualParents = nil
+}
+
+func (tis *txIndexStore) commit() error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "txIndexStore.commit")
+	defer onEnd()
+
+	dbTransaction, err := tis.database.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTransaction.RollbackUnlessClosed()
+
+	for transactionID := range tis.toRemove {
+		err := dbTransaction.Delete(tis.convertTransactionIDToKey(&transactionID))
+		if err != nil {
+			return err
+		}
+	}
+
+	for transactionID, transactionAcceptance := range tis.toAdd {
+		err := dbTransaction.Put(tis.convertTransactionIDToKey(&transactionID),
+			serializeTransactionAcceptance(transactionAcceptance))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = dbTransaction.Put(virtualParentsKey, serializeHashes(tis.virtualParents))
+	if err != nil {
+		return err
+	}
+
+	err = dbTransaction.Commit()
+	if err != nil {
+		return err
+	}
+
+	tis.discard()
+	return nil
+}
+
+func (tis *txIndexStore) addAndCommitTransactionAcceptancesWithoutTransaction(
+	transactionAcceptances TransactionAcceptances) error {
+
+	for transactionID, transactionAcceptance := range transactionAcceptances {
+		err := tis.database.Put(tis.convertTransactionIDToKey(&transactionID),
+			serializeTransactionAcceptance(transactionAcceptance))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tis *txIndexStore) updateAndCommitVirtualParentsWithoutTransaction(virtualParents []*externalapi.DomainHash) error {
+	return tis.database.Put(virtualParentsKey, serializeHashes(virtualParents))
+}
+
+func (tis *txIndexStore) updateAndCommitPruningPointWithoutTransaction(pruningPoint *externalapi.DomainHash) error {
+	return tis.database.Put(pruningPointKey, pruningPoint.ByteSlice())
+}
+
+func (tis *txIndexStore) convertTransactionIDToKey(transactionID *externalapi.DomainTransactionID) *database.Key {
+	return txIndexBucket.Key(transactionID.ByteSlice())
+}
+
+func (tis *txIndexStore) isAnythingStaged() bool {
+	return len(tis.toAdd) > 0 || len(tis.toRemove) > 0
+}
+
+func (tis *txIndexStore) getTransactionAcceptance(transactionID *externalapi.DomainTransactionID) (
+	*TransactionAcceptance, bool, error) {
+
+	if tis.isAnythingStaged() {
+		return nil, false, errors.Errorf("cannot get a transaction acceptance while staging isn't empty")
+	}
+
+	serializedTransactionAcceptance, err := tis.database.Get(tis.convertTransactionIDToKey(transactionID))
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	transactionAcceptance, err := deserializeTransactionAcceptance(serializedTransactionAcceptance)
+	if err != nil {
+		return nil, false, err
+	}
+	return transactionAcceptance, true, nil
+}
+
+func (tis *txIndexStore) getVirtualParents() ([]*externalapi.DomainHash, error) {
+	if tis.isAnythingStaged() {
+		return nil, errors.Errorf("cannot get the virtual parents while staging isn't empty")
+	}
+
+	serializedHashes, err := tis.database.Get(virtualParentsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return deserializeHashes(serializedHashes)
+}
+
+func (tis *txIndexStore) getPruningPoint() (*externalapi.DomainHash, error) {
+	serializedPruningPoint, err := tis.database.Get(pruningPointKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return externalapi.NewDomainHashFromByteSlice(serializedPruningPoint)
+}
+
+// deleteAcceptedBelowDAAScore deletes all the transactions that were accepted by
+// chain blocks with a DAA score lower than the given one, and returns how many were deleted
+func (tis *txIndexStore) deleteAcceptedBelowDAAScore(daaScore uint64) (int, error) {
+	if tis.isAnythingStaged() {
+		return 0, errors.Errorf("cannot delete transaction acceptances while staging isn't empty")
+	}
+
+	cursor, err := tis.database.Cursor(txIndexBucket)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	deletedCount := 0
+	for cursor.Next() {
+		serializedTransactionAcceptance, err := cursor.Value()
+		if err != nil {
+			return 0, err
+		}
+		transactionAcceptance, err := deserializeTransactionAcceptance(serializedTransactionAcceptance)
+		if err != nil {
+			return 0, err
+		}
+		if transactionAcceptance.AcceptingBlockDAAScore >= daaScore {
+			continue
+		}
+
+		key, err := cursor.Key()
+		if err != nil {
+			return 0, err
+		}
+		err = tis.database.Delete(key)
+		if err != nil {
+			return 0, err
+		}
+		deletedCount++
+	}
+
+	return deletedCount, nil
+}
+
+func (tis *txIndexStore) deleteAll() error {
+	// First we delete the virtual parents, so if anything goes wrong, the TX index will be marked as "not synced"
+	// and will be reset.
+	err := tis.database.Delete(virtualParentsKey)
+	if err != nil {
+		return err
+	}
+
+	err = tis.database.Delete(pruningPointKey)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := tis.database.Cursor(txIndexBucket)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+	for cursor.Next() {
+		key, err := cursor.Key()
+		if err != nil {
+			return err
+		}
+
+		err = tis.database.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package txindex
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+)
+
+func TestDeleteAcceptedBelowDAAScore(t *testing.T) {
+	db, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %s", err)
+	}
+	defer db.Close()
+
+	store := newTXIndexStore(db)
+	transactionAcceptances := make(TransactionAcceptances)
+	for i := byte(0); i < 10; i++ {
+		hashBytes := [externalapi.DomainHashSize]byte{i}
+		transactionAcceptances[*externalapi.NewDomainTransactionIDFromByteArray(&hashBytes)] = &TransactionAcceptance{
+			IncludingBlockHash:     externalapi.NewDomainHashFromByteArray(&hashBytes),
+			AcceptingBlockHash:     externalapi.NewDomainHashFromByteArray(&hashBytes),
+			AcceptingBlockDAAScore: uint64(i),
+		}
+	}
+	err = store.addAndCommitTransactionAcceptancesWithoutTransaction(transactionAcceptances)
+	if err != nil {
+		t.Fatalf("addAndCommitTransactionAcceptancesWithoutTransaction: %s", err)
+	}
+
+	deletedCount, err := store.deleteAcceptedBelowDAAScore(4)
+	if err != nil {
+		t.Fatalf("deleteAcceptedBelowDAAScore: %s", err)
+	}
+	if deletedCount != 4 {
+		t.Fatalf("Expected 4 deleted transactions, got %d", deletedCount)
+	}
+
+	for transactionID, transactionAcceptance := range transactionAcceptances {
+		_, found, err := store.getTransactionAcceptance(&transactionID)
+		if err != nil {
+			t.Fatalf("getTransactionAcceptance: %s", err)
+		}
+		shouldBeFound := transactionAcceptance.AcceptingBlockDAAScore >= 4
+		if found != shouldBeFound {
+			t.Fatalf("Transaction %s accepted at DAA score %d: expected found to be %t",
+				transactionID, transactionAcceptance.AcceptingBlockDAAScore, shouldBeFound)
+		}
+	}
+
+	_, err = store.getPruningPoint()
+	if !database.IsNotFoundError(err) {
+		t.Fatalf("Expected the pruning point to be missing, got: %v", err)
+	}
+	expectedPruningPoint := externalapi.NewDomainHashFromByteArray(&[externalapi.DomainHashSize]byte{1})
+	err = store.updateAndCommitPruningPointWithoutTransaction(expectedPruningPoint)
+	if err != nil {
+		t.Fatalf("updateAndCommitPruningPointWithoutTransaction: %s", err)
+	}
+	pruningPoint, err := store.getPruningPoint()
+	if err != nil {
+		t.Fatalf("getPruningPoint: %s", err)
+	}
+	if !pruningPoint.Equal(expectedPruningPoint) {
+		t.Fatalf("Unexpected pruning point %s", pruningPoint)
+	}
+}
@@ -0,0 +1,271 @@
+package txindex
+
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/domain"
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/kaspanet/kaspad/infrastructure/logger"
+)
+
+// TXIndex maintains an index between transaction IDs and the blocks
+// that included and accepted them
+type TXIndex struct {
+	domain domain.Domain
+	store  *txIndexStore
+
+	mutex sync.Mutex
+}
+
+// New creates a new TX index.
+//
+// NOTE: While this is called no new blocks can be added to the consensus.
+func New(domain domain.Domain, database database.Database) (*TXIndex, error) {
+	txIndex := &TXIndex{
+		domain: domain,
+		store:  newTXIndexStore(database),
+	}
+	isSynced, err := txIndex.isSynced()
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSynced {
+		err := txIndex.Reset()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return txIndex, nil
+}
+
+// Reset deletes the whole TX index and resyncs it from consensus.
+func (ti *TXIndex) Reset() error {
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+
+	log.Infof("Starting TX index reset")
+
+	err := ti.store.deleteAll()
+	if err != nil {
+		return err
+	}
+
+	virtualInfo, err := ti.domain.Consensus().GetVirtualInfo()
+	if err != nil {
+		return err
+	}
+
+	pruningPoint, err := ti.domain.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+
+	chainPath, err := ti.domain.Consensus().GetVirtualSelectedParentChainFromBlock(pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	// The transactions accepted by the pruning point itself are indexed as well,
+	// if its acceptance data is available. It isn't when the node synced by
+	// importing the pruning point rather than by validating it.
+	pruningPointTransactionAcceptances, err := ti.acceptedTransactions([]*externalapi.DomainHash{pruningPoint})
+	if err != nil {
+		if !database.IsNotFoundError(err) {
+			return err
+		}
+		log.Debugf("The acceptance data of pruning point %s is not available", pruningPoint)
+	} else {
+		err = ti.store.addAndCommitTransactionAcceptancesWithoutTransaction(pruningPointTransactionAcceptances)
+		if err != nil {
+			return err
+		}
+	}
+
+	const step = 1000
+	for start := 0; start < len(chainPath.Added); start += step {
+		end := start + step
+		if end > len(chainPath.Added) {
+			end = len(chainPath.Added)
+		}
+
+		transactionAcceptances, err := ti.acceptedTransactions(chainPath.Added[start:end])
+		if err != nil {
+			return err
+		}
+
+		err = ti.store.addAndCommitTransactionAcceptancesWithoutTransaction(transactionAcceptances)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ti.store.updateAndCommitPruningPointWithoutTransaction(pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	// This has to be done last to mark that the reset went smoothly and no reset has to be called next time.
+	err = ti.store.updateAndCommitVirtualParentsWithoutTransaction(virtualInfo.ParentHashes)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Finished TX index reset")
+	return nil
+}
+
+func (ti *TXIndex) isSynced() (bool, error) {
+	txIndexVirtualParents, err := ti.store.getVirtualParents()
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	virtualInfo, err := ti.domain.Consensus().GetVirtualInfo()
+	if err != nil {
+		return false, err
+	}
+
+	return externalapi.HashesEqual(virtualInfo.ParentHashes, txIndexVirtualParents), nil
+}
+
+// Update updates the TX index with the given DAG selected parent chain changes
+func (ti *TXIndex) Update(virtualChangeSet *externalapi.VirtualChangeSet) error {
+	onEnd := logger.LogAndMeasureExecutionTime(log, "TXIndex.Update")
+	defer onEnd()
+
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+
+	chainChanges := virtualChangeSet.VirtualSelectedParentChainChanges
+	if chainChanges == nil {
+		chainChanges = &externalapi.SelectedChainPath{}
+	}
+	log.Tracef("Updating TX index with %d removed and %d added chain blocks",
+		len(chainChanges.Removed), len(chainChanges.Added))
+
+	// Transactions accepted by chain blocks that are no longer in the selected
+	// chain are removed first, so that the ones that are re-accepted by the new
+	// chain blocks are added back with their new acceptance data
+	removedTransactionAcceptances, err := ti.acceptedTransactions(chainChanges.Removed)
+	if err != nil {
+		return err
+	}
+	for transactionID := range removedTransactionAcceptances {
+		ti.store.remove(transactionID)
+	}
+
+	addedTransactionAcceptances, err := ti.acceptedTransactions(chainChanges.Added)
+	if err != nil {
+		return err
+	}
+	for transactionID, transactionAcceptance := range addedTransactionAcceptances {
+		ti.store.add(transactionID, transactionAcceptance)
+	}
+
+	ti.store.updateVirtualParents(virtualChangeSet.VirtualParents)
+
+	err = ti.store.commit()
+	if err != nil {
+		return err
+	}
+
+	return ti.pruneIfPruningPointMoved()
+}
+
+// pruneIfPruningPointMoved removes the transactions accepted by chain blocks below
+// the pruning point once it moves, since consensus prunes their data as well
+func (ti *TXIndex) pruneIfPruningPointMoved() error {
+	pruningPoint, err := ti.domain.Consensus().PruningPoint()
+	if err != nil {
+		return err
+	}
+
+	txIndexPruningPoint, err := ti.store.getPruningPoint()
+	if err != nil {
+		if !database.IsNotFoundError(err) {
+			return err
+		}
+	} else if txIndexPruningPoint.Equal(pruningPoint) {
+		return nil
+	}
+
+	pruningPointHeader, err := ti.domain.Consensus().GetBlockHeader(pruningPoint)
+	if err != nil {
+		return err
+	}
+
+	deletedCount, err := ti.store.deleteAcceptedBelowDAAScore(pruningPointHeader.DAAScore())
+	if err != nil {
+		return err
+	}
+	log.Debugf("Pruned %d transactions accepted below pruning point %s from the TX index",
+		deletedCount, pruningPoint)
+
+	// This is done last, so that pruning is retried if it's interrupted
+	return ti.store.updateAndCommitPruningPointWithoutTransaction(pruningPoint)
+}
+
+// acceptedTransactions returns the acceptance data of all the transactions
+// accepted by the given chain blocks
+func (ti *TXIndex) acceptedTransactions(chainBlockHashes []*externalapi.DomainHash) (TransactionAcceptances, error) {
+	transactionAcceptances := make(TransactionAcceptances)
+
+	const chunk = 1000
+	for start := 0; start < len(chainBlockHashes); start += chunk {
+		end := start + chunk
+		if end > len(chainBlockHashes) {
+			end = len(chainBlockHashes)
+		}
+		chainBlocksChunk := chainBlockHashes[start:end]
+
+		// We use chunks in order to avoid blocking consensus for too long
+		chainBlocksAcceptanceData, err := ti.domain.Consensus().GetBlocksAcceptanceData(chainBlocksChunk)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, chainBlockHash := range chainBlocksChunk {
+			chainBlockHeader, err := ti.domain.Consensus().GetBlockHeader(chainBlockHash)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, blockAcceptanceData := range chainBlocksAcceptanceData[i] {
+				for _, transactionAcceptanceData := range blockAcceptanceData.TransactionAcceptanceData {
+					if !transactionAcceptanceData.IsAccepted {
+						continue
+					}
+					transactionID := consensushashing.TransactionID(transactionAcceptanceData.Transaction)
+					transactionAcceptances[*transactionID] = &TransactionAcceptance{
+						IncludingBlockHash:     blockAcceptanceData.BlockHash,
+						AcceptingBlockHash:     chainBlockHash,
+						AcceptingBlockDAAScore: chainBlockHeader.DAAScore(),
+					}
+				}
+			}
+		}
+	}
+
+	return transactionAcceptances, nil
+}
+
+// TransactionAcceptance returns the acceptance data of the transaction with the given ID.
+// Returns false if the transaction was not accepted by the selected chain since the pruning point
+func (ti *TXIndex) TransactionAcceptance(transactionID *externalapi.DomainTransactionID) (
+	*TransactionAcceptance, bool, error) {
+
+	onEnd := logger.LogAndMeasureExecutionTime(log, "TXIndex.TransactionAcceptance")
+	defer onEnd()
+
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+
+	return ti.store.getTransactionAcceptance(transactionID)
+}
@@ -0,0 +1,120 @@
+package utxoindex
+
+import (
+	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+)
+
+// Verify checks that the UTXO index stored in the given database matches
+// the virtual UTXO set of the given consensus. Unlike New, it never resets
+// the index, nor writes anything else to the database.
+func Verify(consensus externalapi.Consensus, db database.Database) (*externalapi.IntegrityCheck, error) {
+	check := externalapi.NewIntegrityCheck("UTXO index")
+	store := newUTXOIndexStore(db)
+
+	utxoIndexVirtualParents, err := store.getVirtualParents()
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			check.AddError("the UTXO index is missing its virtual parents, " +
+				"so it's going to be reset the next time kaspad starts with --utxoindex")
+			return check, nil
+		}
+		return nil, err
+	}
+
+	virtualInfo, err := consensus.GetVirtualInfo()
+	if err != nil {
+		return nil, err
+	}
+	if !externalapi.HashesEqual(virtualInfo.ParentHashes, utxoIndexVirtualParents) {
+		check.AddError("the virtual parents of the UTXO index %s don't match the virtual parents %s",
+			utxoIndexVirtualParents, virtualInfo.ParentHashes)
+	}
+
+	var virtualUTXOSetSompi uint64
+	var foundUTXOCount uint64
+	var fromOutpoint *externalapi.DomainOutpoint
+	for {
+		const step = 1000
+		virtualUTXOs, err := consensus.GetVirtualUTXOs(virtualInfo.ParentHashes, fromOutpoint, step)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, virtualUTXO := range virtualUTXOs {
+			if fromOutpoint != nil && virtualUTXO.Outpoint.Equal(fromOutpoint) {
+				continue
+			}
+			check.ItemsChecked++
+			virtualUTXOSetSompi += virtualUTXO.UTXOEntry.Amount()
+
+			bucket := store.bucketForScriptPublicKey(virtualUTXO.UTXOEntry.ScriptPublicKey())
+			key, err := store.convertOutpointToKey(bucket, virtualUTXO.Outpoint)
+			if err != nil {
+				return nil, err
+			}
+			serializedUTXOEntry, err := db.Get(key)
+			if err != nil {
+				if database.IsNotFoundError(err) {
+					check.AddError("UTXO %s is missing from the UTXO index", virtualUTXO.Outpoint)
+					continue
+				}
+				return nil, err
+			}
+			foundUTXOCount++
+
+			utxoEntry, err := deserializeUTXOEntry(serializedUTXOEntry)
+			if err != nil {
+				return nil, err
+			}
+			if !utxoEntry.Equal(virtualUTXO.UTXOEntry) {
+				check.AddError("UTXO %s in the UTXO index doesn't match the virtual UTXO set",
+					virtualUTXO.Outpoint)
+			}
+		}
+
+		if len(virtualUTXOs) < step {
+			break
+		}
+
+		fromOutpoint = virtualUTXOs[len(virtualUTXOs)-1].Outpoint
+	}
+
+	utxoIndexUTXOCount, err := countUTXOIndexEntries(db)
+	if err != nil {
+		return nil, err
+	}
+	if utxoIndexUTXOCount != foundUTXOCount {
+		check.AddError("the UTXO index has %d UTXOs that are not in the virtual UTXO set",
+			utxoIndexUTXOCount-foundUTXOCount)
+	}
+
+	circulatingSompiSupply, err := store.getCirculatingSompiSupply()
+	if err != nil {
+		if database.IsNotFoundError(err) {
+			check.AddError("the UTXO index is missing its circulating supply")
+			return check, nil
+		}
+		return nil, err
+	}
+	if circulatingSompiSupply != virtualUTXOSetSompi {
+		check.AddError("the circulating supply of the UTXO index is %d sompi while the virtual UTXO set "+
+			"holds %d sompi", circulatingSompiSupply, virtualUTXOSetSompi)
+	}
+
+	return check, nil
+}
+
+func countUTXOIndexEntries(db database.Database) (uint64, error) {
+	cursor, err := db.Cursor(utxoIndexBucket)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close()
+
+	var count uint64
+	for cursor.Next() {
+		count++
+	}
+	return count, nil
+}
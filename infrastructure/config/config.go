@@ -7,6 +7,7 @@ package config
 import (
 	// _ "embed" is necessary for the go:embed feature.
 	_ "embed"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os"
@@ -54,6 +55,14 @@ const (
 	sampleConfigFilename    = "sample-kaspad.conf"
 	defaultMaxUTXOCacheSize = 5_000_000_000
 	defaultProtocolVersion  = 5
+
+	// utxoSnapshotTrustedKeyLength is the length of a serialized Schnorr public key
+	utxoSnapshotTrustedKeyLength = 32
+
+	// DBTypeLevelDB is the --dbtype of the default LevelDB database backend
+	DBTypeLevelDB = "leveldb"
+	// DBTypeMemory is the --dbtype of the in-memory database backend, whose data is lost on shutdown
+	DBTypeMemory = "memory"
 )
 
 var (
@@ -94,11 +103,18 @@ type Flags struct {
 	RPCListeners                    []string      `long:"rpclisten" description:"Add an interface/port to listen for RPC connections (default port: 16110, testnet: 16210)"`
 	RPCCert                         string        `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey                          string        `long:"rpckey" description:"File containing the certificate key"`
+	RPCTLS                          bool          `long:"rpctls" description:"Serve RPC over TLS using --rpccert and --rpckey. A self-signed certificate pair is generated if both files are missing"`
+	RPCUser                         string        `long:"rpcuser" description:"Username required from RPC clients"`
+	RPCPass                         string        `long:"rpcpass" default-mask:"-" description:"Password required from RPC clients"`
+	RPCAuthToken                    string        `long:"rpcauthtoken" default-mask:"-" description:"Bearer token accepted from RPC clients"`
 	RPCMaxClients                   int           `long:"rpcmaxclients" description:"Max number of RPC clients for standard connections"`
 	RPCMaxWebsockets                int           `long:"rpcmaxwebsockets" description:"Max number of RPC websocket connections"`
 	RPCMaxConcurrentReqs            int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
 	DisableRPC                      bool          `long:"norpc" description:"Disable built-in RPC server"`
 	SafeRPC                         bool          `long:"saferpc" description:"Disable RPC commands which affect the state of the node"`
+	RPCRolesFile                    string        `long:"rpcrolesfile" description:"JSON file mapping RPC credentials and listeners to the RPC commands they are allowed to call"`
+	JSONRPCListeners                []string      `long:"jsonrpclisten" description:"Add an interface:port to serve the RPC as JSON-RPC over HTTP and WebSocket"`
+	JSONRPCCORSOrigins              []string      `long:"jsonrpccorsorigin" description:"Add an origin that web pages may call the JSON-RPC server from ('*' allows any origin)"`
 	DisableDNSSeed                  bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
 	DNSSeed                         string        `long:"dnsseed" description:"Override DNS seeds with specified hostname (Only 1 hostname allowed)"`
 	GRPCSeed                        string        `long:"grpcseed" description:"Hostname of gRPC server for seeding peers"`
@@ -106,10 +122,19 @@ type Flags struct {
 	Proxy                           string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser                       string        `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass                       string        `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
-	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG"`
+	ProxyDNS                        bool          `long:"proxydns" description:"Resolve host names such as DNS seeds through the proxy, using Tor's SOCKS5 RESOLVE extension"`
+	DbType                          string        `long:"dbtype" description:"Database backend to use for the Block DAG: leveldb, or memory for an ephemeral node whose data is lost on shutdown"`
+	ExportBlocks                    string        `long:"exportblocks" description:"Export the blocks of the node into the given file and exit"`
+	ImportBlocks                    string        `long:"importblocks" description:"Import the blocks of the given file, such as one created with --exportblocks, and exit"`
+	ExportUTXOSnapshot              string        `long:"exportutxosnapshot" description:"Export a UTXO snapshot of the pruning point into the given file and exit"`
+	ImportUTXOSnapshot              string        `long:"importutxosnapshot" description:"Start from the pruning point of the given UTXO snapshot file, such as one created with --exportutxosnapshot, and exit"`
+	UTXOSnapshotSigningKey          string        `long:"utxosnapshotsigningkey" description:"Sign the exported UTXO snapshot with the hex encoded Schnorr private key in the given file"`
+	UTXOSnapshotTrustedKeys         []string      `long:"utxosnapshottrustedkey" description:"Only import UTXO snapshots signed by this hex encoded Schnorr public key -- Can be used multiple times"`
+	VerifyDB                        bool          `long:"verifydb" description:"Open the database read-only, verify that its consensus stores and UTXO index are consistent, and exit"`
 	Profile                         string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	MetricsListen                   string        `long:"metricslisten" description:"Add an interface/port to expose Prometheus metrics on at /metrics (e.g. 127.0.0.1:9090)"`
 	LogLevel                        string        `short:"d" long:"loglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
-	Upnp                            bool          `long:"upnp" description:"Use UPnP to map our listening port outside of NAT"`
+	Upnp                            bool          `long:"upnp" description:"Use UPnP or NAT-PMP to map our listening port outside of NAT"`
 	MinRelayTxFee                   float64       `long:"minrelaytxfee" description:"The minimum transaction fee in KAS/kB to be considered a non-zero fee."`
 	MaxOrphanTxs                    uint64        `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
 	BlockMaxMass                    uint64        `long:"blockmaxmass" description:"Maximum transaction mass to be used when creating a block"`
@@ -122,6 +147,7 @@ type Flags struct {
 	ResetDatabase                   bool          `long:"reset-db" description:"Reset database before starting node. It's needed when switching between subnetworks."`
 	MaxUTXOCacheSize                uint64        `long:"maxutxocachesize" description:"Max size of loaded UTXO into ram from the disk in bytes"`
 	UTXOIndex                       bool          `long:"utxoindex" description:"Enable the UTXO index"`
+	TxIndex                         bool          `long:"txindex" description:"Enable the transaction index, which maps transaction IDs to the blocks that included and accepted them"`
 	IsArchivalNode                  bool          `long:"archival" description:"Run as an archival node: don't delete old block data when moving the pruning point (Warning: heavy disk usage)'"`
 	AllowSubmitBlockWhenNotSynced   bool          `long:"allow-submit-block-when-not-synced" hidden:"true" description:"Allow the node to accept blocks from RPC while not synced (this flag is mainly used for testing)"`
 	EnableSanityCheckPruningUTXOSet bool          `long:"enable-sanity-check-pruning-utxo" hidden:"true" description:"When moving the pruning point - check that the utxo set matches the utxo commitment"`
@@ -140,6 +166,7 @@ type Config struct {
 	MiningAddrs   []util.Address
 	MinRelayTxFee util.Amount
 	Whitelists    []*net.IPNet
+	RPCRoles      *RPCRolesConfig                 // nil if --rpcrolesfile is not set
 	SubnetworkID  *externalapi.DomainSubnetworkID // nil in full nodes
 }
 
@@ -193,12 +220,17 @@ func defaultFlags() *Flags {
 		MaxUTXOCacheSize:     defaultMaxUTXOCacheSize,
 		ServiceOptions:       &ServiceOptions{},
 		ProtocolVersion:      defaultProtocolVersion,
+		DbType:               DBTypeLevelDB,
 	}
 }
 
 // DefaultConfig returns the default kaspad configuration
 func DefaultConfig() *Config {
-	config := &Config{Flags: defaultFlags()}
+	config := &Config{
+		Flags:  defaultFlags(),
+		Dial:   net.DialTimeout,
+		Lookup: net.LookupIP,
+	}
 	config.NetworkFlags.ActiveNetParams = &dagconfig.MainnetParams
 	return config
 }
@@ -364,6 +396,18 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	// Validate the metrics listen address
+	if cfg.MetricsListen != "" {
+		_, _, err := net.SplitHostPort(cfg.MetricsListen)
+		if err != nil {
+			str := "%s: The metricslisten address %s is invalid: %s"
+			err := errors.Errorf(str, funcName, cfg.MetricsListen, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	// Don't allow ban durations that are too short.
 	if cfg.BanDuration < time.Second {
 		str := "%s: The banduration option may not be less than 1s -- parsed [%s]"
@@ -374,7 +418,7 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Validate any given whitelisted IP addresses and networks.
-	if len(cfg.Whitelists) > 0 {
+	if len(cfg.Flags.Whitelists) > 0 {
 		var ip net.IP
 		cfg.Whitelists = make([]*net.IPNet, 0, len(cfg.Flags.Whitelists))
 
@@ -427,17 +471,11 @@ func LoadConfig() (*Config, error) {
 		cfg.TargetOutboundPeers = 0
 	}
 
-	// Add the default listener if none were specified. The default
-	// listener is all addresses on the listen port for the network
-	// we are to connect to.
-	if len(cfg.Listeners) == 0 {
-		cfg.Listeners = []string{
-			net.JoinHostPort("", cfg.NetParams().DefaultPort),
-		}
-	}
+	cfg.Listeners = p2pListeners(cfg)
 
 	if cfg.DisableRPC {
 		log.Infof("RPC service is disabled")
+		cfg.JSONRPCListeners = nil
 	}
 
 	// Add the default RPC listener if none were specified. The default
@@ -449,6 +487,14 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if (cfg.RPCUser == "") != (cfg.RPCPass == "") {
+		str := "%s: The rpcuser and rpcpass options must be specified together"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	if cfg.RPCMaxConcurrentReqs < 0 {
 		str := "%s: The rpcmaxwebsocketconcurrentrequests option may " +
 			"not be less than 0 -- parsed [%d]"
@@ -490,6 +536,68 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if cfg.DbType != DBTypeLevelDB && cfg.DbType != DBTypeMemory {
+		str := "%s: The dbtype option must be either %s or %s -- parsed [%s]"
+		err := errors.Errorf(str, funcName, DBTypeLevelDB, DBTypeMemory, cfg.DbType)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	offlineModeCount := 0
+	for _, option := range []string{cfg.ExportBlocks, cfg.ImportBlocks, cfg.ExportUTXOSnapshot, cfg.ImportUTXOSnapshot} {
+		if option != "" {
+			offlineModeCount++
+		}
+	}
+	if cfg.VerifyDB {
+		offlineModeCount++
+	}
+	if offlineModeCount > 1 {
+		str := "%s: Only one of the exportblocks, importblocks, exportutxosnapshot, importutxosnapshot " +
+			"and verifydb options can be used at a time"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	if offlineModeCount > 0 && cfg.DbType == DBTypeMemory {
+		str := "%s: The exportblocks, importblocks, exportutxosnapshot, importutxosnapshot and verifydb " +
+			"options can't be used with dbtype=%s, since the database is lost when kaspad exits"
+		err := errors.Errorf(str, funcName, DBTypeMemory)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	if cfg.UTXOSnapshotSigningKey != "" && cfg.ExportUTXOSnapshot == "" {
+		str := "%s: The utxosnapshotsigningkey option can only be used with exportutxosnapshot"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	for _, trustedKey := range cfg.UTXOSnapshotTrustedKeys {
+		trustedKeyBytes, err := hex.DecodeString(trustedKey)
+		if err != nil || len(trustedKeyBytes) != utxoSnapshotTrustedKeyLength {
+			str := "%s: The utxosnapshottrustedkey option must be a hex encoded %d byte Schnorr public key, got %s"
+			err := errors.Errorf(str, funcName, utxoSnapshotTrustedKeyLength, trustedKey)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
+	if cfg.VerifyDB && cfg.ResetDatabase {
+		str := "%s: The verifydb and reset-db options can't be used together"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
 	// Look for illegal characters in the user agent comments.
 	for _, uaComment := range cfg.UserAgentComments {
 		if strings.ContainsAny(uaComment, "/:()") {
@@ -518,6 +626,37 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// JSON-RPC has no default port, so every listener must specify one
+	for _, listener := range cfg.JSONRPCListeners {
+		_, _, err := net.SplitHostPort(listener)
+		if err != nil {
+			str := "%s: invalid jsonrpclisten %s: %s"
+			err := errors.Errorf(str, funcName, listener, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
+	if cfg.RPCRolesFile != "" && cfg.SafeRPC {
+		str := "%s: --saferpc and --rpcrolesfile can not be used together"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	if cfg.RPCRolesFile != "" {
+		cfg.RPCRoles, err = loadRPCRolesFile(cfg.RPCRolesFile, cfg.NetParams().RPCPort)
+		if err != nil {
+			str := "%s: invalid rpcrolesfile: %s"
+			err := errors.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	// Disallow --addpeer and --connect used together
 	if len(cfg.AddPeers) > 0 && len(cfg.ConnectPeers) > 0 {
 		str := "%s: --addpeer and --connect can not be used together"
@@ -545,9 +684,18 @@ func LoadConfig() (*Config, error) {
 	// specified options. The default is to use the standard
 	// net.DialTimeout function as well as the system DNS resolver. When a
 	// proxy is specified, the dial function is set to the proxy specific
-	// dial function.
+	// dial function. Host names are resolved through the proxy, so that
+	// lookups don't leak to the local DNS resolver, only when --proxydns
+	// is set, since ordinary SOCKS5 proxies don't support resolving.
 	cfg.Dial = net.DialTimeout
 	cfg.Lookup = net.LookupIP
+	if cfg.ProxyDNS && cfg.Proxy == "" {
+		str := "%s: the --proxydns option requires --proxy"
+		err := errors.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
 	if cfg.Proxy != "" {
 		_, _, err := net.SplitHostPort(cfg.Proxy)
 		if err != nil {
@@ -564,6 +712,11 @@ func LoadConfig() (*Config, error) {
 			Password: cfg.ProxyPass,
 		}
 		cfg.Dial = proxy.DialTimeout
+		if cfg.ProxyDNS {
+			cfg.Lookup = func(host string) ([]net.IP, error) {
+				return network.ProxyLookupIP(host, cfg.Proxy, cfg.ProxyUser, cfg.ProxyPass)
+			}
+		}
 	}
 
 	// Warn about missing config file only after all other configuration is
@@ -595,3 +748,18 @@ func createDefaultConfigFile(destinationPath string) error {
 
 	return err
 }
+
+// p2pListeners returns the P2P listeners kaspad should bind. Behind a proxy
+// with listening disabled, none are bound, so that the node doesn't accept
+// connections that bypass the proxy. Otherwise, the default listener is
+// added if none were specified. The default listener is all addresses on
+// the listen port for the network we are to connect to.
+func p2pListeners(cfg *Config) []string {
+	if cfg.Proxy != "" && cfg.DisableListen {
+		return nil
+	}
+	if len(cfg.Listeners) == 0 {
+		return []string{net.JoinHostPort("", cfg.NetParams().DefaultPort)}
+	}
+	return cfg.Listeners
+}
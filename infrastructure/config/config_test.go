@@ -2,14 +2,17 @@ package config
 
 import (
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
 
 	"github.com/kaspanet/kaspad/domain/consensus/utils/subnetworks"
 
 	"github.com/kaspanet/kaspad/domain/consensus/model/externalapi"
+	"github.com/kaspanet/kaspad/domain/dagconfig"
 )
 
 func TestCreateDefaultConfigFile(t *testing.T) {
@@ -70,3 +73,121 @@ func TestConstants(t *testing.T) {
 		t.Errorf("subnetworks.SubnetworkIDRegistry value was changed from 2, therefore you probably need to update the help text for SubnetworkID")
 	}
 }
+
+func TestLoadRPCRolesFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError bool
+	}{
+		{
+			name: "valid",
+			content: `{"roles": {"info": ["GetInfo"]},
+				"credentials": [{"user": "miner", "password": "secret", "roles": ["mining"]}, {"name": "explorer", "token": "abc", "roles": ["info"]}],
+				"listeners": {"127.0.0.1": ["admin"]},
+				"defaultRoles": ["read-only"]}`,
+			expectedError: false,
+		},
+		{
+			name:          "credential with both a password and a token",
+			content:       `{"credentials": [{"user": "miner", "password": "secret", "token": "abc"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "credential without a password",
+			content:       `{"credentials": [{"user": "miner"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "duplicate credential names",
+			content:       `{"credentials": [{"user": "miner", "password": "a"}, {"name": "miner", "token": "b"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "reserved credential name",
+			content:       `{"credentials": [{"name": "operator", "token": "b"}]}`,
+			expectedError: true,
+		},
+		{
+			name:          "unknown field",
+			content:       `{"default": ["read-only"]}`,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "rpcroles.json")
+		err := ioutil.WriteFile(path, []byte(test.content), 0600)
+		if err != nil {
+			t.Fatalf("WriteFile: %s", err)
+		}
+
+		rpcRoles, err := loadRPCRolesFile(path, "16110")
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error but got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if rpcRoles.Credentials[0].Name != "miner" {
+			t.Errorf("%s: expected a credential's name to default to its user", test.name)
+		}
+		if _, ok := rpcRoles.Listeners["127.0.0.1:16110"]; !ok {
+			t.Errorf("%s: expected listener addresses to be normalized with the default port", test.name)
+		}
+	}
+}
+
+func TestP2PListeners(t *testing.T) {
+	defaultListener := net.JoinHostPort("", dagconfig.MainnetParams.DefaultPort)
+	tests := []struct {
+		name              string
+		proxy             string
+		disableListen     bool
+		listeners         []string
+		expectedListeners []string
+	}{
+		{
+			name:              "defaults",
+			expectedListeners: []string{defaultListener},
+		},
+		{
+			name:              "explicit listeners",
+			listeners:         []string{"127.0.0.1:1234"},
+			expectedListeners: []string{"127.0.0.1:1234"},
+		},
+		{
+			name:              "nolisten without a proxy keeps the default listener",
+			disableListen:     true,
+			expectedListeners: []string{defaultListener},
+		},
+		{
+			name:              "proxy with listening disabled",
+			proxy:             "127.0.0.1:9050",
+			disableListen:     true,
+			expectedListeners: nil,
+		},
+		{
+			name:              "proxy with explicit listeners",
+			proxy:             "127.0.0.1:9050",
+			listeners:         []string{"127.0.0.1:1234"},
+			expectedListeners: []string{"127.0.0.1:1234"},
+		},
+	}
+
+	for _, test := range tests {
+		cfg := DefaultConfig()
+		cfg.Proxy = test.proxy
+		cfg.DisableListen = test.disableListen
+		cfg.Listeners = test.listeners
+
+		listeners := p2pListeners(cfg)
+		if !reflect.DeepEqual(listeners, test.expectedListeners) {
+			t.Errorf("%s: expected listeners %v, got %v", test.name, test.expectedListeners, listeners)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
+	"github.com/pkg/errors"
+)
+
+// RPCClientFlags holds the configuration used by command line tools to secure their connection to kaspad's RPC server
+type RPCClientFlags struct {
+	RPCTLS       bool   `long:"rpctls" description:"Connect to the RPC server over TLS"`
+	RPCCAFile    string `long:"rpccafile" description:"File containing the certificate authority used to verify the RPC server's certificate (default: the system's root CAs)"`
+	RPCUser      string `long:"rpcuser" description:"RPC username"`
+	RPCPass      string `long:"rpcpass" default-mask:"-" description:"RPC password"`
+	RPCAuthToken string `long:"rpcauthtoken" default-mask:"-" description:"RPC bearer token"`
+}
+
+// ConnectOptions returns the RPC connect options described by the flags
+func (rpcClientFlags *RPCClientFlags) ConnectOptions() (*rpcauth.ConnectOptions, error) {
+	if rpcClientFlags.RPCCAFile != "" && !rpcClientFlags.RPCTLS {
+		return nil, errors.New("--rpccafile requires --rpctls")
+	}
+	if rpcClientFlags.RPCUser == "" && rpcClientFlags.RPCPass != "" {
+		return nil, errors.New("--rpcpass requires --rpcuser")
+	}
+
+	return &rpcauth.ConnectOptions{
+		UseTLS:    rpcClientFlags.RPCTLS,
+		CAFile:    rpcClientFlags.RPCCAFile,
+		User:      rpcClientFlags.RPCUser,
+		Password:  rpcClientFlags.RPCPass,
+		AuthToken: rpcClientFlags.RPCAuthToken,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/kaspanet/kaspad/util/network"
+	"github.com/pkg/errors"
+)
+
+// RPCOperatorIdentity is the identity of RPC clients that authenticate using
+// --rpcuser/--rpcpass or --rpcauthtoken. These clients are allowed to call any command
+const RPCOperatorIdentity = "operator"
+
+// RPCRolesConfig maps RPC clients to the roles they are granted. A role is a named
+// set of RPC commands.
+//
+// Clients that authenticate using one of Credentials are granted that credential's roles.
+// Other clients are granted the roles of the listener they connected through, or
+// DefaultRoles if that listener doesn't appear in Listeners.
+type RPCRolesConfig struct {
+	// Roles defines custom roles in addition to the built-in ones, mapping
+	// each role name to the RPC commands (e.g. "GetBlock") it allows
+	Roles map[string][]string `json:"roles"`
+
+	Credentials  []*RPCRoleCredential `json:"credentials"`
+	Listeners    map[string][]string  `json:"listeners"`
+	DefaultRoles []string             `json:"defaultRoles"`
+}
+
+// RPCRoleCredential is a credential RPC clients may authenticate with, along with the roles it grants.
+// A credential has either a User and a Password, or a Token.
+type RPCRoleCredential struct {
+	Name     string   `json:"name"`
+	User     string   `json:"user"`
+	Password string   `json:"password"`
+	Token    string   `json:"token"`
+	Roles    []string `json:"roles"`
+}
+
+func loadRPCRolesFile(path string, defaultRPCPort string) (*RPCRolesConfig, error) {
+	rpcRolesFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rpcRolesFile.Close()
+
+	decoder := json.NewDecoder(rpcRolesFile)
+	decoder.DisallowUnknownFields()
+	rpcRoles := &RPCRolesConfig{}
+	err = decoder.Decode(rpcRoles)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing %s", path)
+	}
+
+	names := make(map[string]struct{}, len(rpcRoles.Credentials))
+	for i, credential := range rpcRoles.Credentials {
+		if credential.Name == "" {
+			credential.Name = credential.User
+		}
+		if credential.Name == "" {
+			return nil, errors.Errorf("RPC credential #%d has neither a name nor a user", i)
+		}
+		if credential.Name == RPCOperatorIdentity {
+			return nil, errors.Errorf("RPC credential name %s is reserved", RPCOperatorIdentity)
+		}
+		if _, ok := names[credential.Name]; ok {
+			return nil, errors.Errorf("RPC credential name %s appears more than once", credential.Name)
+		}
+		names[credential.Name] = struct{}{}
+
+		hasBasic := credential.User != "" || credential.Password != ""
+		hasToken := credential.Token != ""
+		if hasBasic == hasToken {
+			return nil, errors.Errorf("RPC credential %s must have either a user and a password, or a token",
+				credential.Name)
+		}
+		if hasBasic && (credential.User == "" || credential.Password == "") {
+			return nil, errors.Errorf("RPC credential %s must have both a user and a password", credential.Name)
+		}
+	}
+
+	// Normalize listener addresses the same way --rpclisten is normalized,
+	// so that they can be compared against it
+	listeners := make(map[string][]string, len(rpcRoles.Listeners))
+	for listener, roles := range rpcRoles.Listeners {
+		normalized, err := network.NormalizeAddresses([]string{listener}, defaultRPCPort)
+		if err != nil {
+			return nil, err
+		}
+		listeners[normalized[0]] = roles
+	}
+	rpcRoles.Listeners = listeners
+
+	return rpcRoles, nil
+}
@@ -167,3 +167,47 @@ func testDatabaseDelete(t *testing.T, db database.Database, testName string) {
 			"unexpectedly returned that the value exists", testName)
 	}
 }
+
+func TestDatabasePutEmptyValue(t *testing.T) {
+	testForAllDatabaseTypes(t, "TestDatabasePutEmptyValue", testDatabasePutEmptyValue)
+}
+
+func testDatabasePutEmptyValue(t *testing.T, db database.Database, testName string) {
+	// Put an empty value directly and another one through a transaction
+	key1 := database.MakeBucket(nil).Key([]byte("key1"))
+	err := db.Put(key1, []byte{})
+	if err != nil {
+		t.Fatalf("%s: Put "+
+			"unexpectedly failed: %s", testName, err)
+	}
+	key2 := database.MakeBucket(nil).Key([]byte("key2"))
+	dbTx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("%s: Begin "+
+			"unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Put(key2, nil)
+	if err != nil {
+		t.Fatalf("%s: Put "+
+			"unexpectedly failed: %s", testName, err)
+	}
+	err = dbTx.Commit()
+	if err != nil {
+		t.Fatalf("%s: Commit "+
+			"unexpectedly failed: %s", testName, err)
+	}
+
+	// Make sure that both keys exist with an empty value
+	for _, key := range []*database.Key{key1, key2} {
+		returnedValue, err := db.Get(key)
+		if err != nil {
+			t.Fatalf("%s: Get "+
+				"unexpectedly failed: %s", testName, err)
+		}
+		if len(returnedValue) != 0 {
+			t.Fatalf("%s: Get "+
+				"returned wrong value. Want: empty, got: %s",
+				testName, string(returnedValue))
+		}
+	}
+}
@@ -48,12 +48,41 @@ func NewLevelDB(path string, cacheSizeMiB int) (*LevelDB, error) {
 	return db, nil
 }
 
+// NewLevelDBReadOnly opens an existing leveldb instance defined by the
+// given path in read-only mode. Any attempt to write to it fails, and
+// unlike NewLevelDB, no attempt is made to recover a corrupted database.
+func NewLevelDBReadOnly(path string, cacheSizeMiB int) (*LevelDB, error) {
+	options := Options()
+	options.BlockCacheCapacity = cacheSizeMiB * opt.MiB
+	options.ErrorIfMissing = true
+	options.ReadOnly = true
+	ldb, err := leveldb.OpenFile(path, &options)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	db := &LevelDB{
+		ldb: ldb,
+	}
+	return db, nil
+}
+
 // Compact compacts the leveldb instance.
 func (db *LevelDB) Compact() error {
 	err := db.ldb.CompactRange(util.Range{Start: nil, Limit: nil})
 	return errors.WithStack(err)
 }
 
+// Stats returns the internal statistics of the leveldb instance.
+func (db *LevelDB) Stats() (*leveldb.DBStats, error) {
+	stats := &leveldb.DBStats{}
+	err := db.ldb.Stats(stats)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return stats, nil
+}
+
 // Close closes the leveldb instance.
 func (db *LevelDB) Close() error {
 	err := db.ldb.Close()
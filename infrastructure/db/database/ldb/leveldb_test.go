@@ -2,6 +2,8 @@ package ldb
 
 import (
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -151,3 +153,69 @@ func TestLevelDBTransactionSanity(t *testing.T) {
 			"returned unexpected error: %s", err)
 	}
 }
+
+func TestLevelDBReadOnly(t *testing.T) {
+	path, err := ioutil.TempDir("", "TestLevelDBReadOnly")
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: TempDir unexpectedly "+
+			"failed: %s", err)
+	}
+	defer os.RemoveAll(path)
+
+	// Opening a database that doesn't exist should fail
+	_, err = NewLevelDBReadOnly(filepath.Join(path, "missing"), 8)
+	if err == nil {
+		t.Fatalf("TestLevelDBReadOnly: NewLevelDBReadOnly " +
+			"unexpectedly succeeded for a missing database")
+	}
+
+	// Put something into the db and close it
+	ldb, err := NewLevelDB(path, 8)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: NewLevelDB unexpectedly "+
+			"failed: %s", err)
+	}
+	key := database.MakeBucket(nil).Key([]byte("key"))
+	putData := []byte("Hello world!")
+	err = ldb.Put(key, putData)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: Put returned "+
+			"unexpected error: %s", err)
+	}
+	err = ldb.Close()
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: Close unexpectedly "+
+			"failed: %s", err)
+	}
+
+	// Reopen it read-only and make sure the data is there
+	ldb, err = NewLevelDBReadOnly(path, 8)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: NewLevelDBReadOnly unexpectedly "+
+			"failed: %s", err)
+	}
+	defer ldb.Close()
+
+	getData, err := ldb.Get(key)
+	if err != nil {
+		t.Fatalf("TestLevelDBReadOnly: Get returned "+
+			"unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(getData, putData) {
+		t.Fatalf("TestLevelDBReadOnly: get data and "+
+			"put data are not equal. Put: %s, got: %s",
+			string(putData), string(getData))
+	}
+
+	// Make sure that writing fails
+	err = ldb.Put(key, []byte("Goodbye world!"))
+	if err == nil {
+		t.Fatalf("TestLevelDBReadOnly: Put unexpectedly " +
+			"succeeded on a read-only database")
+	}
+	err = ldb.Delete(key)
+	if err == nil {
+		t.Fatalf("TestLevelDBReadOnly: Delete unexpectedly " +
+			"succeeded on a read-only database")
+	}
+}
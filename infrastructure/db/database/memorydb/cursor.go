@@ -0,0 +1,160 @@
+package memorydb
+
+import (
+	"bytes"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// MemoryDBCursor iterates over the entries of a MemoryDB in a given bucket.
+//
+// The cursor doesn't see a snapshot of the database: it remembers the key it's on and looks up
+// the next key on every move, so entries that are added or deleted while iterating may or may not
+// be visited.
+type MemoryDBCursor struct {
+	db     *MemoryDB
+	bucket *database.Bucket
+	prefix []byte
+
+	isStarted bool
+	key       []byte
+	value     []byte
+
+	isClosed bool
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (db *MemoryDB) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.WithStack(errClosed)
+	}
+	return &MemoryDBCursor{
+		db:     db,
+		bucket: bucket,
+		prefix: bucket.Path(),
+	}, nil
+}
+
+// setPosition moves the cursor to the given node, or marks it as exhausted if the node is nil
+// or out of the bucket of the cursor. It returns whether the cursor is on an entry.
+func (c *MemoryDBCursor) setPosition(node *skipListNode) bool {
+	c.isStarted = true
+	if node == nil || !bytes.HasPrefix(node.key, c.prefix) {
+		c.key = nil
+		c.value = nil
+		return false
+	}
+	c.key = node.key
+	c.value = node.value
+	return true
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted. Panics if the cursor is closed.
+func (c *MemoryDBCursor) Next() bool {
+	if c.isClosed {
+		panic("cannot call next on a closed cursor")
+	}
+	if c.isStarted && c.key == nil {
+		return false
+	}
+
+	c.db.lock.RLock()
+	defer c.db.lock.RUnlock()
+
+	if c.db.isClosed {
+		return c.setPosition(nil)
+	}
+	if !c.isStarted {
+		return c.setPosition(c.db.entries.findGreaterOrEqual(c.prefix, nil))
+	}
+	return c.setPosition(c.db.entries.firstGreaterThan(c.key))
+}
+
+// First moves the iterator to the first key/value pair. It returns false if
+// such a pair does not exist. Panics if the cursor is closed.
+func (c *MemoryDBCursor) First() bool {
+	if c.isClosed {
+		panic("cannot call first on a closed cursor")
+	}
+
+	c.db.lock.RLock()
+	defer c.db.lock.RUnlock()
+
+	if c.db.isClosed {
+		return c.setPosition(nil)
+	}
+	return c.setPosition(c.db.entries.findGreaterOrEqual(c.prefix, nil))
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key. It returns ErrNotFound if such pair does not
+// exist.
+func (c *MemoryDBCursor) Seek(key *database.Key) error {
+	if c.isClosed {
+		return errors.New("cannot seek a closed cursor")
+	}
+
+	c.db.lock.RLock()
+	defer c.db.lock.RUnlock()
+
+	if c.db.isClosed {
+		return errors.WithStack(errClosed)
+	}
+	seekKey := key.Bytes()
+	if bytes.Compare(seekKey, c.prefix) < 0 {
+		seekKey = c.prefix
+	}
+	found := c.setPosition(c.db.entries.findGreaterOrEqual(seekKey, nil))
+	if !found || !bytes.Equal(c.key, key.Bytes()) {
+		return errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return nil
+}
+
+// Key returns the key of the current key/value pair, or ErrNotFound if done.
+// Note that the key is trimmed to not include the prefix the cursor was opened
+// with. The caller should not modify the contents of the returned slice, and
+// its contents may change on the next call to Next.
+func (c *MemoryDBCursor) Key() (*database.Key, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the key of a closed cursor")
+	}
+	if c.key == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"key of an exhausted cursor")
+	}
+	suffix := bytes.TrimPrefix(c.key, c.prefix)
+	return c.bucket.Key(suffix), nil
+}
+
+// Value returns the value of the current key/value pair, or ErrNotFound if done.
+// The caller should not modify the contents of the returned slice, and its
+// contents may change on the next call to Next.
+func (c *MemoryDBCursor) Value() ([]byte, error) {
+	if c.isClosed {
+		return nil, errors.New("cannot get the value of a closed cursor")
+	}
+	if c.key == nil {
+		return nil, errors.Wrapf(database.ErrNotFound, "cannot get the "+
+			"value of an exhausted cursor")
+	}
+	return c.value, nil
+}
+
+// Close releases associated resources.
+func (c *MemoryDBCursor) Close() error {
+	if c.isClosed {
+		return errors.New("cannot close an already closed cursor")
+	}
+	c.isClosed = true
+	c.db = nil
+	c.bucket = nil
+	c.key = nil
+	c.value = nil
+	return nil
+}
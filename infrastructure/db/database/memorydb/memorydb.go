@@ -0,0 +1,107 @@
+package memorydb
+
+import (
+	"sync"
+
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// errClosed is returned when the database is used after it's closed
+var errClosed = errors.New("database is closed")
+
+// MemoryDB is a database that keeps all its data in memory, ordered by key, and
+// loses it when it's closed. It's meant for ephemeral nodes and tests.
+type MemoryDB struct {
+	lock     sync.RWMutex
+	entries  *skipList
+	isClosed bool
+}
+
+// NewMemoryDB returns a new empty in-memory database.
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{
+		entries: newSkipList(),
+	}
+}
+
+// Compact does nothing, since there's nothing to compact in memory.
+func (db *MemoryDB) Compact() error {
+	return nil
+}
+
+// Close closes the database and drops all its data.
+func (db *MemoryDB) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.WithStack(errClosed)
+	}
+	db.isClosed = true
+	db.entries = nil
+	return nil
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (db *MemoryDB) Put(key *database.Key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.WithStack(errClosed)
+	}
+	db.entries.put(copyBytes(key.Bytes()), copyBytes(value))
+	return nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (db *MemoryDB) Get(key *database.Key) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return nil, errors.WithStack(errClosed)
+	}
+	value, ok := db.entries.get(key.Bytes())
+	if !ok {
+		return nil, errors.Wrapf(database.ErrNotFound, "key %s not found", key)
+	}
+	return copyBytes(value), nil
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (db *MemoryDB) Has(key *database.Key) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.isClosed {
+		return false, errors.WithStack(errClosed)
+	}
+	_, ok := db.entries.get(key.Bytes())
+	return ok, nil
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (db *MemoryDB) Delete(key *database.Key) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.isClosed {
+		return errors.WithStack(errClosed)
+	}
+	db.entries.delete(key.Bytes())
+	return nil
+}
+
+// copyBytes returns a copy of the given slice, so that the data stored in the database
+// can't be modified through the slices passed to it or returned from it
+func copyBytes(data []byte) []byte {
+	dataCopy := make([]byte, len(data))
+	copy(dataCopy, data)
+	return dataCopy
+}
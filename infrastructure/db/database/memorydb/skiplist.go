@@ -0,0 +1,122 @@
+package memorydb
+
+import (
+	"bytes"
+	"math/rand"
+)
+
+const (
+	// skipListMaxLevel allows for efficient lookups in up to 4^skipListMaxLevel entries
+	skipListMaxLevel = 16
+
+	// skipListBranching is the inverse of the probability that a node has another level
+	skipListBranching = 4
+)
+
+type skipListNode struct {
+	key   []byte
+	value []byte
+	next  []*skipListNode
+}
+
+// skipList is a set of key/value pairs ordered by key. It is not safe for concurrent use.
+type skipList struct {
+	head   *skipListNode
+	level  int
+	length int
+	random *rand.Rand
+}
+
+func newSkipList() *skipList {
+	return &skipList{
+		head:   &skipListNode{next: make([]*skipListNode, skipListMaxLevel)},
+		level:  1,
+		random: rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+func (s *skipList) randomLevel() int {
+	level := 1
+	for level < skipListMaxLevel && s.random.Intn(skipListBranching) == 0 {
+		level++
+	}
+	return level
+}
+
+// findGreaterOrEqual returns the first node whose key is greater than or equal to the given key,
+// or nil if there's none. If previous is not nil, it's filled with the last node before the
+// returned one in every level.
+func (s *skipList) findGreaterOrEqual(key []byte, previous []*skipListNode) *skipListNode {
+	node := s.head
+	for level := s.level - 1; level >= 0; level-- {
+		for node.next[level] != nil && bytes.Compare(node.next[level].key, key) < 0 {
+			node = node.next[level]
+		}
+		if previous != nil {
+			previous[level] = node
+		}
+	}
+	return node.next[0]
+}
+
+// get returns the value of the given key
+func (s *skipList) get(key []byte) ([]byte, bool) {
+	node := s.findGreaterOrEqual(key, nil)
+	if node == nil || !bytes.Equal(node.key, key) {
+		return nil, false
+	}
+	return node.value, true
+}
+
+// put sets the value of the given key. The skip list keeps the given slices, so the caller must
+// not modify them afterwards.
+func (s *skipList) put(key []byte, value []byte) {
+	previous := make([]*skipListNode, skipListMaxLevel)
+	node := s.findGreaterOrEqual(key, previous)
+	if node != nil && bytes.Equal(node.key, key) {
+		node.value = value
+		return
+	}
+
+	level := s.randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			previous[i] = s.head
+		}
+		s.level = level
+	}
+	node = &skipListNode{key: key, value: value, next: make([]*skipListNode, level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = previous[i].next[i]
+		previous[i].next[i] = node
+	}
+	s.length++
+}
+
+// delete removes the given key, and returns false if it didn't exist
+func (s *skipList) delete(key []byte) bool {
+	previous := make([]*skipListNode, skipListMaxLevel)
+	node := s.findGreaterOrEqual(key, previous)
+	if node == nil || !bytes.Equal(node.key, key) {
+		return false
+	}
+
+	for i := range node.next {
+		previous[i].next[i] = node.next[i]
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.length--
+	return true
+}
+
+// firstGreaterThan returns the first node whose key is greater than the given key, or nil if
+// there's none
+func (s *skipList) firstGreaterThan(key []byte) *skipListNode {
+	node := s.findGreaterOrEqual(key, nil)
+	if node != nil && bytes.Equal(node.key, key) {
+		node = node.next[0]
+	}
+	return node
+}
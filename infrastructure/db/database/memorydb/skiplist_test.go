@@ -0,0 +1,71 @@
+package memorydb
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSkipList(t *testing.T) {
+	list := newSkipList()
+	expected := make(map[string][]byte)
+	random := rand.New(rand.NewSource(0))
+
+	for i := 0; i < 20000; i++ {
+		key := []byte(fmt.Sprintf("key%d", random.Intn(2000)))
+		switch random.Intn(3) {
+		case 0, 1:
+			value := []byte(fmt.Sprintf("value%d", i))
+			list.put(key, value)
+			expected[string(key)] = value
+		case 2:
+			_, existed := expected[string(key)]
+			if list.delete(key) != existed {
+				t.Fatalf("delete of %s returned %t, expected %t", key, !existed, existed)
+			}
+			delete(expected, string(key))
+		}
+	}
+
+	if list.length != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), list.length)
+	}
+	expectedKeys := make([]string, 0, len(expected))
+	for key := range expected {
+		expectedKeys = append(expectedKeys, key)
+	}
+	sort.Strings(expectedKeys)
+
+	node := list.head.next[0]
+	for _, key := range expectedKeys {
+		if node == nil {
+			t.Fatalf("The list ended before %s", key)
+		}
+		if string(node.key) != key || !bytes.Equal(node.value, expected[key]) {
+			t.Fatalf("Expected %s=%s, got %s=%s", key, expected[key], node.key, node.value)
+		}
+		value, ok := list.get([]byte(key))
+		if !ok || !bytes.Equal(value, expected[key]) {
+			t.Fatalf("get(%s) returned %s, %t", key, value, ok)
+		}
+		node = node.next[0]
+	}
+	if node != nil {
+		t.Fatalf("Unexpected entry %s after the last key", node.key)
+	}
+
+	for i, key := range expectedKeys {
+		next := list.firstGreaterThan([]byte(key))
+		if i == len(expectedKeys)-1 {
+			if next != nil {
+				t.Fatalf("Expected nothing after %s, got %s", key, next.key)
+			}
+			continue
+		}
+		if next == nil || string(next.key) != expectedKeys[i+1] {
+			t.Fatalf("Expected %s after %s", expectedKeys[i+1], key)
+		}
+	}
+}
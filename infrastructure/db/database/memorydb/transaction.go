@@ -0,0 +1,131 @@
+package memorydb
+
+import (
+	"github.com/kaspanet/kaspad/infrastructure/db/database"
+	"github.com/pkg/errors"
+)
+
+// MemoryDBTransaction is a batch of changes to a MemoryDB that are applied
+// atomically on commit.
+//
+// Note that reads are done from the Database directly, so if another transaction changed the data,
+// you will read the new data, and not the one from the time the transaction was opened.
+//
+// Note: As it's currently implemented, if one puts data into the transaction
+// then it will not be available to get within the same transaction.
+type MemoryDBTransaction struct {
+	db       *MemoryDB
+	batch    []batchOperation
+	isClosed bool
+}
+
+// batchOperation is a put, or a delete if value is nil
+type batchOperation struct {
+	key   []byte
+	value []byte
+}
+
+// Begin begins a new transaction.
+func (db *MemoryDB) Begin() (database.Transaction, error) {
+	transaction := &MemoryDBTransaction{
+		db:       db,
+		isClosed: false,
+	}
+	return transaction, nil
+}
+
+// Commit commits whatever changes were made to the database
+// within this transaction.
+func (tx *MemoryDBTransaction) Commit() error {
+	if tx.isClosed {
+		return errors.New("cannot commit a closed transaction")
+	}
+	tx.isClosed = true
+
+	tx.db.lock.Lock()
+	defer tx.db.lock.Unlock()
+
+	if tx.db.isClosed {
+		return errors.WithStack(errClosed)
+	}
+	for _, operation := range tx.batch {
+		if operation.value == nil {
+			tx.db.entries.delete(operation.key)
+		} else {
+			tx.db.entries.put(operation.key, operation.value)
+		}
+	}
+	tx.batch = nil
+	return nil
+}
+
+// Rollback rolls back whatever changes were made to the
+// database within this transaction.
+func (tx *MemoryDBTransaction) Rollback() error {
+	if tx.isClosed {
+		return errors.New("cannot rollback a closed transaction")
+	}
+
+	tx.isClosed = true
+	tx.batch = nil
+	return nil
+}
+
+// RollbackUnlessClosed rolls back changes that were made to
+// the database within the transaction, unless the transaction
+// had already been closed using either Rollback or Commit.
+func (tx *MemoryDBTransaction) RollbackUnlessClosed() error {
+	if tx.isClosed {
+		return nil
+	}
+	return tx.Rollback()
+}
+
+// Put sets the value for the given key. It overwrites
+// any previous value for that key.
+func (tx *MemoryDBTransaction) Put(key *database.Key, value []byte) error {
+	if tx.isClosed {
+		return errors.New("cannot put into a closed transaction")
+	}
+
+	tx.batch = append(tx.batch, batchOperation{key: copyBytes(key.Bytes()), value: copyBytes(value)})
+	return nil
+}
+
+// Get gets the value for the given key. It returns
+// ErrNotFound if the given key does not exist.
+func (tx *MemoryDBTransaction) Get(key *database.Key) ([]byte, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot get from a closed transaction")
+	}
+	return tx.db.Get(key)
+}
+
+// Has returns true if the database does contains the
+// given key.
+func (tx *MemoryDBTransaction) Has(key *database.Key) (bool, error) {
+	if tx.isClosed {
+		return false, errors.New("cannot has from a closed transaction")
+	}
+	return tx.db.Has(key)
+}
+
+// Delete deletes the value for the given key. Will not
+// return an error if the key doesn't exist.
+func (tx *MemoryDBTransaction) Delete(key *database.Key) error {
+	if tx.isClosed {
+		return errors.New("cannot delete from a closed transaction")
+	}
+
+	tx.batch = append(tx.batch, batchOperation{key: copyBytes(key.Bytes())})
+	return nil
+}
+
+// Cursor begins a new cursor over the given bucket.
+func (tx *MemoryDBTransaction) Cursor(bucket *database.Bucket) (database.Cursor, error) {
+	if tx.isClosed {
+		return nil, errors.New("cannot open a cursor from a closed transaction")
+	}
+
+	return tx.db.Cursor(bucket)
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// levelDBCollector exposes the internal statistics of a LevelDB instance
+type levelDBCollector struct {
+	stats func() (*leveldb.DBStats, error)
+
+	ioRead             *prometheus.Desc
+	ioWrite            *prometheus.Desc
+	blockCacheSize     *prometheus.Desc
+	openedTables       *prometheus.Desc
+	aliveSnapshots     *prometheus.Desc
+	aliveIterators     *prometheus.Desc
+	writeDelayCount    *prometheus.Desc
+	writeDelayDuration *prometheus.Desc
+	writePaused        *prometheus.Desc
+	levelSize          *prometheus.Desc
+	levelTables        *prometheus.Desc
+	compactions        *prometheus.Desc
+}
+
+// RegisterLevelDBCollector registers a collector that exposes the statistics
+// returned by the given function
+func RegisterLevelDBCollector(stats func() (*leveldb.DBStats, error)) {
+	RegisterCollector(newLevelDBCollector(stats))
+}
+
+func newLevelDBCollector(stats func() (*leveldb.DBStats, error)) *levelDBCollector {
+	desc := func(name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "leveldb", name), help, labels, nil)
+	}
+	return &levelDBCollector{
+		stats: stats,
+
+		ioRead:             desc("io_read_bytes_total", "Number of bytes read from disk"),
+		ioWrite:            desc("io_write_bytes_total", "Number of bytes written to disk"),
+		blockCacheSize:     desc("block_cache_bytes", "Size of the block cache"),
+		openedTables:       desc("opened_tables", "Number of currently opened tables"),
+		aliveSnapshots:     desc("alive_snapshots", "Number of currently alive snapshots"),
+		aliveIterators:     desc("alive_iterators", "Number of currently alive iterators"),
+		writeDelayCount:    desc("write_delays_total", "Number of times writes were delayed due to compaction"),
+		writeDelayDuration: desc("write_delay_seconds_total", "Total time writes were delayed due to compaction"),
+		writePaused:        desc("write_paused", "Whether writes are currently paused due to compaction (1) or not (0)"),
+		levelSize:          desc("level_size_bytes", "Size of each level", "level"),
+		levelTables:        desc("level_tables", "Number of tables in each level", "level"),
+		compactions:        desc("compactions_total", "Number of compactions, by type", "type"),
+	}
+}
+
+func (c *levelDBCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ioRead
+	ch <- c.ioWrite
+	ch <- c.blockCacheSize
+	ch <- c.openedTables
+	ch <- c.aliveSnapshots
+	ch <- c.aliveIterators
+	ch <- c.writeDelayCount
+	ch <- c.writeDelayDuration
+	ch <- c.writePaused
+	ch <- c.levelSize
+	ch <- c.levelTables
+	ch <- c.compactions
+}
+
+func (c *levelDBCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.stats()
+	if err != nil {
+		log.Warnf("Could not collect LevelDB stats: %s", err)
+		return
+	}
+
+	writePaused := 0.0
+	if stats.WritePaused {
+		writePaused = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.ioRead, prometheus.CounterValue, float64(stats.IORead))
+	ch <- prometheus.MustNewConstMetric(c.ioWrite, prometheus.CounterValue, float64(stats.IOWrite))
+	ch <- prometheus.MustNewConstMetric(c.blockCacheSize, prometheus.GaugeValue, float64(stats.BlockCacheSize))
+	ch <- prometheus.MustNewConstMetric(c.openedTables, prometheus.GaugeValue, float64(stats.OpenedTablesCount))
+	ch <- prometheus.MustNewConstMetric(c.aliveSnapshots, prometheus.GaugeValue, float64(stats.AliveSnapshots))
+	ch <- prometheus.MustNewConstMetric(c.aliveIterators, prometheus.GaugeValue, float64(stats.AliveIterators))
+	ch <- prometheus.MustNewConstMetric(c.writeDelayCount, prometheus.CounterValue, float64(stats.WriteDelayCount))
+	ch <- prometheus.MustNewConstMetric(c.writeDelayDuration, prometheus.CounterValue, stats.WriteDelayDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.writePaused, prometheus.GaugeValue, writePaused)
+	for level, size := range stats.LevelSizes {
+		ch <- prometheus.MustNewConstMetric(c.levelSize, prometheus.GaugeValue, float64(size), strconv.Itoa(level))
+	}
+	for level, tables := range stats.LevelTablesCounts {
+		ch <- prometheus.MustNewConstMetric(c.levelTables, prometheus.GaugeValue, float64(tables), strconv.Itoa(level))
+	}
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(stats.MemComp), "memory")
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(stats.Level0Comp), "level0")
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(stats.NonLevel0Comp), "nonlevel0")
+	ch <- prometheus.MustNewConstMetric(c.compactions, prometheus.CounterValue, float64(stats.SeekComp), "seek")
+}
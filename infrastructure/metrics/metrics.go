@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "kaspad"
+
+// registry holds all the metrics exposed by kaspad. A dedicated registry is
+// used instead of the global default one so that only the metrics defined here
+// (plus the standard Go runtime and process metrics) are exposed.
+var registry = prometheus.NewRegistry()
+
+var (
+	processedBlocks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "processed_blocks_total",
+		Help:      "Number of blocks processed by consensus",
+	})
+	processedHeaders = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "processed_headers_total",
+		Help:      "Number of headers-only blocks processed by consensus",
+	})
+	processedTransactions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "consensus",
+		Name:      "processed_transactions_total",
+		Help:      "Number of transactions contained in blocks processed by consensus",
+	})
+
+	ibdRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ibd",
+		Name:      "running",
+		Help:      "Whether IBD is currently running (1) or not (0)",
+	})
+	ibdProgress = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ibd",
+		Name:      "progress_percent",
+		Help:      "Progress of the current IBD phase, by the type of object being synced",
+	}, []string{"object"})
+
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "requests_total",
+		Help:      "Number of handled RPC requests, by command and by whether they failed",
+	}, []string{"command", "error"})
+	rpcRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "rpc",
+		Name:      "request_duration_seconds",
+		Help:      "Time it took to handle RPC requests, by command and by whether they failed",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"command", "error"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: namespace}),
+		processedBlocks,
+		processedHeaders,
+		processedTransactions,
+		ibdRunning,
+		ibdProgress,
+		rpcRequests,
+		rpcRequestDuration,
+	)
+}
+
+// RegisterGaugeFunc registers a gauge whose value is obtained by calling the
+// given function whenever metrics are collected
+func RegisterGaugeFunc(subsystem string, name string, help string, function func() float64) {
+	RegisterCollector(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, function))
+}
+
+// RegisterCollector registers a custom collector, for metrics that cannot be
+// expressed through RegisterGaugeFunc.
+// If an identical collector is already registered, it is replaced by the given one.
+func RegisterCollector(collector prometheus.Collector) {
+	err := registry.Register(collector)
+	if err == nil {
+		return
+	}
+
+	alreadyRegisteredError := prometheus.AlreadyRegisteredError{}
+	if !errors.As(err, &alreadyRegisteredError) {
+		panic(err)
+	}
+	registry.Unregister(alreadyRegisteredError.ExistingCollector)
+	registry.MustRegister(collector)
+}
+
+// AddProcessedBlock records a block processed by consensus
+func AddProcessedBlock(isHeaderOnly bool, transactionCount int) {
+	if isHeaderOnly {
+		processedHeaders.Inc()
+	} else {
+		processedBlocks.Inc()
+	}
+	processedTransactions.Add(float64(transactionCount))
+}
+
+// SetIBDRunning records whether IBD is currently running
+func SetIBDRunning(isRunning bool) {
+	if isRunning {
+		ibdRunning.Set(1)
+		return
+	}
+	ibdRunning.Set(0)
+	ibdProgress.Reset()
+}
+
+// SetIBDProgress records the progress of the current IBD phase
+func SetIBDProgress(objectName string, progressPercent int) {
+	ibdProgress.WithLabelValues(objectName).Set(float64(progressPercent))
+}
+
+// ObserveRPCRequest records an RPC request of the given command, along with
+// the time it took to handle it and whether it failed
+func ObserveRPCRequest(command string, duration time.Duration, isError bool) {
+	errorLabel := strconv.FormatBool(isError)
+	rpcRequests.WithLabelValues(command, errorLabel).Inc()
+	rpcRequestDuration.WithLabelValues(command, errorLabel).Observe(duration.Seconds())
+}
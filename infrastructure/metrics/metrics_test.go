@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func gatherValue(t *testing.T, name string) (float64, bool) {
+	metricFamilies, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %s", err)
+	}
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() != name {
+			continue
+		}
+		metric := metricFamily.GetMetric()[0]
+		if metric.GetGauge() != nil {
+			return metric.GetGauge().GetValue(), true
+		}
+		return metric.GetCounter().GetValue(), true
+	}
+	return 0, false
+}
+
+func TestRegisterGaugeFuncReplacesExisting(t *testing.T) {
+	RegisterGaugeFunc("test", "gauge", "A test gauge", func() float64 { return 1 })
+	RegisterGaugeFunc("test", "gauge", "A test gauge", func() float64 { return 2 })
+
+	value, ok := gatherValue(t, "kaspad_test_gauge")
+	if !ok {
+		t.Fatalf("kaspad_test_gauge was not gathered")
+	}
+	if value != 2 {
+		t.Fatalf("Expected the gauge to be replaced and have value 2, but got %f", value)
+	}
+}
+
+func TestLevelDBCollector(t *testing.T) {
+	RegisterLevelDBCollector(func() (*leveldb.DBStats, error) {
+		return &leveldb.DBStats{
+			IORead:            100,
+			LevelSizes:        leveldb.Sizes{10, 20},
+			LevelTablesCounts: []int{1, 2},
+		}, nil
+	})
+
+	value, ok := gatherValue(t, "kaspad_leveldb_io_read_bytes_total")
+	if !ok {
+		t.Fatalf("kaspad_leveldb_io_read_bytes_total was not gathered")
+	}
+	if value != 100 {
+		t.Fatalf("Expected IO read of 100, but got %f", value)
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Start starts an HTTP server exposing all the registered metrics
+// at /metrics in the Prometheus exposition format
+func Start(listenAddr string) {
+	spawn("metrics.Start", func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+		log.Infof("Metrics server listening on %s", listenAddr)
+		err := http.ListenAndServe(listenAddr, mux)
+		if err != nil {
+			log.Errorf("Metrics server stopped: %s", err)
+		}
+	})
+}
@@ -46,6 +46,9 @@ func (i ipv6) equal(other ipv6) bool {
 // given address is not found in the address manager
 var ErrAddressNotFound = errors.New("address not found")
 
+// ErrAddressWhitelisted is the error returned when trying to ban a whitelisted address
+var ErrAddressWhitelisted = errors.New("address is whitelisted")
+
 // NetAddressKey returns a key of the ip address to use it in maps.
 func netAddressKey(netAddress *appmessage.NetAddress) addressKey {
 	key := addressKey{port: netAddress.Port}
@@ -223,8 +226,33 @@ func (am *AddressManager) BestLocalAddress(remoteAddress *appmessage.NetAddress)
 	return am.localAddresses.bestLocalAddress(remoteAddress)
 }
 
+// AddLocalAddress adds an address of this node, such as one discovered by NAT traversal,
+// to the addresses that are advertised to peers with the given priority
+func (am *AddressManager) AddLocalAddress(address *appmessage.NetAddress, priority AddressPriority) error {
+	return am.localAddresses.addLocalNetAddress(address, priority)
+}
+
+// RemoveLocalAddress stops advertising the given address of this node to peers
+func (am *AddressManager) RemoveLocalAddress(address *appmessage.NetAddress) {
+	am.localAddresses.removeLocalNetAddress(address)
+}
+
+// IsWhitelisted returns whether the given address is in one of the whitelisted networks
+func (am *AddressManager) IsWhitelisted(address *appmessage.NetAddress) bool {
+	for _, whitelist := range am.cfg.Whitelists {
+		if whitelist.Contains(address.IP) {
+			return true
+		}
+	}
+	return false
+}
+
 // Ban marks the given address as banned
 func (am *AddressManager) Ban(addressToBan *appmessage.NetAddress) error {
+	if am.IsWhitelisted(addressToBan) {
+		return errors.Wrapf(ErrAddressWhitelisted, "cannot ban %s", addressToBan.IP)
+	}
+
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
@@ -261,8 +289,13 @@ func (am *AddressManager) Unban(address *appmessage.NetAddress) error {
 	return am.store.removeBanned(key)
 }
 
-// IsBanned returns true if the given address is marked as banned
+// IsBanned returns true if the given address is marked as banned.
+// Whitelisted addresses are never considered banned
 func (am *AddressManager) IsBanned(address *appmessage.NetAddress) (bool, error) {
+	if am.IsWhitelisted(address) {
+		return false, nil
+	}
+
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
@@ -13,6 +13,7 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/config"
 	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
 	"github.com/kaspanet/kaspad/util/mstime"
+	"github.com/pkg/errors"
 )
 
 func newAddressManagerForTest(t *testing.T, testName string) (addressManager *AddressManager, teardown func()) {
@@ -372,3 +373,52 @@ func TestOverfillAddressManager(t *testing.T) {
 		}
 	}
 }
+
+func TestWhitelistedAddresses(t *testing.T) {
+	addressManager, teardown := newAddressManagerForTest(t, "TestWhitelistedAddresses")
+	defer teardown()
+
+	_, whitelist, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %s", err)
+	}
+	addressManager.cfg.Whitelists = []*net.IPNet{whitelist}
+
+	whitelistedAddress := &appmessage.NetAddress{IP: net.ParseIP("10.1.2.3"), Timestamp: mstime.Now()}
+	otherAddress := &appmessage.NetAddress{IP: net.ParseIP("1.2.3.4"), Timestamp: mstime.Now()}
+	err = addressManager.AddAddresses(whitelistedAddress, otherAddress)
+	if err != nil {
+		t.Fatalf("AddAddresses() failed: %s", err)
+	}
+
+	if !addressManager.IsWhitelisted(whitelistedAddress) {
+		t.Fatalf("Expected %s to be whitelisted", whitelistedAddress.IP)
+	}
+	if addressManager.IsWhitelisted(otherAddress) {
+		t.Fatalf("Expected %s to not be whitelisted", otherAddress.IP)
+	}
+
+	err = addressManager.Ban(whitelistedAddress)
+	if !errors.Is(err, ErrAddressWhitelisted) {
+		t.Fatalf("Expected banning a whitelisted address to fail with ErrAddressWhitelisted, got: %v", err)
+	}
+	isBanned, err := addressManager.IsBanned(whitelistedAddress)
+	if err != nil {
+		t.Fatalf("IsBanned() failed: %s", err)
+	}
+	if isBanned {
+		t.Fatalf("Expected %s to not be banned", whitelistedAddress.IP)
+	}
+
+	err = addressManager.Ban(otherAddress)
+	if err != nil {
+		t.Fatalf("Ban() failed: %s", err)
+	}
+	isBanned, err = addressManager.IsBanned(otherAddress)
+	if err != nil {
+		t.Fatalf("IsBanned() failed: %s", err)
+	}
+	if !isBanned {
+		t.Fatalf("Expected %s to be banned", otherAddress.IP)
+	}
+}
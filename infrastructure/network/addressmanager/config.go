@@ -13,6 +13,13 @@ type Config struct {
 	ExternalIPs      []string
 	Listeners        []string
 	Lookup           func(string) ([]net.IP, error)
+
+	// Proxy is the address of the proxy outgoing connections go through, if any.
+	// Behind a proxy, only the addresses given in ExternalIPs are advertised
+	Proxy string
+
+	// Whitelists are the networks whose addresses are never banned
+	Whitelists []*net.IPNet
 }
 
 // NewConfig returns a new address manager Config.
@@ -23,5 +30,7 @@ func NewConfig(cfg *config.Config) *Config {
 		ExternalIPs:      cfg.ExternalIPs,
 		Listeners:        cfg.Listeners,
 		Lookup:           cfg.Lookup,
+		Proxy:            cfg.Proxy,
+		Whitelists:       cfg.Whitelists,
 	}
 }
@@ -84,6 +84,14 @@ func (lam *localAddressManager) addLocalNetAddress(netAddress *appmessage.NetAdd
 	return nil
 }
 
+// removeLocalNetAddress removes netAddress from the list of known local addresses to advertise
+func (lam *localAddressManager) removeLocalNetAddress(netAddress *appmessage.NetAddress) {
+	lam.mutex.Lock()
+	defer lam.mutex.Unlock()
+
+	delete(lam.localAddresses, netAddressKey(netAddress))
+}
+
 // bestLocalAddress returns the most appropriate local address to use
 // for the given remote address.
 func (lam *localAddressManager) bestLocalAddress(remoteAddress *appmessage.NetAddress) *appmessage.NetAddress {
@@ -164,7 +172,8 @@ func (lam *localAddressManager) addLocalAddress(addr string) error {
 }
 
 // initListeners initializes the configured net listeners and adds any bound
-// addresses to the address manager
+// addresses to the address manager. Behind a proxy, bound addresses are not
+// added, so that the node's real address doesn't leak to its peers
 func (lam *localAddressManager) initListeners() error {
 	if len(lam.cfg.ExternalIPs) != 0 {
 		defaultPort, err := strconv.ParseUint(lam.cfg.DefaultPort, 10, 16)
@@ -200,7 +209,7 @@ func (lam *localAddressManager) initListeners() error {
 				log.Warnf("Skipping specified external IP: %s", err)
 			}
 		}
-	} else {
+	} else if lam.cfg.Proxy == "" {
 		// Listen for TCP connections at the configured addresses
 		netAddrs, err := parseListeners(lam.cfg.Listeners)
 		if err != nil {
@@ -0,0 +1,85 @@
+package connmanager
+
+import (
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// banScoreHalfLife is the time it takes a ban score to decay to half its value
+const banScoreHalfLife = 10 * time.Minute
+
+// banScore is the misbehavior score of a single IP, as of lastUpdate
+type banScore struct {
+	value      float64
+	lastUpdate time.Time
+}
+
+func (s *banScore) decayedValue(now time.Time) float64 {
+	elapsed := now.Sub(s.lastUpdate)
+	if elapsed <= 0 {
+		return s.value
+	}
+	return s.value * math.Exp2(-float64(elapsed)/float64(banScoreHalfLife))
+}
+
+// banScores keeps the decaying ban scores of misbehaving peers, by IP
+type banScores struct {
+	scores map[string]*banScore
+	lock   sync.Mutex
+}
+
+func newBanScores() *banScores {
+	return &banScores{
+		scores: make(map[string]*banScore),
+	}
+}
+
+// add adds the given score to the ban score of the given IP, and
+// returns its new ban score
+func (b *banScores) add(ip net.IP, score uint32, now time.Time) uint32 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.prune(now)
+
+	key := ip.String()
+	value := float64(score)
+	if existing, ok := b.scores[key]; ok {
+		value += existing.decayedValue(now)
+	}
+	b.scores[key] = &banScore{value: value, lastUpdate: now}
+
+	return uint32(value)
+}
+
+// get returns the current ban score of the given IP
+func (b *banScores) get(ip net.IP, now time.Time) uint32 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	score, ok := b.scores[ip.String()]
+	if !ok {
+		return 0
+	}
+	return uint32(score.decayedValue(now))
+}
+
+// reset removes the ban score of the given IP
+func (b *banScores) reset(ip net.IP) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.scores, ip.String())
+}
+
+// prune removes the scores that have decayed to zero.
+// This function is not thread safe
+func (b *banScores) prune(now time.Time) {
+	for key, score := range b.scores {
+		if score.decayedValue(now) < 1 {
+			delete(b.scores, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package connmanager
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBanScores(t *testing.T) {
+	scores := newBanScores()
+	ip := net.ParseIP("1.2.3.4")
+	otherIP := net.ParseIP("5.6.7.8")
+	now := time.Unix(1600000000, 0)
+
+	if score := scores.add(ip, 40, now); score != 40 {
+		t.Fatalf("expected a ban score of 40, got %d", score)
+	}
+	if score := scores.add(ip, 40, now); score != 80 {
+		t.Fatalf("expected a ban score of 80, got %d", score)
+	}
+	if score := scores.get(otherIP, now); score != 0 {
+		t.Fatalf("expected a ban score of 0 for an unknown IP, got %d", score)
+	}
+
+	// The score decays to half its value after banScoreHalfLife
+	now = now.Add(banScoreHalfLife)
+	if score := scores.get(ip, now); score != 40 {
+		t.Fatalf("expected a decayed ban score of 40, got %d", score)
+	}
+	if score := scores.add(ip, 10, now); score != 50 {
+		t.Fatalf("expected a ban score of 50, got %d", score)
+	}
+
+	// Scores that decay to zero are pruned
+	now = now.Add(10 * banScoreHalfLife)
+	scores.add(otherIP, 10, now)
+	if _, ok := scores.scores[ip.String()]; ok {
+		t.Fatalf("expected the decayed ban score to be pruned")
+	}
+
+	scores.reset(otherIP)
+	if score := scores.get(otherIP, now); score != 0 {
+		t.Fatalf("expected a ban score of 0 after reset, got %d", score)
+	}
+}
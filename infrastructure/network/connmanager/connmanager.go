@@ -39,6 +39,8 @@ type ConnectionManager struct {
 	activeIncoming   map[string]struct{}
 	maxIncoming      int
 
+	banScores *banScores
+
 	stop                   uint32
 	connectionRequestsLock sync.RWMutex
 
@@ -56,6 +58,7 @@ func New(cfg *config.Config, netAdapter *netadapter.NetAdapter, addressManager *
 		pendingRequested: map[string]*connectionRequest{},
 		activeOutgoing:   map[string]struct{}{},
 		activeIncoming:   map[string]struct{}{},
+		banScores:        newBanScores(),
 		resetLoopChan:    make(chan struct{}),
 		loopTicker:       time.NewTicker(connectionsLoopInterval),
 	}
@@ -142,7 +145,23 @@ func (c *ConnectionManager) Ban(netConnection *netadapter.NetConnection) error {
 		return errors.Wrapf(ErrCannotBanPermanent, "Cannot ban %s because it's a permanent connection", netConnection.Address())
 	}
 
-	return c.addressManager.Ban(netConnection.NetAddress())
+	err := c.addressManager.Ban(netConnection.NetAddress())
+	if err != nil {
+		return err
+	}
+	c.banScores.reset(netConnection.NetAddress().IP)
+	return nil
+}
+
+// AddBanScore adds the given score to the ban score of the given netConnection's IP,
+// and returns its new ban score. Ban scores decay over time
+func (c *ConnectionManager) AddBanScore(netConnection *netadapter.NetConnection, score uint32) uint32 {
+	return c.banScores.add(netConnection.NetAddress().IP, score, time.Now())
+}
+
+// BanScore returns the current ban score of the given netConnection's IP
+func (c *ConnectionManager) BanScore(netConnection *netadapter.NetConnection) uint32 {
+	return c.banScores.get(netConnection.NetAddress().IP, time.Now())
 }
 
 // BanByIP bans the given IP and disconnects from all the connection with that IP.
@@ -166,6 +185,12 @@ func (c *ConnectionManager) BanByIP(ip net.IP) error {
 	return c.addressManager.Ban(appmessage.NewNetAddressIPPort(ip, 0))
 }
 
+// IsWhitelisted returns whether the given netConnection's IP is whitelisted.
+// Whitelisted peers are never banned and don't count towards the inbound peers limit
+func (c *ConnectionManager) IsWhitelisted(netConnection *netadapter.NetConnection) bool {
+	return c.addressManager.IsWhitelisted(netConnection.NetAddress())
+}
+
 // IsBanned returns whether the given netConnection is banned
 func (c *ConnectionManager) IsBanned(netConnection *netadapter.NetConnection) (bool, error) {
 	if c.isPermanent(netConnection.Address()) {
@@ -264,7 +289,7 @@ func (c *ConnectionManager) seedFromDNS() {
 				_ = c.addressManager.AddAddresses(addresses...)
 			})
 
-		dnsseed.SeedFromGRPC(cfg.NetParams(), cfg.GRPCSeed, false, nil,
+		dnsseed.SeedFromGRPC(cfg.NetParams(), cfg.GRPCSeed, false, nil, cfg.Dial,
 			func(addresses []*appmessage.NetAddress) {
 				_ = c.addressManager.AddAddresses(addresses...)
 			})
@@ -1,8 +1,15 @@
 package connmanager
 
 // checkIncomingConnections makes sure there's no more than maxIncoming incoming connections
-// if there are - it randomly disconnects enough to go below that number
+// if there are - it randomly disconnects enough to go below that number.
+// Whitelisted connections are neither counted nor disconnected
 func (c *ConnectionManager) checkIncomingConnections(incomingConnectionSet connectionSet) {
+	for address, connection := range incomingConnectionSet {
+		if c.IsWhitelisted(connection) {
+			delete(incomingConnectionSet, address)
+		}
+	}
+
 	if len(incomingConnectionSet) <= c.maxIncoming {
 		return
 	}
@@ -40,6 +40,9 @@ type OnSeed func(addrs []*appmessage.NetAddress)
 // LookupFunc is the signature of the DNS lookup function.
 type LookupFunc func(string) ([]net.IP, error)
 
+// DialFunc is the signature of the function used to connect to gRPC seeders.
+type DialFunc func(network string, address string, timeout time.Duration) (net.Conn, error)
+
 // SeedFromDNS uses DNS seeding to populate the address manager with peers.
 func SeedFromDNS(dagParams *dagconfig.Params, customSeed string, includeAllSubnetworks bool,
 	subnetworkID *externalapi.DomainSubnetworkID, lookupFn LookupFunc, seedFn OnSeed) {
@@ -96,7 +99,7 @@ func SeedFromDNS(dagParams *dagconfig.Params, customSeed string, includeAllSubne
 
 // SeedFromGRPC send gRPC request to get list of peers for a given host
 func SeedFromGRPC(dagParams *dagconfig.Params, customSeed string, includeAllSubnetworks bool,
-	subnetworkID *externalapi.DomainSubnetworkID, seedFn OnSeed) {
+	subnetworkID *externalapi.DomainSubnetworkID, dialFn DialFunc, seedFn OnSeed) {
 
 	var grpcSeeds []string
 	if customSeed != "" {
@@ -109,7 +112,14 @@ func SeedFromGRPC(dagParams *dagconfig.Params, customSeed string, includeAllSubn
 		spawn("SeedFromGRPC", func() {
 			randSource := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-			conn, err := grpc.Dial(host, grpc.WithInsecure())
+			conn, err := grpc.Dial(host, grpc.WithInsecure(),
+				grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+					timeout := time.Duration(0)
+					if deadline, ok := ctx.Deadline(); ok {
+						timeout = time.Until(deadline)
+					}
+					return dialFn("tcp", address, timeout)
+				}))
 			client := pb2.NewPeerServiceClient(conn)
 			if err != nil {
 				log.Warnf("Failed to connect to gRPC server: %s", host)
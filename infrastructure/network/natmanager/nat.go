@@ -0,0 +1,119 @@
+package natmanager
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NAT is a gateway that maps ports of its external address to ports of hosts behind it
+type NAT interface {
+	// Name returns the name of the protocol used to talk to the gateway
+	Name() string
+
+	// ExternalIP returns the external address of the gateway
+	ExternalIP() (net.IP, error)
+
+	// AddPortMapping maps a port of the external address of the gateway to the given internal
+	// port of this host for the given lifetime, and returns the mapped external port. The
+	// protocol is either "tcp" or "udp".
+	AddPortMapping(protocol string, internalPort, externalPort uint16, description string,
+		lifetime time.Duration) (uint16, error)
+
+	// DeletePortMapping removes a mapping that was added by AddPortMapping
+	DeletePortMapping(protocol string, internalPort, externalPort uint16) error
+}
+
+const (
+	defaultSSDPAddress = "239.255.255.250:1900"
+	discoveryTimeout   = 3 * time.Second
+)
+
+// Discover looks for a gateway that supports either UPnP IGD or NAT-PMP
+func Discover() (NAT, error) {
+	nat, upnpErr := discoverUPnP(defaultSSDPAddress, discoveryTimeout)
+	if upnpErr == nil {
+		return nat, nil
+	}
+	log.Debugf("UPnP discovery failed: %s", upnpErr)
+
+	for _, gateway := range gatewayCandidates() {
+		nat := newNATPMP(net.JoinHostPort(gateway.String(), natPMPPort))
+		_, err := nat.ExternalIP()
+		if err == nil {
+			return nat, nil
+		}
+		log.Debugf("NAT-PMP discovery with %s failed: %s", gateway, err)
+	}
+	return nil, errors.Errorf("no UPnP or NAT-PMP gateway found")
+}
+
+// gatewayCandidates returns the addresses that may be the default IPv4 gateway: the one in the
+// routing table where it's available, and otherwise the first address of each private network
+// this host is on, which is where most home routers are
+func gatewayCandidates() []net.IP {
+	gateway, err := defaultGatewayFromRoutingTable()
+	if err == nil {
+		return []net.IP{gateway}
+	}
+
+	interfaceAddresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var candidates []net.IP
+	for _, interfaceAddress := range interfaceAddresses {
+		ipNet, ok := interfaceAddress.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipNet.IP.To4()
+		if ip == nil || !ip.IsPrivate() {
+			continue
+		}
+		candidate := ip.Mask(ipNet.Mask)
+		candidate[3]++
+		if !candidate.Equal(ip) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// defaultGatewayFromRoutingTable reads the default IPv4 gateway from the routing table of Linux
+func defaultGatewayFromRoutingTable() (net.IP, error) {
+	file, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Each line is: interface, destination, gateway, flags, ... with the addresses in
+		// little-endian hex. The default route is the one with a zero destination.
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		gatewayBytes, err := hex.DecodeString(fields[2])
+		if err != nil || len(gatewayBytes) != net.IPv4len {
+			continue
+		}
+		gateway := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(gateway, binary.LittleEndian.Uint32(gatewayBytes))
+		if !gateway.IsUnspecified() {
+			return gateway, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.Errorf("no default gateway found in the routing table")
+}
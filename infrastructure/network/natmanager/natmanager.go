@@ -0,0 +1,176 @@
+package natmanager
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+	"github.com/pkg/errors"
+)
+
+const (
+	// mappingLifetime is the lease duration requested for the port mapping. The mapping is
+	// refreshed every mappingRefreshInterval, well before it expires.
+	mappingLifetime        = 20 * time.Minute
+	mappingRefreshInterval = mappingLifetime / 2
+
+	// rediscoveryInterval is how long to wait before looking for a gateway again after no
+	// gateway was found or the mapping failed
+	rediscoveryInterval = 5 * time.Minute
+
+	mappingDescription = "kaspad"
+)
+
+// Manager maps the P2P listen port on the NAT gateway of the network using UPnP IGD or NAT-PMP,
+// and advertises the external address of the gateway to peers, so that nodes behind a home router
+// can accept inbound connections
+type Manager struct {
+	port           uint16
+	addressManager *addressmanager.AddressManager
+	discover       func() (NAT, error)
+
+	nat             NAT
+	externalPort    uint16
+	externalAddress *appmessage.NetAddress
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// New returns a Manager for the P2P listen port of the given config
+func New(cfg *config.Config, addressManager *addressmanager.AddressManager) (*Manager, error) {
+	port, err := listenPort(cfg.Listeners, cfg.NetParams().DefaultPort)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		port:           port,
+		addressManager: addressManager,
+		discover:       Discover,
+		stopChan:       make(chan struct{}),
+	}, nil
+}
+
+// listenPort returns the port of the first IPv4 listener, which is the one that can be mapped
+func listenPort(listeners []string, defaultPort string) (uint16, error) {
+	portString := defaultPort
+	for _, listener := range listeners {
+		host, listenerPort, err := net.SplitHostPort(listener)
+		if err != nil {
+			return 0, errors.Wrapf(err, "error parsing listener %s", listener)
+		}
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.To4() != nil) {
+			portString = listenerPort
+			break
+		}
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error parsing port %s", portString)
+	}
+	return uint16(port), nil
+}
+
+// Start looks for a gateway and keeps the port mapping up in the background
+func (m *Manager) Start() {
+	m.wg.Add(1)
+	spawn("natmanager.Manager.loop", func() {
+		defer m.wg.Done()
+		m.loop()
+	})
+}
+
+// Stop stops refreshing the port mapping and removes it from the gateway
+func (m *Manager) Stop() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	if m.nat != nil {
+		err := m.nat.DeletePortMapping("tcp", m.port, m.externalPort)
+		if err != nil {
+			log.Warnf("Error removing the port mapping from the %s gateway: %s", m.nat.Name(), err)
+		}
+	}
+}
+
+func (m *Manager) loop() {
+	for {
+		var delay time.Duration
+		if m.refresh() {
+			delay = mappingRefreshInterval
+		} else {
+			delay = rediscoveryInterval
+		}
+
+		select {
+		case <-m.stopChan:
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// refresh maps the port, looking for a gateway first if there's none yet, and advertises the
+// external address. It returns false if there's no working mapping.
+func (m *Manager) refresh() bool {
+	if m.nat == nil {
+		nat, err := m.discover()
+		if err != nil {
+			log.Infof("Could not find a NAT gateway to map port %d on: %s", m.port, err)
+			return false
+		}
+		log.Infof("Found a %s gateway", nat.Name())
+		m.nat = nat
+	}
+
+	err := m.mapPort()
+	if err != nil {
+		log.Warnf("Error mapping port %d on the %s gateway: %s", m.port, m.nat.Name(), err)
+		m.nat = nil
+		m.setExternalAddress(nil)
+		return false
+	}
+	return true
+}
+
+func (m *Manager) mapPort() error {
+	externalPort, err := m.nat.AddPortMapping("tcp", m.port, m.port, mappingDescription, mappingLifetime)
+	if err != nil {
+		return err
+	}
+	m.externalPort = externalPort
+
+	externalIP, err := m.nat.ExternalIP()
+	if err != nil {
+		return errors.Wrapf(err, "error getting the external address")
+	}
+	m.setExternalAddress(appmessage.NewNetAddressIPPort(externalIP, externalPort))
+	return nil
+}
+
+// setExternalAddress advertises the given address in place of the previous external address
+func (m *Manager) setExternalAddress(externalAddress *appmessage.NetAddress) {
+	if m.externalAddress != nil {
+		if externalAddress != nil && m.externalAddress.IP.Equal(externalAddress.IP) &&
+			m.externalAddress.Port == externalAddress.Port {
+			return
+		}
+		m.addressManager.RemoveLocalAddress(m.externalAddress)
+		m.externalAddress = nil
+	}
+	if externalAddress == nil {
+		return
+	}
+
+	err := m.addressManager.AddLocalAddress(externalAddress, addressmanager.UpnpPrio)
+	if err != nil {
+		log.Warnf("Not advertising the external address %s: %s", externalAddress.TCPAddress(), err)
+		return
+	}
+	m.externalAddress = externalAddress
+	log.Infof("Mapped port %d to %s on the %s gateway", m.port, externalAddress.TCPAddress(), m.nat.Name())
+}
@@ -0,0 +1,119 @@
+package natmanager
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/db/database/ldb"
+	"github.com/kaspanet/kaspad/infrastructure/network/addressmanager"
+	"github.com/pkg/errors"
+)
+
+type fakeNAT struct {
+	externalIP net.IP
+	mapErr     error
+	mappings   map[uint16]uint16
+}
+
+func (n *fakeNAT) Name() string { return "fake" }
+
+func (n *fakeNAT) ExternalIP() (net.IP, error) { return n.externalIP, nil }
+
+func (n *fakeNAT) AddPortMapping(_ string, internalPort, externalPort uint16, _ string, _ time.Duration) (uint16, error) {
+	if n.mapErr != nil {
+		return 0, n.mapErr
+	}
+	n.mappings[internalPort] = externalPort
+	return externalPort, nil
+}
+
+func (n *fakeNAT) DeletePortMapping(_ string, internalPort, _ uint16) error {
+	delete(n.mappings, internalPort)
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Listeners = []string{"[::1]:16611", "0.0.0.0:16511"}
+	database, err := ldb.NewLevelDB(t.TempDir(), 8)
+	if err != nil {
+		t.Fatalf("NewLevelDB: %s", err)
+	}
+	defer database.Close()
+	addressManager, err := addressmanager.New(addressmanager.NewConfig(cfg), database)
+	if err != nil {
+		t.Fatalf("addressmanager.New: %s", err)
+	}
+
+	manager, err := New(cfg, addressManager)
+	if err != nil {
+		t.Fatalf("New: %s", err)
+	}
+	if manager.port != 16511 {
+		t.Fatalf("Expected the port of the IPv4 listener, got %d", manager.port)
+	}
+
+	nat := &fakeNAT{externalIP: net.ParseIP("1.2.3.4"), mappings: make(map[uint16]uint16)}
+	discoveries := 0
+	manager.discover = func() (NAT, error) {
+		discoveries++
+		return nat, nil
+	}
+
+	remoteAddress := appmessage.NewNetAddressIPPort(net.ParseIP("5.6.7.8"), 16511)
+	expectAdvertised := func(expectedAddress string) {
+		t.Helper()
+		bestAddress := addressManager.BestLocalAddress(remoteAddress)
+		if bestAddress.TCPAddress().String() != expectedAddress {
+			t.Fatalf("Expected %s to be advertised, got %s", expectedAddress, bestAddress.TCPAddress())
+		}
+	}
+	expectNotAdvertised := func(address string) {
+		t.Helper()
+		bestAddress := addressManager.BestLocalAddress(remoteAddress)
+		if bestAddress.TCPAddress().String() == address {
+			t.Fatalf("Expected %s to not be advertised", address)
+		}
+	}
+
+	if !manager.refresh() {
+		t.Fatalf("Expected the port to be mapped")
+	}
+	if nat.mappings[16511] != 16511 {
+		t.Fatalf("Unexpected mappings %v", nat.mappings)
+	}
+	expectAdvertised("1.2.3.4:16511")
+
+	// A change of the external address is picked up on the next refresh
+	nat.externalIP = net.ParseIP("1.2.3.5")
+	if !manager.refresh() {
+		t.Fatalf("Expected the port to be mapped")
+	}
+	expectAdvertised("1.2.3.5:16511")
+	if discoveries != 1 {
+		t.Fatalf("Expected a single discovery, got %d", discoveries)
+	}
+
+	// When the mapping fails the address isn't advertised anymore, and the gateway is discovered again
+	nat.mapErr = errors.New("mapping failed")
+	if manager.refresh() {
+		t.Fatalf("Expected the mapping to fail")
+	}
+	expectNotAdvertised("1.2.3.5:16511")
+	nat.mapErr = nil
+	if !manager.refresh() {
+		t.Fatalf("Expected the port to be mapped")
+	}
+	if discoveries != 2 {
+		t.Fatalf("Expected the gateway to be discovered again, got %d discoveries", discoveries)
+	}
+
+	manager.Start()
+	manager.Stop()
+	if len(nat.mappings) != 0 {
+		t.Fatalf("Expected the mapping to be removed on stop, got %v", nat.mappings)
+	}
+}
@@ -0,0 +1,140 @@
+package natmanager
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NAT-PMP is described in RFC 6886
+const (
+	natPMPPort    = "5351"
+	natPMPVersion = 0
+
+	natPMPOpExternalAddress = 0
+	natPMPOpMapUDP          = 1
+	natPMPOpMapTCP          = 2
+	natPMPResponseOpOffset  = 128
+
+	natPMPInitialRetransmitDelay = 250 * time.Millisecond
+	natPMPAttempts               = 4
+)
+
+var natPMPResultCodeDescriptions = map[uint16]string{
+	1: "unsupported version",
+	2: "not authorized or refused",
+	3: "network failure",
+	4: "out of resources",
+	5: "unsupported opcode",
+}
+
+type natPMP struct {
+	gatewayAddress         string
+	initialRetransmitDelay time.Duration
+}
+
+func newNATPMP(gatewayAddress string) *natPMP {
+	return &natPMP{
+		gatewayAddress:         gatewayAddress,
+		initialRetransmitDelay: natPMPInitialRetransmitDelay,
+	}
+}
+
+func (n *natPMP) Name() string {
+	return "NAT-PMP"
+}
+
+func (n *natPMP) ExternalIP() (net.IP, error) {
+	response, err := n.request([]byte{natPMPVersion, natPMPOpExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(response[8], response[9], response[10], response[11]), nil
+}
+
+func (n *natPMP) AddPortMapping(protocol string, internalPort, externalPort uint16, _ string,
+	lifetime time.Duration) (uint16, error) {
+
+	response, err := n.mapPort(protocol, internalPort, externalPort, uint32(lifetime/time.Second))
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(response[10:12]), nil
+}
+
+func (n *natPMP) DeletePortMapping(protocol string, internalPort, _ uint16) error {
+	// A mapping is deleted by requesting it with a zero lifetime and a zero external port
+	_, err := n.mapPort(protocol, internalPort, 0, 0)
+	return err
+}
+
+func (n *natPMP) mapPort(protocol string, internalPort, externalPort uint16, lifetimeSeconds uint32) ([]byte, error) {
+	var op byte
+	switch protocol {
+	case "tcp":
+		op = natPMPOpMapTCP
+	case "udp":
+		op = natPMPOpMapUDP
+	default:
+		return nil, errors.Errorf("unsupported protocol %s", protocol)
+	}
+
+	request := make([]byte, 12)
+	request[0] = natPMPVersion
+	request[1] = op
+	binary.BigEndian.PutUint16(request[4:6], internalPort)
+	binary.BigEndian.PutUint16(request[6:8], externalPort)
+	binary.BigEndian.PutUint32(request[8:12], lifetimeSeconds)
+	return n.request(request, 16)
+}
+
+// request sends the given request to the gateway, retransmitting it with a doubling delay until a
+// response arrives, and returns the response after checking its header
+func (n *natPMP) request(request []byte, responseLength int) ([]byte, error) {
+	connection, err := net.Dial("udp", n.gatewayAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to %s", n.gatewayAddress)
+	}
+	defer connection.Close()
+
+	response := make([]byte, 16)
+	delay := n.initialRetransmitDelay
+	for attempt := 0; attempt < natPMPAttempts; attempt++ {
+		_, err := connection.Write(request)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error sending a request to %s", n.gatewayAddress)
+		}
+		err = connection.SetReadDeadline(time.Now().Add(delay))
+		if err != nil {
+			return nil, err
+		}
+		delay *= 2
+
+		length, err := connection.Read(response)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			return nil, errors.Wrapf(err, "error reading a response from %s", n.gatewayAddress)
+		}
+		if length < responseLength {
+			return nil, errors.Errorf("got a response of %d bytes from %s, expected %d",
+				length, n.gatewayAddress, responseLength)
+		}
+		if response[0] != natPMPVersion || response[1] != request[1]+natPMPResponseOpOffset {
+			return nil, errors.Errorf("got an unexpected response from %s", n.gatewayAddress)
+		}
+		resultCode := binary.BigEndian.Uint16(response[2:4])
+		if resultCode != 0 {
+			description, ok := natPMPResultCodeDescriptions[resultCode]
+			if !ok {
+				description = "unknown error"
+			}
+			return nil, errors.Errorf("%s returned result code %d: %s", n.gatewayAddress, resultCode, description)
+		}
+		return response[:length], nil
+	}
+	return nil, errors.Errorf("no response from %s", n.gatewayAddress)
+}
@@ -0,0 +1,76 @@
+package natmanager
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveFakeNATPMP answers NAT-PMP requests on the given connection, mapping every requested port
+// to the internal port plus one. The first request is dropped to exercise retransmission.
+func serveFakeNATPMP(connection net.PacketConn) {
+	buffer := make([]byte, 16)
+	isFirstRequest := true
+	for {
+		length, address, err := connection.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		if isFirstRequest {
+			isFirstRequest = false
+			continue
+		}
+		if length < 2 {
+			continue
+		}
+		response := make([]byte, 16)
+		response[1] = buffer[1] + natPMPResponseOpOffset
+		switch buffer[1] {
+		case natPMPOpExternalAddress:
+			copy(response[8:12], net.IPv4(1, 2, 3, 4).To4())
+			response = response[:12]
+		case natPMPOpMapTCP:
+			internalPort := binary.BigEndian.Uint16(buffer[4:6])
+			copy(response[8:10], buffer[4:6])
+			binary.BigEndian.PutUint16(response[10:12], internalPort+1)
+			copy(response[12:16], buffer[8:12])
+		default:
+			binary.BigEndian.PutUint16(response[2:4], 5)
+		}
+		connection.WriteTo(response, address)
+	}
+}
+
+func TestNATPMP(t *testing.T) {
+	connection, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket: %s", err)
+	}
+	defer connection.Close()
+	go serveFakeNATPMP(connection)
+
+	nat := newNATPMP(connection.LocalAddr().String())
+	nat.initialRetransmitDelay = 50 * time.Millisecond
+
+	externalIP, err := nat.ExternalIP()
+	if err != nil {
+		t.Fatalf("ExternalIP: %s", err)
+	}
+	if !externalIP.Equal(net.ParseIP("1.2.3.4")) {
+		t.Fatalf("Unexpected external IP %s", externalIP)
+	}
+
+	externalPort, err := nat.AddPortMapping("tcp", 16111, 16111, "kaspad", 20*time.Minute)
+	if err != nil {
+		t.Fatalf("AddPortMapping: %s", err)
+	}
+	if externalPort != 16112 {
+		t.Fatalf("Expected the external port chosen by the gateway, got %d", externalPort)
+	}
+
+	_, err = nat.AddPortMapping("udp", 16111, 16111, "kaspad", 20*time.Minute)
+	if err == nil {
+		t.Fatalf("Expected an error for a request the gateway doesn't support")
+	}
+}
@@ -0,0 +1,326 @@
+package natmanager
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	upnpInternetGatewayDeviceType = "urn:schemas-upnp-org:device:InternetGatewayDevice:1"
+	upnpRequestTimeout            = 5 * time.Second
+
+	// upnpErrorOnlyPermanentLeasesSupported is returned by gateways that don't support mappings
+	// with a limited lease duration
+	upnpErrorOnlyPermanentLeasesSupported = 725
+)
+
+// upnpWANConnectionServiceTypes are the services of an IGD that can map ports
+var upnpWANConnectionServiceTypes = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+type upnp struct {
+	controlURL     string
+	serviceType    string
+	internalClient net.IP
+	httpClient     *http.Client
+}
+
+// discoverUPnP sends an SSDP search for an Internet Gateway Device to the given address and
+// returns a client for the port mapping service of the first gateway that has one
+func discoverUPnP(ssdpAddress string, timeout time.Duration) (*upnp, error) {
+	ssdpUDPAddress, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+
+	search := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + defaultSSDPAddress + "\r\n" +
+		"ST: " + upnpInternetGatewayDeviceType + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n\r\n"
+	_, err = connection.WriteTo([]byte(search), ssdpUDPAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error sending an SSDP search to %s", ssdpAddress)
+	}
+
+	deadline := time.Now().Add(timeout)
+	err = connection.SetReadDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+	buffer := make([]byte, 2048)
+	for {
+		length, _, err := connection.ReadFrom(buffer)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return nil, errors.Errorf("no Internet Gateway Device responded to the SSDP search")
+			}
+			return nil, err
+		}
+
+		response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buffer[:length])), nil)
+		if err != nil {
+			log.Debugf("Ignoring a malformed SSDP response: %s", err)
+			continue
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK || response.Header.Get("ST") != upnpInternetGatewayDeviceType {
+			continue
+		}
+		location := response.Header.Get("Location")
+		nat, err := newUPnP(location, time.Until(deadline))
+		if err != nil {
+			log.Debugf("Ignoring the Internet Gateway Device at %s: %s", location, err)
+			continue
+		}
+		return nat, nil
+	}
+}
+
+type upnpDevice struct {
+	DeviceType string        `xml:"deviceType"`
+	Services   []upnpService `xml:"serviceList>service"`
+	Devices    []upnpDevice  `xml:"deviceList>device"`
+}
+
+type upnpService struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+func (d *upnpDevice) wanConnectionService() (*upnpService, bool) {
+	for i := range d.Services {
+		for _, serviceType := range upnpWANConnectionServiceTypes {
+			if d.Services[i].ServiceType == serviceType {
+				return &d.Services[i], true
+			}
+		}
+	}
+	for i := range d.Devices {
+		if service, ok := d.Devices[i].wanConnectionService(); ok {
+			return service, true
+		}
+	}
+	return nil, false
+}
+
+// newUPnP fetches the description of the gateway at the given location and returns a client for
+// its port mapping service
+func newUPnP(location string, timeout time.Duration) (*upnp, error) {
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the location %s", location)
+	}
+	httpClient := &http.Client{Timeout: upnpRequestTimeout}
+	response, err := (&http.Client{Timeout: timeout}).Get(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching the device description")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching the device description returned status %s", response.Status)
+	}
+
+	var description struct {
+		URLBase string     `xml:"URLBase"`
+		Device  upnpDevice `xml:"device"`
+	}
+	err = xml.NewDecoder(response.Body).Decode(&description)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding the device description")
+	}
+	if description.Device.DeviceType != upnpInternetGatewayDeviceType {
+		return nil, errors.Errorf("the device is a %s and not an Internet Gateway Device",
+			description.Device.DeviceType)
+	}
+	service, ok := description.Device.wanConnectionService()
+	if !ok {
+		return nil, errors.Errorf("the gateway has no port mapping service")
+	}
+
+	baseURL := locationURL
+	if description.URLBase != "" {
+		baseURL, err = url.Parse(description.URLBase)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing the URL base %s", description.URLBase)
+		}
+	}
+	controlURL, err := baseURL.Parse(service.ControlURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing the control URL %s", service.ControlURL)
+	}
+
+	// The gateway maps ports to the address this host reaches it from. Dialing UDP doesn't send
+	// anything, it only picks the local address.
+	connection, err := net.Dial("udp4", net.JoinHostPort(controlURL.Hostname(), "1"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding the local address that reaches the gateway")
+	}
+	internalClient := connection.LocalAddr().(*net.UDPAddr).IP
+	connection.Close()
+
+	return &upnp{
+		controlURL:     controlURL.String(),
+		serviceType:    service.ServiceType,
+		internalClient: internalClient,
+		httpClient:     httpClient,
+	}, nil
+}
+
+func (u *upnp) Name() string {
+	return "UPnP"
+}
+
+func (u *upnp) ExternalIP() (net.IP, error) {
+	response, err := u.soapRequest("GetExternalIPAddress", nil)
+	if err != nil {
+		return nil, err
+	}
+	externalIPString, err := soapResponseValue(response, "NewExternalIPAddress")
+	if err != nil {
+		return nil, err
+	}
+	externalIP := net.ParseIP(externalIPString)
+	if externalIP == nil {
+		return nil, errors.Errorf("the gateway returned a malformed external address %s", externalIPString)
+	}
+	return externalIP, nil
+}
+
+func (u *upnp) AddPortMapping(protocol string, internalPort, externalPort uint16, description string,
+	lifetime time.Duration) (uint16, error) {
+
+	arguments := []soapArgument{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", strconv.Itoa(int(externalPort))},
+		{"NewProtocol", strings.ToUpper(protocol)},
+		{"NewInternalPort", strconv.Itoa(int(internalPort))},
+		{"NewInternalClient", u.internalClient.String()},
+		{"NewEnabled", "1"},
+		{"NewPortMappingDescription", description},
+		{"NewLeaseDuration", strconv.Itoa(int(lifetime / time.Second))},
+	}
+	_, err := u.soapRequest("AddPortMapping", arguments)
+	var upnpErr *upnpError
+	if errors.As(err, &upnpErr) && upnpErr.code == upnpErrorOnlyPermanentLeasesSupported {
+		arguments[len(arguments)-1].value = "0"
+		_, err = u.soapRequest("AddPortMapping", arguments)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return externalPort, nil
+}
+
+func (u *upnp) DeletePortMapping(protocol string, _, externalPort uint16) error {
+	_, err := u.soapRequest("DeletePortMapping", []soapArgument{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", strconv.Itoa(int(externalPort))},
+		{"NewProtocol", strings.ToUpper(protocol)},
+	})
+	return err
+}
+
+type soapArgument struct {
+	name  string
+	value string
+}
+
+// upnpError is an error returned by the gateway in a SOAP fault
+type upnpError struct {
+	code        int
+	description string
+}
+
+func (e *upnpError) Error() string {
+	return fmt.Sprintf("UPnP error %d: %s", e.code, e.description)
+}
+
+// soapRequest calls the given action of the port mapping service and returns the response body
+func (u *upnp) soapRequest(action string, arguments []soapArgument) ([]byte, error) {
+	body := &bytes.Buffer{}
+	body.WriteString(`<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(body, `<u:%s xmlns:u="%s">`, action, u.serviceType)
+	for _, argument := range arguments {
+		fmt.Fprintf(body, "<%s>", argument.name)
+		err := xml.EscapeText(body, []byte(argument.value))
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(body, "</%s>", argument.name)
+	}
+	fmt.Fprintf(body, "</u:%s></s:Body></s:Envelope>", action)
+
+	request, err := http.NewRequest(http.MethodPost, u.controlURL, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	request.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, u.serviceType, action))
+
+	response, err := u.httpClient.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error calling %s", action)
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading the response to %s", action)
+	}
+	if response.StatusCode != http.StatusOK {
+		errorCode, err := soapResponseValue(responseBody, "errorCode")
+		if err == nil {
+			code, err := strconv.Atoi(errorCode)
+			if err == nil {
+				description, _ := soapResponseValue(responseBody, "errorDescription")
+				return nil, errors.Wrapf(&upnpError{code: code, description: description}, "error calling %s", action)
+			}
+		}
+		return nil, errors.Errorf("%s returned status %s", action, response.Status)
+	}
+	return responseBody, nil
+}
+
+// soapResponseValue returns the text of the first element with the given name in a SOAP response
+func soapResponseValue(response []byte, name string) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(response))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", errors.Errorf("%s is missing from the response", name)
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "error decoding the response")
+		}
+		startElement, ok := token.(xml.StartElement)
+		if !ok || startElement.Name.Local != name {
+			continue
+		}
+		var value string
+		err = decoder.DecodeElement(&value, &startElement)
+		if err != nil {
+			return "", errors.Wrapf(err, "error decoding %s", name)
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
@@ -0,0 +1,219 @@
+package natmanager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const fakeIGDDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+    <deviceList>
+      <device>
+        <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+        <deviceList>
+          <device>
+            <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+            <serviceList>
+              <service>
+                <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+                <controlURL>/control</controlURL>
+              </service>
+            </serviceList>
+          </device>
+        </deviceList>
+      </device>
+    </deviceList>
+  </device>
+</root>`
+
+// fakeIGD is a UPnP Internet Gateway Device that answers SSDP searches on a local UDP port
+// and serves its description and port mapping service over HTTP
+type fakeIGD struct {
+	t                     *testing.T
+	ssdpConnection        net.PacketConn
+	httpServer            *httptest.Server
+	onlyPermanentLeases   bool
+	externalIP            string
+	lock                  sync.Mutex
+	mappings              map[string]string
+	addPortMappingRequest string
+}
+
+func newFakeIGD(t *testing.T) *fakeIGD {
+	ssdpConnection, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket: %s", err)
+	}
+	igd := &fakeIGD{
+		t:              t,
+		ssdpConnection: ssdpConnection,
+		externalIP:     "1.2.3.4",
+		mappings:       make(map[string]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/description.xml", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, fakeIGDDescription)
+	})
+	mux.HandleFunc("/control", igd.handleControl)
+	igd.httpServer = httptest.NewServer(mux)
+	go igd.serveSSDP()
+	return igd
+}
+
+func (igd *fakeIGD) close() {
+	igd.ssdpConnection.Close()
+	igd.httpServer.Close()
+}
+
+func (igd *fakeIGD) serveSSDP() {
+	buffer := make([]byte, 2048)
+	for {
+		length, address, err := igd.ssdpConnection.ReadFrom(buffer)
+		if err != nil {
+			return
+		}
+		if !bytes.HasPrefix(buffer[:length], []byte("M-SEARCH")) {
+			continue
+		}
+		// Answer with a non-gateway device first, which should be ignored
+		igd.ssdpConnection.WriteTo([]byte("HTTP/1.1 200 OK\r\n"+
+			"ST: urn:schemas-upnp-org:device:MediaServer:1\r\n"+
+			"LOCATION: http://127.0.0.1:1/description.xml\r\n\r\n"), address)
+		igd.ssdpConnection.WriteTo([]byte("HTTP/1.1 200 OK\r\n"+
+			"ST: "+upnpInternetGatewayDeviceType+"\r\n"+
+			"LOCATION: "+igd.httpServer.URL+"/description.xml\r\n\r\n"), address)
+	}
+}
+
+func (igd *fakeIGD) handleControl(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	value := func(name string) string {
+		value, err := soapResponseValue(body, name)
+		if err != nil {
+			igd.t.Errorf("Request is missing %s: %s", name, err)
+		}
+		return value
+	}
+	fault := func(code int, description string) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>`+
+			`<detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode>`+
+			`<errorDescription>%s</errorDescription></UPnPError></detail></s:Fault></s:Body></s:Envelope>`,
+			code, description)
+	}
+
+	igd.lock.Lock()
+	defer igd.lock.Unlock()
+
+	action := r.Header.Get("SOAPAction")
+	switch {
+	case strings.HasSuffix(action, `#GetExternalIPAddress"`):
+		fmt.Fprintf(w, `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+			`<u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">`+
+			`<NewExternalIPAddress>%s</NewExternalIPAddress></u:GetExternalIPAddressResponse></s:Body></s:Envelope>`,
+			igd.externalIP)
+	case strings.HasSuffix(action, `#AddPortMapping"`):
+		if igd.onlyPermanentLeases && value("NewLeaseDuration") != "0" {
+			fault(upnpErrorOnlyPermanentLeasesSupported, "OnlyPermanentLeasesSupported")
+			return
+		}
+		igd.addPortMappingRequest = string(body)
+		igd.mappings[value("NewProtocol")+value("NewExternalPort")] =
+			value("NewInternalClient") + ":" + value("NewInternalPort")
+	case strings.HasSuffix(action, `#DeletePortMapping"`):
+		key := value("NewProtocol") + value("NewExternalPort")
+		if _, ok := igd.mappings[key]; !ok {
+			fault(714, "NoSuchEntryInArray")
+			return
+		}
+		delete(igd.mappings, key)
+	default:
+		fault(401, "Invalid Action")
+	}
+}
+
+func TestUPnP(t *testing.T) {
+	igd := newFakeIGD(t)
+	defer igd.close()
+
+	nat, err := discoverUPnP(igd.ssdpConnection.LocalAddr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("discoverUPnP: %s", err)
+	}
+	if nat.serviceType != "urn:schemas-upnp-org:service:WANIPConnection:1" {
+		t.Fatalf("Unexpected service type %s", nat.serviceType)
+	}
+
+	externalIP, err := nat.ExternalIP()
+	if err != nil {
+		t.Fatalf("ExternalIP: %s", err)
+	}
+	if !externalIP.Equal(net.ParseIP("1.2.3.4")) {
+		t.Fatalf("Unexpected external IP %s", externalIP)
+	}
+
+	externalPort, err := nat.AddPortMapping("tcp", 16111, 16112, "kaspad <test>", 20*time.Minute)
+	if err != nil {
+		t.Fatalf("AddPortMapping: %s", err)
+	}
+	if externalPort != 16112 {
+		t.Fatalf("Unexpected external port %d", externalPort)
+	}
+	if mapping := igd.mappings["TCP16112"]; mapping != "127.0.0.1:16111" {
+		t.Fatalf("Unexpected mapping %s", mapping)
+	}
+	if !strings.Contains(igd.addPortMappingRequest, "<NewPortMappingDescription>kaspad &lt;test&gt;</NewPortMappingDescription>") ||
+		!strings.Contains(igd.addPortMappingRequest, "<NewLeaseDuration>1200</NewLeaseDuration>") {
+		t.Fatalf("Unexpected AddPortMapping request %s", igd.addPortMappingRequest)
+	}
+
+	err = nat.DeletePortMapping("tcp", 16111, 16112)
+	if err != nil {
+		t.Fatalf("DeletePortMapping: %s", err)
+	}
+	if len(igd.mappings) != 0 {
+		t.Fatalf("Expected the mapping to be deleted, got %v", igd.mappings)
+	}
+
+	// Deleting a missing mapping returns the error of the gateway
+	err = nat.DeletePortMapping("tcp", 16111, 16112)
+	var upnpErr *upnpError
+	if !errors.As(err, &upnpErr) || upnpErr.code != 714 {
+		t.Fatalf("Expected UPnP error 714, got %v", err)
+	}
+
+	// Gateways that only support permanent leases get a mapping without a lease duration
+	igd.onlyPermanentLeases = true
+	_, err = nat.AddPortMapping("tcp", 16111, 16111, "kaspad", 20*time.Minute)
+	if err != nil {
+		t.Fatalf("AddPortMapping: %s", err)
+	}
+	if !strings.Contains(igd.addPortMappingRequest, "<NewLeaseDuration>0</NewLeaseDuration>") {
+		t.Fatalf("Expected a permanent lease, got %s", igd.addPortMappingRequest)
+	}
+}
+
+func TestUPnPNoGateway(t *testing.T) {
+	connection, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket: %s", err)
+	}
+	defer connection.Close()
+
+	_, err = discoverUPnP(connection.LocalAddr().String(), 100*time.Millisecond)
+	if err == nil {
+		t.Fatalf("Expected discovery to fail when no gateway responds")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/jsonrpcserver"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
 	"github.com/pkg/errors"
 )
 
@@ -28,6 +30,7 @@ type NetAdapter struct {
 	p2pServer            server.P2PServer
 	p2pRouterInitializer RouterInitializer
 	rpcServer            server.Server
+	jsonRPCServer        server.Server
 	rpcRouterInitializer RouterInitializer
 	stop                 uint32
 
@@ -42,25 +45,47 @@ func NewNetAdapter(cfg *config.Config) (*NetAdapter, error) {
 	if err != nil {
 		return nil, err
 	}
-	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners)
+	p2pServer, err := grpcserver.NewP2PServer(cfg.Listeners, cfg.Dial)
 	if err != nil {
 		return nil, err
 	}
-	rpcServer, err := grpcserver.NewRPCServer(cfg.RPCListeners, cfg.RPCMaxClients)
+	rpcSecurityConfig := &grpcserver.RPCSecurityConfig{
+		EnableTLS:   cfg.RPCTLS,
+		TLSCertFile: cfg.RPCCert,
+		TLSKeyFile:  cfg.RPCKey,
+		Credentials: rpcCredentials(cfg),
+
+		// With a roles file, clients that don't authenticate are
+		// granted the roles of their listener or the default roles
+		AllowAnonymous: cfg.RPCRoles != nil,
+	}
+	rpcServer, err := grpcserver.NewRPCServer(cfg.RPCListeners, cfg.RPCMaxClients, rpcSecurityConfig)
 	if err != nil {
 		return nil, err
 	}
+	var jsonRPCServer server.Server
+	if len(cfg.JSONRPCListeners) > 0 {
+		jsonRPCServer, err = jsonrpcserver.NewJSONRPCServer(cfg.JSONRPCListeners, cfg.RPCMaxWebsockets,
+			cfg.RPCMaxConcurrentReqs, cfg.JSONRPCCORSOrigins, rpcSecurityConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
 	adapter := NetAdapter{
-		cfg:       cfg,
-		id:        netAdapterID,
-		p2pServer: p2pServer,
-		rpcServer: rpcServer,
+		cfg:           cfg,
+		id:            netAdapterID,
+		p2pServer:     p2pServer,
+		rpcServer:     rpcServer,
+		jsonRPCServer: jsonRPCServer,
 
 		p2pConnections: make(map[*NetConnection]struct{}),
 	}
 
 	adapter.p2pServer.SetOnConnectedHandler(adapter.onP2PConnectedHandler)
 	adapter.rpcServer.SetOnConnectedHandler(adapter.onRPCConnectedHandler)
+	if adapter.jsonRPCServer != nil {
+		adapter.jsonRPCServer.SetOnConnectedHandler(adapter.onRPCConnectedHandler)
+	}
 
 	return &adapter, nil
 }
@@ -82,6 +107,12 @@ func (na *NetAdapter) Start() error {
 	if err != nil {
 		return err
 	}
+	if na.jsonRPCServer != nil {
+		err = na.jsonRPCServer.Start()
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -95,6 +126,12 @@ func (na *NetAdapter) Stop() error {
 	if err != nil {
 		return err
 	}
+	if na.jsonRPCServer != nil {
+		err = na.jsonRPCServer.Stop()
+		if err != nil {
+			return err
+		}
+	}
 	return na.rpcServer.Stop()
 }
 
@@ -190,3 +227,32 @@ func (na *NetAdapter) P2PBroadcast(netConnections []*NetConnection, message appm
 	}
 	return nil
 }
+
+// rpcCredentials returns the credentials RPC clients may authenticate with
+func rpcCredentials(cfg *config.Config) []*rpcauth.Credential {
+	var credentials []*rpcauth.Credential
+	if cfg.RPCUser != "" {
+		credentials = append(credentials, &rpcauth.Credential{
+			Identity: config.RPCOperatorIdentity,
+			User:     cfg.RPCUser,
+			Password: cfg.RPCPass,
+		})
+	}
+	if cfg.RPCAuthToken != "" {
+		credentials = append(credentials, &rpcauth.Credential{
+			Identity:  config.RPCOperatorIdentity,
+			AuthToken: cfg.RPCAuthToken,
+		})
+	}
+	if cfg.RPCRoles != nil {
+		for _, credential := range cfg.RPCRoles.Credentials {
+			credentials = append(credentials, &rpcauth.Credential{
+				Identity:  credential.Name,
+				User:      credential.User,
+				Password:  credential.Password,
+				AuthToken: credential.Token,
+			})
+		}
+	}
+	return credentials
+}
@@ -70,6 +70,22 @@ func (c *NetConnection) Address() string {
 	return c.connection.Address().String()
 }
 
+// LocalAddress returns the local address of an inbound connection,
+// or an empty string if it's unknown
+func (c *NetConnection) LocalAddress() string {
+	localAddress := c.connection.LocalAddress()
+	if localAddress == nil {
+		return ""
+	}
+	return localAddress.String()
+}
+
+// Identity returns the identity an inbound connection's client
+// authenticated as, or an empty string if it did not authenticate
+func (c *NetConnection) Identity() string {
+	return c.connection.Identity()
+}
+
 // IsOutbound returns whether the connection is outbound
 func (c *NetConnection) IsOutbound() bool {
 	return c.connection.IsOutbound()
@@ -17,13 +17,13 @@ const (
 
 var (
 	// ErrTimeout signifies that one of the router functions had a timeout.
-	ErrTimeout = protocolerrors.New(false, "timeout expired")
+	ErrTimeout = protocolerrors.New(protocolerrors.BanScoreNone, "timeout expired")
 
 	// ErrRouteClosed indicates that a route was closed while reading/writing.
 	ErrRouteClosed = errors.New("route is closed")
 
 	// ErrRouteCapacityReached indicates that route's capacity has been reached
-	ErrRouteCapacityReached = protocolerrors.New(false, "route capacity has been reached")
+	ErrRouteCapacityReached = protocolerrors.New(protocolerrors.BanScoreNone, "route capacity has been reached")
 )
 
 // Route represents an incoming or outgoing Router route
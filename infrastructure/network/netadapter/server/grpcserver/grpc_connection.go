@@ -18,6 +18,8 @@ import (
 type gRPCConnection struct {
 	server                   *gRPCServer
 	address                  *net.TCPAddr
+	localAddress             *net.TCPAddr
+	identity                 string
 	stream                   grpcStream
 	router                   *router.Router
 	lowLevelClientConnection *grpc.ClientConn
@@ -127,6 +129,14 @@ func (c *gRPCConnection) Address() *net.TCPAddr {
 	return c.address
 }
 
+func (c *gRPCConnection) LocalAddress() *net.TCPAddr {
+	return c.localAddress
+}
+
+func (c *gRPCConnection) Identity() string {
+	return c.identity
+}
+
 func (c *gRPCConnection) receive() (*protowire.KaspadMessage, error) {
 	// We use RLock here and in send() because they can work
 	// in parallel. closeSend(), however, must not have either
@@ -10,14 +10,20 @@ import (
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/peer"
 	"net"
+	"strconv"
 	"time"
 )
 
 type p2pServer struct {
 	protowire.UnimplementedP2PServer
 	gRPCServer
+	dial DialFunc
 }
 
+// DialFunc is the signature of the function used to open outgoing P2P connections,
+// such as net.DialTimeout or the dial function of a proxy
+type DialFunc func(network string, address string, timeout time.Duration) (net.Conn, error)
+
 const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
 
 // p2pMaxInboundConnections is the max amount of inbound connections for the P2P server.
@@ -26,10 +32,10 @@ const p2pMaxMessageSize = 1024 * 1024 * 1024 // 1GB
 // is handled in the ConnectionManager instead.
 const p2pMaxInboundConnections = 0
 
-// NewP2PServer creates a new P2PServer
-func NewP2PServer(listeningAddresses []string) (server.P2PServer, error) {
+// NewP2PServer creates a new P2PServer that opens outgoing connections using the given dial function
+func NewP2PServer(listeningAddresses []string, dial DialFunc) (server.P2PServer, error) {
 	gRPCServer := newGRPCServer(listeningAddresses, p2pMaxMessageSize, p2pMaxInboundConnections, "P2P")
-	p2pServer := &p2pServer{gRPCServer: *gRPCServer}
+	p2pServer := &p2pServer{gRPCServer: *gRPCServer, dial: dial}
 	protowire.RegisterP2PServer(gRPCServer.server, p2pServer)
 	return p2pServer, nil
 }
@@ -49,7 +55,8 @@ func (p *p2pServer) Connect(address string) (server.Connection, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
 	defer cancel()
 
-	gRPCClientConnection, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock())
+	gRPCClientConnection, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(ContextDialer(p.dial)))
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s error connecting to %s", p.name, address)
 	}
@@ -65,9 +72,9 @@ func (p *p2pServer) Connect(address string) (server.Connection, error) {
 	if !ok {
 		return nil, errors.Errorf("%s error getting stream peer info from context for %s", p.name, address)
 	}
-	tcpAddress, ok := peerInfo.Addr.(*net.TCPAddr)
-	if !ok {
-		return nil, errors.Errorf("non-tcp addresses are not supported")
+	tcpAddress, err := peerTCPAddress(peerInfo.Addr, address)
+	if err != nil {
+		return nil, err
 	}
 
 	connection := newConnection(&p.gRPCServer, tcpAddress, stream, gRPCClientConnection)
@@ -81,3 +88,38 @@ func (p *p2pServer) Connect(address string) (server.Connection, error) {
 
 	return connection, nil
 }
+
+// ContextDialer adapts the given dial function to the dialer expected by grpc.WithContextDialer
+func ContextDialer(dial DialFunc) func(ctx context.Context, address string) (net.Conn, error) {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		timeout := time.Duration(0)
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		return dial("tcp", address, timeout)
+	}
+}
+
+// peerTCPAddress returns the TCP address of the peer at the other end of a connection to
+// dialedAddress. Connections through a proxy don't report a TCP address, so in that case
+// the dialed address is used, as long as it's an IP address. It's never resolved here,
+// since a local lookup would leak the connection outside the proxy
+func peerTCPAddress(peerAddress net.Addr, dialedAddress string) (*net.TCPAddr, error) {
+	if tcpAddress, ok := peerAddress.(*net.TCPAddr); ok {
+		return tcpAddress, nil
+	}
+
+	host, portString, err := net.SplitHostPort(dialedAddress)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, errors.Errorf("non-IP address %s is not supported", dialedAddress)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid port in address %s", dialedAddress)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
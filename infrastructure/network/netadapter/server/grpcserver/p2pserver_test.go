@@ -0,0 +1,34 @@
+package grpcserver
+
+import (
+	"net"
+	"testing"
+
+	"github.com/btcsuite/go-socks/socks"
+)
+
+func TestPeerTCPAddress(t *testing.T) {
+	tcpAddress := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 16111}
+	address, err := peerTCPAddress(tcpAddress, "1.2.3.4:16111")
+	if err != nil {
+		t.Fatalf("peerTCPAddress: %s", err)
+	}
+	if address != tcpAddress {
+		t.Fatalf("expected the peer's TCP address to be used, got %s", address)
+	}
+
+	// Connections through a proxy use the dialed address
+	proxiedAddress := &socks.ProxiedAddr{Net: "tcp", Host: "1.2.3.4", Port: 16111}
+	address, err = peerTCPAddress(proxiedAddress, "1.2.3.4:16111")
+	if err != nil {
+		t.Fatalf("peerTCPAddress: %s", err)
+	}
+	if address.String() != "1.2.3.4:16111" {
+		t.Fatalf("unexpected address %s", address)
+	}
+
+	_, err = peerTCPAddress(proxiedAddress, "example.com:16111")
+	if err == nil {
+		t.Fatalf("expected an error for a host name")
+	}
+}
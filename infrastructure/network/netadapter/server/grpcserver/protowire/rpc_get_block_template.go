@@ -13,9 +13,20 @@ func (x *KaspadMessage_GetBlockTemplateRequest) toAppMessage() (appmessage.Messa
 }
 
 func (x *KaspadMessage_GetBlockTemplateRequest) fromAppMessage(message *appmessage.GetBlockTemplateRequestMessage) error {
+	var payouts []*RpcBlockTemplatePayout
+	if len(message.Payouts) > 0 {
+		payouts = make([]*RpcBlockTemplatePayout, len(message.Payouts))
+		for i, payout := range message.Payouts {
+			payouts[i] = &RpcBlockTemplatePayout{
+				Address: payout.Address,
+				Weight:  payout.Weight,
+			}
+		}
+	}
 	x.GetBlockTemplateRequest = &GetBlockTemplateRequestMessage{
 		PayAddress: message.PayAddress,
 		ExtraData:  message.ExtraData,
+		Payouts:    payouts,
 	}
 	return nil
 }
@@ -24,9 +35,23 @@ func (x *GetBlockTemplateRequestMessage) toAppMessage() (appmessage.Message, err
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "GetBlockTemplateRequestMessage is nil")
 	}
+	var payouts []*appmessage.RPCBlockTemplatePayout
+	if len(x.Payouts) > 0 {
+		payouts = make([]*appmessage.RPCBlockTemplatePayout, len(x.Payouts))
+		for i, payout := range x.Payouts {
+			if payout == nil {
+				return nil, errors.Wrapf(errorNil, "RpcBlockTemplatePayout is nil")
+			}
+			payouts[i] = &appmessage.RPCBlockTemplatePayout{
+				Address: payout.Address,
+				Weight:  payout.Weight,
+			}
+		}
+	}
 	return &appmessage.GetBlockTemplateRequestMessage{
 		PayAddress: x.PayAddress,
 		ExtraData:  x.ExtraData,
+		Payouts:    payouts,
 	}, nil
 }
 
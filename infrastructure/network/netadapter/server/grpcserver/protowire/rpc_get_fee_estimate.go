@@ -10,6 +10,7 @@ func (x *KaspadMessage_GetFeeEstimateRequest) toAppMessage() (appmessage.Message
 }
 
 func (x *KaspadMessage_GetFeeEstimateRequest) fromAppMessage(_ *appmessage.GetFeeEstimateRequestMessage) error {
+	x.GetFeeEstimateRequest = &GetFeeEstimateRequestMessage{}
 	return nil
 }
 
@@ -20,6 +21,25 @@ func (x *KaspadMessage_GetFeeEstimateResponse) toAppMessage() (appmessage.Messag
 	return x.GetFeeEstimateResponse.toAppMessage()
 }
 
+func (x *KaspadMessage_GetFeeEstimateResponse) fromAppMessage(message *appmessage.GetFeeEstimateResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetFeeEstimateResponse = &GetFeeEstimateResponseMessage{
+		Estimate: &RpcFeeEstimate{
+			PriorityBucket: &RpcFeerateBucket{
+				Feerate:          message.Estimate.PriorityBucket.Feerate,
+				EstimatedSeconds: message.Estimate.PriorityBucket.EstimatedSeconds,
+			},
+			NormalBuckets: feeRateBucketsFromAppMessage(message.Estimate.NormalBuckets),
+			LowBuckets:    feeRateBucketsFromAppMessage(message.Estimate.LowBuckets),
+		},
+		Error: err,
+	}
+	return nil
+}
+
 func (x *GetFeeEstimateResponseMessage) toAppMessage() (appmessage.Message, error) {
 	if x == nil {
 		return nil, errors.Wrapf(errorNil, "GetFeeEstimateResponseMessage is nil")
@@ -30,13 +50,18 @@ func (x *GetFeeEstimateResponseMessage) toAppMessage() (appmessage.Message, erro
 		return nil, err
 	}
 
+	if rpcErr != nil {
+		return &appmessage.GetFeeEstimateResponseMessage{
+			Error: rpcErr,
+		}, nil
+	}
+
 	estimate, err := x.Estimate.toAppMessage()
 	if err != nil {
 		return nil, err
 	}
 
 	return &appmessage.GetFeeEstimateResponseMessage{
-		Error:    rpcErr,
 		Estimate: estimate,
 	}, nil
 }
@@ -65,3 +90,14 @@ func feeRateBucketsToAppMessage(protoBuckets []*RpcFeerateBucket) []appmessage.R
 	}
 	return appMsgBuckets
 }
+
+func feeRateBucketsFromAppMessage(appMsgBuckets []appmessage.RPCFeeRateBucket) []*RpcFeerateBucket {
+	protoBuckets := make([]*RpcFeerateBucket, len(appMsgBuckets))
+	for i, bucket := range appMsgBuckets {
+		protoBuckets[i] = &RpcFeerateBucket{
+			Feerate:          bucket.Feerate,
+			EstimatedSeconds: bucket.EstimatedSeconds,
+		}
+	}
+	return protoBuckets
+}
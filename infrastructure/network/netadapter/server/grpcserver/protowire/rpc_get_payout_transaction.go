@@ -0,0 +1,94 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetPayoutTransactionRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetPayoutTransactionRequest is nil")
+	}
+	return x.GetPayoutTransactionRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetPayoutTransactionRequest) fromAppMessage(message *appmessage.GetPayoutTransactionRequestMessage) error {
+	x.GetPayoutTransactionRequest = &GetPayoutTransactionRequestMessage{
+		PayAddress: message.PayAddress,
+		FeeRate:    message.FeeRate,
+	}
+	return nil
+}
+
+func (x *GetPayoutTransactionRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetPayoutTransactionRequestMessage is nil")
+	}
+	return &appmessage.GetPayoutTransactionRequestMessage{
+		PayAddress: x.PayAddress,
+		FeeRate:    x.FeeRate,
+	}, nil
+}
+
+func (x *KaspadMessage_GetPayoutTransactionResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetPayoutTransactionResponse is nil")
+	}
+	return x.GetPayoutTransactionResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetPayoutTransactionResponse) fromAppMessage(message *appmessage.GetPayoutTransactionResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	var transaction *RpcTransaction
+	if message.Transaction != nil {
+		transaction = &RpcTransaction{}
+		transaction.fromAppMessage(message.Transaction)
+	}
+	utxoEntries := make([]*RpcUtxoEntry, len(message.UTXOEntries))
+	for i, utxoEntry := range message.UTXOEntries {
+		utxoEntries[i] = &RpcUtxoEntry{}
+		utxoEntries[i].fromAppMessage(utxoEntry)
+	}
+	x.GetPayoutTransactionResponse = &GetPayoutTransactionResponseMessage{
+		Transaction: transaction,
+		UtxoEntries: utxoEntries,
+		Error:       err,
+	}
+	return nil
+}
+
+func (x *GetPayoutTransactionResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetPayoutTransactionResponseMessage is nil")
+	}
+	rpcErr, err := x.Error.toAppMessage()
+	// Error is an optional field
+	if err != nil && !errors.Is(err, errorNil) {
+		return nil, err
+	}
+
+	var transaction *appmessage.RPCTransaction
+	var utxoEntries []*appmessage.RPCUTXOEntry
+	if rpcErr == nil {
+		transaction, err = x.Transaction.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+		utxoEntries = make([]*appmessage.RPCUTXOEntry, len(x.UtxoEntries))
+		for i, utxoEntry := range x.UtxoEntries {
+			utxoEntries[i], err = utxoEntry.toAppMessage()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &appmessage.GetPayoutTransactionResponseMessage{
+		Transaction: transaction,
+		UTXOEntries: utxoEntries,
+		Error:       rpcErr,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package protowire
+
+import (
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/pkg/errors"
+)
+
+func (x *KaspadMessage_GetTransactionRequest) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetTransactionRequest is nil")
+	}
+	return x.GetTransactionRequest.toAppMessage()
+}
+
+func (x *KaspadMessage_GetTransactionRequest) fromAppMessage(message *appmessage.GetTransactionRequestMessage) error {
+	x.GetTransactionRequest = &GetTransactionRequestMessage{
+		TransactionId: message.TransactionID,
+	}
+	return nil
+}
+
+func (x *GetTransactionRequestMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetTransactionRequestMessage is nil")
+	}
+	return &appmessage.GetTransactionRequestMessage{
+		TransactionID: x.TransactionId,
+	}, nil
+}
+
+func (x *KaspadMessage_GetTransactionResponse) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "KaspadMessage_GetTransactionResponse is nil")
+	}
+	return x.GetTransactionResponse.toAppMessage()
+}
+
+func (x *KaspadMessage_GetTransactionResponse) fromAppMessage(message *appmessage.GetTransactionResponseMessage) error {
+	var err *RPCError
+	if message.Error != nil {
+		err = &RPCError{Message: message.Error.Message}
+	}
+	x.GetTransactionResponse = &GetTransactionResponseMessage{
+		IncludingBlockHash:     message.IncludingBlockHash,
+		AcceptingBlockHash:     message.AcceptingBlockHash,
+		AcceptingBlockDaaScore: message.AcceptingBlockDAAScore,
+		Error:                  err,
+	}
+	if message.Transaction != nil {
+		x.GetTransactionResponse.Transaction = &RpcTransaction{}
+		x.GetTransactionResponse.Transaction.fromAppMessage(message.Transaction)
+	}
+	return nil
+}
+
+func (x *GetTransactionResponseMessage) toAppMessage() (appmessage.Message, error) {
+	if x == nil {
+		return nil, errors.Wrapf(errorNil, "GetTransactionResponseMessage is nil")
+	}
+
+	if x.Error != nil {
+		rpcErr, err := x.Error.toAppMessage()
+		// Error is an optional field
+		if err != nil && !errors.Is(err, errorNil) {
+			return nil, err
+		}
+		return &appmessage.GetTransactionResponseMessage{
+			Error: rpcErr,
+		}, nil
+	}
+
+	var transaction *appmessage.RPCTransaction
+	if x.Transaction != nil {
+		var err error
+		transaction, err = x.Transaction.toAppMessage()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &appmessage.GetTransactionResponseMessage{
+		Transaction:            transaction,
+		IncludingBlockHash:     x.IncludingBlockHash,
+		AcceptingBlockHash:     x.AcceptingBlockHash,
+		AcceptingBlockDAAScore: x.AcceptingBlockDaaScore,
+	}, nil
+}
@@ -0,0 +1,271 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RPCSecurityConfig defines how the RPC server secures its connections
+type RPCSecurityConfig struct {
+	// EnableTLS enables TLS using the certificate and key in TLSCertFile and TLSKeyFile.
+	// If these files don't exist, a self-signed certificate is generated into them
+	EnableTLS   bool
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Credentials, if not empty, are the credentials clients must authenticate with
+	Credentials []*rpcauth.Credential
+
+	// AllowAnonymous allows clients that pass no credentials at all to connect
+	// without an identity, even if Credentials is not empty
+	AllowAnonymous bool
+}
+
+func (c *RPCSecurityConfig) isAuthenticationRequired() bool {
+	return len(c.Credentials) > 0
+}
+
+func rpcServerOptions(listeningAddresses []string, securityConfig *RPCSecurityConfig) ([]grpc.ServerOption, error) {
+	if securityConfig == nil {
+		return nil, nil
+	}
+
+	var serverOptions []grpc.ServerOption
+	tlsConfig, err := securityConfig.TLSConfig(listeningAddresses)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	if securityConfig.isAuthenticationRequired() {
+		if !securityConfig.EnableTLS {
+			log.Warnf("RPC authentication is enabled without TLS. Credentials are sent in plaintext")
+		}
+		serverOptions = append(serverOptions, grpc.StreamInterceptor(securityConfig.authenticateStream))
+	}
+
+	return serverOptions, nil
+}
+
+// TLSConfig returns the TLS configuration of RPC servers listening on the given addresses,
+// or nil if TLS is disabled
+func (c *RPCSecurityConfig) TLSConfig(listeningAddresses []string) (*tls.Config, error) {
+	if !c.EnableTLS {
+		return nil, nil
+	}
+	return loadTLSConfig(listeningAddresses, c.TLSCertFile, c.TLSKeyFile)
+}
+
+type identityContextKey struct{}
+
+// identityFromContext returns the identity the client of the given stream context
+// authenticated as, or an empty string if it did not authenticate
+func identityFromContext(ctx context.Context) string {
+	identity, _ := ctx.Value(identityContextKey{}).(string)
+	return identity
+}
+
+// authenticatedStream is a grpc.ServerStream whose context carries the identity of its client
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (c *RPCSecurityConfig) authenticateStream(server interface{}, stream grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	identity, err := c.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	ctx := context.WithValue(stream.Context(), identityContextKey{}, identity)
+	return handler(server, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate returns the identity of the credential the client of the given stream context
+// authenticated with
+func (c *RPCSecurityConfig) authenticate(ctx context.Context) (string, error) {
+	incomingMetadata, _ := metadata.FromIncomingContext(ctx)
+	identity, err := c.Authenticate(incomingMetadata.Get(rpcauth.AuthorizationMetadataKey))
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	return identity, nil
+}
+
+// Authenticate returns the identity of the credential matching the authorization values
+// an RPC client passed, or an empty string if the client is allowed to connect anonymously
+func (c *RPCSecurityConfig) Authenticate(authorizations []string) (string, error) {
+	if !c.isAuthenticationRequired() {
+		return "", nil
+	}
+	if len(authorizations) == 0 && c.AllowAnonymous {
+		return "", nil
+	}
+	if len(authorizations) != 1 {
+		return "", errors.New("missing RPC credentials")
+	}
+	authorization := authorizations[0]
+
+	if !rpcauth.IsSupportedAuthorization(authorization) {
+		return "", errors.New("unsupported RPC authorization scheme")
+	}
+
+	for _, credential := range c.Credentials {
+		if constantTimeEqual(authorization, credential.Authorization()) {
+			return credential.Identity, nil
+		}
+	}
+
+	return "", errors.New("invalid RPC credentials")
+}
+
+func constantTimeEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// loadTLSConfig loads the certificate and key in the given files,
+// generating a self-signed pair if neither exists
+func loadTLSConfig(listeningAddresses []string, certFile string, keyFile string) (*tls.Config, error) {
+	_, certFileErr := os.Stat(certFile)
+	_, keyFileErr := os.Stat(keyFile)
+	if os.IsNotExist(certFileErr) && os.IsNotExist(keyFileErr) {
+		err := generateTLSCertPair(listeningAddresses, certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error loading the RPC TLS certificate pair")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{keyPair},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func generateTLSCertPair(listeningAddresses []string, certFile string, keyFile string) error {
+	log.Infof("Generating a self-signed TLS certificate pair for the RPC server")
+
+	cert, key, err := newTLSCertPair(listeningAddresses)
+	if err != nil {
+		return errors.Wrapf(err, "error generating the RPC TLS certificate pair")
+	}
+
+	for _, file := range []string{certFile, keyFile} {
+		err := os.MkdirAll(filepath.Dir(file), 0700)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	err = os.WriteFile(certFile, cert, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = os.WriteFile(keyFile, key, 0600)
+	if err != nil {
+		os.Remove(certFile)
+		return errors.WithStack(err)
+	}
+
+	log.Infof("Done generating the RPC TLS certificate pair into %s and %s", certFile, keyFile)
+	return nil
+}
+
+// newTLSCertPair returns a new PEM-encoded self-signed certificate and key pair, valid for
+// localhost, the local interface addresses and the hosts of the given listening addresses
+func newTLSCertPair(listeningAddresses []string) (cert []byte, key []byte, err error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"kaspad autogenerated cert"},
+		},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	hostname, err := os.Hostname()
+	if err == nil && hostname != "localhost" {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+	interfaceAddresses, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, interfaceAddress := range interfaceAddresses {
+			ip, _, err := net.ParseCIDR(interfaceAddress.String())
+			if err == nil && !ip.IsLoopback() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		}
+	}
+	for _, listeningAddress := range listeningAddresses {
+		host, _, err := net.SplitHostPort(listeningAddress)
+		if err != nil || host == "" {
+			continue
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			template.DNSNames = append(template.DNSNames, host)
+		} else if !ip.IsUnspecified() && !ip.IsLoopback() {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		}
+	}
+
+	derCert, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	derKey, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derCert})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: derKey})
+	return cert, key, nil
+}
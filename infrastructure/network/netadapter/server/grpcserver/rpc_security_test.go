@@ -0,0 +1,108 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRPCSecurityConfigAuthenticate(t *testing.T) {
+	securityConfig := &RPCSecurityConfig{
+		Credentials: []*rpcauth.Credential{
+			{Identity: "user", User: "user", Password: "password"},
+			{Identity: "token", AuthToken: "token"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		authorizations   []string
+		expectedOK       bool
+		expectedIdentity string
+	}{
+		{name: "no credentials", authorizations: nil, expectedOK: false},
+		{name: "correct user and password", authorizations: []string{rpcauth.BasicAuthorization("user", "password")}, expectedOK: true, expectedIdentity: "user"},
+		{name: "wrong password", authorizations: []string{rpcauth.BasicAuthorization("user", "wrong")}, expectedOK: false},
+		{name: "correct token", authorizations: []string{rpcauth.TokenAuthorization("token")}, expectedOK: true, expectedIdentity: "token"},
+		{name: "wrong token", authorizations: []string{rpcauth.TokenAuthorization("wrong")}, expectedOK: false},
+		{name: "token passed as basic", authorizations: []string{"Basic token"}, expectedOK: false},
+		{name: "multiple credentials", authorizations: []string{rpcauth.TokenAuthorization("token"), rpcauth.TokenAuthorization("token")}, expectedOK: false},
+	}
+
+	for _, test := range tests {
+		incomingMetadata := metadata.MD{}
+		for _, authorization := range test.authorizations {
+			incomingMetadata.Append(rpcauth.AuthorizationMetadataKey, authorization)
+		}
+		ctx := metadata.NewIncomingContext(context.Background(), incomingMetadata)
+
+		identity, err := securityConfig.authenticate(ctx)
+		if test.expectedOK {
+			if err != nil {
+				t.Errorf("%s: unexpected authentication error: %s", test.name, err)
+			}
+			if identity != test.expectedIdentity {
+				t.Errorf("%s: expected identity %s but got %s", test.name, test.expectedIdentity, identity)
+			}
+			continue
+		}
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: expected an Unauthenticated error but got: %v", test.name, err)
+		}
+	}
+}
+
+func TestRPCSecurityConfigAuthenticateAnonymous(t *testing.T) {
+	securityConfig := &RPCSecurityConfig{
+		Credentials:    []*rpcauth.Credential{{Identity: "token", AuthToken: "token"}},
+		AllowAnonymous: true,
+	}
+
+	identity, err := securityConfig.authenticate(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected authentication error for an anonymous client: %s", err)
+	}
+	if identity != "" {
+		t.Fatalf("expected an anonymous client to have no identity but got %s", identity)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(rpcauth.AuthorizationMetadataKey, rpcauth.TokenAuthorization("wrong")))
+	_, err = securityConfig.authenticate(ctx)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected an Unauthenticated error for wrong credentials but got: %v", err)
+	}
+}
+
+func TestLoadTLSConfigGeneratesCertPair(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "rpc.cert")
+	keyFile := filepath.Join(dir, "rpc.key")
+
+	tlsConfig, err := loadTLSConfig([]string{"127.0.0.1:16110"}, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("loadTLSConfig: %s", err)
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("expected 1 certificate but got %d", len(tlsConfig.Certificates))
+	}
+
+	// A second call must load the generated pair rather than regenerate it
+	_, err = tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("LoadX509KeyPair: %s", err)
+	}
+	secondTLSConfig, err := loadTLSConfig([]string{"127.0.0.1:16110"}, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("loadTLSConfig: %s", err)
+	}
+	if string(secondTLSConfig.Certificates[0].Certificate[0]) != string(tlsConfig.Certificates[0].Certificate[0]) {
+		t.Fatalf("expected the existing certificate to be reused")
+	}
+}
@@ -15,8 +15,14 @@ type rpcServer struct {
 const RPCMaxMessageSize = 1024 * 1024 * 1024 // 1 GB
 
 // NewRPCServer creates a new RPCServer
-func NewRPCServer(listeningAddresses []string, rpcMaxInboundConnections int) (server.Server, error) {
-	gRPCServer := newGRPCServer(listeningAddresses, RPCMaxMessageSize, rpcMaxInboundConnections, "RPC")
+func NewRPCServer(listeningAddresses []string, rpcMaxInboundConnections int,
+	securityConfig *RPCSecurityConfig) (server.Server, error) {
+
+	serverOptions, err := rpcServerOptions(listeningAddresses, securityConfig)
+	if err != nil {
+		return nil, err
+	}
+	gRPCServer := newGRPCServer(listeningAddresses, RPCMaxMessageSize, rpcMaxInboundConnections, "RPC", serverOptions...)
 	rpcServer := &rpcServer{gRPCServer: *gRPCServer}
 	protowire.RegisterRPCServer(gRPCServer.server, rpcServer)
 	return rpcServer, nil
@@ -0,0 +1,265 @@
+package jsonrpcserver
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
+	"github.com/pkg/errors"
+)
+
+// pendingRequest is a request that was passed to the router and was not yet responded to
+type pendingRequest struct {
+	id      json.RawMessage
+	respond bool
+}
+
+// jsonRPCConnection is a server.Connection over which JSON-RPC requests are received
+// and JSON-RPC responses and notifications are sent. A WebSocket is a single long-lived
+// connection, while every HTTP request is a connection of its own.
+type jsonRPCConnection struct {
+	address      *net.TCPAddr
+	localAddress *net.TCPAddr
+	identity     string
+	router       *routerpkg.Router
+
+	// allowSubscriptions is whether the client can receive notifications
+	allowSubscriptions bool
+
+	// writeMessage writes a single JSON-RPC message to the client
+	writeMessage func(message []byte) error
+	writeLock    sync.Mutex
+
+	// close closes the underlying transport, if any
+	close func()
+
+	// pendingRequests are ordered by the order in which they were passed to the router.
+	// The RPC handlers of a single connection handle requests one at a time, so their
+	// responses are sent in that same order
+	pendingRequests     []*pendingRequest
+	pendingRequestsLock sync.Mutex
+
+	stopChan                chan struct{}
+	onDisconnectedHandler   server.OnDisconnectedHandler
+	onInvalidMessageHandler server.OnInvalidMessageHandler
+
+	isConnected uint32
+}
+
+func newConnection(address *net.TCPAddr, localAddress *net.TCPAddr, identity string, allowSubscriptions bool,
+	writeMessage func(message []byte) error, close func()) *jsonRPCConnection {
+
+	return &jsonRPCConnection{
+		address:            address,
+		localAddress:       localAddress,
+		identity:           identity,
+		allowSubscriptions: allowSubscriptions,
+		writeMessage:       writeMessage,
+		close:              close,
+		stopChan:           make(chan struct{}),
+		isConnected:        1,
+	}
+}
+
+func (c *jsonRPCConnection) Start(router *routerpkg.Router) {
+	if c.onDisconnectedHandler == nil {
+		panic(errors.New("onDisconnectedHandler is nil"))
+	}
+
+	c.router = router
+
+	spawn("jsonRPCConnection.Start-sendLoop", func() {
+		err := c.sendLoop()
+		if err != nil {
+			log.Errorf("Error from sendLoop for %s: %s", c, err)
+		}
+		c.Disconnect()
+	})
+}
+
+func (c *jsonRPCConnection) sendLoop() error {
+	outgoingRoute := c.router.OutgoingRoute()
+	for c.IsConnected() {
+		message, err := outgoingRoute.Dequeue()
+		if err != nil {
+			if errors.Is(err, routerpkg.ErrRouteClosed) {
+				return nil
+			}
+			return err
+		}
+
+		log.Debugf("outgoing '%s' message to %s", message.Command(), c)
+
+		encoded, err := encodeAppMessage(message)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(appmessage.RPCMessageCommandToString[message.Command()], "Notification") {
+			err = c.write(&notification{JSONRPC: jsonRPCVersion, Method: encoded.name, Params: encoded.body})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		pending, err := c.popPendingRequest()
+		if err != nil {
+			return err
+		}
+		if !pending.respond {
+			continue
+		}
+		res := &response{JSONRPC: jsonRPCVersion, ID: pending.id}
+		if encoded.rpcError != nil {
+			res.Error = encoded.rpcError
+		} else {
+			res.Result = encoded.body
+		}
+		err = c.write(res)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleRequest passes the given raw JSON-RPC request to the router. If the
+// request is invalid, an error response is written to the client immediately
+func (c *jsonRPCConnection) handleRequest(data []byte, alwaysRespond bool) error {
+	req, responseErr := parseRequest(data)
+	if responseErr != nil {
+		return c.writeError(req, responseErr)
+	}
+
+	message, responseErr := req.toAppMessage()
+	if responseErr != nil {
+		return c.writeError(req, responseErr)
+	}
+	if !c.allowSubscriptions && isSubscriptionCommand(message.Command()) {
+		return c.writeError(req, &responseError{
+			Code:    errorCodeInvalidRequest,
+			Message: "notifications are only available over WebSocket",
+		})
+	}
+
+	log.Debugf("incoming '%s' message from %s", message.Command(), c)
+
+	c.pushPendingRequest(&pendingRequest{id: req.ID, respond: req.hasID() || alwaysRespond})
+	err := c.router.EnqueueIncomingMessage(message)
+	if err != nil {
+		c.dropLastPendingRequest()
+		if errors.Is(err, routerpkg.ErrRouteClosed) {
+			return err
+		}
+		return c.writeError(req, &responseError{Code: errorCodeMethodNotFound, Message: "unsupported method " + req.Method})
+	}
+	return nil
+}
+
+func isSubscriptionCommand(command appmessage.MessageCommand) bool {
+	commandName := appmessage.RPCMessageCommandToString[command]
+	return strings.HasPrefix(commandName, "Notify") || strings.HasPrefix(commandName, "StopNotifying")
+}
+
+func (c *jsonRPCConnection) writeError(req *request, responseErr *responseError) error {
+	var id json.RawMessage
+	if req != nil {
+		id = req.ID
+	}
+	return c.write(&response{JSONRPC: jsonRPCVersion, ID: id, Error: responseErr})
+}
+
+func (c *jsonRPCConnection) write(message interface{}) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.writeMessage(messageBytes)
+}
+
+func (c *jsonRPCConnection) pushPendingRequest(pending *pendingRequest) {
+	c.pendingRequestsLock.Lock()
+	defer c.pendingRequestsLock.Unlock()
+
+	c.pendingRequests = append(c.pendingRequests, pending)
+}
+
+func (c *jsonRPCConnection) popPendingRequest() (*pendingRequest, error) {
+	c.pendingRequestsLock.Lock()
+	defer c.pendingRequestsLock.Unlock()
+
+	if len(c.pendingRequests) == 0 {
+		return nil, errors.New("got a response with no pending request")
+	}
+	pending := c.pendingRequests[0]
+	c.pendingRequests = c.pendingRequests[1:]
+	return pending, nil
+}
+
+func (c *jsonRPCConnection) dropLastPendingRequest() {
+	c.pendingRequestsLock.Lock()
+	defer c.pendingRequestsLock.Unlock()
+
+	c.pendingRequests = c.pendingRequests[:len(c.pendingRequests)-1]
+}
+
+func (c *jsonRPCConnection) String() string {
+	return c.address.String()
+}
+
+func (c *jsonRPCConnection) IsConnected() bool {
+	return atomic.LoadUint32(&c.isConnected) != 0
+}
+
+func (c *jsonRPCConnection) SetOnDisconnectedHandler(onDisconnectedHandler server.OnDisconnectedHandler) {
+	c.onDisconnectedHandler = onDisconnectedHandler
+}
+
+func (c *jsonRPCConnection) SetOnInvalidMessageHandler(onInvalidMessageHandler server.OnInvalidMessageHandler) {
+	c.onInvalidMessageHandler = onInvalidMessageHandler
+}
+
+func (c *jsonRPCConnection) IsOutbound() bool {
+	return false
+}
+
+// Disconnect disconnects the connection
+// Calling this function a second time doesn't do anything
+//
+// This is part of the Connection interface
+func (c *jsonRPCConnection) Disconnect() {
+	if !atomic.CompareAndSwapUint32(&c.isConnected, 1, 0) {
+		return
+	}
+
+	close(c.stopChan)
+	if c.close != nil {
+		c.close()
+	}
+
+	log.Debugf("Disconnecting from %s", c)
+	if c.onDisconnectedHandler != nil {
+		c.onDisconnectedHandler()
+	}
+}
+
+func (c *jsonRPCConnection) Address() *net.TCPAddr {
+	return c.address
+}
+
+func (c *jsonRPCConnection) LocalAddress() *net.TCPAddr {
+	return c.localAddress
+}
+
+func (c *jsonRPCConnection) Identity() string {
+	return c.identity
+}
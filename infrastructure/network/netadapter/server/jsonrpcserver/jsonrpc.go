@@ -0,0 +1,179 @@
+package jsonrpcserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const jsonRPCVersion = "2.0"
+
+// JSON-RPC 2.0 error codes
+const (
+	errorCodeParseError     = -32700
+	errorCodeInvalidRequest = -32600
+	errorCodeMethodNotFound = -32601
+	errorCodeInvalidParams  = -32602
+	errorCodeServerError    = -32000
+)
+
+// request is a JSON-RPC request. Its method is the protowire JSON name of an
+// RPC request message without the "Request" suffix (e.g. "getBlockDagInfo"),
+// and its params are that message's fields in protowire JSON
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// notification is a JSON-RPC notification. Its method is the protowire JSON name
+// of an RPC notification message (e.g. "blockAddedNotification")
+type notification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+var kaspadMessagePayload = (&protowire.KaspadMessage{}).ProtoReflect().Descriptor().Oneofs().ByName("payload")
+
+func parseRequest(data []byte) (*request, *responseError) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return nil, &responseError{Code: errorCodeInvalidRequest, Message: "batch requests are not supported"}
+	}
+
+	req := &request{}
+	err := json.Unmarshal(data, req)
+	if err != nil {
+		return nil, &responseError{Code: errorCodeParseError, Message: err.Error()}
+	}
+	if req.JSONRPC != jsonRPCVersion {
+		return req, &responseError{Code: errorCodeInvalidRequest, Message: "jsonrpc must be \"2.0\""}
+	}
+	if req.Method == "" {
+		return req, &responseError{Code: errorCodeInvalidRequest, Message: "missing method"}
+	}
+	return req, nil
+}
+
+// hasID returns whether the request expects a response
+func (r *request) hasID() bool {
+	return len(r.ID) > 0
+}
+
+// toAppMessage converts the given JSON-RPC request to the RPC request message it describes
+func (r *request) toAppMessage() (appmessage.Message, *responseError) {
+	field := kaspadMessagePayload.Fields().ByJSONName(r.Method + "Request")
+	if field == nil {
+		return nil, &responseError{Code: errorCodeMethodNotFound, Message: "unknown method " + r.Method}
+	}
+
+	params := r.Params
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		params = []byte("{}")
+	}
+	payloadJSON, err := json.Marshal(map[string]json.RawMessage{field.JSONName(): params})
+	if err != nil {
+		return nil, &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+	}
+
+	kaspadMessage := &protowire.KaspadMessage{}
+	err = protojson.Unmarshal(payloadJSON, kaspadMessage)
+	if err != nil {
+		return nil, &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+	}
+	message, err := kaspadMessage.ToAppMessage()
+	if err != nil {
+		return nil, &responseError{Code: errorCodeInvalidParams, Message: err.Error()}
+	}
+
+	commandName, ok := appmessage.RPCMessageCommandToString[message.Command()]
+	if !ok || !strings.HasSuffix(commandName, "Request") {
+		return nil, &responseError{Code: errorCodeMethodNotFound, Message: "unknown method " + r.Method}
+	}
+	return message, nil
+}
+
+// encodedMessage is an outgoing RPC message encoded in protowire JSON
+type encodedMessage struct {
+	name     string
+	body     json.RawMessage
+	rpcError *responseError
+}
+
+// encodeAppMessage encodes the given outgoing RPC message in protowire JSON.
+// If the message carries an RPC error, the error is returned separately and
+// omitted from the encoded body
+func encodeAppMessage(message appmessage.Message) (*encodedMessage, error) {
+	kaspadMessage, err := protowire.FromAppMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := kaspadMessage.ProtoReflect()
+	field := payload.WhichOneof(kaspadMessagePayload)
+	if field == nil {
+		return nil, errors.Errorf("message %s has no payload", message.Command())
+	}
+	inner := payload.Get(field).Message()
+
+	var rpcError *responseError
+	errorField := inner.Descriptor().Fields().ByName("error")
+	if errorField != nil && inner.Has(errorField) {
+		errorMessage := inner.Get(errorField).Message()
+		rpcError = &responseError{
+			Code:    errorCodeServerError,
+			Message: errorMessage.Get(errorMessage.Descriptor().Fields().ByName("message")).String(),
+		}
+		inner.Clear(errorField)
+	}
+
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(inner.Interface())
+	if err != nil {
+		return nil, err
+	}
+	if errorField != nil {
+		body, err = removeJSONField(body, errorField.JSONName())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &encodedMessage{name: field.JSONName(), body: body, rpcError: rpcError}, nil
+}
+
+// removeJSONField removes the given field from the given JSON object. It's
+// used to omit the error field, which EmitUnpopulated otherwise emits as null
+func removeJSONField(object []byte, fieldName string) ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	err := json.Unmarshal(object, &fields)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	delete(fields, fieldName)
+	objectWithoutField, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return objectWithoutField, nil
+}
@@ -0,0 +1,97 @@
+package jsonrpcserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name              string
+		data              string
+		expectedErrorCode int
+	}{
+		{name: "valid", data: `{"jsonrpc":"2.0","id":1,"method":"getInfo"}`},
+		{name: "invalid JSON", data: `{"jsonrpc":`, expectedErrorCode: errorCodeParseError},
+		{name: "batch", data: ` [{"jsonrpc":"2.0","id":1,"method":"getInfo"}]`, expectedErrorCode: errorCodeInvalidRequest},
+		{name: "wrong version", data: `{"jsonrpc":"1.0","id":1,"method":"getInfo"}`, expectedErrorCode: errorCodeInvalidRequest},
+		{name: "no method", data: `{"jsonrpc":"2.0","id":1}`, expectedErrorCode: errorCodeInvalidRequest},
+	}
+
+	for _, test := range tests {
+		_, responseErr := parseRequest([]byte(test.data))
+		if test.expectedErrorCode == 0 {
+			if responseErr != nil {
+				t.Errorf("%s: unexpected error: %s", test.name, responseErr)
+			}
+			continue
+		}
+		if responseErr == nil || responseErr.Code != test.expectedErrorCode {
+			t.Errorf("%s: expected error code %d, got: %+v", test.name, test.expectedErrorCode, responseErr)
+		}
+	}
+}
+
+func TestRequestToAppMessage(t *testing.T) {
+	req, responseErr := parseRequest([]byte(
+		`{"jsonrpc":"2.0","id":1,"method":"getBlock","params":{"hash":"abcd","includeTransactions":true}}`))
+	if responseErr != nil {
+		t.Fatalf("parseRequest: %s", responseErr)
+	}
+	message, responseErr := req.toAppMessage()
+	if responseErr != nil {
+		t.Fatalf("toAppMessage: %s", responseErr)
+	}
+	getBlockRequest, ok := message.(*appmessage.GetBlockRequestMessage)
+	if !ok {
+		t.Fatalf("expected a GetBlockRequestMessage, got %T", message)
+	}
+	if getBlockRequest.Hash != "abcd" || !getBlockRequest.IncludeTransactions {
+		t.Fatalf("unexpected request %+v", getBlockRequest)
+	}
+
+	// Methods must name RPC requests, and not other messages
+	for _, method := range []string{"noSuchMethod", "getBlockResponse", "blockAddedNotification", "version"} {
+		req := &request{JSONRPC: jsonRPCVersion, Method: method}
+		_, responseErr := req.toAppMessage()
+		if responseErr == nil || responseErr.Code != errorCodeMethodNotFound {
+			t.Errorf("method %s: expected a method not found error, got: %+v", method, responseErr)
+		}
+	}
+
+	req = &request{JSONRPC: jsonRPCVersion, Method: "getBlock", Params: json.RawMessage(`{"noSuchField":1}`)}
+	_, responseErr = req.toAppMessage()
+	if responseErr == nil || responseErr.Code != errorCodeInvalidParams {
+		t.Fatalf("expected an invalid params error, got: %+v", responseErr)
+	}
+}
+
+func TestEncodeAppMessage(t *testing.T) {
+	encoded, err := encodeAppMessage(&appmessage.GetBlockCountResponseMessage{BlockCount: 3, HeaderCount: 5})
+	if err != nil {
+		t.Fatalf("encodeAppMessage: %s", err)
+	}
+	if encoded.name != "getBlockCountResponse" || encoded.rpcError != nil {
+		t.Fatalf("unexpected encoded message %+v", encoded)
+	}
+	var result map[string]interface{}
+	err = json.Unmarshal(encoded.body, &result)
+	if err != nil {
+		t.Fatalf("unmarshal: %s", err)
+	}
+	if _, ok := result["error"]; ok {
+		t.Fatalf("expected the result to not contain an error field")
+	}
+
+	errorResponse := &appmessage.GetBlockCountResponseMessage{}
+	errorResponse.Error = appmessage.RPCErrorf("something went wrong")
+	encoded, err = encodeAppMessage(errorResponse)
+	if err != nil {
+		t.Fatalf("encodeAppMessage: %s", err)
+	}
+	if encoded.rpcError == nil || encoded.rpcError.Message != "something went wrong" {
+		t.Fatalf("expected the RPC error to be extracted, got %+v", encoded.rpcError)
+	}
+}
@@ -0,0 +1,382 @@
+package jsonrpcserver
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server"
+	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
+	"github.com/kaspanet/kaspad/util/panics"
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+// maxMessageSize is the max size of a single JSON-RPC request
+const maxMessageSize = appmessage.MaxMessagePayload
+
+// webSocketPath is the path on which WebSocket connections are accepted.
+// HTTP requests are accepted on any other path
+const webSocketPath = "/ws"
+
+// tokenQueryParameter is the query parameter in which WebSocket clients that
+// can't set the Authorization header (e.g. browsers) may pass a bearer token
+const tokenQueryParameter = "token"
+
+type jsonRPCServer struct {
+	listeningAddresses []string
+	securityConfig     *grpcserver.RPCSecurityConfig
+	tlsConfig          *tls.Config
+	allowedOrigins     map[string]struct{}
+	allowedHosts       map[string]struct{}
+	allowAnyIPHost     bool
+	maxWebSockets      int
+	requestSemaphore   chan struct{}
+	onConnectedHandler server.OnConnectedHandler
+	httpServers        []*http.Server
+	webSocketCount     int
+	webSocketCountLock sync.Mutex
+}
+
+// NewJSONRPCServer creates a new server that serves the node's RPC as JSON-RPC 2.0
+// over HTTP, and over WebSocket for clients that subscribe to notifications
+func NewJSONRPCServer(listeningAddresses []string, maxWebSockets int, maxConcurrentRequests int,
+	allowedOrigins []string, securityConfig *grpcserver.RPCSecurityConfig) (server.Server, error) {
+
+	tlsConfig, err := securityConfig.TLSConfig(listeningAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedOriginsSet := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowedOriginsSet[origin] = struct{}{}
+	}
+
+	allowedHosts, allowAnyIPHost, err := listeningAddressesAllowedHosts(listeningAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	// A limit of 0 means HTTP requests are not limited
+	var requestSemaphore chan struct{}
+	if maxConcurrentRequests > 0 {
+		requestSemaphore = make(chan struct{}, maxConcurrentRequests)
+	}
+
+	return &jsonRPCServer{
+		listeningAddresses: listeningAddresses,
+		securityConfig:     securityConfig,
+		tlsConfig:          tlsConfig,
+		allowedOrigins:     allowedOriginsSet,
+		allowedHosts:       allowedHosts,
+		allowAnyIPHost:     allowAnyIPHost,
+		maxWebSockets:      maxWebSockets,
+		requestSemaphore:   requestSemaphore,
+	}, nil
+}
+
+func (s *jsonRPCServer) Start() error {
+	if s.onConnectedHandler == nil {
+		return errors.New("onConnectedHandler is nil")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleHTTPRequest)
+	mux.HandleFunc(webSocketPath, s.handleWebSocket)
+
+	for _, listeningAddress := range s.listeningAddresses {
+		listener, err := net.Listen("tcp", listeningAddress)
+		if err != nil {
+			return errors.Wrapf(err, "JSON-RPC server failed listening on %s", listeningAddress)
+		}
+		if s.tlsConfig != nil {
+			listener = tls.NewListener(listener, s.tlsConfig)
+		}
+
+		httpServer := &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		s.httpServers = append(s.httpServers, httpServer)
+
+		spawn("jsonRPCServer.Start-Serve", func() {
+			err := httpServer.Serve(listener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				panics.Exit(log, errors.Wrapf(err, "JSON-RPC server failed serving on %s", listeningAddress).Error())
+			}
+		})
+
+		log.Infof("JSON-RPC server listening on %s", listeningAddress)
+	}
+	return nil
+}
+
+func (s *jsonRPCServer) Stop() error {
+	for _, httpServer := range s.httpServers {
+		err := httpServer.Close()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (s *jsonRPCServer) SetOnConnectedHandler(onConnectedHandler server.OnConnectedHandler) {
+	s.onConnectedHandler = onConnectedHandler
+}
+
+func (s *jsonRPCServer) handleHTTPRequest(w http.ResponseWriter, r *http.Request) {
+	if !s.checkHost(w, r) || !s.checkOrigin(w, r) {
+		return
+	}
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests must be sent using POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	identity, ok := s.authenticate(w, r, false)
+	if !ok {
+		return
+	}
+	address, localAddress, ok := requestAddresses(w, r)
+	if !ok {
+		return
+	}
+
+	if s.requestSemaphore != nil {
+		select {
+		case s.requestSemaphore <- struct{}{}:
+			defer func() { <-s.requestSemaphore }()
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	responseChan := make(chan []byte, 1)
+	writeMessage := func(message []byte) error {
+		responseChan <- message
+		return nil
+	}
+	connection := newConnection(address, localAddress, identity, false, writeMessage, nil)
+	err = s.onConnectedHandler(connection)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer connection.Disconnect()
+
+	err = connection.handleRequest(body, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	select {
+	case message := <-responseChan:
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write(message)
+		if err != nil {
+			log.Debugf("Error writing JSON-RPC response to %s: %s", address, err)
+		}
+	case <-connection.stopChan:
+		http.Error(w, "the request was not handled", http.StatusInternalServerError)
+	case <-r.Context().Done():
+	}
+}
+
+func (s *jsonRPCServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	if !s.checkHost(w, r) || !s.checkOrigin(w, r) {
+		return
+	}
+	identity, ok := s.authenticate(w, r, true)
+	if !ok {
+		return
+	}
+	address, localAddress, ok := requestAddresses(w, r)
+	if !ok {
+		return
+	}
+	if !s.incrementWebSocketCount() {
+		http.Error(w, "too many WebSocket connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.decrementWebSocketCount()
+
+	webSocketServer := websocket.Server{
+		// The origin was already checked above
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(webSocket *websocket.Conn) {
+			webSocket.MaxPayloadBytes = maxMessageSize
+			s.serveWebSocket(webSocket, address, localAddress, identity)
+		},
+	}
+	webSocketServer.ServeHTTP(w, r)
+}
+
+func (s *jsonRPCServer) serveWebSocket(webSocket *websocket.Conn, address *net.TCPAddr,
+	localAddress *net.TCPAddr, identity string) {
+
+	writeMessage := func(message []byte) error {
+		return websocket.Message.Send(webSocket, string(message))
+	}
+	closeWebSocket := func() {
+		webSocket.Close()
+	}
+	connection := newConnection(address, localAddress, identity, true, writeMessage, closeWebSocket)
+	err := s.onConnectedHandler(connection)
+	if err != nil {
+		log.Warnf("Error handling a new WebSocket connection from %s: %s", address, err)
+		webSocket.Close()
+		return
+	}
+	defer connection.Disconnect()
+
+	log.Infof("JSON-RPC WebSocket connection from %s", address)
+
+	for connection.IsConnected() {
+		var data []byte
+		err := websocket.Message.Receive(webSocket, &data)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && connection.IsConnected() {
+				log.Debugf("Error receiving from WebSocket %s: %s", address, err)
+			}
+			return
+		}
+		err = connection.handleRequest(data, false)
+		if err != nil {
+			log.Debugf("Error handling a JSON-RPC request from %s: %s", address, err)
+			return
+		}
+	}
+}
+
+// checkHost rejects requests whose Host header names a host the server doesn't listen on.
+// A web page whose domain is rebound to the address of the node sends the domain of the page
+// as its Host, so this keeps such pages from reaching the node through the browser
+func (s *jsonRPCServer) checkHost(w http.ResponseWriter, r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = strings.Trim(r.Host, "[]")
+	}
+
+	_, isAllowed := s.allowedHosts[strings.ToLower(host)]
+	isAllowedIP := s.allowAnyIPHost && net.ParseIP(host) != nil
+	if !isAllowed && !isAllowedIP {
+		http.Error(w, "host not allowed", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// checkOrigin rejects requests made by web pages of origins that aren't explicitly allowed,
+// so that pages on other sites can't use the browser to make requests to the node.
+// Requests that carry no Origin header don't come from a web page, and are allowed
+func (s *jsonRPCServer) checkOrigin(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	_, isAllowed := s.allowedOrigins[origin]
+	_, isAllAllowed := s.allowedOrigins["*"]
+	if !isAllowed && !isAllAllowed {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return false
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Add("Vary", "Origin")
+	return true
+}
+
+func (s *jsonRPCServer) authenticate(w http.ResponseWriter, r *http.Request, allowTokenQueryParameter bool) (string, bool) {
+	authorizations := r.Header.Values("Authorization")
+	if len(authorizations) == 0 && allowTokenQueryParameter {
+		token := r.URL.Query().Get(tokenQueryParameter)
+		if token != "" {
+			authorizations = []string{rpcauth.TokenAuthorization(token)}
+		}
+	}
+
+	identity, err := s.securityConfig.Authenticate(authorizations)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", false
+	}
+	return identity, true
+}
+
+// listeningAddressesAllowedHosts returns the hosts that requests may name in their Host header:
+// the localhost names and the hosts of the listening addresses. A listening address of an
+// unspecified host accepts connections on any address of the machine, so any IP is allowed then.
+// Other domain names are never allowed, since their owners could rebind them to the node
+func listeningAddressesAllowedHosts(listeningAddresses []string) (allowedHosts map[string]struct{},
+	allowAnyIPHost bool, err error) {
+
+	allowedHosts = map[string]struct{}{
+		"localhost": {},
+		"127.0.0.1": {},
+		"::1":       {},
+	}
+	for _, listeningAddress := range listeningAddresses {
+		host, _, err := net.SplitHostPort(listeningAddress)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "invalid JSON-RPC listening address %s", listeningAddress)
+		}
+		ip := net.ParseIP(host)
+		if host == "" || (ip != nil && ip.IsUnspecified()) {
+			allowAnyIPHost = true
+			continue
+		}
+		allowedHosts[strings.ToLower(host)] = struct{}{}
+	}
+	return allowedHosts, allowAnyIPHost, nil
+}
+
+func requestAddresses(w http.ResponseWriter, r *http.Request) (address *net.TCPAddr, localAddress *net.TCPAddr, ok bool) {
+	address, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	localAddress, _ = r.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr)
+	return address, localAddress, true
+}
+
+func (s *jsonRPCServer) incrementWebSocketCount() bool {
+	s.webSocketCountLock.Lock()
+	defer s.webSocketCountLock.Unlock()
+
+	if s.maxWebSockets > 0 && s.webSocketCount >= s.maxWebSockets {
+		log.Warnf("Limit of %d JSON-RPC WebSocket connections has been exceeded", s.maxWebSockets)
+		return false
+	}
+	s.webSocketCount++
+	return true
+}
+
+func (s *jsonRPCServer) decrementWebSocketCount() {
+	s.webSocketCountLock.Lock()
+	defer s.webSocketCountLock.Unlock()
+
+	s.webSocketCount--
+}
@@ -0,0 +1,115 @@
+package jsonrpcserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckHostAndOrigin(t *testing.T) {
+	tests := []struct {
+		name               string
+		listeningAddresses []string
+		allowedOrigins     []string
+		host               string
+		origin             string
+		expectedAllowed    bool
+	}{
+		{
+			name:               "localhost without origin",
+			listeningAddresses: []string{"127.0.0.1:16120"},
+			host:               "localhost:16120",
+			expectedAllowed:    true,
+		},
+		{
+			name:               "listening address without origin",
+			listeningAddresses: []string{"192.168.1.5:16120"},
+			host:               "192.168.1.5:16120",
+			expectedAllowed:    true,
+		},
+		{
+			name:               "other IP on a specific listening address",
+			listeningAddresses: []string{"192.168.1.5:16120"},
+			host:               "10.0.0.1:16120",
+			expectedAllowed:    false,
+		},
+		{
+			name:               "any IP on an unspecified listening address",
+			listeningAddresses: []string{"0.0.0.0:16120"},
+			host:               "10.0.0.1:16120",
+			expectedAllowed:    true,
+		},
+		{
+			name:               "IPv6 loopback",
+			listeningAddresses: []string{"[::1]:16120"},
+			host:               "[::1]:16120",
+			expectedAllowed:    true,
+		},
+		{
+			name:               "rebound domain on an unspecified listening address",
+			listeningAddresses: []string{":16120"},
+			host:               "evil.example:16120",
+			expectedAllowed:    false,
+		},
+		{
+			name:               "rebound domain with a matching origin",
+			listeningAddresses: []string{"127.0.0.1:16120"},
+			host:               "evil.example:16120",
+			origin:             "http://evil.example:16120",
+			expectedAllowed:    false,
+		},
+		{
+			name:               "same host origin that isn't explicitly allowed",
+			listeningAddresses: []string{"127.0.0.1:16120"},
+			host:               "127.0.0.1:16120",
+			origin:             "http://127.0.0.1:16120",
+			expectedAllowed:    false,
+		},
+		{
+			name:               "explicitly allowed origin",
+			listeningAddresses: []string{"127.0.0.1:16120"},
+			allowedOrigins:     []string{"https://explorer.example.com"},
+			host:               "127.0.0.1:16120",
+			origin:             "https://explorer.example.com",
+			expectedAllowed:    true,
+		},
+		{
+			name:               "any origin allowed",
+			listeningAddresses: []string{"127.0.0.1:16120"},
+			allowedOrigins:     []string{"*"},
+			host:               "localhost:16120",
+			origin:             "https://explorer.example.com",
+			expectedAllowed:    true,
+		},
+	}
+
+	for _, test := range tests {
+		allowedHosts, allowAnyIPHost, err := listeningAddressesAllowedHosts(test.listeningAddresses)
+		if err != nil {
+			t.Fatalf("%s: listeningAddressesAllowedHosts: %+v", test.name, err)
+		}
+		allowedOrigins := make(map[string]struct{})
+		for _, origin := range test.allowedOrigins {
+			allowedOrigins[origin] = struct{}{}
+		}
+		server := &jsonRPCServer{
+			allowedOrigins: allowedOrigins,
+			allowedHosts:   allowedHosts,
+			allowAnyIPHost: allowAnyIPHost,
+		}
+
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.Host = test.host
+		if test.origin != "" {
+			request.Header.Set("Origin", test.origin)
+		}
+		recorder := httptest.NewRecorder()
+		isAllowed := server.checkHost(recorder, request) && server.checkOrigin(recorder, request)
+		if isAllowed != test.expectedAllowed {
+			t.Errorf("%s: expected allowed to be %t but got %t", test.name, test.expectedAllowed, isAllowed)
+		}
+		if !isAllowed && recorder.Code != http.StatusForbidden {
+			t.Errorf("%s: expected status %d but got %d", test.name, http.StatusForbidden, recorder.Code)
+		}
+	}
+}
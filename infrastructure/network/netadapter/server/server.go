@@ -43,4 +43,12 @@ type Connection interface {
 	SetOnDisconnectedHandler(onDisconnectedHandler OnDisconnectedHandler)
 	SetOnInvalidMessageHandler(onInvalidMessageHandler OnInvalidMessageHandler)
 	Address() *net.TCPAddr
+
+	// LocalAddress returns the local address of an inbound connection,
+	// or nil if it's unknown
+	LocalAddress() *net.TCPAddr
+
+	// Identity returns the identity an inbound connection's client
+	// authenticated as, or an empty string if it did not authenticate
+	Identity() string
 }
@@ -0,0 +1,58 @@
+package rpcauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// ConnectOptions defines how a client secures its connection to the RPC server
+type ConnectOptions struct {
+	// UseTLS connects to the server over TLS. The server certificate is verified
+	// against CAFile if it's set, and against the system's root CAs otherwise
+	UseTLS bool
+	CAFile string
+
+	// User and Password, if set, are used to authenticate using basic authentication
+	User     string
+	Password string
+
+	// AuthToken, if set, is used to authenticate using a bearer token
+	AuthToken string
+}
+
+// TLSConfig returns the TLS configuration to connect to the RPC server with,
+// or nil if TLS is disabled
+func (o *ConnectOptions) TLSConfig() (*tls.Config, error) {
+	if !o.UseTLS {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if o.CAFile != "" {
+		caCert, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading the RPC CA file")
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf("no certificates found in RPC CA file %s", o.CAFile)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	return tlsConfig, nil
+}
+
+// Authorization returns the authorization metadata value to pass to the RPC server,
+// or an empty string if the client doesn't authenticate
+func (o *ConnectOptions) Authorization() string {
+	if o.AuthToken != "" {
+		return TokenAuthorization(o.AuthToken)
+	}
+	if o.User != "" {
+		return BasicAuthorization(o.User, o.Password)
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+// Package rpcauth holds the RPC authentication and TLS helpers that are
+// shared by the RPC server and its clients
+package rpcauth
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// AuthorizationMetadataKey is the gRPC metadata key in which RPC clients pass their credentials
+const AuthorizationMetadataKey = "authorization"
+
+const (
+	basicAuthorizationPrefix = "Basic "
+	tokenAuthorizationPrefix = "Bearer "
+)
+
+// BasicAuthorization returns the authorization metadata value for the given user and password
+func BasicAuthorization(user string, password string) string {
+	return basicAuthorizationPrefix + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// TokenAuthorization returns the authorization metadata value for the given authentication token
+func TokenAuthorization(authToken string) string {
+	return tokenAuthorizationPrefix + authToken
+}
+
+// IsSupportedAuthorization returns whether the given authorization metadata value
+// uses one of the supported authorization schemes
+func IsSupportedAuthorization(authorization string) bool {
+	return strings.HasPrefix(authorization, basicAuthorizationPrefix) ||
+		strings.HasPrefix(authorization, tokenAuthorizationPrefix)
+}
+
+// Credential is a credential RPC clients may authenticate with.
+// A credential has either a User and a Password, or an AuthToken
+type Credential struct {
+	// Identity names the clients that authenticate using this credential
+	Identity string
+
+	User     string
+	Password string
+
+	AuthToken string
+}
+
+// Authorization returns the authorization metadata value of the credential
+func (c *Credential) Authorization() string {
+	if c.AuthToken != "" {
+		return TokenAuthorization(c.AuthToken)
+	}
+	return BasicAuthorization(c.User, c.Password)
+}
@@ -0,0 +1,33 @@
+package rpcauth
+
+import "testing"
+
+func TestConnectOptionsAuthorization(t *testing.T) {
+	tests := []struct {
+		name                  string
+		connectOptions        *ConnectOptions
+		expectedAuthorization string
+	}{
+		{name: "no credentials", connectOptions: &ConnectOptions{}, expectedAuthorization: ""},
+		{
+			name:                  "user and password",
+			connectOptions:        &ConnectOptions{User: "user", Password: "password"},
+			expectedAuthorization: (&Credential{User: "user", Password: "password"}).Authorization(),
+		},
+		{
+			name:                  "token",
+			connectOptions:        &ConnectOptions{AuthToken: "token"},
+			expectedAuthorization: (&Credential{AuthToken: "token"}).Authorization(),
+		},
+	}
+
+	for _, test := range tests {
+		authorization := test.connectOptions.Authorization()
+		if authorization != test.expectedAuthorization {
+			t.Errorf("%s: expected authorization %q but got %q", test.name, test.expectedAuthorization, authorization)
+		}
+		if authorization != "" && !IsSupportedAuthorization(authorization) {
+			t.Errorf("%s: authorization %q uses an unsupported scheme", test.name, authorization)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package grpcclient
+
+import (
+	"context"
+
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func dialOptions(options *rpcauth.ConnectOptions) ([]grpc.DialOption, error) {
+	if options == nil {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+
+	var dialOptions []grpc.DialOption
+	tlsConfig, err := options.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	}
+
+	authorization := options.Authorization()
+	if authorization != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(&perRPCCredentials{
+			authorization:            authorization,
+			requireTransportSecurity: options.UseTLS,
+		}))
+	}
+
+	return dialOptions, nil
+}
+
+// perRPCCredentials attaches the RPC authorization metadata to every call
+type perRPCCredentials struct {
+	authorization            string
+	requireTransportSecurity bool
+}
+
+func (c *perRPCCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{rpcauth.AuthorizationMetadataKey: c.authorization}, nil
+}
+
+func (c *perRPCCredentials) RequireTransportSecurity() bool {
+	return c.requireTransportSecurity
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver"
 	"github.com/kaspanet/kaspad/infrastructure/network/netadapter/server/grpcserver/protowire"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/encoding/gzip"
@@ -29,11 +30,23 @@ type GRPCClient struct {
 
 // Connect connects to the RPC server with the given address
 func Connect(address string) (*GRPCClient, error) {
+	return ConnectWithOptions(address, nil)
+}
+
+// ConnectWithOptions connects to the RPC server with the given address,
+// securing the connection as defined in the given options. A nil options
+// connects without TLS or authentication
+func ConnectWithOptions(address string, options *rpcauth.ConnectOptions) (*GRPCClient, error) {
+	dialOptions, err := dialOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
 	const dialTimeout = 5 * time.Second
 	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
 	defer cancel()
 
-	gRPCConnection, err := grpc.DialContext(ctx, address, grpc.WithInsecure(), grpc.WithBlock())
+	gRPCConnection, err := grpc.DialContext(ctx, address, append(dialOptions, grpc.WithBlock())...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error connecting to %s", address)
 	}
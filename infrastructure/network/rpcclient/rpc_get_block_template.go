@@ -18,3 +18,24 @@ func (c *RPCClient) GetBlockTemplate(miningAddress, extraData string) (*appmessa
 	}
 	return getBlockTemplateResponse, nil
 }
+
+// GetBlockTemplateWithPayouts sends an RPC request respective to the function's name and returns the RPC server's response.
+// The coinbase of the returned template pays into miningAddress and commits to the given payouts, which the node's
+// payout ledger distributes the reward of a block built from the template to. See GetPayoutTransaction.
+func (c *RPCClient) GetBlockTemplateWithPayouts(miningAddress string, payouts []*appmessage.RPCBlockTemplatePayout,
+	extraData string) (*appmessage.GetBlockTemplateResponseMessage, error) {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetBlockTemplateWithPayoutsRequestMessage(miningAddress, payouts, extraData))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetBlockTemplateResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getBlockTemplateResponse := response.(*appmessage.GetBlockTemplateResponseMessage)
+	if getBlockTemplateResponse.Error != nil {
+		return nil, c.convertRPCError(getBlockTemplateResponse.Error)
+	}
+	return getBlockTemplateResponse, nil
+}
@@ -0,0 +1,22 @@
+package rpcclient
+
+import "github.com/kaspanet/kaspad/app/appmessage"
+
+// GetPayoutTransaction sends an RPC request respective to the function's name and returns the RPC server's response
+func (c *RPCClient) GetPayoutTransaction(payAddress string, feeRate float64) (
+	*appmessage.GetPayoutTransactionResponseMessage, error) {
+
+	err := c.rpcRouter.outgoingRoute().Enqueue(appmessage.NewGetPayoutTransactionRequestMessage(payAddress, feeRate))
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.route(appmessage.CmdGetPayoutTransactionResponseMessage).DequeueWithTimeout(c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	getPayoutTransactionResponse := response.(*appmessage.GetPayoutTransactionResponseMessage)
+	if getPayoutTransactionResponse.Error != nil {
+		return nil, c.convertRPCError(getPayoutTransactionResponse.Error)
+	}
+	return getPayoutTransactionResponse, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/kaspanet/kaspad/app/appmessage"
 	"github.com/kaspanet/kaspad/infrastructure/logger"
 	routerpkg "github.com/kaspanet/kaspad/infrastructure/network/netadapter/router"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
 	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient/grpcclient"
 	"github.com/kaspanet/kaspad/util/panics"
 	"github.com/kaspanet/kaspad/version"
@@ -20,20 +21,30 @@ type RPCClient struct {
 	*grpcclient.GRPCClient
 
 	rpcAddress           string
+	connectOptions       *rpcauth.ConnectOptions
 	rpcRouter            *rpcRouter
 	isConnected          uint32
 	isClosed             uint32
 	isReconnecting       uint32
 	lastDisconnectedTime time.Time
 
+	onReconnectedHandler func()
+
 	timeout time.Duration
 }
 
 // NewRPCClient сreates a new RPC client with a default call timeout value
 func NewRPCClient(rpcAddress string) (*RPCClient, error) {
+	return NewRPCClientWithOptions(rpcAddress, nil)
+}
+
+// NewRPCClientWithOptions creates a new RPC client with a default call timeout value,
+// securing its connection as defined in the given connect options
+func NewRPCClientWithOptions(rpcAddress string, connectOptions *rpcauth.ConnectOptions) (*RPCClient, error) {
 	rpcClient := &RPCClient{
-		rpcAddress: rpcAddress,
-		timeout:    defaultTimeout,
+		rpcAddress:     rpcAddress,
+		connectOptions: connectOptions,
+		timeout:        defaultTimeout,
 	}
 	err := rpcClient.connect()
 	if err != nil {
@@ -44,7 +55,7 @@ func NewRPCClient(rpcAddress string) (*RPCClient, error) {
 }
 
 func (c *RPCClient) connect() error {
-	rpcClient, err := grpcclient.Connect(c.rpcAddress)
+	rpcClient, err := grpcclient.ConnectWithOptions(c.rpcAddress, c.connectOptions)
 	if err != nil {
 		return errors.Wrapf(err, "error connecting to address %s", c.rpcAddress)
 	}
@@ -118,6 +129,9 @@ func (c *RPCClient) Reconnect() error {
 		if time.Since(c.lastDisconnectedTime) > retryDelay {
 			err := c.connect()
 			if err == nil {
+				if c.onReconnectedHandler != nil {
+					c.onReconnectedHandler()
+				}
 				return nil
 			}
 			log.Warnf("Could not automatically reconnect to %s: %s", c.rpcAddress, err)
@@ -150,6 +164,13 @@ func (c *RPCClient) handleClientError(err error) {
 	c.handleClientDisconnected()
 }
 
+// SetOnReconnectedHandler sets a handler that is called every time the client reconnects.
+// Note that notification registrations don't survive a reconnection, so this handler is
+// the place to renew them
+func (c *RPCClient) SetOnReconnectedHandler(onReconnectedHandler func()) {
+	c.onReconnectedHandler = onReconnectedHandler
+}
+
 // SetTimeout sets the timeout by which to wait for RPC responses
 func (c *RPCClient) SetTimeout(timeout time.Duration) {
 	c.timeout = timeout
@@ -22,6 +22,8 @@ const (
 	rpcAddress4 = "127.0.0.1:12348"
 	rpcAddress5 = "127.0.0.1:12349"
 
+	jsonRPCAddress1 = "127.0.0.1:12355"
+
 	miningAddress1           = "kaspasim:qqqqnc0pxg7qw3qkc7l6sge8kfhsvvyt7mkw8uamtndqup27ftnd6c769gn66"
 	miningAddress1PrivateKey = "0d81045b0deb2af36a25403c2154c87aa82d89dd337b575bae27ce7f5de53cee"
 
@@ -39,7 +41,14 @@ func setConfig(t *testing.T, harness *appHarness, protocolVersion uint32) {
 	harness.config.AppDir = randomDirectory(t)
 	harness.config.Listeners = []string{harness.p2pAddress}
 	harness.config.RPCListeners = []string{harness.rpcAddress}
+	if harness.jsonRPCAddress != "" {
+		harness.config.JSONRPCListeners = []string{harness.jsonRPCAddress}
+		// WebSocket clients always send an origin, so allow the one the tests connect from
+		harness.config.JSONRPCCORSOrigins = []string{"http://" + harness.jsonRPCAddress}
+	}
 	harness.config.UTXOIndex = harness.utxoIndex
+	harness.config.TxIndex = harness.txIndex
+	harness.config.RPCRoles = harness.rpcRoles
 	harness.config.AllowSubmitBlockWhenNotSynced = true
 	if protocolVersion != 0 {
 		harness.config.ProtocolVersion = protocolVersion
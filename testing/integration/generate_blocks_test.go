@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/transactionhelper"
+)
+
+func TestGenerateBlocks(t *testing.T) {
+	// Setup a single kaspad instance
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	// skip the first block because it's paying to genesis script
+	mineNextBlock(t, kaspad)
+	// use the second block to get money to pay with
+	secondBlock := mineNextBlock(t, kaspad)
+
+	// Generate BlockCoinbaseMaturity more blocks for our money to mature
+	maturity := uint32(kaspad.config.ActiveNetParams.BlockCoinbaseMaturity)
+	generateBlocksResponse, err := kaspad.rpcClient.GenerateBlocks(maturity, kaspad.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("Error generating blocks: %+v", err)
+	}
+	if uint32(len(generateBlocksResponse.BlockHashes)) != maturity {
+		t.Fatalf("Expected %d generated blocks, got %d", maturity, len(generateBlocksResponse.BlockHashes))
+	}
+	selectedTipHashResponse, err := kaspad.rpcClient.GetSelectedTipHash()
+	if err != nil {
+		t.Fatalf("Error getting selected tip hash: %+v", err)
+	}
+	lastBlockHash := generateBlocksResponse.BlockHashes[len(generateBlocksResponse.BlockHashes)-1]
+	if selectedTipHashResponse.SelectedTipHash != lastBlockHash {
+		t.Fatalf("Expected the selected tip to be the last generated block %s, got %s",
+			lastBlockHash, selectedTipHashResponse.SelectedTipHash)
+	}
+
+	msgTx := generateTx(t, secondBlock.Transactions[transactionhelper.CoinbaseTransactionIndex], kaspad, kaspad)
+	domainTransaction := appmessage.MsgTxToDomainTransaction(msgTx)
+	rpcTransaction := appmessage.DomainTransactionToRPCTransaction(domainTransaction)
+	submitTransactionResponse, err := kaspad.rpcClient.SubmitTransaction(rpcTransaction,
+		consensushashing.TransactionID(domainTransaction).String(), false)
+	if err != nil {
+		t.Fatalf("Error submitting transaction: %+v", err)
+	}
+	transactionID := submitTransactionResponse.TransactionID
+
+	// The chosen transaction is included in the first block only
+	generateBlocksResponse, err = kaspad.rpcClient.GenerateBlocks(2, kaspad.miningAddress, []string{transactionID})
+	if err != nil {
+		t.Fatalf("Error generating blocks: %+v", err)
+	}
+	for i, blockHash := range generateBlocksResponse.BlockHashes {
+		getBlockResponse, err := kaspad.rpcClient.GetBlock(blockHash, true)
+		if err != nil {
+			t.Fatalf("Error getting block %s: %+v", blockHash, err)
+		}
+		transactions := getBlockResponse.Block.Transactions
+		expectedTransactionCount := 1
+		if i == 0 {
+			expectedTransactionCount = 2
+		}
+		if len(transactions) != expectedTransactionCount {
+			t.Fatalf("Expected block %d to have %d transactions, got %d", i, expectedTransactionCount, len(transactions))
+		}
+		if i == 0 && transactions[1].VerboseData.TransactionID != transactionID {
+			t.Fatalf("Expected block %d to include transaction %s, got %s",
+				i, transactionID, transactions[1].VerboseData.TransactionID)
+		}
+	}
+
+	// The transaction was removed from the mempool when its block was added
+	_, err = kaspad.rpcClient.GenerateBlocks(1, kaspad.miningAddress, []string{transactionID})
+	if err == nil {
+		t.Fatalf("Expected generating a block with a transaction that isn't in the mempool to fail")
+	}
+	_, err = kaspad.rpcClient.GenerateBlocks(0, kaspad.miningAddress, nil)
+	if err == nil {
+		t.Fatalf("Expected generating zero blocks to fail")
+	}
+}
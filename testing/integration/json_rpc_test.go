@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+type jsonRPCMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Params json.RawMessage `json:"params"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestJSONRPC(t *testing.T) {
+	kaspad, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		jsonRPCAddress:          jsonRPCAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+	})
+	defer teardown()
+
+	httpURL := "http://" + kaspad.jsonRPCAddress
+	response := postJSONRPC(t, httpURL, `{"jsonrpc":"2.0","id":1,"method":"getBlockDagInfo"}`)
+	if response.Error != nil {
+		t.Fatalf("Error getting the block DAG info: %s", response.Error.Message)
+	}
+	var blockDAGInfo struct {
+		NetworkName string `json:"networkName"`
+	}
+	err := json.Unmarshal(response.Result, &blockDAGInfo)
+	if err != nil {
+		t.Fatalf("Error decoding the block DAG info: %s", err)
+	}
+	if blockDAGInfo.NetworkName != kaspad.config.NetParams().Name {
+		t.Fatalf("Unexpected network name %s", blockDAGInfo.NetworkName)
+	}
+
+	response = postJSONRPC(t, httpURL, `{"jsonrpc":"2.0","id":2,"method":"noSuchMethod"}`)
+	if response.Error == nil {
+		t.Fatalf("Expected an error for an unknown method")
+	}
+	response = postJSONRPC(t, httpURL, `{"jsonrpc":"2.0","id":3,"method":"notifyBlockAdded"}`)
+	if response.Error == nil {
+		t.Fatalf("Expected an error for subscribing to notifications over HTTP")
+	}
+
+	// Web pages of origins that weren't allowed can't connect
+	_, err = websocket.Dial("ws://"+kaspad.jsonRPCAddress+"/ws", "", "http://example.com")
+	if err == nil {
+		t.Fatalf("Expected connecting from an origin that isn't allowed to fail")
+	}
+
+	webSocket, err := websocket.Dial("ws://"+kaspad.jsonRPCAddress+"/ws", "", httpURL)
+	if err != nil {
+		t.Fatalf("Error connecting over WebSocket: %s", err)
+	}
+	defer webSocket.Close()
+
+	err = websocket.Message.Send(webSocket, `{"jsonrpc":"2.0","id":"subscribe","method":"notifyBlockAdded"}`)
+	if err != nil {
+		t.Fatalf("Error sending over WebSocket: %s", err)
+	}
+	message := receiveJSONRPC(t, webSocket)
+	if string(message.ID) != `"subscribe"` || message.Error != nil {
+		t.Fatalf("Unexpected response to notifyBlockAdded: %+v", message)
+	}
+
+	mineNextBlock(t, kaspad)
+
+	message = receiveJSONRPC(t, webSocket)
+	if message.Method != "blockAddedNotification" {
+		t.Fatalf("Expected a blockAddedNotification, got: %+v", message)
+	}
+}
+
+func postJSONRPC(t *testing.T, url string, body string) *jsonRPCMessage {
+	httpResponse, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Error posting a JSON-RPC request: %s", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected HTTP status %s", httpResponse.Status)
+	}
+	response := &jsonRPCMessage{}
+	err = json.NewDecoder(httpResponse.Body).Decode(response)
+	if err != nil {
+		t.Fatalf("Error decoding a JSON-RPC response: %s", err)
+	}
+	return response
+}
+
+func receiveJSONRPC(t *testing.T, webSocket *websocket.Conn) *jsonRPCMessage {
+	err := webSocket.SetReadDeadline(time.Now().Add(defaultTimeout))
+	if err != nil {
+		t.Fatalf("Error setting a WebSocket deadline: %s", err)
+	}
+	var data []byte
+	err = websocket.Message.Receive(webSocket, &data)
+	if err != nil {
+		t.Fatalf("Error receiving over WebSocket: %s", err)
+	}
+	message := &jsonRPCMessage{}
+	err = json.Unmarshal(data, message)
+	if err != nil {
+		t.Fatalf("Error decoding a JSON-RPC message: %s", err)
+	}
+	return message
+}
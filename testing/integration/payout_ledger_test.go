@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"testing"
+
+	"github.com/kaspanet/go-secp256k1"
+	"github.com/kaspanet/kaspad/app/appmessage"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/mining"
+	"github.com/kaspanet/kaspad/domain/consensus/utils/txscript"
+)
+
+func TestPayoutLedger(t *testing.T) {
+	// Setup a single kaspad instance
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		utxoIndex:               true,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	_, err := kaspad.rpcClient.GetPayoutTransaction(kaspad.miningAddress, 0)
+	if err == nil {
+		t.Fatalf("Expected getting a payout transaction without payouts to fail")
+	}
+
+	// Mine blocks from templates with payouts for the mining address. Their coinbases still pay into it
+	payouts := []*appmessage.RPCBlockTemplatePayout{
+		{Address: miningAddress2, Weight: 3},
+		{Address: miningAddress3, Weight: 1},
+	}
+	_, err = kaspad.rpcClient.GetBlockTemplateWithPayouts(kaspad.miningAddress,
+		[]*appmessage.RPCBlockTemplatePayout{{Address: miningAddress2, Weight: 0}}, "")
+	if err == nil {
+		t.Fatalf("Expected getting a block template with a zero weight payout to fail")
+	}
+	const blocksWithPayouts = 10
+	for i := 0; i < blocksWithPayouts; i++ {
+		blockTemplate, err := kaspad.rpcClient.GetBlockTemplateWithPayouts(kaspad.miningAddress, payouts, "")
+		if err != nil {
+			t.Fatalf("Error getting block template: %+v", err)
+		}
+		block, err := appmessage.RPCBlockToDomainBlock(blockTemplate.Block)
+		if err != nil {
+			t.Fatalf("Error converting block: %s", err)
+		}
+		mining.SolveBlock(block, rand.New(rand.NewSource(int64(i))))
+		_, err = kaspad.rpcClient.SubmitBlockAlsoIfNonDAA(block)
+		if err != nil {
+			t.Fatalf("Error submitting block: %s", err)
+		}
+	}
+
+	// Another client can't replace the payouts of the mining address, until the client that
+	// registered them asks for a template without payouts
+	otherClient, err := newTestRPCClient(kaspad.rpcAddress)
+	if err != nil {
+		t.Fatalf("Error creating RPC client: %+v", err)
+	}
+	defer otherClient.Close()
+	otherPayouts := []*appmessage.RPCBlockTemplatePayout{{Address: miningAddress2, Weight: 1}}
+	_, err = otherClient.GetBlockTemplateWithPayouts(kaspad.miningAddress, otherPayouts, "")
+	if err == nil {
+		t.Fatalf("Expected replacing the payouts of another client to fail")
+	}
+	_, err = kaspad.rpcClient.GetBlockTemplate(kaspad.miningAddress, "")
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+	_, err = otherClient.GetBlockTemplateWithPayouts(kaspad.miningAddress, otherPayouts, "")
+	if err != nil {
+		t.Fatalf("Error getting block template: %+v", err)
+	}
+
+	// Generate enough blocks for the rewards of the blocks with payouts to mature. The rewards
+	// of the generated blocks themselves aren't credited, since they don't commit to any payouts
+	maturity := uint32(kaspad.config.ActiveNetParams.BlockCoinbaseMaturity)
+	_, err = kaspad.rpcClient.GenerateBlocks(maturity+blocksWithPayouts, kaspad.miningAddress, nil)
+	if err != nil {
+		t.Fatalf("Error generating blocks: %+v", err)
+	}
+
+	getPayoutTransactionResponse, err := kaspad.rpcClient.GetPayoutTransaction(kaspad.miningAddress, 0)
+	if err != nil {
+		t.Fatalf("Error getting payout transaction: %+v", err)
+	}
+	rpcTransaction := getPayoutTransactionResponse.Transaction
+	if len(rpcTransaction.Inputs) == 0 || len(rpcTransaction.Inputs) != len(getPayoutTransactionResponse.UTXOEntries) {
+		t.Fatalf("Expected a UTXO entry for each of the %d inputs, got %d",
+			len(rpcTransaction.Inputs), len(getPayoutTransactionResponse.UTXOEntries))
+	}
+	if len(rpcTransaction.Inputs) > blocksWithPayouts {
+		t.Fatalf("Expected only the rewards of the %d blocks with payouts to be paid out, got %d inputs",
+			blocksWithPayouts, len(rpcTransaction.Inputs))
+	}
+	if len(rpcTransaction.Outputs) != len(payouts) {
+		t.Fatalf("Expected %d outputs, got %d", len(payouts), len(rpcTransaction.Outputs))
+	}
+
+	totalInputAmount := uint64(0)
+	for _, utxoEntry := range getPayoutTransactionResponse.UTXOEntries {
+		if !utxoEntry.IsCoinbase {
+			t.Fatalf("Expected the payout transaction to spend coinbase UTXOs only")
+		}
+		totalInputAmount += utxoEntry.Amount
+	}
+	totalOutputAmount := uint64(0)
+	for _, output := range rpcTransaction.Outputs {
+		totalOutputAmount += output.Amount
+	}
+	if totalOutputAmount >= totalInputAmount {
+		t.Fatalf("Expected the payout transaction to pay a fee")
+	}
+	// The fee is deducted from the payouts in proportion to their amounts
+	if ratio := float64(rpcTransaction.Outputs[0].Amount) / float64(rpcTransaction.Outputs[1].Amount); ratio < 2.99 || ratio > 3.01 {
+		t.Fatalf("Expected the payouts to be split 3:1, got a ratio of %f", ratio)
+	}
+
+	// Sign the payout transaction with the key of the mining address and submit it
+	domainTransaction, err := appmessage.RPCTransactionToDomainTransaction(rpcTransaction)
+	if err != nil {
+		t.Fatalf("Error converting the payout transaction: %+v", err)
+	}
+	for i, rpcUTXOEntry := range getPayoutTransactionResponse.UTXOEntries {
+		domainTransaction.Inputs[i].UTXOEntry, err = appmessage.RPCUTXOEntryToUTXOEntry(rpcUTXOEntry)
+		if err != nil {
+			t.Fatalf("Error converting UTXO entry: %+v", err)
+		}
+	}
+	privateKeyBytes, err := hex.DecodeString(kaspad.miningAddressPrivateKey)
+	if err != nil {
+		t.Fatalf("Error decoding private key: %+v", err)
+	}
+	privateKey, err := secp256k1.DeserializeSchnorrPrivateKeyFromSlice(privateKeyBytes)
+	if err != nil {
+		t.Fatalf("Error deserializing private key: %+v", err)
+	}
+	sighashReusedValues := &consensushashing.SighashReusedValues{}
+	for i, input := range domainTransaction.Inputs {
+		input.SignatureScript, err = txscript.SignatureScript(domainTransaction, i, consensushashing.SigHashAll,
+			privateKey, sighashReusedValues)
+		if err != nil {
+			t.Fatalf("Error signing transaction: %+v", err)
+		}
+	}
+	submitTransactionResponse, err := kaspad.rpcClient.SubmitTransaction(
+		appmessage.DomainTransactionToRPCTransaction(domainTransaction),
+		consensushashing.TransactionID(domainTransaction).String(), false)
+	if err != nil {
+		t.Fatalf("Error submitting the payout transaction: %+v", err)
+	}
+	_, err = kaspad.rpcClient.GenerateBlocks(2, kaspad.miningAddress, []string{submitTransactionResponse.TransactionID})
+	if err != nil {
+		t.Fatalf("Error generating blocks: %+v", err)
+	}
+
+	// The rewards spent by the accepted payout transaction were all the credited ones
+	_, err = kaspad.rpcClient.GetPayoutTransaction(kaspad.miningAddress, 0)
+	if err == nil {
+		t.Fatalf("Expected the spent rewards not to be paid out again")
+	}
+}
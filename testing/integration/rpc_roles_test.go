@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/infrastructure/config"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcauth"
+	"github.com/kaspanet/kaspad/infrastructure/network/rpcclient"
+)
+
+func TestRPCRoles(t *testing.T) {
+	kaspad, teardown := setupHarness(t, &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		rpcRoles: &config.RPCRolesConfig{
+			Credentials: []*config.RPCRoleCredential{
+				{Name: "miner", User: "miner", Password: "secret", Roles: []string{"mining"}},
+			},
+			DefaultRoles: []string{"read-only"},
+		},
+	})
+	defer teardown()
+
+	// The harness's client doesn't authenticate, so it's granted the default read-only role
+	_, err := kaspad.rpcClient.GetBlockDAGInfo()
+	if err != nil {
+		t.Fatalf("Error getting the block DAG info: %s", err)
+	}
+	_, err = kaspad.rpcClient.GetBlockTemplate(kaspad.miningAddress, "")
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("Expected GetBlockTemplate to be denied to an anonymous client, got: %v", err)
+	}
+
+	minerClient, err := rpcclient.NewRPCClientWithOptions(kaspad.rpcAddress,
+		&rpcauth.ConnectOptions{User: "miner", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Error connecting as the miner: %s", err)
+	}
+	defer minerClient.Close()
+	minerClient.SetTimeout(rpcTimeout)
+
+	_, err = minerClient.GetBlockTemplate(kaspad.miningAddress, "")
+	if err != nil {
+		t.Fatalf("Error getting a block template as the miner: %s", err)
+	}
+	_, err = minerClient.GetBlockDAGInfo()
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("Expected GetBlockDAGInfo to be denied to the miner, got: %v", err)
+	}
+}
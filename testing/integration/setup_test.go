@@ -19,20 +19,26 @@ type appHarness struct {
 	rpcClient               *testRPCClient
 	p2pAddress              string
 	rpcAddress              string
+	jsonRPCAddress          string
 	miningAddress           string
 	miningAddressPrivateKey string
 	config                  *config.Config
 	database                database.Database
 	utxoIndex               bool
+	txIndex                 bool
+	rpcRoles                *config.RPCRolesConfig
 	overrideDAGParams       *dagconfig.Params
 }
 
 type harnessParams struct {
 	p2pAddress              string
 	rpcAddress              string
+	jsonRPCAddress          string
 	miningAddress           string
 	miningAddressPrivateKey string
 	utxoIndex               bool
+	txIndex                 bool
+	rpcRoles                *config.RPCRolesConfig
 	overrideDAGParams       *dagconfig.Params
 	protocolVersion         uint32
 }
@@ -42,9 +48,12 @@ func setupHarness(t *testing.T, params *harnessParams) (harness *appHarness, tea
 	harness = &appHarness{
 		p2pAddress:              params.p2pAddress,
 		rpcAddress:              params.rpcAddress,
+		jsonRPCAddress:          params.jsonRPCAddress,
 		miningAddress:           params.miningAddress,
 		miningAddressPrivateKey: params.miningAddressPrivateKey,
 		utxoIndex:               params.utxoIndex,
+		txIndex:                 params.txIndex,
+		rpcRoles:                params.rpcRoles,
 		overrideDAGParams:       params.overrideDAGParams,
 	}
 
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kaspanet/kaspad/domain/consensus/utils/consensushashing"
+)
+
+func TestTXIndex(t *testing.T) {
+	// Setup a single kaspad instance
+	harnessParams := &harnessParams{
+		p2pAddress:              p2pAddress1,
+		rpcAddress:              rpcAddress1,
+		miningAddress:           miningAddress1,
+		miningAddressPrivateKey: miningAddress1PrivateKey,
+		txIndex:                 true,
+	}
+	kaspad, teardown := setupHarness(t, harnessParams)
+	defer teardown()
+
+	// Mine two blocks. The coinbase transaction of the first block
+	// is accepted by the second one, which is its selected child
+	includingBlock := mineNextBlock(t, kaspad)
+	acceptingBlock := mineNextBlock(t, kaspad)
+
+	coinbaseTransactionID := consensushashing.TransactionID(includingBlock.Transactions[0])
+	getTransactionResponse, err := kaspad.rpcClient.GetTransaction(coinbaseTransactionID.String())
+	if err != nil {
+		t.Fatalf("Error getting transaction %s: %s", coinbaseTransactionID, err)
+	}
+
+	includingBlockHash := consensushashing.BlockHash(includingBlock)
+	if getTransactionResponse.IncludingBlockHash != includingBlockHash.String() {
+		t.Fatalf("Unexpected including block hash. Want: %s, got: %s",
+			includingBlockHash, getTransactionResponse.IncludingBlockHash)
+	}
+	acceptingBlockHash := consensushashing.BlockHash(acceptingBlock)
+	if getTransactionResponse.AcceptingBlockHash != acceptingBlockHash.String() {
+		t.Fatalf("Unexpected accepting block hash. Want: %s, got: %s",
+			acceptingBlockHash, getTransactionResponse.AcceptingBlockHash)
+	}
+	if getTransactionResponse.AcceptingBlockDAAScore != acceptingBlock.Header.DAAScore() {
+		t.Fatalf("Unexpected accepting block DAA score. Want: %d, got: %d",
+			acceptingBlock.Header.DAAScore(), getTransactionResponse.AcceptingBlockDAAScore)
+	}
+	if getTransactionResponse.Transaction == nil ||
+		getTransactionResponse.Transaction.VerboseData.TransactionID != coinbaseTransactionID.String() {
+		t.Fatalf("Unexpected transaction: %+v", getTransactionResponse.Transaction)
+	}
+
+	// The coinbase transaction of the second block has not been accepted yet
+	unacceptedTransactionID := consensushashing.TransactionID(acceptingBlock.Transactions[0])
+	_, err = kaspad.rpcClient.GetTransaction(unacceptedTransactionID.String())
+	if err == nil {
+		t.Fatalf("Expected getting transaction %s to fail", unacceptedTransactionID)
+	}
+	if !strings.Contains(err.Error(), "was not found") {
+		t.Fatalf("Unexpected error getting transaction %s: %s", unacceptedTransactionID, err)
+	}
+}
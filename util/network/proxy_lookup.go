@@ -0,0 +1,155 @@
+package network
+
+import (
+	"io"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone             = 0x00
+	socks5AuthUsernamePassword = 0x02
+	socks5AuthNoAcceptable     = 0xff
+	socks5AuthSubVersion       = 0x01
+
+	// socks5CommandResolve is the Tor extension to SOCKS5 that resolves a host name
+	// through the proxy. See https://spec.torproject.org/socks-extensions.html
+	socks5CommandResolve = 0xf0
+
+	socks5AddressIPv4       = 0x01
+	socks5AddressDomainName = 0x03
+	socks5AddressIPv6       = 0x04
+
+	socks5Succeeded = 0x00
+)
+
+// proxyLookupTimeout is the timeout of a whole host name resolution through a proxy
+const proxyLookupTimeout = 30 * time.Second
+
+var socks5ReplyErrors = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// ProxyLookupIP resolves the given host name through the SOCKS5 proxy at proxyAddress,
+// so that the lookup doesn't leak to the local DNS resolver. The proxy must support
+// Tor's RESOLVE extension.
+func ProxyLookupIP(host string, proxyAddress string, user string, password string) ([]net.IP, error) {
+	if len(host) > 255 {
+		return nil, errors.Errorf("host name %s is too long", host)
+	}
+
+	connection, err := net.DialTimeout("tcp", proxyAddress, proxyLookupTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to proxy %s", proxyAddress)
+	}
+	defer connection.Close()
+
+	err = connection.SetDeadline(time.Now().Add(proxyLookupTimeout))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = socks5Authenticate(connection, user, password)
+	if err != nil {
+		return nil, err
+	}
+
+	request := []byte{socks5Version, socks5CommandResolve, 0x00, socks5AddressDomainName, byte(len(host))}
+	request = append(request, host...)
+	request = append(request, 0x00, 0x00) // The port is unused
+	_, err = connection.Write(request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(connection, reply)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading resolve reply from proxy")
+	}
+	if reply[0] != socks5Version {
+		return nil, errors.Errorf("invalid proxy SOCKS version %d", reply[0])
+	}
+	if reply[1] != socks5Succeeded {
+		message, ok := socks5ReplyErrors[reply[1]]
+		if !ok {
+			message = "unknown SOCKS error"
+		}
+		return nil, errors.Errorf("proxy failed resolving %s: %s", host, message)
+	}
+
+	var ip net.IP
+	switch reply[3] {
+	case socks5AddressIPv4:
+		ip = make(net.IP, net.IPv4len)
+	case socks5AddressIPv6:
+		ip = make(net.IP, net.IPv6len)
+	default:
+		return nil, errors.Errorf("proxy returned unsupported address type %d", reply[3])
+	}
+	_, err = io.ReadFull(connection, ip)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading resolved address from proxy")
+	}
+
+	return []net.IP{ip}, nil
+}
+
+// socks5Authenticate performs the SOCKS5 method negotiation, and the username/password
+// authentication (RFC 1929) if a user is given
+func socks5Authenticate(connection net.Conn, user string, password string) error {
+	method := byte(socks5AuthNone)
+	if user != "" {
+		if len(user) > 255 || len(password) > 255 {
+			return errors.New("proxy user and password must be at most 255 bytes long")
+		}
+		method = socks5AuthUsernamePassword
+	}
+
+	_, err := connection.Write([]byte{socks5Version, 1, method})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	reply := make([]byte, 2)
+	_, err = io.ReadFull(connection, reply)
+	if err != nil {
+		return errors.Wrapf(err, "error reading method reply from proxy")
+	}
+	if reply[0] != socks5Version {
+		return errors.Errorf("invalid proxy SOCKS version %d", reply[0])
+	}
+	if reply[1] == socks5AuthNoAcceptable || reply[1] != method {
+		return errors.New("proxy rejected the authentication method")
+	}
+	if method == socks5AuthNone {
+		return nil
+	}
+
+	request := []byte{socks5AuthSubVersion, byte(len(user))}
+	request = append(request, user...)
+	request = append(request, byte(len(password)))
+	request = append(request, password...)
+	_, err = connection.Write(request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.ReadFull(connection, reply)
+	if err != nil {
+		return errors.Wrapf(err, "error reading authentication reply from proxy")
+	}
+	if reply[1] != socks5Succeeded {
+		return errors.New("proxy rejected the user and password")
+	}
+	return nil
+}
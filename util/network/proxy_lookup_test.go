@@ -0,0 +1,107 @@
+package network
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+// serveSOCKS5Resolve accepts a single connection on the given listener and answers a
+// Tor RESOLVE request with the given IP, after checking the given user and password
+func serveSOCKS5Resolve(t *testing.T, listener net.Listener, user string, password string, ip net.IP) {
+	connection, err := listener.Accept()
+	if err != nil {
+		t.Errorf("Accept: %s", err)
+		return
+	}
+	defer connection.Close()
+
+	read := func(n int) []byte {
+		buffer := make([]byte, n)
+		_, err := io.ReadFull(connection, buffer)
+		if err != nil {
+			t.Errorf("Read: %s", err)
+		}
+		return buffer
+	}
+
+	greeting := read(3)
+	if user == "" {
+		if !bytes.Equal(greeting, []byte{socks5Version, 1, socks5AuthNone}) {
+			t.Errorf("unexpected greeting %x", greeting)
+		}
+		connection.Write([]byte{socks5Version, socks5AuthNone})
+	} else {
+		if !bytes.Equal(greeting, []byte{socks5Version, 1, socks5AuthUsernamePassword}) {
+			t.Errorf("unexpected greeting %x", greeting)
+		}
+		connection.Write([]byte{socks5Version, socks5AuthUsernamePassword})
+		header := read(2)
+		receivedUser := string(read(int(header[1])))
+		receivedPassword := string(read(int(read(1)[0])))
+		if receivedUser != user || receivedPassword != password {
+			connection.Write([]byte{socks5AuthSubVersion, 0x01})
+			return
+		}
+		connection.Write([]byte{socks5AuthSubVersion, socks5Succeeded})
+	}
+
+	header := read(5)
+	if header[1] != socks5CommandResolve || header[3] != socks5AddressDomainName {
+		t.Errorf("unexpected request header %x", header)
+	}
+	read(int(header[4]) + 2)
+
+	reply := []byte{socks5Version, socks5Succeeded, 0x00, socks5AddressIPv4}
+	reply = append(reply, ip.To4()...)
+	reply = append(reply, 0x00, 0x00)
+	connection.Write(reply)
+}
+
+func TestProxyLookupIP(t *testing.T) {
+	tests := []struct {
+		name          string
+		proxyUser     string
+		proxyPassword string
+		clientUser    string
+		clientPass    string
+		expectedError bool
+	}{
+		{name: "no authentication"},
+		{name: "user and password", proxyUser: "user", proxyPassword: "pass", clientUser: "user", clientPass: "pass"},
+		{name: "wrong password", proxyUser: "user", proxyPassword: "pass", clientUser: "user", clientPass: "wrong",
+			expectedError: true},
+	}
+
+	expectedIP := net.ParseIP("1.2.3.4")
+	for _, test := range tests {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen: %s", err)
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			serveSOCKS5Resolve(t, listener, test.proxyUser, test.proxyPassword, expectedIP)
+		}()
+
+		ips, err := ProxyLookupIP("seeder.example.com", listener.Addr().String(), test.clientUser, test.clientPass)
+		<-done
+		listener.Close()
+
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: ProxyLookupIP: %s", test.name, err)
+			continue
+		}
+		if len(ips) != 1 || !ips[0].Equal(expectedIP) {
+			t.Errorf("%s: expected %s, got %s", test.name, expectedIP, ips)
+		}
+	}
+}
@@ -35,6 +35,10 @@ func (c *Calculator) MassPerScriptPubKeyByte() uint64 { return c.massPerScriptPu
 // MassPerSigOp returns the mass per SigOp byte configured for this Calculator
 func (c *Calculator) MassPerSigOp() uint64 { return c.massPerSigOp }
 
+// StorageMassParameter returns the parameter for scaling inverse KAS value to mass units (KIP-0009)
+// configured for this Calculator
+func (c *Calculator) StorageMassParameter() uint64 { return c.storageMassParameter }
+
 // CalculateTransactionMass calculates the mass of the given transaction
 func (c *Calculator) CalculateTransactionMass(transaction *externalapi.DomainTransaction) uint64 {
 	if transactionhelper.IsCoinBase(transaction) {